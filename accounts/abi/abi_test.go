@@ -713,3 +713,30 @@ func TestABI_MethodById(t *testing.T) {
 	}
 
 }
+
+func TestUnpackRevert(t *testing.T) {
+	var cases = []struct {
+		input     string
+		expect    string
+		expectErr bool
+	}{
+		{"", "", true},
+		{"08c379a1", "", true},
+		{"08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000", "revert reason", false},
+	}
+	for index, c := range cases {
+		got, err := UnpackRevert(common.Hex2Bytes(c.input))
+		if c.expectErr {
+			if err == nil {
+				t.Fatalf("case %d: expected error", index)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", index, err)
+		}
+		if c.expect != got {
+			t.Fatalf("case %d: reason mismatch: have %q, want %q", index, got, c.expect)
+		}
+	}
+}
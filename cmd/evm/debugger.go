@@ -0,0 +1,174 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of go-vsc.
+//
+// go-vsc is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vsc is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vsc. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/core/vm"
+)
+
+const debuggerHelp = `Commands:
+  step, s                        execute a single operation
+  continue, c [pc|op|depth <v>]  run until the breakpoint hits or the execution ends
+  stack                          print the stack, top item last
+  memory [offset size]           print the memory
+  storage <key>                  print a storage slot of the current contract
+  quit, q                        abort the execution
+`
+
+// runDebugger executes fn under the step debugger, reading commands from the
+// input and printing the state of the paused execution to the output, until
+// the execution finishes or is aborted.
+func runDebugger(debugger *vm.Debugger, fn func() error, in io.Reader, out io.Writer) error {
+	step, err := debugger.Start(fn)
+	defer debugger.Stop()
+
+	scanner, moved := bufio.NewScanner(in), true
+	for err == nil {
+		// Print the step the execution moved to and wait for the next command
+		if moved {
+			printStep(out, step)
+			moved = false
+		}
+		fmt.Fprint(out, "> ")
+
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case "step", "s":
+			step, err = debugger.Step()
+			moved = true
+
+		case "continue", "c":
+			breakpoint, perr := parseBreakpoint(args[1:])
+			if perr != nil {
+				fmt.Fprintln(out, perr)
+				continue
+			}
+			step, err = debugger.Continue(breakpoint)
+			moved = true
+
+		case "stack":
+			for i, item := range step.Stack {
+				fmt.Fprintf(out, "%04d: %x\n", i, math.PaddedBigBytes(item, 32))
+			}
+
+		case "memory":
+			offset, size := 0, len(step.Memory)
+			if len(args) == 3 {
+				o, oerr := strconv.Atoi(args[1])
+				s, serr := strconv.Atoi(args[2])
+				if oerr != nil || serr != nil || o < 0 || s < 0 {
+					fmt.Fprintln(out, "invalid memory range")
+					continue
+				}
+				offset, size = o, s
+			}
+			if offset > len(step.Memory) {
+				offset = len(step.Memory)
+			}
+			if size > len(step.Memory)-offset {
+				size = len(step.Memory) - offset
+			}
+			for i := offset; i < offset+size; i += 32 {
+				end := i + 32
+				if end > offset+size {
+					end = offset + size
+				}
+				fmt.Fprintf(out, "%04x: %x\n", i, step.Memory[i:end])
+			}
+
+		case "storage":
+			if len(args) != 2 {
+				fmt.Fprintln(out, "usage: storage <key>")
+				continue
+			}
+			value, serr := debugger.Storage(common.HexToHash(args[1]))
+			if serr != nil {
+				fmt.Fprintln(out, serr)
+				continue
+			}
+			fmt.Fprintf(out, "%x\n", value)
+
+		case "quit", "q":
+			return nil
+
+		default:
+			fmt.Fprint(out, debuggerHelp)
+		}
+	}
+	if err == vm.ErrDebuggerFinished {
+		return nil
+	}
+	return err
+}
+
+// parseBreakpoint converts the arguments of a continue command into a debugger
+// breakpoint. Without arguments, the execution is run to completion.
+func parseBreakpoint(args []string) (vm.Breakpoint, error) {
+	if len(args) == 0 {
+		return func(uint64, vm.OpCode, int) bool { return false }, nil
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("usage: continue [pc|op|depth <value>]")
+	}
+	switch args[0] {
+	case "pc":
+		pc, err := strconv.ParseUint(args[1], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pc: %v", err)
+		}
+		return vm.PcBreakpoint(pc), nil
+
+	case "op":
+		name := strings.ToUpper(args[1])
+		op := vm.StringToOp(name)
+		if op == vm.STOP && name != "STOP" {
+			return nil, fmt.Errorf("unknown opcode %q", args[1])
+		}
+		return vm.OpBreakpoint(op), nil
+
+	case "depth":
+		depth, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid depth: %v", err)
+		}
+		return vm.DepthBreakpoint(depth), nil
+	}
+	return nil, fmt.Errorf("unknown breakpoint %q", args[0])
+}
+
+// printStep prints a one line summary of the operation the execution is paused at.
+func printStep(out io.Writer, step *vm.StructLog) {
+	fmt.Fprintf(out, "pc=%08d op=%-10v gas=%v cost=%v depth=%d", step.Pc, step.Op, step.Gas, step.GasCost, step.Depth)
+	if step.Err != nil {
+		fmt.Fprintf(out, " error=%v", step.Err)
+	}
+	fmt.Fprintln(out)
+}
@@ -110,6 +110,10 @@ var (
 		Name:  "nostack",
 		Usage: "disable stack output",
 	}
+	DebuggerFlag = cli.BoolFlag{
+		Name:  "debugger",
+		Usage: "step through the execution interactively, reading commands from stdin",
+	}
 )
 
 func init() {
@@ -133,6 +137,7 @@ func init() {
 		ReceiverFlag,
 		DisableMemoryFlag,
 		DisableStackFlag,
+		DebuggerFlag,
 	}
 	app.Commands = []cli.Command{
 		compileCommand,
@@ -81,6 +81,7 @@ func runCmd(ctx *cli.Context) error {
 
 	var (
 		tracer      vm.Tracer
+		debugger    *vm.Debugger
 		debugLogger *vm.StructLogger
 		statedb     *state.StateDB
 		chainConfig *params.ChainConfig
@@ -88,7 +89,10 @@ func runCmd(ctx *cli.Context) error {
 		receiver    = common.BytesToAddress([]byte("receiver"))
 		blockNumber uint64
 	)
-	if ctx.GlobalBool(MachineFlag.Name) {
+	if ctx.GlobalBool(DebuggerFlag.Name) {
+		debugger = vm.NewDebugger()
+		tracer = debugger
+	} else if ctx.GlobalBool(MachineFlag.Name) {
 		tracer = NewJSONLogger(logconfig, os.Stdout)
 	} else if ctx.GlobalBool(DebugFlag.Name) {
 		debugLogger = vm.NewStructLogger(logconfig)
@@ -100,11 +104,11 @@ func runCmd(ctx *cli.Context) error {
 		gen := readGenesis(ctx.GlobalString(GenesisFlag.Name))
 		db := ethdb.NewMemDatabase()
 		genesis := gen.ToBlock(db)
-		statedb, _ = state.New(genesis.Root(), state.NewDatabase(db))
+		statedb, _ = state.New(genesis.Root(), state.NewDatabase(db), nil)
 		chainConfig = gen.Config
 		blockNumber = gen.Number
 	} else {
-		statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	}
 	if ctx.GlobalString(SenderFlag.Name) != "" {
 		sender = common.HexToAddress(ctx.GlobalString(SenderFlag.Name))
@@ -165,7 +169,7 @@ func runCmd(ctx *cli.Context) error {
 		BlockNumber: new(big.Int).SetUint64(blockNumber),
 		EVMConfig: vm.Config{
 			Tracer: tracer,
-			Debug:  ctx.GlobalBool(DebugFlag.Name) || ctx.GlobalBool(MachineFlag.Name),
+			Debug:  ctx.GlobalBool(DebugFlag.Name) || ctx.GlobalBool(MachineFlag.Name) || debugger != nil,
 		},
 	}
 
@@ -187,14 +191,24 @@ func runCmd(ctx *cli.Context) error {
 	}
 	tstart := time.Now()
 	var leftOverGas uint64
-	if ctx.GlobalBool(CreateFlag.Name) {
-		input := append(code, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name))...)
-		ret, _, leftOverGas, err = runtime.Create(input, &runtimeConfig)
-	} else {
-		if len(code) > 0 {
-			statedb.SetCode(receiver, code)
+	execute := func() error {
+		if ctx.GlobalBool(CreateFlag.Name) {
+			input := append(code, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name))...)
+			ret, _, leftOverGas, err = runtime.Create(input, &runtimeConfig)
+		} else {
+			if len(code) > 0 {
+				statedb.SetCode(receiver, code)
+			}
+			ret, leftOverGas, err = runtime.Call(receiver, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name)), &runtimeConfig)
 		}
-		ret, leftOverGas, err = runtime.Call(receiver, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name)), &runtimeConfig)
+		return nil
+	}
+	if debugger != nil {
+		if derr := runDebugger(debugger, execute, os.Stdin, os.Stdout); derr != nil {
+			return derr
+		}
+	} else {
+		execute()
 	}
 	execTime := time.Since(tstart)
 
@@ -237,7 +251,7 @@ Gas used:           %d
 
 `, execTime, mem.HeapObjects, mem.Alloc, mem.TotalAlloc, mem.NumGC, initialGas-leftOverGas)
 	}
-	if tracer == nil {
+	if tracer == nil || debugger != nil {
 		fmt.Printf("0x%x\n", ret)
 		if err != nil {
 			fmt.Printf(" error: %v\n", err)
@@ -30,13 +30,13 @@ import (
 	"github.com/vsportchain/go-vsc/console"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/state/pruner"
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/eth/downloader"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/event"
 	"github.com/vsportchain/go-vsc/log"
 	"github.com/vsportchain/go-vsc/trie"
-	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -48,6 +48,8 @@ var (
 		ArgsUsage: "<genesisPath>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
@@ -65,6 +67,8 @@ It expects the genesis file as argument.`,
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 			utils.GCModeFlag,
@@ -86,6 +90,8 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -103,6 +109,8 @@ if already existing.`,
 		ArgsUsage: "<datafile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -117,6 +125,8 @@ if already existing.`,
 		ArgsUsage: "<dumpfile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -131,6 +141,8 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		ArgsUsage: "<sourceChaindataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
@@ -148,6 +160,8 @@ The first argument must be the directory containing the blockchain to download f
 		ArgsUsage: " ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
@@ -161,6 +175,8 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -169,6 +185,30 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "vsportchain dump 0" to dump the genesis block.`,
 	}
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Prune stale state data from the database",
+		ArgsUsage: "[<root>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
+			utils.CacheFlag,
+			utils.TestnetFlag,
+			utils.RinkebyFlag,
+			utils.BloomFilterSizeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command deletes all the historical state data except the state
+of the given root, or of the most recent block with its state on disk if none
+is specified. The genesis state is always retained.
+
+This is an offline operation, the node must be stopped while it is running. If
+it's interrupted after the pruning started, it is resumed automatically on the
+next node or prune-state startup.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -192,7 +232,15 @@ func initGenesis(ctx *cli.Context) error {
 	// Open an initialise both full and light databases
 	stack := makeFullNode(ctx)
 	for _, name := range []string{"chaindata", "lightchaindata"} {
-		chaindb, err := stack.OpenDatabase(name, 0, 0)
+		var (
+			chaindb ethdb.Database
+			err     error
+		)
+		if name == "chaindata" {
+			chaindb, err = stack.OpenDatabaseWithFreezer(name, 0, 0, ctx.GlobalString(utils.AncientFlag.Name), "")
+		} else {
+			chaindb, err = stack.OpenDatabase(name, 0, 0)
+		}
 		if err != nil {
 			utils.Fatalf("Failed to open database: %v", err)
 		}
@@ -246,19 +294,7 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*ethdb.LDBDatabase)
-
-	stats, err := db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
-
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
-	}
-	fmt.Println(ioStats)
+	showDatabaseStats(chainDb)
 
 	fmt.Printf("Trie cache misses:  %d\n", trie.CacheMisses())
 	fmt.Printf("Trie cache unloads: %d\n\n", trie.CacheUnloads())
@@ -279,24 +315,28 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err := chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
+	showDatabaseStats(chainDb)
+	return nil
+}
 
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
+// showDatabaseStats prints the internal statistics of the database, whichever
+// the backing engine is.
+func showDatabaseStats(db ethdb.Database) {
+	var found bool
+	for _, property := range []string{"leveldb.stats", "leveldb.iostats", "logdb.stats"} {
+		if stats, err := db.Stat(property); err == nil {
+			fmt.Println(stats)
+			found = true
+		}
+	}
+	if !found {
+		log.Warn("Failed to read database stats")
 	}
-	fmt.Println(ioStats)
-
-	return nil
 }
 
 func exportChain(ctx *cli.Context) error {
@@ -337,7 +377,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -353,7 +393,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -376,7 +416,7 @@ func copyDb(ctx *cli.Context) error {
 	dl := downloader.New(syncmode, chainDb, new(event.TypeMux), chain, nil, nil)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := ethdb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	db, err := ethdb.NewDiskDatabase("", ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
 	if err != nil {
 		return err
 	}
@@ -403,7 +443,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*ethdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -414,7 +454,12 @@ func copyDb(ctx *cli.Context) error {
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
-	for _, name := range []string{"chaindata", "lightchaindata"} {
+	names := []string{"chaindata", "lightchaindata"}
+	if ancient := ctx.GlobalString(utils.AncientFlag.Name); ancient != "" {
+		// The freezer lives outside of chaindata, remove it separately
+		names = append(names, ancient)
+	}
+	for _, name := range names {
 		// Ensure the database exists in the first place
 		logger := log.New("database", name)
 
@@ -455,7 +500,7 @@ func dump(ctx *cli.Context) error {
 			fmt.Println("{}")
 			utils.Fatalf("block not found")
 		} else {
-			state, err := state.New(block.Root(), state.NewDatabase(chainDb))
+			state, err := state.New(block.Root(), state.NewDatabase(chainDb), nil)
 			if err != nil {
 				utils.Fatalf("could not create new state: %v", err)
 			}
@@ -466,6 +511,31 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func pruneState(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command accepts at most one argument: the target state root")
+	}
+	var root common.Hash
+	if len(ctx.Args()) == 1 {
+		if !hashish(ctx.Args()[0]) {
+			utils.Fatalf("Invalid state root: %s", ctx.Args()[0])
+		}
+		root = common.HexToHash(ctx.Args()[0])
+	}
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	prune, err := pruner.NewPruner(chainDb, stack.ResolvePath(""), ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to open state pruner: %v", err)
+	}
+	if err := prune.Prune(root); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
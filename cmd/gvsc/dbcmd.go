@@ -0,0 +1,128 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of go-vsc.
+//
+// go-vsc is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vsc is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vsc. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/vsportchain/go-vsc/cmd/utils"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbVerifyStateFlag = cli.BoolFlag{
+		Name:  "state",
+		Usage: "Iterate the entire head state trie to ensure all its nodes are reachable",
+	}
+	dbCommand = cli.Command{
+		Name:      "db",
+		Usage:     "Low level database operations",
+		ArgsUsage: "",
+		Category:  "DATABASE COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(inspectDatabase),
+				Name:      "inspect",
+				Usage:     "Inspect the storage size for each type of data in the database",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.DBEngineFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+				},
+				Category: "DATABASE COMMANDS",
+				Description: `
+The inspect command iterates over the entire database and prints the number of
+entries and their total size for each data category of the database schema, as
+well as for each ancient table, as a JSON array.`,
+			},
+			{
+				Action:    utils.MigrateFlags(verifyDatabase),
+				Name:      "verify",
+				Usage:     "Verify the consistency of the chain data in the database",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.DBEngineFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					dbVerifyStateFlag,
+				},
+				Category: "DATABASE COMMANDS",
+				Description: `
+The verify command walks the canonical chain from genesis to the head header and
+cross-checks the stored chain data: the canonical hash and hash to number
+mappings, the header hashes and parent links, the transaction and receipt roots
+and blooms against the bodies and receipts, the total difficulty continuity and
+the transaction lookup entries. It also checks that the state root of the head
+block is present, iterating the entire state trie if --state is specified.
+
+Every inconsistency found is printed as a JSON object on its own line. The command
+exits with a non-zero status if any inconsistency was found.`,
+			},
+		},
+	}
+)
+
+// inspectDatabase prints the per-category size breakdown of the chain database.
+func inspectDatabase(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	stats, err := rawdb.InspectDatabase(chainDb)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	return out.Encode(stats)
+}
+
+// verifyDatabase cross-checks the canonical chain data in the chain database,
+// printing every inconsistency found.
+func verifyDatabase(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	var (
+		out    = json.NewEncoder(os.Stdout)
+		issues int
+	)
+	err := core.VerifyChain(chainDb, ctx.Bool(dbVerifyStateFlag.Name), func(issue *core.ChainIssue) {
+		out.Encode(issue)
+		issues++
+	})
+	if err != nil {
+		utils.Fatalf("Failed to verify database: %v", err)
+	}
+	if issues > 0 {
+		utils.Fatalf("Found %d inconsistencies in the database", issues)
+	}
+	return nil
+}
@@ -60,6 +60,9 @@ func (w *wizard) makeGenesis() {
 		genesis.Config.Ethash = new(params.EthashConfig)
 		genesis.ExtraData = make([]byte, 32)
 
+		// Proof-of-work chains may also mint new coins with every block
+		genesis.Config.Reward = w.makeReward()
+
 	case choice == "" || choice == "2":
 		// In the case of clique, configure the consensus parameters
 		genesis.Difficulty = big.NewInt(1)
@@ -130,6 +133,43 @@ func (w *wizard) makeGenesis() {
 	w.conf.flush()
 }
 
+// makeReward queries the user for the issuance schedule of the block rewards,
+// returning nil if no rewards should be paid at all.
+func (w *wizard) makeReward() *params.RewardConfig {
+	fmt.Println()
+	fmt.Println("How many wei should be rewarded for mining a block? (default = 0, no rewards)")
+	reward := w.readDefaultBigInt(new(big.Int))
+	if reward.Sign() <= 0 {
+		return nil
+	}
+	config := &params.RewardConfig{
+		Block:       new(big.Int),
+		BlockReward: reward,
+	}
+	fmt.Println()
+	fmt.Println("After how many blocks should the reward halve? (default = 0, never)")
+	config.HalvingInterval = uint64(w.readDefaultInt(0))
+
+	fmt.Println()
+	fmt.Println("Should uncles and their inclusion be rewarded (y/n)? (default = yes)")
+	config.UncleRewards = w.readDefaultString("y") == "y"
+
+	fmt.Println()
+	fmt.Println("Which account should receive a treasury share of the rewards? (default = none)")
+	if config.Treasury = w.readAddress(); config.Treasury != nil {
+		for {
+			fmt.Println()
+			fmt.Println("What percentage of the rewards should go to the treasury? (default = 10)")
+			if share := w.readDefaultInt(10); share >= 0 && share <= 100 {
+				config.TreasuryShare = uint64(share)
+				break
+			}
+			log.Error("Invalid treasury share, expected percentage between 0 and 100")
+		}
+	}
+	return config
+}
+
 // manageGenesis permits the modification of chain configuration parameters in
 // a genesis config and the export of the entire genesis spec.
 func (w *wizard) manageGenesis() {
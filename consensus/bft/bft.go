@@ -0,0 +1,872 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bft implements a byzantine fault tolerant consensus engine with
+// instant finality, in the style of Istanbul BFT.
+//
+// Blocks are agreed on by a set of validators in rounds of pre-prepare, prepare
+// and commit messages exchanged over a dedicated network protocol. A block is
+// final once more than two thirds of the validators committed to it, their
+// committed seals being embedded into the header's extra-data.
+package bft
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/vsportchain/go-vsc/accounts"
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/consensus/misc"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/params"
+	"github.com/vsportchain/go-vsc/rlp"
+	"github.com/vsportchain/go-vsc/rpc"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
+	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+	inmemoryMessages   = 4096 // Number of recent consensus messages to remember to avoid relaying loops
+)
+
+// BFT protocol constants.
+var (
+	epochLength    = uint64(30000) // Default number of blocks after which to checkpoint and reset the pending votes
+	requestTimeout = uint64(10000) // Default number of milliseconds to wait for a round to commit
+
+	nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Magic nonce number to vote on adding a new validator
+	nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Magic nonce number to vote on removing a validator.
+
+	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
+
+	defaultDifficulty = big.NewInt(1) // Block difficulty, meaningless with instant finality
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of validators is requested for a
+	// block that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidCheckpointBeneficiary is returned if a checkpoint/epoch transition
+	// block has a beneficiary set to non-zeroes.
+	errInvalidCheckpointBeneficiary = errors.New("beneficiary in checkpoint block non-zero")
+
+	// errInvalidVote is returned if a nonce value is something else that the two
+	// allowed constants of 0x00..0 or 0xff..f.
+	errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
+
+	// errInvalidCheckpointVote is returned if a checkpoint/epoch transition block
+	// has a vote nonce set to non-zeroes.
+	errInvalidCheckpointVote = errors.New("vote nonce in checkpoint block non-zero")
+
+	// errInvalidExtraDataFormat is returned if the extra-data of a block can't be
+	// decoded into a vanity prefix and the BFT consensus fields.
+	errInvalidExtraDataFormat = errors.New("invalid extra-data format")
+
+	// errExtraValidators is returned if non-checkpoint block contain validator
+	// data in their extra-data fields.
+	errExtraValidators = errors.New("non-checkpoint block contains extra validator list")
+
+	// errInvalidCheckpointValidators is returned if a checkpoint block contains a
+	// list of validators different from the one of the local snapshot.
+	errInvalidCheckpointValidators = errors.New("invalid validator list on checkpoint block")
+
+	// errInvalidMixDigest is returned if a block's mix digest is not the BFT one.
+	errInvalidMixDigest = errors.New("invalid mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not 1.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// ErrInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errInvalidVotingChain is returned if an authorization list is attempted to
+	// be modified via out-of-range or non-contiguous headers.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errInvalidSignature is returned if the proposer seal of a block is missing
+	// or malformed.
+	errInvalidSignature = errors.New("invalid proposer seal")
+
+	// errUnauthorized is returned if a header is proposed by a non-validator.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errInvalidCommittedSeals is returned if a committed seal of a block is
+	// malformed, not signed by a validator or duplicated.
+	errInvalidCommittedSeals = errors.New("invalid committed seals")
+
+	// errInsufficientCommittedSeals is returned if a block was committed by less
+	// than a quorum of the validators.
+	errInsufficientCommittedSeals = errors.New("insufficient committed seals")
+
+	// errInvalidTransactionHash is returned if a proposed block's transactions
+	// don't match the transaction root of its header.
+	errInvalidTransactionHash = errors.New("invalid transaction hash")
+
+	// errLockedProposal is returned if a block is proposed in a round while the
+	// validator is locked on a different block prepared in an earlier round.
+	errLockedProposal = errors.New("proposal conflicts with locked block")
+
+	// errWaitTransactions is returned if an empty block is attempted to be sealed
+	// on an instant chain (0 second period). It's important to refuse these as the
+	// block reward is zero, so an empty block just bloats the chain... fast.
+	errWaitTransactions = errors.New("waiting for transactions")
+
+	// errStopped is returned if a block is attempted to be sealed while the
+	// consensus state machine isn't running.
+	errStopped = errors.New("consensus not started")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// sigHash returns the hash which is used as input for the proposer seal. It is
+// the hash of the entire header apart from the proposer and committed seals.
+func sigHash(header *types.Header) (hash common.Hash) {
+	if filtered := types.BFTFilteredHeader(header, false); filtered != nil {
+		header = filtered
+	}
+	return rlpHash(header)
+}
+
+// commitHash returns the hash which is used as input for the committed seals of
+// the block with the given hash.
+func commitHash(hash common.Hash) []byte {
+	return crypto.Keccak256(hash.Bytes(), []byte{byte(msgCommit)})
+}
+
+// rlpHash returns the keccak256 hash of the RLP encoding of x.
+func rlpHash(x interface{}) common.Hash {
+	blob, _ := rlp.EncodeToBytes(x)
+	return crypto.Keccak256Hash(blob)
+}
+
+// recoverAddress extracts the VSportChain account address which signed a hash.
+func recoverAddress(hash []byte, sig []byte) (common.Address, error) {
+	pubkey, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// ecrecover extracts the VSportChain account address of the proposer of a block.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// If the signature's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Retrieve the signature from the header extra-data
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		return common.Address{}, errInvalidExtraDataFormat
+	}
+	if len(extra.Seal) != types.BFTExtraSeal {
+		return common.Address{}, errInvalidSignature
+	}
+	signer, err := recoverAddress(sigHash(header).Bytes(), extra.Seal)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// BFT is the byzantine fault tolerant consensus engine, finalizing every block
+// as soon as it's committed by a quorum of the validators.
+type BFT struct {
+	config *params.BFTConfig // Consensus engine configuration parameters
+	db     ethdb.Database    // Database to store and retrieve snapshot checkpoints
+
+	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
+	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+	messages   *lru.ARCCache // Hashes of recently seen consensus messages
+
+	proposals map[common.Address]bool // Current list of proposals we are pushing
+
+	signer common.Address // VSportChain address of the signing key
+	signFn SignerFn       // Signer function to authorize hashes with
+	lock   sync.RWMutex   // Protects the signer and proposal fields
+
+	peers *peerSet // Peers running the consensus protocol
+
+	transition           uint64           // Block the engine took over the chain at, 0 if running since genesis
+	transitionValidators []common.Address // Validators authorized at the takeover
+
+	core     *stateMachine                   // Consensus state machine, nil if not running
+	insert   func(types.Blocks) (int, error) // Callback to import blocks committed by the validators
+	coreLock sync.RWMutex                    // Protects the consensus state machine fields
+
+	sealHash common.Hash       // Hash of the block being sealed locally
+	sealCh   chan *types.Block // Channel to deliver the local block on once committed
+	sealLock sync.Mutex        // Protects the local sealing fields
+}
+
+// New creates a BFT consensus engine with the initial validators set to the
+// ones in the genesis block.
+func New(config *params.BFTConfig, db ethdb.Database) *BFT {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.RequestTimeout == 0 {
+		conf.RequestTimeout = requestTimeout
+	}
+	// Allocate the snapshot caches and create the engine
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	messages, _ := lru.NewARC(inmemoryMessages)
+
+	return &BFT{
+		config:     &conf,
+		db:         db,
+		recents:    recents,
+		signatures: signatures,
+		messages:   messages,
+		proposals:  make(map[common.Address]bool),
+		peers:      newPeerSet(),
+	}
+}
+
+// Transition configures the engine to take over a running chain from another
+// consensus engine at the given block, authorizing the given validators from
+// then on instead of the ones in the genesis block. It must be called before the
+// engine is first used.
+func (c *BFT) Transition(number uint64, validators []common.Address) {
+	c.transition = number
+	c.transitionValidators = validators
+}
+
+// Author implements consensus.Engine, returning the VSportChain address of the
+// validator which proposed the block.
+func (c *BFT) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, c.signatures)
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules. The
+// committed seals are only verified if the seal is requested to be checked.
+func (c *BFT) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return c.verifyHeader(chain, header, nil, seal)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (c *BFT) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := c.verifyHeader(chain, header, headers[:i], seals[i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules. The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database. The committed seals are only verified if
+// requested, as proposals still being agreed on don't have any yet.
+func (c *BFT) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header, committed bool) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Don't waste time checking blocks from the future
+	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Ensure that the extra-data contains the vanity and the consensus fields
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	// Checkpoint blocks need to enforce zero beneficiary
+	checkpoint := (number % c.config.Epoch) == 0
+	if checkpoint && header.Coinbase != (common.Address{}) {
+		return errInvalidCheckpointBeneficiary
+	}
+	// Nonces must be 0x00..0 or 0xff..f, zeroes enforced on checkpoints
+	if !bytes.Equal(header.Nonce[:], nonceAuthVote) && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidVote
+	}
+	if checkpoint && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidCheckpointVote
+	}
+	// Ensure that the extra-data contains a validator list on checkpoint, but none otherwise
+	if !checkpoint && len(extra.Validators) != 0 {
+		return errExtraValidators
+	}
+	// Ensure that the mix digest marks the block as BFT sealed
+	if number > 0 && header.MixDigest != types.BFTDigest {
+		return errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in BFT
+	if header.UncleHash != uncleHash {
+		return errInvalidUncleHash
+	}
+	// Ensure that the block's difficulty is meaningful
+	if number > 0 && (header.Difficulty == nil || header.Difficulty.Cmp(defaultDifficulty) != 0) {
+		return errInvalidDifficulty
+	}
+	// If all checks passed, validate any special fields for hard forks
+	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
+		return err
+	}
+	// All basic checks passed, verify cascading fields
+	return c.verifyCascadingFields(chain, header, parents, committed)
+}
+
+// verifyCascadingFields verifies all the header fields that are not standalone,
+// rather depend on a batch of previous headers. The caller may optionally pass
+// in a batch of parents (ascending order) to avoid looking those up from the
+// database. This is useful for concurrently verifying a batch of new headers.
+func (c *BFT) verifyCascadingFields(chain consensus.ChainReader, header *types.Header, parents []*types.Header, committed bool) error {
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	// Ensure that the block's timestamp isn't too close to it's parent
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time.Uint64()+c.config.Period > header.Time.Uint64() {
+		return ErrInvalidTimestamp
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// If the block is a checkpoint block, verify the validator list
+	if number%c.config.Epoch == 0 {
+		extra, _ := types.ExtractBFTExtra(header)
+
+		validators := snap.validators()
+		if len(extra.Validators) != len(validators) {
+			return errInvalidCheckpointValidators
+		}
+		for i, validator := range validators {
+			if extra.Validators[i] != validator {
+				return errInvalidCheckpointValidators
+			}
+		}
+	}
+	// All basic checks passed, verify the seals and return
+	if err := c.verifySeal(chain, header, parents); err != nil {
+		return err
+	}
+	if committed {
+		return c.verifyCommittedSeals(header, snap)
+	}
+	return nil
+}
+
+// snapshot retrieves the authorization snapshot at a given point in time.
+func (c *BFT) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or on disk for checkpoints
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := c.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// If an on-disk checkpoint snapshot can be found, use that
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
+				log.Trace("Loaded voting snapshot from disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// If we're at the block preceding a takeover, make a snapshot of the new validators
+		if c.transition > 0 && number == c.transition-1 {
+			snap = newSnapshot(c.config, c.signatures, number, hash, c.transitionValidators)
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored transition voting snapshot to disk", "number", number, "hash", hash)
+			break
+		}
+		// If we're at block zero, make a snapshot
+		if number == 0 {
+			genesis := chain.GetHeaderByNumber(0)
+			if err := c.VerifyHeader(chain, genesis, false); err != nil {
+				return nil, err
+			}
+			extra, err := types.ExtractBFTExtra(genesis)
+			if err != nil {
+				return nil, errInvalidExtraDataFormat
+			}
+			snap = newSnapshot(c.config, c.signatures, 0, genesis.Hash(), extra.Validators)
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored genesis voting snapshot to disk")
+			break
+		}
+		// No snapshot for this header, gather the header and move backward
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	c.recents.Add(snap.Hash, snap)
+
+	// If we've generated a new checkpoint snapshot, save to disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(c.db); err != nil {
+			return nil, err
+		}
+		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (c *BFT) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the proposer seal and
+// the committed seals contained in the header satisfy the consensus protocol
+// requirements.
+func (c *BFT) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	if err := c.verifySeal(chain, header, nil); err != nil {
+		return err
+	}
+	snap, err := c.snapshot(chain, header.Number.Uint64()-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	return c.verifyCommittedSeals(header, snap)
+}
+
+// verifySeal checks whether the proposer seal contained in the header satisfies
+// the consensus protocol requirements. The method accepts an optional list of
+// parent headers that aren't yet part of the local blockchain to generate the
+// snapshots from.
+func (c *BFT) verifySeal(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// Resolve the authorization key and check against validators
+	proposer, err := ecrecover(header, c.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[proposer]; !ok {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// verifyCommittedSeals checks whether the header was committed by a quorum of
+// the validators of the given snapshot.
+func (c *BFT) verifyCommittedSeals(header *types.Header, snap *Snapshot) error {
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	var (
+		hash      = commitHash(header.Hash())
+		committed = make(map[common.Address]struct{})
+	)
+	for _, seal := range extra.CommittedSeal {
+		if len(seal) != types.BFTExtraSeal {
+			return errInvalidCommittedSeals
+		}
+		validator, err := recoverAddress(hash, seal)
+		if err != nil {
+			return errInvalidCommittedSeals
+		}
+		if _, ok := snap.Validators[validator]; !ok {
+			return errInvalidCommittedSeals
+		}
+		if _, ok := committed[validator]; ok {
+			return errInvalidCommittedSeals
+		}
+		committed[validator] = struct{}{}
+	}
+	if len(committed) < snap.quorum() {
+		return errInsufficientCommittedSeals
+	}
+	return nil
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (c *BFT) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	// If the block isn't a checkpoint, cast a random vote (good enough for now)
+	header.Coinbase = common.Address{}
+	header.Nonce = types.BlockNonce{}
+
+	number := header.Number.Uint64()
+	// Assemble the voting snapshot to check which votes make sense
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if number%c.config.Epoch != 0 {
+		c.lock.RLock()
+
+		// Gather all the proposals that make sense voting on
+		addresses := make([]common.Address, 0, len(c.proposals))
+		for address, authorize := range c.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		// If there's pending proposals, cast a vote on them
+		if len(addresses) > 0 {
+			header.Coinbase = addresses[rand.Intn(len(addresses))]
+			if c.proposals[header.Coinbase] {
+				copy(header.Nonce[:], nonceAuthVote)
+			} else {
+				copy(header.Nonce[:], nonceDropVote)
+			}
+		}
+		c.lock.RUnlock()
+	}
+	// Set the fixed difficulty and mix digest
+	header.Difficulty = new(big.Int).Set(defaultDifficulty)
+	header.MixDigest = types.BFTDigest
+
+	// Ensure the extra data has all it's components
+	if len(header.Extra) < types.BFTExtraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, types.BFTExtraVanity-len(header.Extra))...)
+	}
+	extra := new(types.BFTExtra)
+	if number%c.config.Epoch == 0 {
+		extra.Validators = snap.validators()
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return err
+	}
+	header.Extra = append(header.Extra[:types.BFTExtraVanity], payload...)
+
+	// Ensure the timestamp has the correct delay
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(c.config.Period))
+	if header.Time.Int64() < time.Now().Unix() {
+		header.Time = big.NewInt(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize implements consensus.Engine, ensuring no uncles are set, nor block
+// rewards given, and returns the final block.
+func (c *BFT) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	// No block rewards in BFT, so the state remains as is and uncles are dropped
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	// Assemble and return the final block for sealing
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// Authorize injects a private key into the consensus engine to propose blocks
+// and take part in the consensus with.
+func (c *BFT) Authorize(signer common.Address, signFn SignerFn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.signer = signer
+	c.signFn = signFn
+}
+
+// sign signs a hash with the local validator key.
+func (c *BFT) sign(hash []byte) ([]byte, error) {
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errUnauthorized
+	}
+	return signFn(accounts.Account{Address: signer}, hash)
+}
+
+// address returns the address of the local validator key.
+func (c *BFT) address() common.Address {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.signer
+}
+
+// Seal implements consensus.Engine, proposing the block to the validators and
+// waiting until they commit it. If another block gets committed instead, the
+// sealing is aborted through the stop channel once the chain moves on.
+func (c *BFT) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+		return nil, errWaitTransactions
+	}
+	// Bail out if we're not a validator or not taking part in the consensus
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, authorized := snap.Validators[c.address()]; !authorized {
+		return nil, errUnauthorized
+	}
+	c.coreLock.RLock()
+	core := c.core
+	c.coreLock.RUnlock()
+
+	if core == nil {
+		return nil, errStopped
+	}
+	// Sweet, the protocol permits us to propose the block, wait for our time
+	delay := time.Unix(header.Time.Int64(), 0).Sub(time.Now()) // nolint: gosimple
+	log.Trace("Waiting for slot to propose", "delay", common.PrettyDuration(delay))
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	// Sign all the things and hand the proposal over to the consensus
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		return nil, errInvalidExtraDataFormat
+	}
+	if extra.Seal, err = c.sign(sigHash(header).Bytes()); err != nil {
+		return nil, err
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return nil, err
+	}
+	header.Extra = append(header.Extra[:types.BFTExtraVanity], payload...)
+	block = block.WithSeal(header)
+
+	sealCh := make(chan *types.Block, 1)
+
+	c.sealLock.Lock()
+	c.sealHash, c.sealCh = block.Hash(), sealCh
+	c.sealLock.Unlock()
+
+	core.request(block)
+
+	select {
+	case committed := <-sealCh:
+		return committed, nil
+
+	case <-stop:
+		// Sealing aborted, make sure a racing commit doesn't get lost
+		c.sealLock.Lock()
+		if c.sealCh == sealCh {
+			c.sealHash, c.sealCh = common.Hash{}, nil
+		}
+		c.sealLock.Unlock()
+
+		select {
+		case committed := <-sealCh:
+			go c.importBlock(committed)
+		default:
+		}
+		return nil, nil
+	}
+}
+
+// commit delivers a block committed by the validators, either to the sealer if
+// it's the local proposal, or directly into the chain otherwise.
+func (c *BFT) commit(block *types.Block) {
+	c.sealLock.Lock()
+	if c.sealCh != nil && c.sealHash == block.Hash() {
+		c.sealCh <- block
+		c.sealHash, c.sealCh = common.Hash{}, nil
+		c.sealLock.Unlock()
+		return
+	}
+	c.sealLock.Unlock()
+
+	go c.importBlock(block)
+}
+
+// importBlock inserts a committed block into the local chain.
+func (c *BFT) importBlock(block *types.Block) {
+	c.coreLock.RLock()
+	insert := c.insert
+	c.coreLock.RUnlock()
+
+	if insert == nil {
+		return
+	}
+	if _, err := insert(types.Blocks{block}); err != nil {
+		log.Error("Failed to import committed block", "number", block.Number(), "hash", block.Hash(), "err", err)
+
+		// Let the consensus move on instead of waiting for a head that never comes
+		c.coreLock.RLock()
+		if c.core != nil {
+			c.core.importFailed(block)
+		}
+		c.coreLock.RUnlock()
+		return
+	}
+	c.NewChainHead()
+}
+
+// Start starts the consensus state machine on top of the given chain, taking
+// part in the agreement on new blocks with the authorized validator key.
+//
+// Proposals are only prepared once the verify callback accepted them, which is
+// expected to execute the block on its parent state. Blocks committed by the
+// validators are imported through the insert callback.
+func (c *BFT) Start(chain consensus.ChainReader, verify func(*types.Block) error, insert func(types.Blocks) (int, error)) error {
+	c.coreLock.Lock()
+	defer c.coreLock.Unlock()
+
+	if c.core != nil {
+		return nil
+	}
+	c.insert = insert
+	c.core = newStateMachine(c, chain, verify)
+	c.core.start()
+
+	return nil
+}
+
+// Stop terminates the consensus state machine.
+func (c *BFT) Stop() error {
+	c.coreLock.Lock()
+	defer c.coreLock.Unlock()
+
+	if c.core == nil {
+		return nil
+	}
+	c.core.stop()
+	c.core, c.insert = nil, nil
+
+	return nil
+}
+
+// NewChainHead implements consensus.Handler, moving the consensus on to the
+// block following the new chain head.
+func (c *BFT) NewChainHead() {
+	c.coreLock.RLock()
+	defer c.coreLock.RUnlock()
+
+	if c.core != nil {
+		c.core.newChainHead()
+	}
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
+// that a new block should have, which is constant with instant finality.
+func (c *BFT) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(defaultDifficulty)
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to allow
+// inspecting the validators and controlling the voting.
+func (c *BFT) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "bft",
+		Version:   "1.0",
+		Service:   &API{chain: chain, bft: c},
+		Public:    false,
+	}}
+}
@@ -0,0 +1,287 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vsportchain/go-vsc/accounts"
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/p2p"
+	"github.com/vsportchain/go-vsc/p2p/discover"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// testerNode is a validator of a simulated BFT network.
+type testerNode struct {
+	engine *BFT
+	chain  *core.BlockChain
+	tamper func(*types.Block) *types.Block // Modifies the blocks the validator proposes, if set
+}
+
+// newTesterNetwork creates a fully connected network of validators, each with
+// its own chain on top of a shared genesis block. The consensus is started on
+// all the validators but the offline ones.
+func newTesterNetwork(t *testing.T, pool *testerAccountPool, names []string, offline map[string]bool) (map[string]*testerNode, func()) {
+	config := *params.TestChainConfig
+	config.BFT = &params.BFTConfig{Period: 1, Epoch: 30000, RequestTimeout: 500}
+
+	genesis := &core.Genesis{
+		Config:    &config,
+		ExtraData: testerExtra(pool.addresses(names)),
+	}
+	nodes := make(map[string]*testerNode)
+	for _, name := range names {
+		db := ethdb.NewMemDatabase()
+		genesis.MustCommit(db)
+
+		engine := New(config.BFT, db)
+		chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+		if err != nil {
+			t.Fatalf("failed to create chain of %s: %v", name, err)
+		}
+		key := pool.key(name)
+		engine.Authorize(pool.address(name), func(account accounts.Account, hash []byte) ([]byte, error) {
+			return crypto.Sign(hash, key)
+		})
+		nodes[name] = &testerNode{engine: engine, chain: chain}
+	}
+	// Connect all the online validators with each other
+	var pipes []*p2p.MsgPipeRW
+	for i, local := range names {
+		for _, remote := range names[i+1:] {
+			if offline[local] || offline[remote] {
+				continue
+			}
+			localRW, remoteRW := p2p.MsgPipe()
+			pipes = append(pipes, localRW, remoteRW)
+
+			go nodes[local].engine.runPeer(p2p.NewPeer(testerNodeID(remote), remote, nil), localRW)
+			go nodes[remote].engine.runPeer(p2p.NewPeer(testerNodeID(local), local, nil), remoteRW)
+		}
+	}
+	for _, name := range names {
+		if !offline[name] {
+			nodes[name].engine.Start(nodes[name].chain, nodes[name].chain.VerifyBlock, nodes[name].chain.InsertChain)
+		}
+	}
+	teardown := func() {
+		for _, node := range nodes {
+			node.engine.Stop()
+		}
+		for _, pipe := range pipes {
+			pipe.Close()
+		}
+		for _, node := range nodes {
+			node.chain.Stop()
+		}
+	}
+	return nodes, teardown
+}
+
+// testerNodeID derives a unique network identifier from a validator name.
+func testerNodeID(name string) discover.NodeID {
+	var id discover.NodeID
+	copy(id[:], crypto.Keccak256([]byte(name)))
+	return id
+}
+
+// newTesterBlock assembles a block on top of the chain head of a validator,
+// ready to be sealed.
+func newTesterBlock(node *testerNode) (*types.Block, error) {
+	parent := node.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+	}
+	if err := node.engine.Prepare(node.chain, header); err != nil {
+		return nil, err
+	}
+	statedb, err := node.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	return node.engine.Finalize(node.chain, header, statedb, nil, nil, nil)
+}
+
+// sealAndWait seals a block on every online validator, and waits until all of
+// them imported a block committed by the network.
+func sealAndWait(t *testing.T, nodes map[string]*testerNode, offline map[string]bool) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for name, node := range nodes {
+		if offline[name] {
+			continue
+		}
+		block, err := newTesterBlock(node)
+		if err != nil {
+			t.Fatalf("failed to create block of %s: %v", name, err)
+		}
+		if node.tamper != nil {
+			block = node.tamper(block)
+		}
+		go func(node *testerNode, block *types.Block) {
+			// Sealed blocks are inserted by the miner, anything else by the engine
+			if sealed, err := node.engine.Seal(node.chain, block, stop); err == nil && sealed != nil {
+				node.chain.InsertChain(types.Blocks{sealed})
+			}
+		}(node, block)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for name, node := range nodes {
+		if offline[name] {
+			continue
+		}
+		for node.chain.CurrentBlock().NumberU64() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("validator %s: block not committed in time", name)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// checkCommitted verifies that all the online validators imported the same block
+// and that it's been committed by a quorum of them.
+func checkCommitted(t *testing.T, nodes map[string]*testerNode, offline map[string]bool) {
+	var hash common.Hash
+	for name, node := range nodes {
+		if offline[name] {
+			continue
+		}
+		head := node.chain.CurrentBlock()
+		if hash == (common.Hash{}) {
+			hash = head.Hash()
+		}
+		if head.Hash() != hash {
+			t.Errorf("validator %s: head mismatch: have %x, want %x", name, head.Hash(), hash)
+		}
+		if err := node.engine.VerifySeal(node.chain, head.Header()); err != nil {
+			t.Errorf("validator %s: committed block invalid: %v", name, err)
+		}
+	}
+}
+
+// Tests that a network of validators agrees on a block and commits it.
+func TestCommit(t *testing.T) {
+	names := []string{"A", "B", "C", "D"}
+
+	nodes, teardown := newTesterNetwork(t, newTesterAccountPool(), names, nil)
+	defer teardown()
+
+	sealAndWait(t, nodes, nil)
+	checkCommitted(t, nodes, nil)
+}
+
+// Tests that the validators change rounds and commit a block even if the first
+// proposer is offline.
+func TestRoundChange(t *testing.T) {
+	names := []string{"A", "B", "C", "D"}
+
+	// Take the proposer of the first block's first round offline
+	accounts := newTesterAccountPool()
+	proposer := accounts.addresses(names)[1]
+
+	offline := make(map[string]bool)
+	for _, name := range names {
+		if accounts.address(name) == proposer {
+			offline[name] = true
+		}
+	}
+	nodes, teardown := newTesterNetwork(t, accounts, names, offline)
+	defer teardown()
+
+	sealAndWait(t, nodes, offline)
+	checkCommitted(t, nodes, offline)
+}
+
+// Tests that the validators refuse to prepare a proposal with an invalid state
+// root, changing rounds and committing a valid block instead.
+func TestInvalidStateProposal(t *testing.T) {
+	names := []string{"A", "B", "C", "D"}
+
+	// Make the proposer of the first block's first round propose a bogus state
+	accounts := newTesterAccountPool()
+	proposer := accounts.addresses(names)[1]
+
+	nodes, teardown := newTesterNetwork(t, accounts, names, nil)
+	defer teardown()
+
+	bogus := common.HexToHash("0xdeadbeef")
+	for _, name := range names {
+		if accounts.address(name) == proposer {
+			nodes[name].tamper = func(block *types.Block) *types.Block {
+				header := block.Header()
+				header.Root = bogus
+				return types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil)
+			}
+		}
+	}
+	sealAndWait(t, nodes, nil)
+	checkCommitted(t, nodes, nil)
+
+	for name, node := range nodes {
+		head := node.chain.CurrentBlock()
+		if head.Root() == bogus {
+			t.Errorf("validator %s: bogus state committed", name)
+		}
+		if author, _ := node.engine.Author(head.Header()); author == proposer {
+			t.Errorf("validator %s: block of bogus proposer committed", name)
+		}
+	}
+}
+
+// Tests that the committed seals are only verified if the seal is requested to
+// be checked.
+func TestVerifyHeaderSeal(t *testing.T) {
+	names := []string{"A", "B", "C", "D"}
+
+	nodes, teardown := newTesterNetwork(t, newTesterAccountPool(), names, nil)
+	defer teardown()
+
+	sealAndWait(t, nodes, nil)
+
+	node := nodes["A"]
+	header := types.BFTFilteredHeader(node.chain.CurrentHeader(), true)
+
+	if err := node.engine.VerifyHeader(node.chain, header, false); err != nil {
+		t.Errorf("header without committed seals rejected without seal check: %v", err)
+	}
+	if err := node.engine.VerifyHeader(node.chain, header, true); err != errInsufficientCommittedSeals {
+		t.Errorf("header without committed seals: error mismatch: have %v, want %v", err, errInsufficientCommittedSeals)
+	}
+	for _, seal := range []bool{false, true} {
+		_, results := node.engine.VerifyHeaders(node.chain, []*types.Header{header}, []bool{seal})
+
+		want := error(nil)
+		if seal {
+			want = errInsufficientCommittedSeals
+		}
+		if err := <-results; err != want {
+			t.Errorf("batch header without committed seals, seal check %v: error mismatch: have %v, want %v", seal, err, want)
+		}
+	}
+}
@@ -0,0 +1,575 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+const (
+	eventChanSize = 256 // Size of the channel queueing events for the state machine
+
+	maxBacklogSize      = 1024 // Maximum number of future messages to keep around
+	maxBacklogSequences = 16   // Maximum number of blocks ahead to keep future messages for
+	maxTimeoutShift     = 8    // Maximum number of times the round timeout doubles
+)
+
+// roundState is the progress of the agreement on a block within a round.
+type roundState uint8
+
+const (
+	stateAcceptRequest roundState = iota // Waiting for the proposal of the round
+	statePreprepared                     // Proposal accepted, waiting for a quorum of prepares
+	statePrepared                        // Proposal prepared and locked, waiting for a quorum of commits
+	stateCommitted                       // Proposal committed, waiting for the chain to move on
+)
+
+// requestEvent is posted when a local block is ready to be proposed.
+type requestEvent struct{ block *types.Block }
+
+// chainHeadEvent is posted when the head of the local chain changed.
+type chainHeadEvent struct{}
+
+// timeoutEvent is posted when a round failed to commit in time.
+type timeoutEvent struct{ sequence, round uint64 }
+
+// importFailedEvent is posted when a committed block couldn't be imported.
+type importFailedEvent struct{ block *types.Block }
+
+// commitVote is the commitment of a validator to a proposal.
+type commitVote struct {
+	hash common.Hash // Hash of the proposal committed to
+	seal []byte      // Committed seal over the proposal hash
+}
+
+// stateMachine is the consensus state machine, driving the agreement of the
+// validators on the block following the local chain head, one round at a time.
+//
+// All the fields apart from the channels are owned by the event loop.
+type stateMachine struct {
+	engine *BFT
+	chain  consensus.ChainReader
+	verify func(*types.Block) error // Callback to execute and validate a proposal on its parent state
+
+	events chan interface{} // Queue of messages and events to process
+	quit   chan struct{}    // Channel to terminate the event loop
+	wg     sync.WaitGroup
+
+	sequence   uint64           // Number of the block being agreed on
+	round      uint64           // Current round of the agreement
+	state      roundState       // Progress of the agreement within the round
+	parent     common.Hash      // Hash of the block being built upon
+	validators []common.Address // Validators of the block being agreed on, sorted
+
+	proposal *types.Block // Proposal accepted in the current round
+	locked   *types.Block // Proposal prepared in a previous round, the only one acceptable
+	pending  *types.Block // Local block waiting for our turn to be proposed
+
+	prepares     map[common.Address]common.Hash         // Prepares of the current round
+	commits      map[common.Address]commitVote          // Commits of the current round
+	roundChanges map[uint64]map[common.Address]struct{} // Round change requests, by target round
+	roundChanged uint64                                 // Latest round a round change was requested for
+
+	backlog []*message  // Messages of future rounds or blocks
+	timer   *time.Timer // Timer to change rounds if the current one doesn't commit
+}
+
+// newStateMachine creates a consensus state machine on top of the given chain.
+func newStateMachine(engine *BFT, chain consensus.ChainReader, verify func(*types.Block) error) *stateMachine {
+	return &stateMachine{
+		engine: engine,
+		chain:  chain,
+		verify: verify,
+		events: make(chan interface{}, eventChanSize),
+		quit:   make(chan struct{}),
+	}
+}
+
+// start starts the event loop, joining the agreement on the next block.
+func (c *stateMachine) start() {
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// stop terminates the event loop and waits for it to return.
+func (c *stateMachine) stop() {
+	close(c.quit)
+	c.wg.Wait()
+}
+
+// post queues an event for the event loop to process.
+func (c *stateMachine) post(ev interface{}) {
+	select {
+	case c.events <- ev:
+	case <-c.quit:
+	}
+}
+
+// request queues a local block to be proposed once it's our turn.
+func (c *stateMachine) request(block *types.Block) {
+	c.post(requestEvent{block})
+}
+
+// newChainHead notifies the state machine that the local chain moved on.
+func (c *stateMachine) newChainHead() {
+	c.post(chainHeadEvent{})
+}
+
+// importFailed notifies the state machine that a committed block was rejected
+// by the local chain.
+func (c *stateMachine) importFailed(block *types.Block) {
+	c.post(importFailedEvent{block})
+}
+
+// loop is the event loop of the state machine.
+func (c *stateMachine) loop() {
+	defer c.wg.Done()
+
+	c.startSequence()
+	for {
+		select {
+		case ev := <-c.events:
+			switch ev := ev.(type) {
+			case *message:
+				c.handleMessage(ev)
+			case requestEvent:
+				c.handleRequest(ev.block)
+			case chainHeadEvent:
+				if head := c.chain.CurrentHeader(); head.Number.Uint64() >= c.sequence {
+					c.startSequence()
+				}
+			case timeoutEvent:
+				c.handleTimeout(ev)
+			case importFailedEvent:
+				c.handleImportFailure(ev.block)
+			}
+		case <-c.quit:
+			if c.timer != nil {
+				c.timer.Stop()
+			}
+			return
+		}
+	}
+}
+
+// startSequence starts the agreement on the block following the chain head. If
+// the validators can't be retrieved, the attempt is repeated after the request
+// timeout, so the validator doesn't drop out of the agreement for good.
+func (c *stateMachine) startSequence() {
+	head := c.chain.CurrentHeader()
+	snap, err := c.engine.snapshot(c.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		log.Error("Failed to retrieve validators", "number", head.Number, "hash", head.Hash(), "err", err)
+		c.retrySequence()
+		return
+	}
+	c.sequence, c.parent, c.validators = head.Number.Uint64()+1, head.Hash(), snap.validators()
+	c.locked, c.roundChanges, c.roundChanged = nil, make(map[uint64]map[common.Address]struct{}), 0
+
+	if c.pending != nil && (c.pending.NumberU64() != c.sequence || c.pending.ParentHash() != c.parent) {
+		c.pending = nil
+	}
+	c.startRound(0)
+}
+
+// startRound starts a new round of the agreement on the current block.
+func (c *stateMachine) startRound(round uint64) {
+	c.round, c.state, c.proposal = round, stateAcceptRequest, nil
+	c.prepares, c.commits = make(map[common.Address]common.Hash), make(map[common.Address]commitVote)
+
+	for r := range c.roundChanges {
+		if r <= round {
+			delete(c.roundChanges, r)
+		}
+	}
+	c.resetTimer(round)
+
+	proposer := c.proposer()
+	log.Debug("Starting new consensus round", "sequence", c.sequence, "round", round, "proposer", proposer)
+
+	// If it's our turn, propose the locked block if any, or our own otherwise
+	if proposer == c.engine.address() {
+		switch {
+		case c.locked != nil:
+			c.propose(c.locked)
+		case c.pending != nil:
+			c.propose(c.pending)
+		}
+	}
+	c.processBacklog()
+}
+
+// resetTimer schedules the round change for a round which doesn't commit in
+// time, doubling the timeout with every round to let validators catch up.
+func (c *stateMachine) resetTimer(round uint64) {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	shift := round
+	if shift > maxTimeoutShift {
+		shift = maxTimeoutShift
+	}
+	timeout := time.Duration(c.engine.config.RequestTimeout) * time.Millisecond << shift
+
+	sequence, current := c.sequence, c.round
+	c.timer = time.AfterFunc(timeout, func() {
+		c.post(timeoutEvent{sequence: sequence, round: current})
+	})
+}
+
+// retrySequence schedules another attempt at starting the agreement on the block
+// following the chain head, replacing any pending round change.
+func (c *stateMachine) retrySequence() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(time.Duration(c.engine.config.RequestTimeout)*time.Millisecond, func() {
+		c.post(chainHeadEvent{})
+	})
+}
+
+// proposer returns the validator proposing the block in the current round.
+func (c *stateMachine) proposer() common.Address {
+	if len(c.validators) == 0 {
+		return common.Address{}
+	}
+	return c.validators[(c.sequence+c.round)%uint64(len(c.validators))]
+}
+
+// isValidator returns whether the address belongs to a validator of the current
+// block.
+func (c *stateMachine) isValidator(address common.Address) bool {
+	i := sort.Search(len(c.validators), func(i int) bool {
+		return bytes.Compare(c.validators[i][:], address[:]) >= 0
+	})
+	return i < len(c.validators) && c.validators[i] == address
+}
+
+// broadcast signs a message of the local validator, sends it to the network and
+// processes it locally too.
+func (c *stateMachine) broadcast(msg *message) {
+	msg.Sequence = c.sequence
+
+	sig, err := c.engine.sign(msg.sigHash().Bytes())
+	if err != nil {
+		log.Error("Failed to sign consensus message", "err", err)
+		return
+	}
+	msg.Signature = sig
+	payload, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		log.Error("Failed to encode consensus message", "err", err)
+		return
+	}
+	c.engine.broadcast(payload)
+
+	msg.sender = c.engine.address()
+	c.handleMessage(msg)
+}
+
+// propose sends a block proposal for the current round.
+func (c *stateMachine) propose(block *types.Block) {
+	payload, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Error("Failed to encode block proposal", "err", err)
+		return
+	}
+	log.Debug("Proposing block", "sequence", c.sequence, "round", c.round, "hash", block.Hash())
+	c.broadcast(&message{Code: msgPreprepare, Round: c.round, Payload: payload})
+}
+
+// handleRequest stores a local block to propose once it's our turn, proposing
+// it right away if it's our turn already.
+func (c *stateMachine) handleRequest(block *types.Block) {
+	if block.NumberU64() < c.sequence {
+		return
+	}
+	c.pending = block
+
+	if block.NumberU64() == c.sequence && block.ParentHash() == c.parent && c.state == stateAcceptRequest &&
+		c.locked == nil && c.proposer() == c.engine.address() {
+		c.propose(block)
+	}
+}
+
+// handleMessage processes a consensus message, whether from the network or a
+// local one.
+func (c *stateMachine) handleMessage(msg *message) {
+	// Discard messages for past blocks and keep the ones for future blocks around
+	switch {
+	case msg.Sequence < c.sequence:
+		return
+	case msg.Sequence > c.sequence:
+		c.store(msg)
+		return
+	}
+	if !c.isValidator(msg.sender) {
+		log.Debug("Discarded message from non-validator", "msg", msg)
+		return
+	}
+	// Round changes are about other rounds, everything else about the current one
+	if msg.Code == msgRoundChange {
+		c.handleRoundChange(msg)
+		return
+	}
+	switch {
+	case msg.Round < c.round:
+		return
+	case msg.Round > c.round:
+		c.store(msg)
+		return
+	}
+	switch msg.Code {
+	case msgPreprepare:
+		c.handlePreprepare(msg)
+	case msgPrepare:
+		c.prepares[msg.sender] = common.BytesToHash(msg.Payload)
+		c.advance()
+	case msgCommit:
+		c.handleCommit(msg)
+	default:
+		log.Debug("Discarded unknown consensus message", "msg", msg)
+	}
+}
+
+// store adds a message of a future round or block to the backlog.
+func (c *stateMachine) store(msg *message) {
+	if len(c.backlog) >= maxBacklogSize || msg.Sequence > c.sequence+maxBacklogSequences {
+		return
+	}
+	c.backlog = append(c.backlog, msg)
+}
+
+// processBacklog reprocesses all the messages of the backlog, keeping only the
+// ones still in the future.
+func (c *stateMachine) processBacklog() {
+	backlog := c.backlog
+	c.backlog = nil
+
+	for _, msg := range backlog {
+		c.handleMessage(msg)
+	}
+}
+
+// handlePreprepare accepts the block proposal of the round, if it's valid.
+func (c *stateMachine) handlePreprepare(msg *message) {
+	if c.state != stateAcceptRequest {
+		return
+	}
+	if msg.sender != c.proposer() {
+		log.Debug("Discarded proposal from non-proposer", "msg", msg)
+		return
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(msg.Payload, block); err != nil {
+		log.Debug("Discarded undecodable proposal", "msg", msg, "err", err)
+		return
+	}
+	if err := c.verifyProposal(block); err != nil {
+		log.Warn("Discarded invalid proposal", "msg", msg, "hash", block.Hash(), "err", err)
+		return
+	}
+	c.proposal, c.state = block, statePreprepared
+	c.broadcast(&message{Code: msgPrepare, Round: c.round, Payload: block.Hash().Bytes()})
+}
+
+// verifyProposal checks whether a proposed block is acceptable for the current
+// round: valid, built on the local chain head and matching any locked block.
+//
+// As the validators commit to the proposal before importing it, the block is
+// fully executed here, otherwise a proposal with a bogus state could be committed
+// and then rejected by every validator's chain.
+func (c *stateMachine) verifyProposal(block *types.Block) error {
+	if block.NumberU64() != c.sequence || block.ParentHash() != c.parent {
+		return consensus.ErrUnknownAncestor
+	}
+	if c.locked != nil && c.locked.Hash() != block.Hash() {
+		return errLockedProposal
+	}
+	if hash := types.DeriveSha(block.Transactions()); hash != block.TxHash() {
+		return errInvalidTransactionHash
+	}
+	if len(block.Uncles()) > 0 {
+		return errInvalidUncleHash
+	}
+	if err := c.engine.verifyHeader(c.chain, block.Header(), nil, false); err != nil {
+		return err
+	}
+	return c.verify(block)
+}
+
+// handleCommit records the commitment of a validator to a proposal.
+func (c *stateMachine) handleCommit(msg *message) {
+	hash := common.BytesToHash(msg.Payload)
+
+	// The committed seal ends up in the block, so it must be valid
+	if signer, err := recoverAddress(commitHash(hash), msg.Seal); err != nil || signer != msg.sender {
+		log.Debug("Discarded commit with invalid seal", "msg", msg)
+		return
+	}
+	c.commits[msg.sender] = commitVote{hash: hash, seal: msg.Seal}
+	c.advance()
+}
+
+// advance moves the agreement on the current proposal forward if a quorum of
+// the validators agrees on it.
+func (c *stateMachine) advance() {
+	if c.proposal == nil {
+		return
+	}
+	hash := c.proposal.Hash()
+
+	if c.state == statePreprepared {
+		prepares := 0
+		for _, prepare := range c.prepares {
+			if prepare == hash {
+				prepares++
+			}
+		}
+		if prepares >= quorumSize(len(c.validators)) {
+			// Proposal prepared, lock it and commit to it
+			c.state, c.locked = statePrepared, c.proposal
+
+			seal, err := c.engine.sign(commitHash(hash))
+			if err != nil {
+				log.Error("Failed to sign committed seal", "err", err)
+				return
+			}
+			c.broadcast(&message{Code: msgCommit, Round: c.round, Payload: hash.Bytes(), Seal: seal})
+		}
+	}
+	if c.state == statePreprepared || c.state == statePrepared {
+		var seals []commitSeal
+		for validator, commit := range c.commits {
+			if commit.hash == hash {
+				seals = append(seals, commitSeal{validator, commit.seal})
+			}
+		}
+		if len(seals) >= quorumSize(len(c.validators)) {
+			c.commit(seals)
+		}
+	}
+}
+
+// commitSeal is a committed seal along with the validator it belongs to.
+type commitSeal struct {
+	validator common.Address
+	seal      []byte
+}
+
+// commit finalizes the current proposal with the committed seals of a quorum of
+// validators and hands it over to be imported.
+func (c *stateMachine) commit(seals []commitSeal) {
+	sort.Slice(seals, func(i, j int) bool {
+		return bytes.Compare(seals[i].validator[:], seals[j].validator[:]) < 0
+	})
+	header := c.proposal.Header()
+
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		log.Error("Failed to decode proposal extra-data", "err", err)
+		return
+	}
+	extra.CommittedSeal = make([][]byte, len(seals))
+	for i, seal := range seals {
+		extra.CommittedSeal[i] = seal.seal
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		log.Error("Failed to encode proposal extra-data", "err", err)
+		return
+	}
+	header.Extra = append(header.Extra[:types.BFTExtraVanity], payload...)
+	block := c.proposal.WithSeal(header)
+
+	c.state = stateCommitted
+	c.timer.Stop()
+
+	log.Info("Committed new block", "number", block.Number(), "hash", block.Hash(), "round", c.round, "seals", len(seals))
+	c.engine.commit(block)
+}
+
+// handleTimeout requests a round change if the round failed to commit in time.
+func (c *stateMachine) handleTimeout(ev timeoutEvent) {
+	if ev.sequence != c.sequence || ev.round != c.round || c.state == stateCommitted {
+		return
+	}
+	log.Debug("Consensus round timed out", "sequence", c.sequence, "round", c.round)
+	c.requestRoundChange()
+}
+
+// handleImportFailure moves on to the next round if the block committed in the
+// current one was rejected by the local chain. The block is unlocked so it isn't
+// proposed or accepted again.
+func (c *stateMachine) handleImportFailure(block *types.Block) {
+	if c.state != stateCommitted || block.NumberU64() != c.sequence || block.ParentHash() != c.parent {
+		return
+	}
+	log.Warn("Committed block rejected, changing round", "sequence", c.sequence, "round", c.round, "hash", block.Hash())
+
+	c.state, c.proposal, c.locked = stateAcceptRequest, nil, nil
+	c.requestRoundChange()
+}
+
+// requestRoundChange requests the validators to move on to the round following
+// the current one, or any later one requested already.
+func (c *stateMachine) requestRoundChange() {
+	round := c.round + 1
+	if c.roundChanged >= round {
+		round = c.roundChanged + 1
+	}
+	c.resetTimer(round)
+	c.sendRoundChange(round)
+}
+
+// sendRoundChange requests the validators to move on to the given round.
+func (c *stateMachine) sendRoundChange(round uint64) {
+	c.roundChanged = round
+	c.broadcast(&message{Code: msgRoundChange, Round: round})
+}
+
+// handleRoundChange records the request of a validator to move on to a later
+// round, moving on once a quorum of the validators requested it.
+func (c *stateMachine) handleRoundChange(msg *message) {
+	if msg.Round <= c.round || c.state == stateCommitted {
+		return
+	}
+	requests, ok := c.roundChanges[msg.Round]
+	if !ok {
+		requests = make(map[common.Address]struct{})
+		c.roundChanges[msg.Round] = requests
+	}
+	requests[msg.sender] = struct{}{}
+
+	// If enough validators want to move on, at least one of them is honest, join them
+	if len(requests) > faultySize(len(c.validators)) && msg.Round > c.roundChanged {
+		c.sendRoundChange(msg.Round)
+		return
+	}
+	if len(requests) >= quorumSize(len(c.validators)) {
+		c.startRound(msg.Round)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+// testerStateMachine is a consensus state machine of a single validator, driven
+// directly by the test instead of its event loop.
+type testerStateMachine struct {
+	*stateMachine
+
+	node     *testerNode
+	accounts *testerAccountPool
+	names    map[common.Address]string // Validator names, by address
+	sorted   []common.Address          // Validator addresses, sorted
+}
+
+// newTesterStateMachine creates the state machine of the first of the sorted
+// validators, which doesn't propose in the first rounds of the first block.
+func newTesterStateMachine(t *testing.T) (*testerStateMachine, func()) {
+	accounts := newTesterAccountPool()
+	names := []string{"A", "B", "C", "D"}
+
+	offline := make(map[string]bool)
+	for _, name := range names {
+		offline[name] = true
+	}
+	nodes, teardown := newTesterNetwork(t, accounts, names, offline)
+
+	tester := &testerStateMachine{
+		accounts: accounts,
+		names:    make(map[common.Address]string),
+		sorted:   accounts.addresses(names),
+	}
+	for _, name := range names {
+		tester.names[accounts.address(name)] = name
+	}
+	tester.node = nodes[tester.names[tester.sorted[0]]]
+	tester.stateMachine = newStateMachine(tester.node.engine, tester.node.chain, tester.node.chain.VerifyBlock)
+	tester.startSequence()
+
+	return tester, func() {
+		tester.timer.Stop()
+		close(tester.quit)
+		teardown()
+	}
+}
+
+// block creates a block on top of the local chain head proposed by a validator,
+// with a vanity to tell apart different blocks.
+func (c *testerStateMachine) block(t *testing.T, proposer common.Address, vanity byte) *types.Block {
+	block, err := newTesterBlock(c.node)
+	if err != nil {
+		t.Fatalf("failed to create block: %v", err)
+	}
+	header := block.Header()
+	header.Extra[0] = vanity
+	c.accounts.sign(header, c.names[proposer])
+	return block.WithSeal(header)
+}
+
+// deliver hands a message of a validator for the current block to the state
+// machine.
+func (c *testerStateMachine) deliver(t *testing.T, sender common.Address, code, round uint64, payload []byte) {
+	msg := &message{Code: code, Sequence: c.sequence, Round: round, Payload: payload, sender: sender}
+	if code == msgCommit {
+		seal, err := crypto.Sign(commitHash(common.BytesToHash(payload)), c.accounts.key(c.names[sender]))
+		if err != nil {
+			t.Fatalf("failed to sign committed seal: %v", err)
+		}
+		msg.Seal = seal
+	}
+	c.handleMessage(msg)
+}
+
+// propose delivers a block proposal of a validator to the state machine.
+func (c *testerStateMachine) propose(t *testing.T, proposer common.Address, round uint64, block *types.Block) {
+	payload, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatalf("failed to encode proposal: %v", err)
+	}
+	c.deliver(t, proposer, msgPreprepare, round, payload)
+}
+
+// lock makes the state machine prepare and lock the given block in the first
+// round.
+func (c *testerStateMachine) lock(t *testing.T, block *types.Block) {
+	c.propose(t, c.sorted[1], 0, block)
+	if c.state != statePreprepared {
+		t.Fatalf("proposal not accepted: state %d", c.state)
+	}
+	for _, validator := range c.sorted[1:3] {
+		c.deliver(t, validator, msgPrepare, 0, block.Hash().Bytes())
+	}
+	if c.state != statePrepared || c.locked == nil || c.locked.Hash() != block.Hash() {
+		t.Fatalf("proposal not locked: state %d", c.state)
+	}
+}
+
+// Tests that a validator locked on a block prepared in an earlier round only
+// accepts that block in later rounds.
+func TestLockedProposal(t *testing.T) {
+	c, teardown := newTesterStateMachine(t)
+	defer teardown()
+
+	locked := c.block(t, c.sorted[1], 0)
+	c.lock(t, locked)
+
+	// Move on to the next round, keeping the lock
+	for _, validator := range c.sorted[1:3] {
+		c.deliver(t, validator, msgRoundChange, 1, nil)
+	}
+	if c.round != 1 || c.state != stateAcceptRequest {
+		t.Fatalf("round not changed: round %d, state %d", c.round, c.state)
+	}
+	if c.locked == nil || c.locked.Hash() != locked.Hash() {
+		t.Fatalf("lock lost on round change")
+	}
+	// Any other block must be refused, the locked one accepted
+	c.propose(t, c.sorted[2], 1, c.block(t, c.sorted[2], 1))
+	if c.state != stateAcceptRequest {
+		t.Fatalf("conflicting proposal accepted: state %d", c.state)
+	}
+	c.propose(t, c.sorted[2], 1, locked)
+	if c.state != statePreprepared || c.proposal.Hash() != locked.Hash() {
+		t.Fatalf("locked proposal not accepted: state %d", c.state)
+	}
+}
+
+// Tests that a proposal which fails to execute on the parent state is refused.
+func TestInvalidProposal(t *testing.T) {
+	c, teardown := newTesterStateMachine(t)
+	defer teardown()
+
+	block := c.block(t, c.sorted[1], 0)
+	header := block.Header()
+	header.Root = common.HexToHash("0xdeadbeef")
+	c.accounts.sign(header, c.names[c.sorted[1]])
+
+	c.propose(t, c.sorted[1], 0, block.WithSeal(header))
+	if c.state != stateAcceptRequest || c.proposal != nil {
+		t.Fatalf("invalid proposal accepted: state %d", c.state)
+	}
+	if len(c.prepares) != 0 {
+		t.Fatalf("invalid proposal prepared")
+	}
+}
+
+// Tests that a validator whose committed block is rejected by the local chain
+// releases the lock and requests a round change instead of stalling.
+func TestImportFailure(t *testing.T) {
+	c, teardown := newTesterStateMachine(t)
+	defer teardown()
+
+	block := c.block(t, c.sorted[1], 0)
+	c.lock(t, block)
+
+	for _, validator := range c.sorted[1:3] {
+		c.deliver(t, validator, msgCommit, 0, block.Hash().Bytes())
+	}
+	if c.state != stateCommitted {
+		t.Fatalf("proposal not committed: state %d", c.state)
+	}
+	c.handleImportFailure(block)
+	if c.state == stateCommitted {
+		t.Fatalf("still committed after import failure")
+	}
+	if c.locked != nil {
+		t.Fatalf("rejected block still locked")
+	}
+	if c.roundChanged != 1 {
+		t.Fatalf("round change mismatch: have %d, want %d", c.roundChanged, 1)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/p2p"
+)
+
+const (
+	protocolName    = "bft"    // Name of the consensus protocol
+	protocolVersion = 1        // Version of the consensus protocol
+	protocolLength  = 1        // Number of message codes used by the consensus protocol
+	protocolMaxSize = 10 << 20 // Maximum size of a consensus message (large blocks are proposed)
+
+	consensusMsg = 0x00 // Message code of a signed, RLP encoded consensus message
+
+	maxQueuedMsgs = 256 // Maximum number of messages queued for sending to a peer
+)
+
+// errMsgTooLarge is returned if a peer sends a message above the maximum size.
+var errMsgTooLarge = errors.New("message too large")
+
+// peer is a remote node running the consensus protocol.
+type peer struct {
+	id    string
+	rw    p2p.MsgReadWriter
+	queue chan []byte   // Consensus messages waiting to be sent
+	term  chan struct{} // Channel to stop the send loop on disconnection
+}
+
+// sendLoop sends the queued consensus messages to the remote peer until it
+// disconnects.
+func (p *peer) sendLoop() {
+	for {
+		select {
+		case payload := <-p.queue:
+			if err := p2p.Send(p.rw, consensusMsg, payload); err != nil {
+				return
+			}
+		case <-p.term:
+			return
+		}
+	}
+}
+
+// send queues a consensus message for sending, dropping it if the peer can't
+// keep up. Lost messages are recovered from by round changes.
+func (p *peer) send(payload []byte) {
+	select {
+	case p.queue <- payload:
+	default:
+		log.Debug("Dropping consensus message to slow peer", "peer", p.id)
+	}
+}
+
+// peerSet is the set of remote nodes running the consensus protocol.
+type peerSet struct {
+	peers map[string]*peer
+	lock  sync.RWMutex
+}
+
+// newPeerSet creates an empty set of consensus peers.
+func newPeerSet() *peerSet {
+	return &peerSet{peers: make(map[string]*peer)}
+}
+
+// Protocols implements consensus.Handler, returning the network protocol the
+// validators exchange the consensus messages over.
+func (c *BFT) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run:     c.runPeer,
+	}}
+}
+
+// runPeer is the protocol handler of a consensus peer, relaying all its messages
+// until it disconnects.
+func (c *BFT) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	remote := &peer{
+		id:    p.ID().String(),
+		rw:    rw,
+		queue: make(chan []byte, maxQueuedMsgs),
+		term:  make(chan struct{}),
+	}
+	c.peers.lock.Lock()
+	c.peers.peers[remote.id] = remote
+	c.peers.lock.Unlock()
+
+	defer func() {
+		c.peers.lock.Lock()
+		delete(c.peers.peers, remote.id)
+		c.peers.lock.Unlock()
+
+		close(remote.term)
+	}()
+	go remote.sendLoop()
+
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > protocolMaxSize {
+			msg.Discard()
+			return errMsgTooLarge
+		}
+		if msg.Code != consensusMsg {
+			msg.Discard()
+			continue
+		}
+		var payload []byte
+		if err := msg.Decode(&payload); err != nil {
+			return err
+		}
+		c.handleMessage(remote.id, payload)
+	}
+}
+
+// handleMessage verifies a consensus message received from a peer, relaying it
+// to the other peers and feeding it into the consensus state machine.
+func (c *BFT) handleMessage(from string, payload []byte) {
+	// Drop any messages already seen, there's nothing to do with them
+	hash := crypto.Keccak256Hash(payload)
+	if c.messages.Contains(hash) {
+		return
+	}
+	c.messages.Add(hash, struct{}{})
+
+	c.coreLock.RLock()
+	core := c.core
+	c.coreLock.RUnlock()
+
+	if core == nil {
+		return
+	}
+	msg, err := decodeMessage(payload)
+	if err != nil {
+		log.Debug("Discarded invalid consensus message", "peer", from, "err", err)
+		return
+	}
+	// Only relay messages of known validators to avoid amplifying spam
+	head := core.chain.CurrentHeader()
+	snap, err := c.snapshot(core.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return
+	}
+	if _, ok := snap.Validators[msg.sender]; !ok {
+		log.Debug("Discarded consensus message from non-validator", "peer", from, "msg", msg)
+		return
+	}
+	c.gossip(from, payload)
+	core.post(msg)
+}
+
+// broadcast sends a local consensus message to all the peers.
+func (c *BFT) broadcast(payload []byte) {
+	c.messages.Add(crypto.Keccak256Hash(payload), struct{}{})
+	c.gossip("", payload)
+}
+
+// gossip sends a consensus message to all the peers, apart from the one it was
+// received from.
+func (c *BFT) gossip(from string, payload []byte) {
+	c.peers.lock.RLock()
+	defer c.peers.lock.RUnlock()
+
+	for id, p := range c.peers.peers {
+		if id != from {
+			p.send(payload)
+		}
+	}
+}
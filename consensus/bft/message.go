@@ -0,0 +1,81 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"fmt"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+// Consensus message codes.
+const (
+	msgPreprepare  = 0x00 // Block proposal of the round's proposer
+	msgPrepare     = 0x01 // Acknowledgement of a valid proposal
+	msgCommit      = 0x02 // Commitment to a proposal acknowledged by a quorum
+	msgRoundChange = 0x03 // Request to move on to a later round
+)
+
+// message is a consensus message exchanged between the validators.
+type message struct {
+	Code      uint64 // Consensus message code
+	Sequence  uint64 // Number of the block being agreed on
+	Round     uint64 // Round of the agreement the message belongs to
+	Payload   []byte // Encoded proposal for pre-prepares, proposal hash otherwise
+	Seal      []byte // Committed seal over the proposal hash, only for commits
+	Signature []byte // Signature of the sender over all the other fields
+
+	sender common.Address // Validator who sent the message, recovered from the signature
+}
+
+// sigHash returns the hash which is signed by the sender of the message.
+func (m *message) sigHash() common.Hash {
+	return rlpHash([]interface{}{m.Code, m.Sequence, m.Round, m.Payload, m.Seal})
+}
+
+// String implements fmt.Stringer.
+func (m *message) String() string {
+	var code string
+	switch m.Code {
+	case msgPreprepare:
+		code = "preprepare"
+	case msgPrepare:
+		code = "prepare"
+	case msgCommit:
+		code = "commit"
+	case msgRoundChange:
+		code = "roundchange"
+	default:
+		code = fmt.Sprintf("unknown(%d)", m.Code)
+	}
+	return fmt.Sprintf("{%s sequence: %d round: %d sender: %x}", code, m.Sequence, m.Round, m.sender)
+}
+
+// decodeMessage decodes a consensus message and recovers its sender.
+func decodeMessage(payload []byte) (*message, error) {
+	msg := new(message)
+	if err := rlp.DecodeBytes(payload, msg); err != nil {
+		return nil, err
+	}
+	sender, err := recoverAddress(msg.sigHash().Bytes(), msg.Signature)
+	if err != nil {
+		return nil, err
+	}
+	msg.sender = sender
+	return msg, nil
+}
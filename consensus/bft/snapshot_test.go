@@ -0,0 +1,252 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+type testerVote struct {
+	validator string
+	voted     string
+	auth      bool
+}
+
+// testerAccountPool is a pool to maintain currently active tester accounts,
+// mapped from textual names used in the tests below to actual VSportChain private
+// keys capable of signing transactions.
+type testerAccountPool struct {
+	accounts map[string]*ecdsa.PrivateKey
+}
+
+func newTesterAccountPool() *testerAccountPool {
+	return &testerAccountPool{
+		accounts: make(map[string]*ecdsa.PrivateKey),
+	}
+}
+
+func (ap *testerAccountPool) key(account string) *ecdsa.PrivateKey {
+	// Ensure we have a persistent key for the account
+	if ap.accounts[account] == nil {
+		ap.accounts[account], _ = crypto.GenerateKey()
+	}
+	return ap.accounts[account]
+}
+
+func (ap *testerAccountPool) sign(header *types.Header, validator string) {
+	// Sign the header and embed the proposer seal in extra data
+	sig, _ := crypto.Sign(sigHash(header).Bytes(), ap.key(validator))
+
+	extra, _ := types.ExtractBFTExtra(header)
+	extra.Seal = sig
+	payload, _ := rlp.EncodeToBytes(extra)
+	header.Extra = append(header.Extra[:types.BFTExtraVanity], payload...)
+}
+
+func (ap *testerAccountPool) address(account string) common.Address {
+	return crypto.PubkeyToAddress(ap.key(account).PublicKey)
+}
+
+// addresses resolves the accounts into their addresses, sorted ascending.
+func (ap *testerAccountPool) addresses(accounts []string) []common.Address {
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = ap.address(account)
+	}
+	for i := 0; i < len(addresses); i++ {
+		for j := i + 1; j < len(addresses); j++ {
+			if bytes.Compare(addresses[i][:], addresses[j][:]) > 0 {
+				addresses[i], addresses[j] = addresses[j], addresses[i]
+			}
+		}
+	}
+	return addresses
+}
+
+// testerExtra creates the extra-data of a header with the given validators.
+func testerExtra(validators []common.Address) []byte {
+	payload, _ := rlp.EncodeToBytes(&types.BFTExtra{Validators: validators})
+	return append(make([]byte, types.BFTExtraVanity), payload...)
+}
+
+// testerChainReader implements consensus.ChainReader to access the genesis
+// block. All other methods and requests will panic.
+type testerChainReader struct {
+	db ethdb.Database
+}
+
+func (r *testerChainReader) Config() *params.ChainConfig                 { return params.TestChainConfig }
+func (r *testerChainReader) CurrentHeader() *types.Header                { panic("not supported") }
+func (r *testerChainReader) GetHeader(common.Hash, uint64) *types.Header { panic("not supported") }
+func (r *testerChainReader) GetBlock(common.Hash, uint64) *types.Block   { panic("not supported") }
+func (r *testerChainReader) GetHeaderByHash(common.Hash) *types.Header   { panic("not supported") }
+func (r *testerChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number == 0 {
+		return rawdb.ReadHeader(r.db, rawdb.ReadCanonicalHash(r.db, 0), 0)
+	}
+	panic("not supported")
+}
+
+// Tests that voting is evaluated correctly for various simple and complex scenarios.
+func TestVoting(t *testing.T) {
+	// Define the various voting scenarios to test
+	tests := []struct {
+		epoch      uint64
+		validators []string
+		votes      []testerVote
+		results    []string
+		quorum     int
+	}{
+		{
+			// Single validator, no votes cast
+			validators: []string{"A"},
+			votes:      []testerVote{{validator: "A"}},
+			results:    []string{"A"},
+			quorum:     1,
+		}, {
+			// Single validator, voting to add two others (only accept first, second needs 2 votes)
+			validators: []string{"A"},
+			votes: []testerVote{
+				{validator: "A", voted: "B", auth: true},
+				{validator: "B"},
+				{validator: "A", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+			quorum:  2,
+		}, {
+			// Four validators, adding a fifth needs three votes
+			validators: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{validator: "A", voted: "E", auth: true},
+				{validator: "B", voted: "E", auth: true},
+				{validator: "C", voted: "E", auth: true},
+			},
+			results: []string{"A", "B", "C", "D", "E"},
+			quorum:  4,
+		}, {
+			// Four validators, dropping one without a majority fails
+			validators: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{validator: "A", voted: "D", auth: false},
+				{validator: "B", voted: "D", auth: false},
+			},
+			results: []string{"A", "B", "C", "D"},
+			quorum:  3,
+		}, {
+			// Four validators, dropping one with a majority succeeds
+			validators: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{validator: "A", voted: "D", auth: false},
+				{validator: "B", voted: "D", auth: false},
+				{validator: "C", voted: "D", auth: false},
+			},
+			results: []string{"A", "B", "C"},
+			quorum:  2,
+		}, {
+			// Repeated votes of the same validator only count once
+			validators: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{validator: "A", voted: "D", auth: true},
+				{validator: "A", voted: "D", auth: true},
+				{validator: "B"},
+			},
+			results: []string{"A", "B", "C"},
+			quorum:  2,
+		}, {
+			// Pending votes are discarded on epoch transitions
+			epoch:      3,
+			validators: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{validator: "A", voted: "D", auth: true},
+				{validator: "B"},
+				{validator: "C"},
+				{validator: "B", voted: "D", auth: true},
+			},
+			results: []string{"A", "B", "C"},
+			quorum:  2,
+		},
+	}
+	// Run through the scenarios and test them
+	for i, tt := range tests {
+		// Create the account pool and the genesis block with the initial validators
+		accounts := newTesterAccountPool()
+
+		genesis := &core.Genesis{
+			ExtraData: testerExtra(accounts.addresses(tt.validators)),
+		}
+		db := ethdb.NewMemDatabase()
+		genesis.Commit(db)
+
+		// Assemble a chain of headers from the cast votes
+		headers := make([]*types.Header, len(tt.votes))
+		for j, vote := range tt.votes {
+			headers[j] = &types.Header{
+				Number:     big.NewInt(int64(j) + 1),
+				Time:       big.NewInt(int64(j)),
+				Difficulty: new(big.Int).Set(defaultDifficulty),
+				MixDigest:  types.BFTDigest,
+				Extra:      testerExtra(nil),
+			}
+			if vote.voted != "" {
+				headers[j].Coinbase = accounts.address(vote.voted)
+			}
+			if j > 0 {
+				headers[j].ParentHash = headers[j-1].Hash()
+			}
+			if vote.auth {
+				copy(headers[j].Nonce[:], nonceAuthVote)
+			}
+			accounts.sign(headers[j], vote.validator)
+		}
+		// Pass all the headers through the engine and ensure tallying succeeds
+		head := headers[len(headers)-1]
+
+		snap, err := New(&params.BFTConfig{Epoch: tt.epoch}, db).snapshot(&testerChainReader{db: db}, head.Number.Uint64(), head.Hash(), headers)
+		if err != nil {
+			t.Errorf("test %d: failed to create voting snapshot: %v", i, err)
+			continue
+		}
+		// Verify the final list of validators against the expected ones
+		validators := accounts.addresses(tt.results)
+
+		result := snap.validators()
+		if len(result) != len(validators) {
+			t.Errorf("test %d: validators mismatch: have %x, want %x", i, result, validators)
+			continue
+		}
+		for j := 0; j < len(result); j++ {
+			if result[j] != validators[j] {
+				t.Errorf("test %d, validator %d: validator mismatch: have %x, want %x", i, j, result[j], validators[j])
+			}
+		}
+		if quorum := snap.quorum(); quorum != tt.quorum {
+			t.Errorf("test %d: quorum mismatch: have %d, want %d", i, quorum, tt.quorum)
+		}
+	}
+}
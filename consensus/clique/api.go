@@ -17,12 +17,19 @@
 package clique
 
 import (
+	"fmt"
+
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/consensus"
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/rpc"
 )
 
+const (
+	statusBlocks   = 64    // Number of recent blocks to report the signer status over
+	maxStatsBlocks = 10000 // Maximum number of blocks to gather signer stats over in one go
+)
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -117,3 +124,67 @@ func (api *API) Discard(address common.Address) {
 
 	delete(api.clique.proposals, address)
 }
+
+// Status is the sealing activity of the signers over the recent blocks.
+type Status struct {
+	Number        uint64                          `json:"number"`        // Number of the latest block inspected
+	NumBlocks     uint64                          `json:"numBlocks"`     // Number of blocks inspected
+	InTurnPercent float64                         `json:"inturnPercent"` // Percentage of blocks sealed in-turn
+	Signers       map[common.Address]*SignerStats `json:"signers"`       // Sealing activity of the signers
+}
+
+// Status returns the sealing activity of the signers over the recent blocks, to
+// spot any signers gone offline.
+func (api *API) Status() (*Status, error) {
+	head := api.chain.CurrentHeader().Number.Uint64()
+	if head == 0 {
+		return &Status{Signers: make(map[common.Address]*SignerStats)}, nil
+	}
+	from := uint64(1)
+	if head > statusBlocks {
+		from = head - statusBlocks + 1
+	}
+	stats, err := api.clique.signerStats(api.chain, from, head)
+	if err != nil {
+		return nil, err
+	}
+	var inturn uint64
+	for _, signer := range stats {
+		inturn += signer.InTurn
+	}
+	numBlocks := head - from + 1
+	return &Status{
+		Number:        head,
+		NumBlocks:     numBlocks,
+		InTurnPercent: float64(inturn) * 100 / float64(numBlocks),
+		Signers:       stats,
+	}, nil
+}
+
+// GetSignerStats returns the sealing activity of the signers over the specified
+// range of blocks.
+func (api *API) GetSignerStats(from, to rpc.BlockNumber) (map[common.Address]*SignerStats, error) {
+	head := api.chain.CurrentHeader().Number.Uint64()
+
+	// Resolve the requested range, capping it to the available blocks
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number < 0 {
+			return head
+		}
+		return uint64(number.Int64())
+	}
+	start, end := resolve(from), resolve(to)
+	if start == 0 {
+		start = 1 // genesis isn't sealed
+	}
+	if end > head {
+		return nil, errUnknownBlock
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid block range %d-%d", start, end)
+	}
+	if end-start+1 > maxStatsBlocks {
+		return nil, fmt.Errorf("block range too large, %d > %d", end-start+1, maxStatsBlocks)
+	}
+	return api.clique.signerStats(api.chain, start, end)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/vsportchain/go-vsc/crypto/sha3"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/metrics"
 	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rlp"
 	"github.com/vsportchain/go-vsc/rpc"
@@ -206,6 +207,11 @@ type Clique struct {
 	signer common.Address // VSportChain address of the signing key
 	signFn SignerFn       // Signer function to authorize hashes with
 	lock   sync.RWMutex   // Protects the signer fields
+
+	transition        uint64           // Block the engine took over the chain at, 0 if running since genesis
+	transitionSigners []common.Address // Signers authorized at the takeover
+
+	metrics signerMetrics // Liveness gauges of the signers
 }
 
 // New creates a Clique proof-of-authority consensus engine with the initial
@@ -229,6 +235,15 @@ func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
 	}
 }
 
+// Transition configures the engine to take over a running chain from another
+// consensus engine at the given block, authorizing the given signers from then
+// on instead of the ones in the genesis block. It must be called before the
+// engine is first used.
+func (c *Clique) Transition(number uint64, signers []common.Address) {
+	c.transition = number
+	c.transitionSigners = signers
+}
+
 // Author implements consensus.Engine, returning the VSportChain address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
@@ -352,8 +367,13 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainReader, header *type
 	if err != nil {
 		return err
 	}
-	// If the block is a checkpoint block, verify the signer list
-	if number%c.config.Epoch == 0 {
+	// If the block is a checkpoint block, verify the signer list. With governance
+	// the list comes from the contract, so only its format can be checked here.
+	if number%c.config.Epoch == 0 && c.config.Governance != nil {
+		if err := verifyGovernanceSigners(checkpointSigners(header.Extra)); err != nil {
+			return err
+		}
+	} else if number%c.config.Epoch == 0 {
 		signers := make([]byte, len(snap.Signers)*common.AddressLength)
 		for i, signer := range snap.signers() {
 			copy(signers[i*common.AddressLength:], signer[:])
@@ -388,17 +408,22 @@ func (c *Clique) snapshot(chain consensus.ChainReader, number uint64, hash commo
 				break
 			}
 		}
+		// If we're at the block preceding a takeover, make a snapshot of the new signers
+		if c.transition > 0 && number == c.transition-1 {
+			snap = newSnapshot(c.config, c.signatures, number, hash, c.transitionSigners)
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored transition voting snapshot to disk", "number", number, "hash", hash)
+			break
+		}
 		// If we're at block zero, make a snapshot
 		if number == 0 {
 			genesis := chain.GetHeaderByNumber(0)
 			if err := c.VerifyHeader(chain, genesis, false); err != nil {
 				return nil, err
 			}
-			signers := make([]common.Address, (len(genesis.Extra)-extraVanity-extraSeal)/common.AddressLength)
-			for i := 0; i < len(signers); i++ {
-				copy(signers[i][:], genesis.Extra[extraVanity+i*common.AddressLength:])
-			}
-			snap = newSnapshot(c.config, c.signatures, 0, genesis.Hash(), signers)
+			snap = newSnapshot(c.config, c.signatures, 0, genesis.Hash(), checkpointSigners(genesis.Extra))
 			if err := snap.store(c.db); err != nil {
 				return nil, err
 			}
@@ -515,7 +540,7 @@ func (c *Clique) Prepare(chain consensus.ChainReader, header *types.Header) erro
 	if err != nil {
 		return err
 	}
-	if number%c.config.Epoch != 0 {
+	if number%c.config.Epoch != 0 && c.config.Governance == nil {
 		c.lock.RLock()
 
 		// Gather all the proposals that make sense voting on
@@ -545,7 +570,9 @@ func (c *Clique) Prepare(chain consensus.ChainReader, header *types.Header) erro
 	}
 	header.Extra = header.Extra[:extraVanity]
 
-	if number%c.config.Epoch == 0 {
+	// With governance, checkpoint signers are only known after running the
+	// transactions, so they are filled in by Finalize
+	if number%c.config.Epoch == 0 && c.config.Governance == nil {
 		for _, signer := range snap.signers() {
 			header.Extra = append(header.Extra, signer[:]...)
 		}
@@ -569,7 +596,42 @@ func (c *Clique) Prepare(chain consensus.ChainReader, header *types.Header) erro
 
 // Finalize implements consensus.Engine, ensuring no uncles are set, nor block
 // rewards given, and returns the final block.
+//
+// With governance enabled, the signers of checkpoint blocks are read from the
+// contract after running the transactions. They are filled into headers being
+// mined and checked against the ones listed by headers being imported. This is
+// the only place the list is checked, headers verified without their state are
+// trusted to list the right signers.
 func (c *Clique) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	if number := header.Number.Uint64(); number%c.config.Epoch == 0 && c.config.Governance != nil {
+		signers := governanceSigners(state, *c.config.Governance)
+		if signers == nil {
+			// The contract holds no usable list, keep the current signers
+			snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+			if err != nil {
+				return nil, err
+			}
+			signers = snap.signers()
+		}
+		if listed := checkpointSigners(header.Extra); len(listed) == 0 {
+			extra := append([]byte{}, header.Extra[:extraVanity]...)
+			for _, signer := range signers {
+				extra = append(extra, signer[:]...)
+			}
+			header.Extra = append(extra, header.Extra[len(header.Extra)-extraSeal:]...)
+		} else if !signersEqual(listed, signers) {
+			return nil, errInvalidCheckpointSigners
+		}
+	}
+	// Record the liveness of the signers now that the block is being imported.
+	// Headers being mined aren't sealed yet, they are recorded by Seal instead.
+	if metrics.Enabled {
+		if signer, err := ecrecover(header, c.signatures); err == nil {
+			if snap, err := c.snapshot(chain, header.Number.Uint64()-1, header.ParentHash, nil); err == nil {
+				c.metrics.record(snap, header.Number.Uint64(), signer)
+			}
+		}
+	}
 	// No block rewards in PoA, so the state remains as is and uncles are dropped
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = types.CalcUncleHash(nil)
@@ -648,6 +710,7 @@ func (c *Clique) Seal(chain consensus.ChainReader, block *types.Block, stop <-ch
 		return nil, err
 	}
 	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+	c.metrics.record(snap, number, signer)
 
 	return block.WithSeal(header), nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/crypto"
+)
+
+// maxGovernanceSigners is the maximum number of signers accepted from the
+// governance contract, protecting checkpoint headers from a runaway list.
+const maxGovernanceSigners = 1024
+
+// governanceSignersSlot is the storage slot of the governance contract holding
+// the authorized signers. It is the first state variable of the contract, which
+// needs to be declared as a dynamic array of addresses (`address[] signers`).
+var governanceSignersSlot = common.Hash{}
+
+// governanceSigners reads the list of authorized signers from the governance
+// contract, sorted in ascending order and without duplicates. Nil is returned if
+// the contract doesn't hold a usable list.
+func governanceSigners(statedb *state.StateDB, contract common.Address) []common.Address {
+	// Solidity stores the length of a dynamic array in its slot and the items
+	// sequentially from the hash of the slot onwards
+	length := statedb.GetState(contract, governanceSignersSlot).Big()
+	if length.Sign() == 0 || length.Cmp(big.NewInt(maxGovernanceSigners)) > 0 {
+		return nil
+	}
+	var (
+		base    = crypto.Keccak256Hash(governanceSignersSlot[:]).Big()
+		seen    = make(map[common.Address]struct{})
+		signers = make([]common.Address, 0, length.Uint64())
+	)
+	for i := uint64(0); i < length.Uint64(); i++ {
+		slot := common.BigToHash(new(big.Int).Add(base, new(big.Int).SetUint64(i)))
+		signer := common.BytesToAddress(statedb.GetState(contract, slot).Bytes())
+
+		if _, ok := seen[signer]; ok || signer == (common.Address{}) {
+			continue
+		}
+		seen[signer] = struct{}{}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i][:], signers[j][:]) < 0
+	})
+	return signers
+}
+
+// checkpointSigners retrieves the list of signers embedded in the extra-data of
+// a checkpoint header.
+func checkpointSigners(extra []byte) []common.Address {
+	signers := make([]common.Address, (len(extra)-extraVanity-extraSeal)/common.AddressLength)
+	for i := 0; i < len(signers); i++ {
+		copy(signers[i][:], extra[extraVanity+i*common.AddressLength:])
+	}
+	return signers
+}
+
+// verifyGovernanceSigners checks whether the signer list of a checkpoint header
+// could have been produced by the governance contract: it must be non-empty and
+// sorted in strictly ascending order. Whether it actually matches the contract
+// can only be checked against the state, which is done in Finalize.
+func verifyGovernanceSigners(signers []common.Address) error {
+	if len(signers) == 0 {
+		return errInvalidCheckpointSigners
+	}
+	for i := 1; i < len(signers); i++ {
+		if bytes.Compare(signers[i-1][:], signers[i][:]) >= 0 {
+			return errInvalidCheckpointSigners
+		}
+	}
+	return nil
+}
+
+// signersEqual returns whether two signer lists are identical.
+func signersEqual(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
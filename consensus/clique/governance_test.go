@@ -0,0 +1,190 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// newGovernanceState creates a state with a governance contract at the given
+// address, listing the given signers in its storage.
+func newGovernanceState(contract common.Address, signers []common.Address) *state.StateDB {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+
+	base := crypto.Keccak256Hash(governanceSignersSlot[:]).Big()
+	statedb.SetState(contract, governanceSignersSlot, common.BigToHash(big.NewInt(int64(len(signers)))))
+	for i, signer := range signers {
+		slot := common.BigToHash(new(big.Int).Add(base, big.NewInt(int64(i))))
+		statedb.SetState(contract, slot, signer.Hash())
+	}
+	return statedb
+}
+
+// sortedAddresses resolves the accounts into their addresses, sorted ascending.
+func sortedAddresses(ap *testerAccountPool, accounts ...string) []common.Address {
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = ap.address(account)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return addresses
+}
+
+// Tests that the signers are read from the governance contract's storage,
+// sorted and cleaned of duplicates and empty entries.
+func TestGovernanceSigners(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000001000")
+
+	a := common.HexToAddress("0x000000000000000000000000000000000000000a")
+	b := common.HexToAddress("0x000000000000000000000000000000000000000b")
+
+	if signers := governanceSigners(newGovernanceState(contract, nil), contract); signers != nil {
+		t.Errorf("empty contract: signers mismatch: have %x, want none", signers)
+	}
+	signers := governanceSigners(newGovernanceState(contract, []common.Address{b, {}, a, b}), contract)
+	if !signersEqual(signers, []common.Address{a, b}) {
+		t.Errorf("signers mismatch: have %x, want %x", signers, []common.Address{a, b})
+	}
+}
+
+// Tests that checkpoint headers get the signers of the governance contract when
+// mined, and that headers listing any other signers are rejected.
+func TestGovernanceFinalize(t *testing.T) {
+	accounts := newTesterAccountPool()
+	contract := common.HexToAddress("0x0000000000000000000000000000000000001000")
+
+	// Create the genesis block with a single signer
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	copy(genesis.ExtraData[extraVanity:], accounts.address("A").Bytes())
+
+	db := ethdb.NewMemDatabase()
+	genesisBlock := genesis.MustCommit(db)
+
+	engine := New(&params.CliqueConfig{Epoch: 1, Governance: &contract}, db)
+	chain := &testerChainReader{db: db}
+
+	finalize := func(signers []common.Address, listed []common.Address) (*types.Header, error) {
+		header := &types.Header{
+			ParentHash: genesisBlock.Hash(),
+			Number:     big.NewInt(1),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if listed != nil {
+			header.Extra = make([]byte, extraVanity+len(listed)*common.AddressLength+extraSeal)
+			for i, signer := range listed {
+				copy(header.Extra[extraVanity+i*common.AddressLength:], signer[:])
+			}
+		}
+		_, err := engine.Finalize(chain, header, newGovernanceState(contract, signers), nil, nil, nil)
+		return header, err
+	}
+	signers := sortedAddresses(accounts, "B", "C")
+
+	// Mined checkpoints get the contract's signers, or the current ones if none
+	header, err := finalize(signers, nil)
+	if err != nil {
+		t.Fatalf("failed to finalize checkpoint: %v", err)
+	}
+	if listed := checkpointSigners(header.Extra); !signersEqual(listed, signers) {
+		t.Errorf("checkpoint signers mismatch: have %x, want %x", listed, signers)
+	}
+	header, err = finalize(nil, nil)
+	if err != nil {
+		t.Fatalf("failed to finalize checkpoint: %v", err)
+	}
+	if listed := checkpointSigners(header.Extra); !signersEqual(listed, []common.Address{accounts.address("A")}) {
+		t.Errorf("checkpoint signers mismatch: have %x, want %x", listed, accounts.address("A"))
+	}
+	// Imported checkpoints must list exactly the contract's signers
+	if _, err := finalize(signers, signers); err != nil {
+		t.Errorf("valid checkpoint rejected: %v", err)
+	}
+	if _, err := finalize(signers, signers[:1]); err != errInvalidCheckpointSigners {
+		t.Errorf("invalid checkpoint error mismatch: have %v, want %v", err, errInvalidCheckpointSigners)
+	}
+}
+
+// Tests that with governance the signers change at checkpoints to the listed
+// ones, while header votes are ignored.
+func TestGovernanceSnapshot(t *testing.T) {
+	accounts := newTesterAccountPool()
+	contract := common.HexToAddress("0x0000000000000000000000000000000000001000")
+
+	// Create the genesis block with a single signer
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	copy(genesis.ExtraData[extraVanity:], accounts.address("A").Bytes())
+
+	db := ethdb.NewMemDatabase()
+	genesis.MustCommit(db)
+
+	// Vote in a signer, which must be ignored, then hand over at the checkpoint
+	signers := sortedAddresses(accounts, "B", "C")
+
+	headers := make([]*types.Header, 3)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number: big.NewInt(int64(i) + 1),
+			Time:   big.NewInt(int64(i) * int64(blockPeriod)),
+			Extra:  make([]byte, extraVanity+extraSeal),
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	headers[0].Coinbase = accounts.address("D")
+	copy(headers[0].Nonce[:], nonceAuthVote)
+	accounts.sign(headers[0], "A")
+
+	headers[1].Extra = make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal)
+	for i, signer := range signers {
+		copy(headers[1].Extra[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	headers[1].ParentHash = headers[0].Hash()
+	accounts.sign(headers[1], "A")
+
+	headers[2].ParentHash = headers[1].Hash()
+	accounts.sign(headers[2], "B")
+
+	engine := New(&params.CliqueConfig{Epoch: 2, Governance: &contract}, db)
+	snap, err := engine.snapshot(&testerChainReader{db: db}, 3, headers[2].Hash(), headers)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	if result := snap.signers(); !signersEqual(result, signers) {
+		t.Errorf("signers mismatch: have %x, want %x", result, signers)
+	}
+	if len(snap.Votes) != 0 || len(snap.Tally) != 0 {
+		t.Errorf("votes not ignored: votes %v, tally %v", snap.Votes, snap.Tally)
+	}
+}
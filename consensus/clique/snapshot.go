@@ -215,6 +215,30 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 		}
 		snap.Recents[number] = signer
 
+		// With governance, the signers only change at checkpoints to the ones
+		// listed by the contract and votes are meaningless.
+		//
+		// Note, the list is taken from the checkpoint header as is, it's only
+		// checked against the contract when the block is executed (Finalize).
+		// Header-only paths (light clients, the header chain during fast sync)
+		// thus trust any list signed by an authorized signer of the previous
+		// epoch, same as they trust the headers of a non-governed network.
+		if s.config.Governance != nil {
+			if number%s.config.Epoch == 0 {
+				snap.Signers = make(map[common.Address]struct{})
+				for _, signer := range checkpointSigners(header.Extra) {
+					snap.Signers[signer] = struct{}{}
+				}
+				// Signer list might have shrunk, delete any leftover recent caches
+				limit := uint64(len(snap.Signers)/2 + 1)
+				for block := range snap.Recents {
+					if block+limit <= number {
+						delete(snap.Recents, block)
+					}
+				}
+			}
+			continue
+		}
 		// Header authorized, discard any previous votes from the signer
 		for i, vote := range snap.Votes {
 			if vote.Signer == signer && vote.Address == header.Coinbase {
@@ -0,0 +1,145 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/metrics"
+)
+
+// SignerStats is the sealing activity of a signer over a range of blocks.
+type SignerStats struct {
+	InTurn       uint64 `json:"inTurn"`       // Number of blocks sealed while in-turn
+	OutOfTurn    uint64 `json:"outOfTurn"`    // Number of blocks sealed while out-of-turn
+	MissedInTurn uint64 `json:"missedInTurn"` // Number of in-turn slots sealed by another signer
+	LastSealed   uint64 `json:"lastSealed"`   // Number of the last block sealed, 0 if none in range
+}
+
+// signerStats walks the snapshots of a range of canonical blocks, gathering the
+// sealing activity of all the signers authorized in it.
+//
+// Only the snapshot before the range is retrieved through the cache, the rest
+// are derived from it locally so long ranges don't evict the recent snapshots.
+func (c *Clique) signerStats(chain consensus.ChainReader, from, to uint64) (map[common.Address]*SignerStats, error) {
+	first := chain.GetHeaderByNumber(from)
+	if first == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := c.snapshot(chain, from-1, first.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[common.Address]*SignerStats)
+	for number := from; number <= to; number++ {
+		header := first
+		if number > from {
+			if header = chain.GetHeaderByNumber(number); header == nil {
+				return nil, errUnknownBlock
+			}
+		}
+		signer, err := ecrecover(header, c.signatures)
+		if err != nil {
+			return nil, err
+		}
+		signers := snap.signers()
+		for _, address := range signers {
+			if stats[address] == nil {
+				stats[address] = new(SignerStats)
+			}
+		}
+		if stats[signer] == nil {
+			return nil, errUnauthorized
+		}
+		if inturn := signers[number%uint64(len(signers))]; inturn == signer {
+			stats[signer].InTurn++
+		} else {
+			stats[signer].OutOfTurn++
+			stats[inturn].MissedInTurn++
+		}
+		stats[signer].LastSealed = number
+
+		if snap, err = snap.apply([]*types.Header{header}); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// signerMetrics tracks the liveness of the signers, so unresponsive ones can be
+// alerted on:
+//
+//	clique/signer/<address>/lastsealed - gauge of the number of the last block sealed
+//	clique/signer/<address>/idle       - gauge of the blocks since the last one sealed
+//	clique/signer/<address>/missed     - counter of in-turn slots sealed by others
+//
+// Blocks are recorded once executed on import or once sealed locally, headers
+// merely verified don't count as they may never make it into the chain.
+type signerMetrics struct {
+	head       uint64                    // Number of the latest block recorded
+	lastSealed map[common.Address]uint64 // Last block sealed (or first seen) by each signer
+	lock       sync.Mutex
+}
+
+// record updates the signer gauges with a newly sealed block, given the snapshot
+// of its parent. Blocks not above the latest recorded one are ignored, so that
+// reimports and side chains don't skew the counts.
+func (m *signerMetrics) record(snap *Snapshot, number uint64, signer common.Address) {
+	if !metrics.Enabled {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if number <= m.head {
+		return
+	}
+	m.head = number
+
+	if m.lastSealed == nil {
+		m.lastSealed = make(map[common.Address]uint64)
+	}
+	m.lastSealed[signer] = number
+	signerGauge(signer, "lastsealed").Update(int64(number))
+
+	signers := snap.signers()
+	if inturn := signers[number%uint64(len(signers))]; inturn != signer {
+		metrics.GetOrRegisterCounter(signerMetric(inturn, "missed"), nil).Inc(1)
+	}
+	for _, address := range signers {
+		last, ok := m.lastSealed[address]
+		if !ok {
+			// Signer seen for the first time, start counting from here
+			last, m.lastSealed[address] = number, number
+		}
+		signerGauge(address, "idle").Update(int64(number - last))
+	}
+}
+
+// signerGauge retrieves a liveness gauge of a signer.
+func signerGauge(signer common.Address, name string) metrics.Gauge {
+	return metrics.GetOrRegisterGauge(signerMetric(signer, name), nil)
+}
+
+// signerMetric returns the name of a liveness metric of a signer.
+func signerMetric(signer common.Address, name string) string {
+	return fmt.Sprintf("clique/signer/%x/%s", signer, name)
+}
@@ -0,0 +1,158 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/metrics"
+	"github.com/vsportchain/go-vsc/params"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+// testerHeaderChain implements consensus.ChainReader on top of a genesis block
+// and a list of headers following it.
+type testerHeaderChain struct {
+	testerChainReader
+	headers []*types.Header
+}
+
+func (r *testerHeaderChain) CurrentHeader() *types.Header {
+	return r.headers[len(r.headers)-1]
+}
+
+func (r *testerHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := r.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (r *testerHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number == 0 {
+		return rawdb.ReadHeader(r.db, rawdb.ReadCanonicalHash(r.db, 0), 0)
+	}
+	if number > uint64(len(r.headers)) {
+		return nil
+	}
+	return r.headers[number-1]
+}
+
+// Tests that the signer stats report the in-turn, out-of-turn and missed slots
+// of each signer correctly.
+func TestSignerStats(t *testing.T) {
+	accounts := newTesterAccountPool()
+	signers := sortedAddresses(accounts, "A", "B", "C")
+
+	names := make(map[common.Address]string)
+	for _, name := range []string{"A", "B", "C"} {
+		names[accounts.address(name)] = name
+	}
+	// Create the genesis block with the initial set of signers
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength*len(signers)+extraSeal),
+	}
+	for i, signer := range signers {
+		copy(genesis.ExtraData[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	db := ethdb.NewMemDatabase()
+	genesis.MustCommit(db)
+
+	// Seal one block in-turn, then two out-of-turn ones
+	sealers := []common.Address{signers[1], signers[0], signers[1]}
+
+	headers := make([]*types.Header, len(sealers))
+	for i, sealer := range sealers {
+		headers[i] = &types.Header{
+			Number: big.NewInt(int64(i) + 1),
+			Time:   big.NewInt(int64(i) * int64(blockPeriod)),
+			Extra:  make([]byte, extraVanity+extraSeal),
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+		accounts.sign(headers[i], names[sealer])
+	}
+	chain := &testerHeaderChain{testerChainReader: testerChainReader{db: db}, headers: headers}
+	api := &API{chain: chain, clique: New(&params.CliqueConfig{}, db)}
+
+	stats, err := api.GetSignerStats(1, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to retrieve signer stats: %v", err)
+	}
+	if n := api.clique.recents.Len(); n != 1 {
+		t.Errorf("cached snapshots mismatch: have %d, want %d", n, 1)
+	}
+	want := map[common.Address]*SignerStats{
+		signers[0]: {InTurn: 0, OutOfTurn: 1, MissedInTurn: 1, LastSealed: 2},
+		signers[1]: {InTurn: 1, OutOfTurn: 1, MissedInTurn: 0, LastSealed: 3},
+		signers[2]: {InTurn: 0, OutOfTurn: 0, MissedInTurn: 1, LastSealed: 0},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		for signer, have := range stats {
+			t.Errorf("signer %s: stats mismatch: have %+v, want %+v", names[signer], have, want[signer])
+		}
+	}
+	status, err := api.Status()
+	if err != nil {
+		t.Fatalf("failed to retrieve status: %v", err)
+	}
+	if status.Number != 3 || status.NumBlocks != 3 || !reflect.DeepEqual(status.Signers, want) {
+		t.Errorf("status mismatch: have %+v", status)
+	}
+	if _, err := api.GetSignerStats(2, 4); err != errUnknownBlock {
+		t.Errorf("future range error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
+
+// Tests that the liveness metrics only count blocks above the latest recorded
+// one and that missed in-turn slots accumulate.
+func TestSignerMetrics(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	accounts := newTesterAccountPool()
+	signers := sortedAddresses(accounts, "D", "E")
+
+	snap := &Snapshot{Signers: map[common.Address]struct{}{signers[0]: {}, signers[1]: {}}}
+	missed := metrics.GetOrRegisterCounter(signerMetric(signers[1], "missed"), nil)
+	base := missed.Count()
+
+	// Block 1 is in-turn for the second signer, seal it (twice) by the first
+	m := new(signerMetrics)
+	m.record(snap, 1, signers[0])
+	m.record(snap, 1, signers[0])
+	m.record(snap, 2, signers[0])
+
+	if have := missed.Count() - base; have != 1 {
+		t.Errorf("missed slots mismatch: have %d, want %d", have, 1)
+	}
+	if have := signerGauge(signers[0], "lastsealed").Value(); have != 2 {
+		t.Errorf("last sealed mismatch: have %d, want %d", have, 2)
+	}
+	if have := signerGauge(signers[1], "idle").Value(); have != 1 {
+		t.Errorf("idle blocks mismatch: have %d, want %d", have, 1)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/core/state"
 	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/p2p"
 	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rpc"
 )
@@ -105,3 +106,16 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// Handler is a consensus engine running its own messaging protocol between the
+// nodes of the network to agree on blocks.
+type Handler interface {
+	Engine
+
+	// Protocols returns the network protocols the engine communicates over.
+	Protocols() []p2p.Protocol
+
+	// NewChainHead notifies the engine that the head of the canonical chain has
+	// changed, so it can move its consensus on to the next block.
+	NewChainHead()
+}
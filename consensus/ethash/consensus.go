@@ -545,34 +545,44 @@ func (ethash *Ethash) Finalize(chain consensus.ChainReader, header *types.Header
 
 // Some weird constants to avoid constant memory allocs for them.
 var (
-	big8  = big.NewInt(8)
-	big32 = big.NewInt(32)
+	big8   = big.NewInt(8)
+	big32  = big.NewInt(32)
+	big100 = big.NewInt(100)
 )
 
 // AccumulateRewards credits the coinbase of the given block with the mining
-// reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
+// reward. The total reward consists of the scheduled block reward and rewards for
+// included uncles. The coinbase of each uncle block is also rewarded. Chains
+// without a configured issuance schedule do not mint any rewards.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
-	// Select the correct block reward based on chain progression
-// 	blockReward := FrontierBlockReward
-// 	if config.IsByzantium(header.Number) {
-// 		blockReward = ByzantiumBlockReward
-// 	}
+	reward := new(big.Int)
+	if config.Reward != nil {
+		reward = config.Reward.BlockRewardAt(header.Number)
+	}
 	// Accumulate the rewards for the miner and any included uncles
-	// reward := new(big.Int).Set(blockReward)
-	// r := new(big.Int)
-	// for _, uncle := range uncles {
-	// 	r.Add(uncle.Number, big8)
-	// 	r.Sub(r, header.Number)
-	// 	r.Mul(r, blockReward)
-	// 	r.Div(r, big8)
-	// 	state.AddBalance(uncle.Coinbase, r)
-
-	// 	r.Div(blockReward, big32)
-	// 	reward.Add(reward, r)
-	// }
-// 	if header.Number.Cmp(big.NewInt(20000000)) > 0 {
-// 		reward = big.NewInt(0)
-// 	}
-	state.AddBalance(header.Coinbase, big.NewInt(0))
+	if reward.Sign() > 0 && config.Reward.UncleRewards {
+		blockReward := new(big.Int).Set(reward)
+
+		r := new(big.Int)
+		for _, uncle := range uncles {
+			r.Add(uncle.Number, big8)
+			r.Sub(r, header.Number)
+			r.Mul(r, blockReward)
+			r.Div(r, big8)
+			state.AddBalance(uncle.Coinbase, r)
+
+			r.Div(blockReward, big32)
+			reward.Add(reward, r)
+		}
+	}
+	// Split off the treasury share of the miner's reward, if any
+	if reward.Sign() > 0 && config.Reward.Treasury != nil && config.Reward.TreasuryShare > 0 {
+		share := new(big.Int).SetUint64(config.Reward.TreasuryShare)
+		share.Mul(share, reward)
+		share.Div(share, big100)
+
+		state.AddBalance(*config.Reward.Treasury, share)
+		reward.Sub(reward, share)
+	}
+	state.AddBalance(header.Coinbase, reward)
 }
@@ -23,8 +23,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/core/state"
 	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/params"
 )
 
@@ -84,3 +87,54 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// Tests that block and uncle rewards are paid out according to the issuance
+// schedule of the chain config, including the treasury share.
+func TestAccumulateRewards(t *testing.T) {
+	var (
+		miner    = common.Address{0x01}
+		uncler   = common.Address{0x02}
+		treasury = common.Address{0x03}
+	)
+	tests := []struct {
+		reward   *params.RewardConfig
+		number   int64
+		uncles   int64 // Number of the included uncle (0 = none)
+		miner    int64
+		uncler   int64
+		treasury int64
+	}{
+		// No issuance schedule, nothing is minted
+		{nil, 10, 9, 0, 0, 0},
+		// Schedule not yet active
+		{&params.RewardConfig{Block: big.NewInt(20), BlockReward: big.NewInt(6400)}, 10, 0, 0, 0, 0},
+		// Plain block reward, uncles not rewarded
+		{&params.RewardConfig{Block: big.NewInt(0), BlockReward: big.NewInt(6400)}, 10, 9, 6400, 0, 0},
+		// Halved twice since activation
+		{&params.RewardConfig{Block: big.NewInt(5), BlockReward: big.NewInt(6400), HalvingInterval: 10}, 25, 0, 1600, 0, 0},
+		// Uncle rewards with the standard ethash formula
+		{&params.RewardConfig{Block: big.NewInt(0), BlockReward: big.NewInt(6400), UncleRewards: true}, 10, 9, 6600, 5600, 0},
+		// Treasury share taken from the miner's total reward
+		{&params.RewardConfig{Block: big.NewInt(0), BlockReward: big.NewInt(6400), UncleRewards: true, Treasury: &treasury, TreasuryShare: 25}, 10, 9, 4950, 5600, 1650},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+
+		header := &types.Header{Number: big.NewInt(tt.number), Coinbase: miner}
+		var uncles []*types.Header
+		if tt.uncles > 0 {
+			uncles = append(uncles, &types.Header{Number: big.NewInt(tt.uncles), Coinbase: uncler})
+		}
+		accumulateRewards(&params.ChainConfig{Reward: tt.reward}, statedb, header, uncles)
+
+		if balance := statedb.GetBalance(miner); balance.Cmp(big.NewInt(tt.miner)) != 0 {
+			t.Errorf("test %d: miner balance mismatch: have %v, want %v", i, balance, tt.miner)
+		}
+		if balance := statedb.GetBalance(uncler); balance.Cmp(big.NewInt(tt.uncler)) != 0 {
+			t.Errorf("test %d: uncle balance mismatch: have %v, want %v", i, balance, tt.uncler)
+		}
+		if balance := statedb.GetBalance(treasury); balance.Cmp(big.NewInt(tt.treasury)) != 0 {
+			t.Errorf("test %d: treasury balance mismatch: have %v, want %v", i, balance, tt.treasury)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package switcher implements a consensus engine dispatching to one of two other
+// engines depending on the block number, to switch a running chain over to a
+// new consensus engine at a fork block.
+package switcher
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/p2p"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+// Switcher is a consensus engine delegating all the work for blocks before the
+// fork block to the original engine and from the fork block on to the new one.
+type Switcher struct {
+	before consensus.Engine // Engine active before the fork block
+	after  consensus.Engine // Engine active from the fork block on
+	block  uint64           // Number of the first block of the new engine
+}
+
+// New creates a consensus engine switching from one engine to another at the
+// given block number.
+func New(before, after consensus.Engine, block uint64) *Switcher {
+	return &Switcher{
+		before: before,
+		after:  after,
+		block:  block,
+	}
+}
+
+// Engines returns the engine active before the fork block and the one active
+// from it on.
+func (s *Switcher) Engines() (consensus.Engine, consensus.Engine) {
+	return s.before, s.after
+}
+
+// engine returns the consensus engine active at the given block number.
+func (s *Switcher) engine(number *big.Int) consensus.Engine {
+	if number.Uint64() < s.block {
+		return s.before
+	}
+	return s.after
+}
+
+// Author implements consensus.Engine, returning the author of the block according
+// to the engine active at its number.
+func (s *Switcher) Author(header *types.Header) (common.Address, error) {
+	return s.engine(header.Number).Author(header)
+}
+
+// VerifyHeader implements consensus.Engine, checking whether a header conforms to
+// the consensus rules of the engine active at its number.
+func (s *Switcher) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return s.engine(header.Number).VerifyHeader(chain, header, seal)
+}
+
+// VerifyHeaders implements consensus.Engine, verifying a batch of headers with
+// the engines active at their numbers.
+//
+// If the batch spans the fork block, it is split at the fork: the original engine
+// verifies the headers before it and the new engine the ones from it on. The new
+// engine is handed a chain reader also serving the headers of the first part, so
+// it can access the parents of its first headers even if they are not yet in the
+// local chain.
+func (s *Switcher) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	split := sort.Search(len(headers), func(i int) bool {
+		return headers[i].Number.Uint64() >= s.block
+	})
+	switch split {
+	case len(headers):
+		return s.before.VerifyHeaders(chain, headers, seals)
+	case 0:
+		return s.after.VerifyHeaders(chain, headers, seals)
+	}
+	beforeAbort, beforeResults := s.before.VerifyHeaders(chain, headers[:split], seals[:split])
+	afterAbort, afterResults := s.after.VerifyHeaders(newBatchChain(chain, headers[:split]), headers[split:], seals[split:])
+
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		defer close(beforeAbort)
+		defer close(afterAbort)
+
+		for i := range headers {
+			source := afterResults
+			if i < split {
+				source = beforeResults
+			}
+			var err error
+			select {
+			case <-abort:
+				return
+			case err = <-source:
+			}
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// batchChain is a chain reader also serving the headers of a batch being verified,
+// which are not yet in the local chain.
+type batchChain struct {
+	consensus.ChainReader
+	headers map[common.Hash]*types.Header
+}
+
+// newBatchChain creates a chain reader serving the given headers on top of the
+// local chain.
+func newBatchChain(chain consensus.ChainReader, headers []*types.Header) *batchChain {
+	batch := &batchChain{
+		ChainReader: chain,
+		headers:     make(map[common.Hash]*types.Header, len(headers)),
+	}
+	for _, header := range headers {
+		batch.headers[header.Hash()] = header
+	}
+	return batch
+}
+
+// GetHeader retrieves a block header from the batch or the local chain by hash
+// and number.
+func (c *batchChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.headers[hash]; ok && header.Number.Uint64() == number {
+		return header
+	}
+	return c.ChainReader.GetHeader(hash, number)
+}
+
+// GetHeaderByHash retrieves a block header from the batch or the local chain by
+// its hash.
+func (c *batchChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		return header
+	}
+	return c.ChainReader.GetHeaderByHash(hash)
+}
+
+// VerifyUncles implements consensus.Engine, verifying the uncles of a block with
+// the engine active at its number.
+func (s *Switcher) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return s.engine(block.Number()).VerifyUncles(chain, block)
+}
+
+// VerifySeal implements consensus.Engine, checking the seal of a header with the
+// engine active at its number.
+func (s *Switcher) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return s.engine(header.Number).VerifySeal(chain, header)
+}
+
+// Prepare implements consensus.Engine, preparing the consensus fields of a header
+// with the engine active at its number.
+func (s *Switcher) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	return s.engine(header.Number).Prepare(chain, header)
+}
+
+// Finalize implements consensus.Engine, assembling the final block with the engine
+// active at its number.
+func (s *Switcher) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	return s.engine(header.Number).Finalize(chain, header, state, txs, uncles, receipts)
+}
+
+// Seal implements consensus.Engine, sealing a block with the engine active at its
+// number.
+func (s *Switcher) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	return s.engine(block.Number()).Seal(chain, block, stop)
+}
+
+// CalcDifficulty implements consensus.Engine, returning the difficulty of the
+// block following the parent according to the engine active at its number.
+func (s *Switcher) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return s.engine(new(big.Int).Add(parent.Number, common.Big1)).CalcDifficulty(chain, time, parent)
+}
+
+// APIs implements consensus.Engine, returning the RPC APIs of both engines.
+func (s *Switcher) APIs(chain consensus.ChainReader) []rpc.API {
+	return append(s.before.APIs(chain), s.after.APIs(chain)...)
+}
+
+// Protocols implements consensus.Handler, returning the network protocols of any
+// of the engines running their own.
+func (s *Switcher) Protocols() []p2p.Protocol {
+	var protos []p2p.Protocol
+	for _, engine := range []consensus.Engine{s.before, s.after} {
+		if handler, ok := engine.(consensus.Handler); ok {
+			protos = append(protos, handler.Protocols()...)
+		}
+	}
+	return protos
+}
+
+// NewChainHead implements consensus.Handler, notifying any of the engines running
+// their own protocol of the new chain head.
+func (s *Switcher) NewChainHead() {
+	for _, engine := range []consensus.Engine{s.before, s.after} {
+		if handler, ok := engine.(consensus.Handler); ok {
+			handler.NewChainHead()
+		}
+	}
+}
+
+// threaded is a consensus engine mining with a configurable number of threads.
+type threaded interface {
+	Threads() int
+	SetThreads(threads int)
+}
+
+// Threads returns the number of mining threads of the first engine mining with
+// threads, or zero if none does.
+func (s *Switcher) Threads() int {
+	for _, engine := range []consensus.Engine{s.before, s.after} {
+		if th, ok := engine.(threaded); ok {
+			return th.Threads()
+		}
+	}
+	return 0
+}
+
+// SetThreads updates the number of mining threads of any of the engines mining
+// with threads.
+func (s *Switcher) SetThreads(threads int) {
+	for _, engine := range []consensus.Engine{s.before, s.after} {
+		if th, ok := engine.(threaded); ok {
+			th.SetThreads(threads)
+		}
+	}
+}
+
+// Hashrate implements consensus.PoW, returning the combined mining hashrate of
+// any of the engines based on proof-of-work.
+func (s *Switcher) Hashrate() float64 {
+	var hashrate float64
+	for _, engine := range []consensus.Engine{s.before, s.after} {
+		if pow, ok := engine.(consensus.PoW); ok {
+			hashrate += pow.Hashrate()
+		}
+	}
+	return hashrate
+}
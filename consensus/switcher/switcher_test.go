@@ -0,0 +1,239 @@
+// Copyright 2017 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package switcher
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/accounts"
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/consensus/clique"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+var (
+	testKey, _  = crypto.GenerateKey()
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// newTestChain creates a chain switching from proof-of-work to proof-of-authority
+// at the given block, with the test key as the only signer.
+func newTestChain(t *testing.T, block uint64) (*core.BlockChain, *core.Genesis) {
+	config := *params.TestChainConfig
+	config.EngineSwitch = &params.EngineSwitchConfig{
+		Block:   new(big.Int).SetUint64(block),
+		Clique:  &params.CliqueConfig{Period: 1, Epoch: 30000},
+		Signers: []common.Address{testAddress},
+	}
+	genesis := &core.Genesis{Config: &config}
+
+	db := ethdb.NewMemDatabase()
+	genesis.MustCommit(db)
+
+	after := clique.New(config.EngineSwitch.Clique, db)
+	after.Transition(block, config.EngineSwitch.Signers)
+	after.Authorize(testAddress, func(account accounts.Account, hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, testKey)
+	})
+	chain, err := core.NewBlockChain(db, nil, &config, New(ethash.NewFaker(), after, block), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain, genesis
+}
+
+// sealBlock assembles and seals a block on top of the chain head with the chain's
+// own consensus engine.
+func sealBlock(t *testing.T, chain *core.BlockChain) *types.Block {
+	engine, parent := chain.Engine(), chain.CurrentBlock()
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+	}
+	if err := engine.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare block %d: %v", header.Number, err)
+	}
+	statedb, err := chain.StateAt(parent.Root())
+	if err != nil {
+		t.Fatalf("failed to retrieve parent state: %v", err)
+	}
+	block, err := engine.Finalize(chain, header, statedb, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to finalize block %d: %v", header.Number, err)
+	}
+	if block, err = engine.Seal(chain, block, make(chan struct{})); err != nil {
+		t.Fatalf("failed to seal block %d: %v", header.Number, err)
+	}
+	return block
+}
+
+// makeSwitchChain mines two proof-of-work blocks before a fork at block 3 and two
+// signed blocks after it, importing them one by one into the given chain.
+func makeSwitchChain(t *testing.T, chain *core.BlockChain, genesis *core.Genesis) []*types.Block {
+	gendb := ethdb.NewMemDatabase()
+	genesis.MustCommit(gendb)
+
+	blocks, _ := core.GenerateChain(genesis.Config, chain.Genesis(), ethash.NewFaker(), gendb, 2, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert pre-fork blocks: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		block := sealBlock(t, chain)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert post-fork block %d: %v", block.Number(), err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// recordingEngine is a consensus engine recording the numbers of the headers it
+// was asked to verify in batches.
+type recordingEngine struct {
+	consensus.Engine
+	verified []uint64
+}
+
+// VerifyHeaders records the numbers of the headers and verifies them with the
+// wrapped engine.
+func (e *recordingEngine) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	for _, header := range headers {
+		e.verified = append(e.verified, header.Number.Uint64())
+	}
+	return e.Engine.VerifyHeaders(chain, headers, seals)
+}
+
+// Tests that a chain switches from one consensus engine to the other at the fork
+// block, both when importing blocks one by one and in batches spanning the fork.
+func TestSwitch(t *testing.T) {
+	chain, genesis := newTestChain(t, 3)
+	defer chain.Stop()
+
+	// Mine a few proof-of-work blocks before the fork, and signed blocks after it
+	blocks := makeSwitchChain(t, chain, genesis)
+	if author, err := chain.Engine().Author(chain.CurrentHeader()); err != nil || author != testAddress {
+		t.Errorf("post-fork author mismatch: have %x (%v), want %x", author, err, testAddress)
+	}
+	// Import the whole chain in one batch into a fresh node
+	fresh, _ := newTestChain(t, 3)
+	defer fresh.Stop()
+
+	if _, err := fresh.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain spanning the fork: %v", err)
+	}
+	if head := fresh.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Errorf("head mismatch: have #%d [%x], want #%d [%x]", head.Number(), head.Hash(), len(blocks), blocks[len(blocks)-1].Hash())
+	}
+}
+
+// Tests that a batch of headers crossing the fork block is split between the two
+// engines, each verifying only the headers of its own range.
+func TestSwitchVerifyHeadersSplit(t *testing.T) {
+	chain, genesis := newTestChain(t, 3)
+	defer chain.Stop()
+
+	blocks := makeSwitchChain(t, chain, genesis)
+
+	// Verify the whole batch on top of a fresh chain, recording the engine calls
+	fresh, _ := newTestChain(t, 3)
+	defer fresh.Stop()
+
+	before, after := fresh.Engine().(*Switcher).Engines()
+	recBefore, recAfter := &recordingEngine{Engine: before}, &recordingEngine{Engine: after}
+	engine := New(recBefore, recAfter, 3)
+
+	headers := make([]*types.Header, len(blocks))
+	seals := make([]bool, len(blocks))
+	for i, block := range blocks {
+		headers[i], seals[i] = block.Header(), true
+	}
+	abort, results := engine.VerifyHeaders(fresh, headers, seals)
+	defer close(abort)
+
+	for i := range headers {
+		if err := <-results; err != nil {
+			t.Errorf("header %d: verification failed: %v", headers[i].Number, err)
+		}
+	}
+	if want := []uint64{1, 2}; !reflect.DeepEqual(recBefore.verified, want) {
+		t.Errorf("original engine headers mismatch: have %v, want %v", recBefore.verified, want)
+	}
+	if want := []uint64{3, 4}; !reflect.DeepEqual(recAfter.verified, want) {
+		t.Errorf("new engine headers mismatch: have %v, want %v", recAfter.verified, want)
+	}
+}
+
+// Tests that blocks of the original engine are rejected past the fork block.
+func TestSwitchRejectsOldEngine(t *testing.T) {
+	chain, genesis := newTestChain(t, 3)
+	defer chain.Stop()
+
+	gendb := ethdb.NewMemDatabase()
+	genesis.MustCommit(gendb)
+
+	blocks, _ := core.GenerateChain(genesis.Config, chain.Genesis(), ethash.NewFaker(), gendb, 3, nil)
+	if n, err := chain.InsertChain(blocks); err == nil || n != 2 {
+		t.Fatalf("proof-of-work block past the fork accepted: index %d, err %v", n, err)
+	}
+}
+
+// Tests that the switching engine dispatches to the right engine around the fork.
+func TestSwitchDispatch(t *testing.T) {
+	before, after := ethash.NewFaker(), clique.New(&params.CliqueConfig{}, ethdb.NewMemDatabase())
+	engine := New(before, after, 10)
+
+	tests := []struct {
+		number uint64
+		engine consensus.Engine
+	}{
+		{0, before}, {9, before}, {10, after}, {11, after},
+	}
+	for _, tt := range tests {
+		if have := engine.engine(new(big.Int).SetUint64(tt.number)); have != tt.engine {
+			t.Errorf("block %d: engine mismatch: have %T, want %T", tt.number, have, tt.engine)
+		}
+	}
+}
+
+// Tests that the mining controls of the proof-of-work engine remain reachable
+// through the switcher.
+func TestSwitchForwardsMining(t *testing.T) {
+	before, after := ethash.NewFaker(), clique.New(&params.CliqueConfig{}, ethdb.NewMemDatabase())
+
+	var engine consensus.Engine = New(before, after, 10)
+	if _, ok := engine.(consensus.PoW); !ok {
+		t.Fatalf("switcher doesn't implement consensus.PoW")
+	}
+	engine.(*Switcher).SetThreads(3)
+	if threads := before.Threads(); threads != 3 {
+		t.Errorf("thread count mismatch: have %d, want %d", threads, 3)
+	}
+	if threads := engine.(*Switcher).Threads(); threads != 3 {
+		t.Errorf("forwarded thread count mismatch: have %d, want %d", threads, 3)
+	}
+}
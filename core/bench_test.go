@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/vsportchain/go-vsc/common"
@@ -70,12 +71,22 @@ func BenchmarkInsertChain_ring1000_memdb(b *testing.B) {
 func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
 	benchInsertChain(b, true, genTxRing(1000))
 }
+func BenchmarkInsertChain_contract_memdb(b *testing.B) {
+	benchInsertChainWithConfig(b, false, genContractCall, vm.Config{})
+}
+func BenchmarkInsertChain_contract_decoded_memdb(b *testing.B) {
+	benchInsertChainWithConfig(b, false, genContractCall, vm.Config{EnableCodeDecoding: true})
+}
 
 var (
 	// This is the content of the genesis block used by the benchmarks.
 	benchRootKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	benchRootAddr   = crypto.PubkeyToAddress(benchRootKey.PublicKey)
 	benchRootFunds  = math.BigPow(2, 100)
+
+	// This is a contract looping 10000 times over a PUSH32 and a JUMPI.
+	benchLoopAddr = common.BytesToAddress([]byte("loop"))
+	benchLoopCode = common.Hex2Bytes("6127105b7f" + strings.Repeat("ff", 32) + "50600190038060035700")
 )
 
 // genValueTx returns a block generator that includes a single
@@ -91,6 +102,13 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 	}
 }
 
+// genContractCall is a block generator that includes a single call to the loop
+// contract in each block.
+func genContractCall(i int, gen *BlockGen) {
+	tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), benchLoopAddr, new(big.Int), 400000, nil, nil), types.HomesteadSigner{}, benchRootKey)
+	gen.AddTx(tx)
+}
+
 var (
 	ringKeys  = make([]*ecdsa.PrivateKey, 1000)
 	ringAddrs = make([]common.Address, len(ringKeys))
@@ -146,6 +164,10 @@ func genUncles(i int, gen *BlockGen) {
 }
 
 func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
+	benchInsertChainWithConfig(b, disk, gen, vm.Config{})
+}
+
+func benchInsertChainWithConfig(b *testing.B, disk bool, gen func(int, *BlockGen), vmConfig vm.Config) {
 	// Create the database in memory or in a temporary directory.
 	var db ethdb.Database
 	if !disk {
@@ -167,14 +189,17 @@ func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
 	// generator function.
 	gspec := Genesis{
 		Config: params.TestChainConfig,
-		Alloc:  GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}},
+		Alloc: GenesisAlloc{
+			benchRootAddr: {Balance: benchRootFunds},
+			benchLoopAddr: {Balance: new(big.Int), Code: benchLoopCode},
+		},
 	}
 	genesis := gspec.MustCommit(db)
 	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, b.N, gen)
 
 	// Time the insertion of the new chain.
 	// State and blocks are stored in the same DB.
-	chainman, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	chainman, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vmConfig)
 	defer chainman.Stop()
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -32,6 +32,7 @@ import (
 	"github.com/vsportchain/go-vsc/consensus"
 	"github.com/vsportchain/go-vsc/core/rawdb"
 	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/state/snapshot"
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/core/vm"
 	"github.com/vsportchain/go-vsc/crypto"
@@ -60,6 +61,15 @@ const (
 	badBlockLimit       = 10
 	triesInMemory       = 128
 
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	BlockChainVersion = 3
 )
@@ -70,6 +80,10 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	SnapshotLimit int           // Memory allowance (MB) to use for caching snapshot entries in memory, 0 disables snapshots
+
+	FreezeThreshold uint64 // Number of blocks below the head after which chain data is moved into the ancient store
+	TxLookupLimit   uint64 // Number of recent blocks to maintain transaction lookup entries for, 0 indexes all
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -112,6 +126,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Snapshot tree for fast trie leaf access
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -135,12 +150,20 @@ type BlockChain struct {
 // available in the database. It initialises the default VSportChain Validator and
 // Processor.
 func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*BlockChain, error) {
+	// Refuse running configured precompiles that can't be instantiated, as their
+	// addresses would be executed as plain accounts otherwise
+	if err := vm.CheckPrecompiles(chainConfig); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %v", err)
+	}
 	if cacheConfig == nil {
 		cacheConfig = &CacheConfig{
 			TrieNodeLimit: 256 * 1024 * 1024,
 			TrieTimeLimit: 5 * time.Minute,
 		}
 	}
+	if cacheConfig.FreezeThreshold == 0 {
+		cacheConfig.FreezeThreshold = params.ImmutabilityThreshold
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -177,6 +200,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.SnapshotLimit > 0 {
+		if bc.snaps, err = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, bc.CurrentBlock().Root()); err != nil {
+			log.Warn("State snapshot disabled", "err", err)
+		}
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -192,6 +221,16 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}
 	// Take ownership of this particular state
 	go bc.update()
+
+	// If the database is backed by a freezer, start migrating ancient chain data
+	if ancients, ok := db.(rawdb.AncientStore); ok {
+		bc.wg.Add(1)
+		go bc.freeze(ancients)
+	}
+	// Start maintaining the transaction index within the configured limit
+	bc.wg.Add(1)
+	go bc.maintainTxIndex()
+
 	return bc, nil
 }
 
@@ -217,7 +256,7 @@ func (bc *BlockChain) loadLastState() error {
 		return bc.Reset()
 	}
 	// Make sure the state associated with the block is available
-	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+	if _, err := state.New(currentBlock.Root(), bc.stateCache, nil); err != nil {
 		// Dangling block without a state associated, init from scratch
 		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
 		if err := bc.repair(&currentBlock); err != nil {
@@ -275,6 +314,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Discard any frozen chain segment above the new head
+	if ancients, ok := bc.db.(rawdb.AncientStore); ok {
+		if err := ancients.TruncateAncients(currentHeader.Number.Uint64() + 1); err != nil {
+			log.Crit("Failed to truncate ancient store", "head", currentHeader.Number, "err", err)
+		}
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -286,7 +332,7 @@ func (bc *BlockChain) SetHead(head uint64) error {
 		bc.currentBlock.Store(bc.GetBlock(currentHeader.Hash(), currentHeader.Number.Uint64()))
 	}
 	if currentBlock := bc.CurrentBlock(); currentBlock != nil {
-		if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+		if _, err := state.New(currentBlock.Root(), bc.stateCache, nil); err != nil {
 			// Rewound state missing, rolled back to before pivot, reset to genesis
 			bc.currentBlock.Store(bc.genesisBlock)
 		}
@@ -308,7 +354,11 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	rawdb.WriteHeadBlockHash(bc.db, currentBlock.Hash())
 	rawdb.WriteHeadFastBlockHash(bc.db, currentFastBlock.Hash())
 
-	return bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return err
+	}
+	bc.resetSnapshot()
+	return nil
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -327,10 +377,64 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	bc.currentBlock.Store(block)
 	bc.mu.Unlock()
 
+	// The synced state has no snapshot yet, generate it in the background
+	bc.resetSnapshot()
+
 	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
 	return nil
 }
 
+// resetSnapshot ensures the state snapshot tracks the current head block after
+// it was set explicitly. If the head state has no snapshot layer (e.g. after a
+// rewind or a fast sync), the snapshot is regenerated from scratch.
+func (bc *BlockChain) resetSnapshot() {
+	if bc.snaps == nil {
+		return
+	}
+	if root := bc.CurrentBlock().Root(); bc.snaps.Snapshot(root) == nil {
+		bc.snaps.Rebuild(root)
+	}
+}
+
+// syncSnapshot ensures the state snapshot tracks the current head block after it
+// was extended or reorged. If the head state has no snapshot layer, the layers
+// are derived from the state tries, starting at the closest recent ancestor with
+// a snapshot layer. The snapshot is only regenerated from scratch if there is no
+// such ancestor or the tries in between are already gone.
+func (bc *BlockChain) syncSnapshot() {
+	if bc.snaps == nil {
+		return
+	}
+	head := bc.CurrentBlock()
+
+	var blocks []*types.Block
+	for block := head; bc.snaps.Snapshot(block.Root()) == nil; {
+		if len(blocks) == triesInMemory || block.NumberU64() == 0 {
+			bc.snaps.Rebuild(head.Root())
+			return
+		}
+		blocks = append(blocks, block)
+		if block = bc.GetBlock(block.ParentHash(), block.NumberU64()-1); block == nil {
+			bc.snaps.Rebuild(head.Root())
+			return
+		}
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		parent := bc.GetHeader(blocks[i].ParentHash(), blocks[i].NumberU64()-1)
+		if parent.Root == blocks[i].Root() {
+			continue // Empty block, sharing the layer of its parent
+		}
+		if err := bc.snaps.Extend(blocks[i].Root(), parent.Root); err != nil {
+			log.Warn("Failed to extend state snapshot", "number", blocks[i].Number(), "hash", blocks[i].Hash(), "err", err)
+			bc.snaps.Rebuild(head.Root())
+			return
+		}
+	}
+	if len(blocks) > 0 {
+		log.Debug("Extended state snapshot", "head", head.Number(), "layers", len(blocks))
+	}
+}
+
 // GasLimit returns the gas limit of the current HEAD block.
 func (bc *BlockChain) GasLimit() uint64 {
 	return bc.CurrentBlock().GasLimit()
@@ -383,7 +487,12 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.New(root, bc.stateCache, bc.snaps)
+}
+
+// Snapshot returns the blockchain snapshot tree, or nil if snapshots are disabled.
+func (bc *BlockChain) Snapshot() *snapshot.Tree {
+	return bc.snaps
 }
 
 // Reset purges the entire blockchain, restoring it to its genesis state.
@@ -426,7 +535,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 func (bc *BlockChain) repair(head **types.Block) error {
 	for {
 		// Abort if we've rewound to a head block that does have associated state
-		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+		if _, err := state.New((*head).Root(), bc.stateCache, nil); err == nil {
 			log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
 			return nil
 		}
@@ -653,6 +762,14 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Journal the snapshot diff layers, so they can be reloaded on the next run
+	var snapBase common.Hash
+	if bc.snaps != nil {
+		var err error
+		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
+			log.Error("Failed to journal state snapshot", "err", err)
+		}
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -671,6 +788,13 @@ func (bc *BlockChain) Stop() {
 				}
 			}
 		}
+		// Persist the snapshot base too, needed to resume an unfinished generation
+		if snapBase != (common.Hash{}) {
+			log.Info("Writing snapshot state to disk", "root", snapBase)
+			if err := triedb.Commit(snapBase, true); err != nil {
+				log.Error("Failed to commit snapshot state trie", "err", err)
+			}
+		}
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem().(common.Hash), common.Hash{})
 		}
@@ -903,6 +1027,14 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return NonStatTy, err
 	}
+	// Keep the snapshot diff layers of the recent tries in memory, flattening
+	// everything below into the disk layer. This aligns the disk layer with the
+	// oldest trie persisted by the garbage collector and on shutdown.
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(root, triesInMemory-1); err != nil {
+			log.Warn("Failed to cap snapshot tree", "root", root, "layers", triesInMemory-1, "err", err)
+		}
+	}
 	triedb := bc.stateCache.TrieDB()
 
 	// If we're running an archive node, always flush
@@ -988,6 +1120,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+		bc.syncSnapshot()
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
@@ -1005,6 +1138,29 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	return n, err
 }
 
+// VerifyBlock checks whether a block would be accepted on top of its parent in
+// the local chain, without inserting it: its body is validated, its transactions
+// executed on the parent state and the resulting state checked against the header.
+// The header itself is assumed to be verified already.
+func (bc *BlockChain) VerifyBlock(block *types.Block) error {
+	if err := bc.Validator().ValidateBody(block); err != nil {
+		return err
+	}
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	statedb, err := state.New(parent.Root(), bc.stateCache, bc.snaps)
+	if err != nil {
+		return err
+	}
+	receipts, _, usedGas, err := bc.Processor().Process(block, statedb, bc.vmConfig)
+	if err != nil {
+		return err
+	}
+	return bc.Validator().ValidateState(block, parent, statedb, receipts, usedGas)
+}
+
 // insertChain will execute the actual chain insertion and event aggregation. The
 // only reason this method exists as a separate one is to make locking cleaner
 // with deferred statements.
@@ -1136,7 +1292,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		} else {
 			parent = chain[i-1]
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.New(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return i, events, coalescedLogs, err
 		}
@@ -1387,6 +1543,237 @@ func (bc *BlockChain) update() {
 	}
 }
 
+// freeze periodically moves canonical chain data that is deeper than the freeze
+// threshold below the current head out of the key-value store into the ancient
+// store.
+func (bc *BlockChain) freeze(ancients rawdb.AncientStore) {
+	defer bc.wg.Done()
+
+	for {
+		if err := bc.freezeBlocks(ancients); err != nil {
+			log.Error("Failed to freeze ancient blocks", "err", err)
+		}
+		select {
+		case <-time.After(freezerRecheckInterval):
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// freezeBlocks moves at most freezerBatchLimit canonical blocks into the ancient
+// store and, once they are safely flushed to disk, deletes them from the key-value
+// store. The blocks are copied without holding the chain lock, which is only taken
+// to ensure they're still canonical before deleting them.
+func (bc *BlockChain) freezeBlocks(ancients rawdb.AncientStore) error {
+	head := bc.CurrentBlock().NumberU64()
+	if head <= bc.cacheConfig.FreezeThreshold {
+		return nil
+	}
+	frozen, err := ancients.Ancients()
+	if err != nil {
+		return err
+	}
+	limit := head - bc.cacheConfig.FreezeThreshold
+	if frozen > limit {
+		return nil
+	}
+	if limit-frozen >= freezerBatchLimit {
+		limit = frozen + freezerBatchLimit - 1
+	}
+	var (
+		start  = time.Now()
+		first  = frozen
+		hashes []common.Hash
+	)
+	for number := first; number <= limit && atomic.LoadInt32(&bc.procInterrupt) == 0; number++ {
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("canonical hash missing, can't freeze block %d", number)
+		}
+		header := rawdb.ReadHeaderRLP(bc.db, hash, number)
+		if len(header) == 0 {
+			return fmt.Errorf("block header missing, can't freeze block %d", number)
+		}
+		body := rawdb.ReadBodyRLP(bc.db, hash, number)
+		if len(body) == 0 {
+			return fmt.Errorf("block body missing, can't freeze block %d", number)
+		}
+		receipts := rawdb.ReadReceiptsRLP(bc.db, hash, number)
+		if len(receipts) == 0 {
+			return fmt.Errorf("block receipts missing, can't freeze block %d", number)
+		}
+		td := rawdb.ReadTdRLP(bc.db, hash, number)
+		if len(td) == 0 {
+			return fmt.Errorf("total difficulty missing, can't freeze block %d", number)
+		}
+		if err := ancients.AppendAncient(number, hash[:], header, body, receipts, td); err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	// Ensure the ancient data is safely on disk before wiping the active copy
+	if err := ancients.Sync(); err != nil {
+		return err
+	}
+	// Block any rewinds and reorgs while the frozen chain segment is deleted, and
+	// drop the copied segment if the chain changed under it in the meantime
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	if frozen, err := ancients.Ancients(); err != nil {
+		return err
+	} else if frozen != first+uint64(len(hashes)) {
+		return fmt.Errorf("ancient store rewound while freezing blocks %d-%d", first, first+uint64(len(hashes))-1)
+	}
+	kvdb := rawdb.KeyValueStore(bc.db)
+	for i, hash := range hashes {
+		if number := first + uint64(i); rawdb.ReadCanonicalHash(kvdb, number) != hash {
+			if err := ancients.TruncateAncients(first); err != nil {
+				return err
+			}
+			return fmt.Errorf("canonical chain changed while freezing block %d", number)
+		}
+	}
+	for i, hash := range hashes {
+		number := first + uint64(i)
+
+		rawdb.DeleteBlockWithoutNumber(kvdb, hash, number)
+		rawdb.DeleteCanonicalHash(kvdb, number)
+	}
+	log.Info("Moved blocks into the ancient store", "blocks", len(hashes), "first", first, "last", first+uint64(len(hashes))-1, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// txIndexTarget returns the number of the oldest block whose transactions are
+// to be indexed, given the current chain head.
+func (bc *BlockChain) txIndexTarget(head uint64) uint64 {
+	if limit := bc.cacheConfig.TxLookupLimit; limit != 0 && head >= limit {
+		return head - limit + 1
+	}
+	return 0
+}
+
+// maintainTxIndex keeps the transaction lookup entries of the most recent
+// TxLookupLimit blocks indexed, removing the older ones in the background and
+// indexing the missing ones if the limit is raised. New blocks are indexed by
+// the chain insertion itself.
+func (bc *BlockChain) maintainTxIndex() {
+	defer bc.wg.Done()
+
+	// Listen for chain head events to move the index along with the chain
+	headCh := make(chan ChainHeadEvent, 1)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return // Chain already stopped
+	}
+	defer sub.Unsubscribe()
+
+	// Run the index updates on a separate goroutine, not to block event delivery
+	var (
+		done      chan struct{}
+		interrupt = make(chan struct{})
+	)
+	run := func(head uint64) {
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			bc.updateTxIndex(head, interrupt)
+		}()
+	}
+	run(bc.CurrentBlock().NumberU64())
+	for {
+		select {
+		case head := <-headCh:
+			if done == nil {
+				run(head.Block.NumberU64())
+			}
+		case <-done:
+			done = nil
+		case <-bc.quit:
+			close(interrupt)
+			if done != nil {
+				<-done
+			}
+			return
+		}
+	}
+}
+
+// updateTxIndex moves the transaction index tail to the target belonging to
+// the given head, indexing or unindexing the blocks in between.
+func (bc *BlockChain) updateTxIndex(head uint64, interrupt chan struct{}) {
+	// Before the limit was introduced all blocks were indexed, start from genesis
+	tail := rawdb.ReadTxIndexTail(bc.db)
+	if tail == nil {
+		rawdb.WriteTxIndexTail(bc.db, 0)
+		tail = new(uint64)
+	}
+	// Move the tail, only reporting larger jobs as the chain progression will do
+	// single block updates all the time
+	var (
+		start  = time.Now()
+		target = bc.txIndexTarget(head)
+		logger = log.Debug
+	)
+	switch {
+	case target < *tail:
+		if *tail-target > 1 {
+			logger = log.Info
+		}
+		last := rawdb.IndexTransactions(bc.db, target, *tail, interrupt)
+		logger("Indexed transactions", "blocks", *tail-last, "tail", last, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	case target > *tail:
+		if target-*tail > 1 {
+			logger = log.Info
+		}
+		last := rawdb.UnindexTransactions(bc.db, *tail, target, interrupt)
+		logger("Unindexed transactions", "blocks", last-*tail, "tail", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// TxIndexProgress is the progress of the transaction indexing.
+type TxIndexProgress struct {
+	Tail      uint64 // Number of the oldest block whose transactions are indexed
+	Indexed   uint64 // Number of blocks whose transactions are indexed
+	Remaining uint64 // Number of blocks whose transactions are yet to be indexed
+}
+
+// Done returns an indicator whether the transaction indexing is finished.
+func (progress TxIndexProgress) Done() bool {
+	return progress.Remaining == 0
+}
+
+// TxIndexProgress returns the progress of the transaction indexing. Blocks older
+// than the configured lookup limit are not counted, their transactions can't be
+// looked up by hash after the background unindexing removed them.
+func (bc *BlockChain) TxIndexProgress() TxIndexProgress {
+	var (
+		head     = bc.CurrentBlock().NumberU64()
+		target   = bc.txIndexTarget(head)
+		tail     uint64
+		progress TxIndexProgress
+	)
+	if stored := rawdb.ReadTxIndexTail(bc.db); stored != nil {
+		tail = *stored
+	}
+	progress.Tail = tail
+	if tail <= head {
+		progress.Indexed = head - tail + 1
+	}
+	if tail > target {
+		progress.Remaining = tail - target
+		if tail > head+1 {
+			progress.Remaining = head + 1 - target
+		}
+	}
+	return progress
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash   common.Hash   `json:"hash"`
@@ -18,8 +18,10 @@ package core
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"math/rand"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -114,7 +116,7 @@ func testBlockChainImport(chain types.Blocks, blockchain *BlockChain) error {
 			}
 			return err
 		}
-		statedb, err := state.New(blockchain.GetBlockByHash(block.ParentHash()).Root(), blockchain.stateCache)
+		statedb, err := state.New(blockchain.GetBlockByHash(block.ParentHash()).Root(), blockchain.stateCache, nil)
 		if err != nil {
 			return err
 		}
@@ -1340,6 +1342,223 @@ func TestLargeReorgTrieGC(t *testing.T) {
 	}
 }
 
+// Tests that chain data deeper than the freeze threshold gets moved into the
+// ancient store, remains accessible afterwards and is discarded when rewinding.
+func TestBlockchainFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	engine := ethash.NewFaker()
+
+	db := ethdb.NewMemDatabase()
+	genesis := new(Genesis).MustCommit(db)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 64, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+
+	diskdb, err := rawdb.NewDatabaseWithFreezer(ethdb.NewMemDatabase(), dir, "")
+	if err != nil {
+		t.Fatalf("failed to create database with freezer: %v", err)
+	}
+	defer diskdb.Close()
+	new(Genesis).MustCommit(diskdb)
+
+	cacheConfig := &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, FreezeThreshold: 16}
+	chain, err := NewBlockChain(diskdb, cacheConfig, params.TestChainConfig, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if err := chain.freezeBlocks(diskdb.(rawdb.AncientStore)); err != nil {
+		t.Fatalf("failed to freeze blocks: %v", err)
+	}
+	if frozen, _ := diskdb.(rawdb.AncientStore).Ancients(); frozen != 64-16+1 {
+		t.Fatalf("frozen block count mismatch: have %d, want %d", frozen, 64-16+1)
+	}
+	// Ensure frozen data is gone from the key-value store but still accessible
+	kvdb := rawdb.KeyValueStore(diskdb)
+	for i, block := range append([]*types.Block{genesis}, blocks...) {
+		number := block.NumberU64()
+		if number <= 64-16 && rawdb.ReadCanonicalHash(kvdb, number) != (common.Hash{}) {
+			t.Fatalf("block %d: frozen canonical hash still in key-value store", i)
+		}
+		if have := chain.GetBlockByNumber(number); have == nil || have.Hash() != block.Hash() {
+			t.Fatalf("block %d: retrieved block mismatch: have %v, want %v", i, have, block.Hash())
+		}
+		if receipts := chain.GetReceiptsByHash(block.Hash()); receipts == nil {
+			t.Fatalf("block %d: receipts missing", i)
+		}
+		if td := chain.GetTd(block.Hash(), number); td == nil {
+			t.Fatalf("block %d: total difficulty missing", i)
+		}
+	}
+	// Rewind the chain into the frozen segment and ensure the freezer follows
+	if err := chain.SetHead(32); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if frozen, _ := diskdb.(rawdb.AncientStore).Ancients(); frozen != 33 {
+		t.Fatalf("frozen block count mismatch after rewind: have %d, want %d", frozen, 33)
+	}
+	if block := chain.GetBlockByNumber(40); block != nil {
+		t.Fatalf("rewound block still accessible: %v", block.Hash())
+	}
+}
+
+// Tests that the state snapshot follows the chain head, serving the same data
+// as the state tries, and that it's reloaded from its journal after a restart.
+func TestBlockchainSnapshot(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.HomesteadSigner{}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 10, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{byte(0x10 + i)}, big.NewInt(int64(1000+i)), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	cacheConfig := &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 16}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// checkSnapshot ensures the snapshot of the head state matches the tries
+	checkSnapshot := func(chain *BlockChain, accounts int) {
+		head := chain.CurrentBlock()
+		snap := chain.Snapshot().Snapshot(head.Root())
+		if snap == nil {
+			t.Fatalf("block #%d: snapshot missing", head.NumberU64())
+		}
+		statedb, _ := state.New(head.Root(), state.NewDatabase(db), nil)
+		for i := 0; i < accounts; i++ {
+			addr := common.Address{byte(0x10 + i)}
+			account, err := snap.Account(crypto.Keccak256Hash(addr[:]))
+			if err != nil {
+				t.Fatalf("block #%d: account %x: failed to retrieve: %v", head.NumberU64(), addr, err)
+			}
+			if account == nil || account.Balance.Cmp(statedb.GetBalance(addr)) != 0 {
+				t.Errorf("block #%d: account %x: snapshot mismatch: have %v, want balance %v", head.NumberU64(), addr, account, statedb.GetBalance(addr))
+			}
+		}
+		addr := common.Address{byte(0x10 + accounts)}
+		if account, _ := snap.Account(crypto.Keccak256Hash(addr[:])); account != nil {
+			t.Errorf("block #%d: account %x: non-existent account in snapshot", head.NumberU64(), addr)
+		}
+	}
+	checkSnapshot(chain, 10)
+
+	// Rewind the chain and ensure the snapshot follows
+	if err := chain.SetHead(5); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	checkSnapshot(chain, 5)
+
+	// Restart the chain and ensure the snapshot is reloaded from the journal
+	chain.Stop()
+
+	chain, err = NewBlockChain(db, cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	checkSnapshot(chain, 5)
+	if _, err := chain.InsertChain(blocks[5:]); err != nil {
+		t.Fatalf("failed to reinsert chain: %v", err)
+	}
+	checkSnapshot(chain, 10)
+}
+
+// Tests that a head state imported without a snapshot layer is linked up to the
+// closest ancestor with one, instead of regenerating the snapshot.
+func TestBlockchainSnapshotExtend(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.HomesteadSigner{}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 10, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{byte(0x10 + i)}, big.NewInt(int64(1000+i)), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	cacheConfig := &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 16}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:5]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Import a few blocks without maintaining their snapshot layers
+	snaps := chain.snaps
+	chain.snaps = nil
+	if _, err := chain.InsertChain(blocks[5:9]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.snaps = snaps
+	for _, block := range blocks[5:9] {
+		if snaps.Snapshot(block.Root()) != nil {
+			t.Fatalf("block #%d: unexpected snapshot layer", block.NumberU64())
+		}
+	}
+	// Import the next block and ensure the missing layers are linked up
+	if _, err := chain.InsertChain(blocks[9:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if snaps.Snapshot(blocks[4].Root()) == nil {
+		t.Fatalf("ancestor snapshot layer dropped, snapshot regenerated")
+	}
+	head := chain.CurrentBlock()
+	snap := snaps.Snapshot(head.Root())
+	if snap == nil {
+		t.Fatalf("block #%d: snapshot missing", head.NumberU64())
+	}
+	statedb, _ := state.New(head.Root(), state.NewDatabase(db), nil)
+	for i := 0; i <= 10; i++ {
+		addr := common.Address{byte(0x10 + i)}
+		account, err := snap.Account(crypto.Keccak256Hash(addr[:]))
+		if err != nil {
+			t.Fatalf("account %x: failed to retrieve: %v", addr, err)
+		}
+		if i == 10 {
+			if account != nil {
+				t.Errorf("account %x: non-existent account in snapshot", addr)
+			}
+			continue
+		}
+		if account == nil || account.Balance.Cmp(statedb.GetBalance(addr)) != 0 {
+			t.Errorf("account %x: snapshot mismatch: have %v, want balance %v", addr, account, statedb.GetBalance(addr))
+		}
+	}
+}
+
 // Benchmarks large blocks with value transfers to non-existing accounts
 func benchmarkLargeNumberOfValueToNonexisting(b *testing.B, numTxs, numBlocks int, recipientFn func(uint64) common.Address, dataFn func(uint64) []byte) {
 	var (
@@ -1450,3 +1669,74 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
+
+// Tests that the transaction index is maintained within the configured lookup
+// limit, unindexing old blocks as the chain progresses and reindexing them when
+// the limit is raised.
+func TestTransactionIndices(t *testing.T) {
+	var (
+		gendb   = ethdb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.HomesteadSigner{}
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, 64, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	// checkIndices waits for the background indexing to finish and ensures only
+	// the transactions of the blocks above the expected tail are indexed
+	checkIndices := func(chain *BlockChain, db ethdb.Database, tail uint64) {
+		for i := 0; ; i++ {
+			if progress := chain.TxIndexProgress(); progress.Done() && progress.Tail == tail {
+				break
+			}
+			if i == 100 {
+				t.Fatalf("transaction indexing timed out: have %+v, want tail %d", chain.TxIndexProgress(), tail)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		for _, block := range blocks {
+			hash, _, _ := rawdb.ReadTxLookupEntry(db, block.Transactions()[0].Hash())
+			if indexed := hash != (common.Hash{}); indexed != (block.NumberU64() >= tail) {
+				t.Errorf("block #%d: index mismatch: have %v, want %v (tail %d)", block.NumberU64(), indexed, !indexed, tail)
+			}
+		}
+	}
+	db := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	// Import the chain with a limit and ensure old entries are removed
+	limits := []uint64{32, 16, 48, 0}
+	chain, err := NewBlockChain(db, &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, TxLookupLimit: limits[0]}, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	checkIndices(chain, db, 64-limits[0]+1)
+	chain.Stop()
+
+	// Restart the chain with lower and higher limits, ensuring the index follows
+	for _, limit := range limits[1:] {
+		chain, err = NewBlockChain(db, &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, TxLookupLimit: limit}, gspec.Config, ethash.NewFaker(), vm.Config{})
+		if err != nil {
+			t.Fatalf("failed to recreate tester chain: %v", err)
+		}
+		tail := uint64(0)
+		if limit != 0 {
+			tail = 64 - limit + 1
+		}
+		checkIndices(chain, db, tail)
+		chain.Stop()
+	}
+}
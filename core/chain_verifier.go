@@ -0,0 +1,236 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+// The kinds of inconsistencies the chain verifier may report.
+const (
+	IssueMissingCanonicalHash = "missing-canonical-hash" // No canonical hash stored for a number
+	IssueMissingHashNumber    = "missing-hash-number"    // No hash->number mapping for a canonical hash
+	IssueHashNumberMismatch   = "hash-number-mismatch"   // Hash->number mapping points to another number
+	IssueMissingHeader        = "missing-header"         // Canonical header not found
+	IssueHeaderHashMismatch   = "header-hash-mismatch"   // Stored header hashes to something else
+	IssueHeaderNumberMismatch = "header-number-mismatch" // Stored header has another number
+	IssueParentHashMismatch   = "parent-hash-mismatch"   // Header doesn't link to the previous canonical one
+	IssueMissingTd            = "missing-td"             // Total difficulty not found
+	IssueTdMismatch           = "td-mismatch"            // Total difficulty is not parent td + difficulty
+	IssueMissingBody          = "missing-body"           // Block body not found
+	IssueTxRootMismatch       = "tx-root-mismatch"       // Transactions don't match the header's tx root
+	IssueUncleHashMismatch    = "uncle-hash-mismatch"    // Uncles don't match the header's uncle hash
+	IssueMissingReceipts      = "missing-receipts"       // Block receipts not found
+	IssueReceiptCountMismatch = "receipt-count-mismatch" // Number of receipts differs from number of txs
+	IssueReceiptRootMismatch  = "receipt-root-mismatch"  // Receipts don't match the header's receipt root
+	IssueBloomMismatch        = "bloom-mismatch"         // Receipt logs don't match the header's bloom
+	IssueMissingTxLookup      = "missing-tx-lookup"      // Transaction lookup entry not found
+	IssueTxLookupMismatch     = "tx-lookup-mismatch"     // Transaction lookup entry points elsewhere
+	IssueHeadNotCanonical     = "head-not-canonical"     // Head marker points to a non-canonical block
+	IssueMissingHeadState     = "missing-head-state"     // Head state root not found in the trie database
+	IssueIncompleteHeadState  = "incomplete-head-state"  // Head state trie has unreachable nodes
+)
+
+// ChainIssue is a single inconsistency found by the chain verifier.
+type ChainIssue struct {
+	Number uint64      `json:"number"`           // Number of the block the issue belongs to
+	Hash   common.Hash `json:"hash"`             // Hash of the block the issue belongs to (if known)
+	Kind   string      `json:"kind"`             // Kind of the inconsistency (Issue* constants)
+	Detail string      `json:"detail,omitempty"` // Additional human readable details
+}
+
+// VerifyChain walks the canonical chain stored in the database from genesis to
+// the head header, cross-checking the header, body, receipt, total difficulty
+// and transaction lookup entries (above the index tail) against each other. It also checks that the
+// state of the head block is available, optionally iterating the entire state
+// trie to ensure all of its nodes are reachable.
+//
+// Every inconsistency found is passed to the report callback, the returned error
+// is only non-nil if the database is too broken to even start the verification.
+func VerifyChain(db ethdb.Database, fullState bool, report func(issue *ChainIssue)) error {
+	// Resolve the head markers, bailing out if not even the header chain is present
+	headHeaderHash := rawdb.ReadHeadHeaderHash(db)
+	if headHeaderHash == (common.Hash{}) {
+		return errors.New("head header marker missing")
+	}
+	headHeaderNumber := rawdb.ReadHeaderNumber(db, headHeaderHash)
+	if headHeaderNumber == nil {
+		return fmt.Errorf("head header %x unknown", headHeaderHash)
+	}
+	// Bodies and receipts are only expected up to the full or fast block head
+	var (
+		headBlockHash = rawdb.ReadHeadBlockHash(db)
+		headFullLimit uint64
+		headBlock     *types.Header
+	)
+	for _, hash := range []common.Hash{headBlockHash, rawdb.ReadHeadFastBlockHash(db)} {
+		if number := rawdb.ReadHeaderNumber(db, hash); number != nil && *number > headFullLimit {
+			headFullLimit = *number
+		}
+	}
+	for _, head := range []struct {
+		hash   common.Hash
+		number *uint64
+	}{
+		{headHeaderHash, headHeaderNumber},
+		{headBlockHash, rawdb.ReadHeaderNumber(db, headBlockHash)},
+	} {
+		if head.number == nil {
+			report(&ChainIssue{Hash: head.hash, Kind: IssueHeadNotCanonical, Detail: "unknown head"})
+			continue
+		}
+		if canon := rawdb.ReadCanonicalHash(db, *head.number); canon != head.hash {
+			report(&ChainIssue{Number: *head.number, Hash: head.hash, Kind: IssueHeadNotCanonical, Detail: fmt.Sprintf("canonical %x", canon)})
+		}
+	}
+	// Transaction lookups are only expected above the index tail
+	var txIndexTail uint64
+	if tail := rawdb.ReadTxIndexTail(db); tail != nil {
+		txIndexTail = *tail
+	}
+	// Iterate over all the canonical blocks and cross-check their data
+	var (
+		parentHash common.Hash
+		parentTd   *big.Int
+
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(0); number <= *headHeaderNumber; number++ {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying chain", "number", number, "head", *headHeaderNumber, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			report(&ChainIssue{Number: number, Kind: IssueMissingCanonicalHash})
+			parentHash, parentTd = common.Hash{}, nil
+			continue
+		}
+		if mapped := rawdb.ReadHeaderNumber(db, hash); mapped == nil {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingHashNumber})
+		} else if *mapped != number {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueHashNumberMismatch, Detail: fmt.Sprintf("mapped to %d", *mapped)})
+		}
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingHeader})
+			parentHash, parentTd = hash, nil
+			continue
+		}
+		if have := header.Hash(); have != hash {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueHeaderHashMismatch, Detail: fmt.Sprintf("hashes to %x", have)})
+		}
+		if !header.Number.IsUint64() || header.Number.Uint64() != number {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueHeaderNumberMismatch, Detail: fmt.Sprintf("header number %v", header.Number)})
+		}
+		if number > 0 && parentHash != (common.Hash{}) && header.ParentHash != parentHash {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueParentHashMismatch, Detail: fmt.Sprintf("parent %x, canonical %x", header.ParentHash, parentHash)})
+		}
+		// Ensure the total difficulty is continuous with the parent's. The genesis
+		// td is taken as is, since it's defined by the genesis spec, not the header.
+		td := rawdb.ReadTd(db, hash, number)
+		if td == nil {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingTd})
+		} else if number > 0 && parentTd != nil {
+			if want := new(big.Int).Add(parentTd, header.Difficulty); td.Cmp(want) != 0 {
+				report(&ChainIssue{Number: number, Hash: hash, Kind: IssueTdMismatch, Detail: fmt.Sprintf("have %v, want %v", td, want)})
+			}
+		}
+		parentHash, parentTd = hash, td
+
+		// Verify the block body, receipts and lookups if they should be present
+		if number <= headFullLimit {
+			verifyBlockData(db, header, number >= txIndexTail, report)
+		}
+		if hash == headBlockHash {
+			headBlock = header
+		}
+	}
+	// Ensure the state of the head block is available
+	if headBlock != nil {
+		statedb, err := state.New(headBlock.Root, state.NewDatabase(db), nil)
+		if err != nil {
+			report(&ChainIssue{Number: headBlock.Number.Uint64(), Hash: headBlock.Hash(), Kind: IssueMissingHeadState, Detail: err.Error()})
+		} else if fullState {
+			it := state.NewNodeIterator(statedb)
+			for it.Next() {
+			}
+			if it.Error != nil {
+				report(&ChainIssue{Number: headBlock.Number.Uint64(), Hash: headBlock.Hash(), Kind: IssueIncompleteHeadState, Detail: it.Error.Error()})
+			}
+		}
+	}
+	log.Info("Verified chain", "head", *headHeaderNumber, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// verifyBlockData checks the body, receipts and optionally the transaction lookup
+// entries of a canonical block against its header.
+func verifyBlockData(db ethdb.Database, header *types.Header, lookups bool, report func(issue *ChainIssue)) {
+	var (
+		hash   = header.Hash()
+		number = header.Number.Uint64()
+	)
+	body := rawdb.ReadBody(db, hash, number)
+	if body == nil {
+		report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingBody})
+		return
+	}
+	txs := types.Transactions(body.Transactions)
+	if root := types.DeriveSha(txs); root != header.TxHash {
+		report(&ChainIssue{Number: number, Hash: hash, Kind: IssueTxRootMismatch, Detail: fmt.Sprintf("have %x, want %x", root, header.TxHash)})
+	}
+	if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+		report(&ChainIssue{Number: number, Hash: hash, Kind: IssueUncleHashMismatch, Detail: fmt.Sprintf("have %x, want %x", uncles, header.UncleHash)})
+	}
+	if lookups {
+		for i, tx := range txs {
+			blockHash, blockNumber, index := rawdb.ReadTxLookupEntry(db, tx.Hash())
+			switch {
+			case blockHash == (common.Hash{}):
+				report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingTxLookup, Detail: fmt.Sprintf("tx %x", tx.Hash())})
+			case blockHash != hash || blockNumber != number || index != uint64(i):
+				report(&ChainIssue{Number: number, Hash: hash, Kind: IssueTxLookupMismatch, Detail: fmt.Sprintf("tx %x: have %x #%d [%d], want #%d [%d]", tx.Hash(), blockHash, blockNumber, index, number, i)})
+			}
+		}
+	}
+	receipts := rawdb.ReadReceipts(db, hash, number)
+	switch {
+	case receipts == nil:
+		report(&ChainIssue{Number: number, Hash: hash, Kind: IssueMissingReceipts})
+	case len(receipts) != len(txs):
+		report(&ChainIssue{Number: number, Hash: hash, Kind: IssueReceiptCountMismatch, Detail: fmt.Sprintf("have %d, want %d", len(receipts), len(txs))})
+	default:
+		if root := types.DeriveSha(receipts); root != header.ReceiptHash {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueReceiptRootMismatch, Detail: fmt.Sprintf("have %x, want %x", root, header.ReceiptHash)})
+		}
+		if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+			report(&ChainIssue{Number: number, Hash: hash, Kind: IssueBloomMismatch})
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// Tests that the chain verifier accepts a consistent database and detects the
+// various kinds of corruptions injected into it.
+func TestVerifyChain(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		signer = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	gendb := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, 16, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	db := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+	chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	chain.Stop()
+
+	verify := func() map[string]uint64 {
+		issues := make(map[string]uint64)
+		if err := VerifyChain(db, true, func(issue *ChainIssue) { issues[issue.Kind] = issue.Number }); err != nil {
+			t.Fatalf("failed to verify chain: %v", err)
+		}
+		return issues
+	}
+	if issues := verify(); len(issues) != 0 {
+		t.Fatalf("consistent chain reported issues: %v", issues)
+	}
+	// Corrupt various parts of the chain and ensure they are detected
+	rawdb.DeleteTxLookupEntry(db, blocks[2].Transactions()[0].Hash())
+	rawdb.DeleteReceipts(db, blocks[4].Hash(), blocks[4].NumberU64())
+	rawdb.WriteTd(db, blocks[6].Hash(), blocks[6].NumberU64(), big.NewInt(1))
+	rawdb.WriteBody(db, blocks[8].Hash(), blocks[8].NumberU64(), &types.Body{})
+	rawdb.DeleteCanonicalHash(db, blocks[10].NumberU64())
+
+	want := map[string]uint64{
+		IssueMissingTxLookup:      blocks[2].NumberU64(),
+		IssueMissingReceipts:      blocks[4].NumberU64(),
+		IssueTdMismatch:           blocks[7].NumberU64(), // The corrupted td is last reported by its child
+		IssueTxRootMismatch:       blocks[8].NumberU64(),
+		IssueReceiptCountMismatch: blocks[8].NumberU64(),
+		IssueMissingCanonicalHash: blocks[10].NumberU64(),
+	}
+	issues := verify()
+	if len(issues) != len(want) {
+		t.Fatalf("issue count mismatch: have %v, want %v", issues, want)
+	}
+	for kind, number := range want {
+		if have, ok := issues[kind]; !ok || have != number {
+			t.Errorf("issue %s: have block %d (reported %v), want block %d", kind, have, ok, number)
+		}
+	}
+	// Ensure missing head state is detected too
+	db.Delete(blocks[len(blocks)-1].Root().Bytes())
+	if _, ok := verify()[IssueMissingHeadState]; !ok {
+		t.Errorf("missing head state not detected")
+	}
+}
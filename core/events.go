@@ -21,8 +21,16 @@ import (
 	"github.com/vsportchain/go-vsc/core/types"
 )
 
-// NewTxsEvent is posted when a batch of transactions enter the transaction pool.
-type NewTxsEvent struct{ Txs []*types.Transaction }
+// NewTxsEvent is posted when a batch of transactions enter the transaction pool,
+// or on the lifecycle feed, when a batch changes state in the pool. Dropped
+// transactions carry the reason of their removal. Private transactions are posted
+// in batches of their own, flagged so that they are never disclosed.
+type NewTxsEvent struct {
+	Txs     []*types.Transaction
+	Kind    TxLifecycle
+	Reason  string
+	Private bool
+}
 
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
@@ -31,6 +31,7 @@ import (
 	"github.com/vsportchain/go-vsc/core/rawdb"
 	"github.com/vsportchain/go-vsc/core/state"
 	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/log"
 	"github.com/vsportchain/go-vsc/params"
@@ -154,6 +155,11 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	if genesis != nil && genesis.Config == nil {
 		return params.AllEthashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
+	if genesis != nil {
+		if err := checkConfig(genesis.Config); err != nil {
+			return genesis.Config, common.Hash{}, fmt.Errorf("invalid genesis config: %v", err)
+		}
+	}
 
 	// Just commit the new block if there is no stored genesis block.
 	stored := rawdb.ReadCanonicalHash(db, 0)
@@ -188,6 +194,9 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	// config is supplied. These chains would get AllProtocolChanges (and a compat error)
 	// if we just continued here.
 	if genesis == nil && stored != params.MainnetGenesisHash {
+		if err := checkConfig(storedcfg); err != nil {
+			return storedcfg, stored, fmt.Errorf("invalid stored chain config: %v", err)
+		}
 		return storedcfg, stored, nil
 	}
 
@@ -205,6 +214,15 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	return newcfg, stored, nil
 }
 
+// checkConfig verifies that a chain configuration can be acted upon, including
+// the instantiation of all the precompiled contracts it configures.
+func checkConfig(config *params.ChainConfig) error {
+	if err := config.CheckConfig(); err != nil {
+		return err
+	}
+	return vm.CheckPrecompiles(config)
+}
+
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	switch {
 	case g != nil:
@@ -224,7 +242,7 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	if db == nil {
 		db = ethdb.NewMemDatabase()
 	}
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
 	for addr, account := range g.Alloc {
 		statedb.AddBalance(addr, account.Balance)
 		statedb.SetCode(addr, account.Code)
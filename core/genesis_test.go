@@ -17,6 +17,7 @@
 package core
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
@@ -162,3 +163,64 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that chain specific precompiles can be configured through the genesis
+// JSON and that unknown implementations are rejected.
+func TestSetupGenesisPrecompiles(t *testing.T) {
+	var (
+		addr  = common.HexToAddress("0x0000000000000000000000000000000000000100")
+		valid = `{
+			"config": {
+				"chainId": 1337,
+				"precompiles": {
+					"0x0000000000000000000000000000000000000100": {
+						"name":   "ed25519",
+						"block":  10,
+						"gas":    {"base": 1000, "perWord": 5},
+						"params": {"scale": 1000000}
+					}
+				}
+			},
+			"difficulty": "0x1",
+			"gasLimit":   "0x47b760",
+			"alloc":      {}
+		}`
+		unknown = `{"config": {"chainId": 1337, "precompiles": {"0x0000000000000000000000000000000000000100": {"name": "unknown", "block": 0}}}, "difficulty": "0x1", "gasLimit": "0x47b760", "alloc": {}}`
+	)
+	genesis := new(Genesis)
+	if err := json.Unmarshal([]byte(valid), genesis); err != nil {
+		t.Fatalf("failed to parse genesis: %v", err)
+	}
+	config, _, err := SetupGenesisBlock(ethdb.NewMemDatabase(), genesis)
+	if err != nil {
+		t.Fatalf("failed to set up genesis: %v", err)
+	}
+	precompile := config.Precompiles[addr]
+	if precompile == nil || precompile.Name != "ed25519" || precompile.Block.Uint64() != 10 || precompile.Gas.PerWord != 5 {
+		t.Fatalf("precompile config mismatch: %v", precompile)
+	}
+	if config.IsPrecompileActive(addr, big.NewInt(9)) || !config.IsPrecompileActive(addr, big.NewInt(10)) {
+		t.Errorf("precompile activation mismatch")
+	}
+	genesis = new(Genesis)
+	if err := json.Unmarshal([]byte(unknown), genesis); err != nil {
+		t.Fatalf("failed to parse genesis: %v", err)
+	}
+	if _, _, err := SetupGenesisBlock(ethdb.NewMemDatabase(), genesis); err == nil {
+		t.Errorf("expected error for unknown precompile")
+	}
+	// Unknown implementations in an already stored config must be refused too
+	db := ethdb.NewMemDatabase()
+	genesis.Config = &params.ChainConfig{ChainId: big.NewInt(1337)}
+	stored := genesis.MustCommit(db).Hash()
+
+	unusable := &params.ChainConfig{ChainId: big.NewInt(1337), Precompiles: map[common.Address]*params.PrecompileConfig{addr: {Name: "unknown", Block: big.NewInt(0)}}}
+	rawdb.WriteChainConfig(db, stored, unusable)
+
+	if _, _, err := SetupGenesisBlock(db, nil); err == nil {
+		t.Errorf("expected error for unknown precompile in stored config")
+	}
+	if _, err := NewBlockChain(db, nil, unusable, ethash.NewFaker(), vm.Config{}); err == nil {
+		t.Errorf("expected error creating chain with unknown precompile")
+	}
+}
@@ -31,7 +31,13 @@ import (
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
 	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...))
 	if len(data) == 0 {
-		return common.Hash{}
+		// Get it by hash from the freezer if the block is already frozen
+		if frdb, ok := db.(AncientReader); ok {
+			data, _ = frdb.Ancient(freezerHashTable, number)
+		}
+		if len(data) == 0 {
+			return common.Hash{}
+		}
 	}
 	return common.BytesToHash(data)
 }
@@ -127,9 +133,34 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 	}
 }
 
+// isFrozen checks whether the block with the given hash and number has already
+// been moved into the ancient store of a freezer backed database.
+func isFrozen(db DatabaseReader, hash common.Hash, number uint64) bool {
+	frdb, ok := db.(AncientReader)
+	if !ok {
+		return false
+	}
+	data, _ := frdb.Ancient(freezerHashTable, number)
+	return len(data) > 0 && common.BytesToHash(data) == hash
+}
+
+// readAncient retrieves a frozen binary blob of the given kind, provided that
+// the block with the given hash and number has already been moved into the
+// ancient store.
+func readAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if !isFrozen(db, hash, number) {
+		return nil
+	}
+	data, _ := db.(AncientReader).Ancient(kind, number)
+	return data
+}
+
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
@@ -137,7 +168,7 @@ func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawVa
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
 	key := append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
 	if has, err := db.Has(key); !has || err != nil {
-		return false
+		return isFrozen(db, hash, number)
 	}
 	return true
 }
@@ -193,6 +224,9 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerBodiesTable, hash, number)
+	}
 	return data
 }
 
@@ -208,7 +242,7 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
 	key := append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 	if has, err := db.Has(key); !has || err != nil {
-		return false
+		return isFrozen(db, hash, number)
 	}
 	return true
 }
@@ -243,9 +277,19 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadTdRLP retrieves a block's total difficulty corresponding to the hash in
+// its raw RLP database encoding.
+func ReadTdRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), headerTDSuffix...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerDifficultyTable, hash, number)
+	}
+	return data
+}
+
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), headerTDSuffix...))
+	data := ReadTdRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -276,10 +320,20 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadReceiptsRLP retrieves all the transaction receipts belonging to a block
+// in their raw RLP database encoding.
+func ReadReceiptsRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerReceiptTable, hash, number)
+	}
+	return data
+}
+
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data := ReadReceiptsRLP(db, hash, number)
 	if len(data) == 0 {
 		return nil
 	}
@@ -353,6 +407,17 @@ func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteTd(db, hash, number)
 }
 
+// DeleteBlockWithoutNumber removes all block data associated with a hash, except
+// the hash to number mapping.
+func DeleteBlockWithoutNumber(db DatabaseDeleter, hash common.Hash, number uint64) {
+	DeleteReceipts(db, hash, number)
+	if err := db.Delete(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...)); err != nil {
+		log.Crit("Failed to delete header", "err", err)
+	}
+	DeleteBody(db, hash, number)
+	DeleteTd(db, hash, number)
+}
+
 // FindCommonAncestor returns the last common ancestor of two block headers
 func FindCommonAncestor(db DatabaseReader, a, b *types.Header) *types.Header {
 	for bn := b.Number.Uint64(); a.Number.Uint64() > bn; {
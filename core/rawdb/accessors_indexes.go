@@ -18,9 +18,11 @@ package rawdb
 
 import (
 	"encoding/binary"
+	"time"
 
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/log"
 	"github.com/vsportchain/go-vsc/rlp"
 )
@@ -43,10 +45,16 @@ func ReadTxLookupEntry(db DatabaseReader, hash common.Hash) (common.Hash, uint64
 // WriteTxLookupEntries stores a positional metadata for every transaction from
 // a block, enabling hash based transaction and receipt lookups.
 func WriteTxLookupEntries(db DatabaseWriter, block *types.Block) {
-	for i, tx := range block.Transactions() {
+	writeTxLookupEntries(db, block.Hash(), block.NumberU64(), block.Transactions())
+}
+
+// writeTxLookupEntries stores a positional metadata for every transaction of the
+// block with the given hash and number.
+func writeTxLookupEntries(db DatabaseWriter, hash common.Hash, number uint64, txs types.Transactions) {
+	for i, tx := range txs {
 		entry := TxLookupEntry{
-			BlockHash:  block.Hash(),
-			BlockIndex: block.NumberU64(),
+			BlockHash:  hash,
+			BlockIndex: number,
 			Index:      uint64(i),
 		}
 		data, err := rlp.EncodeToBytes(entry)
@@ -64,6 +72,112 @@ func DeleteTxLookupEntry(db DatabaseDeleter, hash common.Hash) {
 	db.Delete(append(txLookupPrefix, hash.Bytes()...))
 }
 
+// ReadTxIndexTail retrieves the number of the oldest block whose transaction
+// lookup entries are indexed. A nil return value means the tail was never
+// tracked, in which case all the blocks are indexed.
+func ReadTxIndexTail(db DatabaseReader) *uint64 {
+	data, _ := db.Get(txIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTxIndexTail stores the number of the oldest block whose transaction
+// lookup entries are indexed.
+func WriteTxIndexTail(db DatabaseWriter, number uint64) {
+	if err := db.Put(txIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the transaction index tail", "err", err)
+	}
+}
+
+// IndexTransactions creates the transaction lookup entries of the canonical
+// blocks in the range [from, to), moving the index tail down to from. Blocks
+// are processed from the newest to the oldest, with the tail being persisted
+// together with the entries, so an interrupted run leaves a consistent index
+// behind. The number of the oldest block indexed is returned.
+func IndexTransactions(db ethdb.Database, from, to uint64, interrupt chan struct{}) uint64 {
+	var (
+		batch  = db.NewBatch()
+		logged = time.Now()
+	)
+	for number := to; number > from; number-- {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing transactions", "block", number-1, "remaining", number-1-from)
+			logged = time.Now()
+		}
+		select {
+		case <-interrupt:
+			WriteTxIndexTail(batch, number)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write transaction index", "err", err)
+			}
+			return number
+		default:
+		}
+		hash := ReadCanonicalHash(db, number-1)
+		if body := ReadBody(db, hash, number-1); body != nil {
+			writeTxLookupEntries(batch, hash, number-1, body.Transactions)
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			WriteTxIndexTail(batch, number-1)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write transaction index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	WriteTxIndexTail(batch, from)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write transaction index", "err", err)
+	}
+	return from
+}
+
+// UnindexTransactions removes the transaction lookup entries of the canonical
+// blocks in the range [from, to), moving the index tail up to to. Blocks are
+// processed from the oldest to the newest, with the tail being persisted
+// together with the deletions. The number of the new index tail is returned.
+func UnindexTransactions(db ethdb.Database, from, to uint64, interrupt chan struct{}) uint64 {
+	var (
+		batch  = db.NewBatch()
+		logged = time.Now()
+	)
+	for number := from; number < to; number++ {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Unindexing transactions", "block", number, "remaining", to-number)
+			logged = time.Now()
+		}
+		select {
+		case <-interrupt:
+			WriteTxIndexTail(batch, number)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write transaction index", "err", err)
+			}
+			return number
+		default:
+		}
+		if body := ReadBody(db, ReadCanonicalHash(db, number), number); body != nil {
+			for _, tx := range body.Transactions {
+				DeleteTxLookupEntry(batch, tx.Hash())
+			}
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			WriteTxIndexTail(batch, number+1)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write transaction index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	WriteTxIndexTail(batch, to)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write transaction index", "err", err)
+	}
+	return to
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadTransaction(db DatabaseReader, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -117,3 +231,103 @@ func WriteBloomBits(db DatabaseWriter, bit uint, section uint64, head common.Has
 		log.Crit("Failed to store bloom bits", "err", err)
 	}
 }
+
+// ReadInternalTxIndexTail retrieves the number of the oldest block whose internal
+// value transfers are indexed, or nil if the index was never enabled.
+func ReadInternalTxIndexTail(db DatabaseReader) *uint64 {
+	data, _ := db.Get(internalTxIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteInternalTxIndexTail stores the number of the oldest block whose internal
+// value transfers are indexed.
+func WriteInternalTxIndexTail(db DatabaseWriter, number uint64) {
+	if err := db.Put(internalTxIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the internal transaction index tail", "err", err)
+	}
+}
+
+// WriteInternalTransfers stores the internal value transfers an account took
+// part in within a block.
+func WriteInternalTransfers(db DatabaseWriter, addr common.Address, number uint64, hash common.Hash, transfers []*types.InternalTransfer) {
+	data, err := rlp.EncodeToBytes(transfers)
+	if err != nil {
+		log.Crit("Failed to encode internal transfers", "err", err)
+	}
+	if err := db.Put(internalTxKey(addr, number, hash), data); err != nil {
+		log.Crit("Failed to store internal transfers", "err", err)
+	}
+}
+
+// IterateInternalTransfers iterates over the internal value transfers an account
+// took part in within the blocks of the range [from, to], in ascending block
+// order. Transfers of all the blocks with the same number are reported, not only
+// of the canonical ones. Iteration stops if the callback returns false.
+func IterateInternalTransfers(db ethdb.Iteratee, addr common.Address, from, to uint64, fn func(number uint64, hash common.Hash, transfers []*types.InternalTransfer) bool) {
+	prefix := append(append([]byte{}, internalTxPrefix...), addr.Bytes()...)
+
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()[len(prefix):]
+		if len(key) != 8+common.HashLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[:8])
+		if number > to {
+			return
+		}
+		var transfers []*types.InternalTransfer
+		if err := rlp.DecodeBytes(it.Value(), &transfers); err != nil {
+			log.Error("Invalid internal transfers RLP", "number", number, "err", err)
+			continue
+		}
+		if !fn(number, common.BytesToHash(key[8:]), transfers) {
+			return
+		}
+	}
+}
+
+// WriteInternalTxGap marks a block whose internal value transfers couldn't be
+// indexed, leaving a gap in the otherwise indexed range.
+func WriteInternalTxGap(db DatabaseWriter, number uint64, hash common.Hash) {
+	if err := db.Put(internalTxGapKey(number, hash), nil); err != nil {
+		log.Crit("Failed to store internal transaction index gap", "err", err)
+	}
+}
+
+// DeleteInternalTxGap removes the gap marker of a block, once its internal value
+// transfers have been indexed.
+func DeleteInternalTxGap(db DatabaseDeleter, number uint64, hash common.Hash) {
+	if err := db.Delete(internalTxGapKey(number, hash)); err != nil {
+		log.Crit("Failed to delete internal transaction index gap", "err", err)
+	}
+}
+
+// IterateInternalTxGaps iterates over the blocks of the range [from, to] whose
+// internal value transfers aren't indexed, in ascending block order. Gaps of all
+// the blocks with the same number are reported, not only of the canonical ones.
+// Iteration stops if the callback returns false.
+func IterateInternalTxGaps(db ethdb.Iteratee, from, to uint64, fn func(number uint64, hash common.Hash) bool) {
+	it := db.NewIterator(internalTxGapPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()[len(internalTxGapPrefix):]
+		if len(key) != 8+common.HashLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[:8])
+		if number > to {
+			return
+		}
+		if !fn(number, common.BytesToHash(key[8:])) {
+			return
+		}
+	}
+}
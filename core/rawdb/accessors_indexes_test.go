@@ -66,3 +66,124 @@ func TestLookupStorage(t *testing.T) {
 		}
 	}
 }
+
+// Tests that transaction lookup entries can be created and removed in bulk for
+// a range of canonical blocks, tracking the index tail.
+func TestIndexTransactions(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	var blocks []*types.Block
+	for i := uint64(0); i < 10; i++ {
+		tx := types.NewTransaction(i, common.BytesToAddress([]byte{0x11}), big.NewInt(111), 1111, big.NewInt(11111), nil)
+		block := types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(i)}, []*types.Transaction{tx}, nil, nil)
+
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), i)
+		blocks = append(blocks, block)
+	}
+	check := func(tail uint64) {
+		if stored := ReadTxIndexTail(db); stored == nil || *stored != tail {
+			t.Fatalf("index tail mismatch: have %v, want %d", stored, tail)
+		}
+		for _, block := range blocks {
+			hash, number, _ := ReadTxLookupEntry(db, block.Transactions()[0].Hash())
+			if block.NumberU64() >= tail && (hash != block.Hash() || number != block.NumberU64()) {
+				t.Errorf("block #%d: lookup entry missing", block.NumberU64())
+			}
+			if block.NumberU64() < tail && hash != (common.Hash{}) {
+				t.Errorf("block #%d: lookup entry not removed", block.NumberU64())
+			}
+		}
+	}
+	if tail := IndexTransactions(db, 4, 10, nil); tail != 4 {
+		t.Fatalf("indexed tail mismatch: have %d, want 4", tail)
+	}
+	check(4)
+	if tail := UnindexTransactions(db, 4, 7, nil); tail != 7 {
+		t.Fatalf("unindexed tail mismatch: have %d, want 7", tail)
+	}
+	check(7)
+	if tail := IndexTransactions(db, 0, 7, nil); tail != 0 {
+		t.Fatalf("indexed tail mismatch: have %d, want 0", tail)
+	}
+	check(0)
+
+	// Ensure interrupted runs leave a consistent index behind
+	interrupt := make(chan struct{})
+	close(interrupt)
+	if tail := UnindexTransactions(db, 0, 5, interrupt); tail != 0 {
+		t.Fatalf("interrupted tail mismatch: have %d, want 0", tail)
+	}
+	check(0)
+}
+
+// Tests that internal transfers can be stored and iterated over by account and
+// block range.
+func TestInternalTransferStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	var (
+		alice = common.Address{0x01}
+		bob   = common.Address{0x02}
+	)
+	for number := uint64(1); number <= 4; number++ {
+		transfer := &types.InternalTransfer{TxHash: common.Hash{byte(number)}, Type: "CALL", From: alice, To: bob, Value: big.NewInt(int64(number))}
+		WriteInternalTransfers(db, alice, number, common.Hash{byte(number)}, []*types.InternalTransfer{transfer})
+	}
+	WriteInternalTransfers(db, bob, 2, common.Hash{0x02}, []*types.InternalTransfer{{Type: "SELFDESTRUCT", From: bob, To: alice, Value: big.NewInt(10)}})
+
+	// Iterate over a sub-range of the first account and check the results
+	var numbers []uint64
+	IterateInternalTransfers(db, alice, 2, 3, func(number uint64, hash common.Hash, transfers []*types.InternalTransfer) bool {
+		if hash != (common.Hash{byte(number)}) {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, hash, common.Hash{byte(number)})
+		}
+		if len(transfers) != 1 || transfers[0].Value.Uint64() != number || transfers[0].To != bob {
+			t.Errorf("block %d: transfer mismatch: have %v", number, transfers)
+		}
+		numbers = append(numbers, number)
+		return true
+	})
+	if len(numbers) != 2 || numbers[0] != 2 || numbers[1] != 3 {
+		t.Fatalf("iterated blocks mismatch: have %v, want [2 3]", numbers)
+	}
+	// Ensure the transfers of different accounts don't mix and iteration aborts
+	var count int
+	IterateInternalTransfers(db, bob, 0, 100, func(number uint64, hash common.Hash, transfers []*types.InternalTransfer) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("iterated block count mismatch: have %d, want %d", count, 1)
+	}
+	if tail := ReadInternalTxIndexTail(db); tail != nil {
+		t.Fatalf("unexpected index tail: %d", *tail)
+	}
+	WriteInternalTxIndexTail(db, 3)
+	if tail := ReadInternalTxIndexTail(db); tail == nil || *tail != 3 {
+		t.Fatalf("index tail mismatch: have %v, want %d", tail, 3)
+	}
+	// Ensure gaps are iterated within the requested range and can be cleared
+	WriteInternalTxGap(db, 4, common.Hash{0x04})
+	WriteInternalTxGap(db, 7, common.Hash{0x07})
+	WriteInternalTxGap(db, 9, common.Hash{0x09})
+
+	var gaps []uint64
+	IterateInternalTxGaps(db, 5, 9, func(number uint64, hash common.Hash) bool {
+		gaps = append(gaps, number)
+		return true
+	})
+	if len(gaps) != 2 || gaps[0] != 7 || gaps[1] != 9 {
+		t.Fatalf("index gaps mismatch: have %v, want [7 9]", gaps)
+	}
+	DeleteInternalTxGap(db, 7, common.Hash{0x07})
+
+	gaps = gaps[:0]
+	IterateInternalTxGaps(db, 0, 100, func(number uint64, hash common.Hash) bool {
+		gaps = append(gaps, number)
+		return true
+	})
+	if len(gaps) != 2 || gaps[0] != 4 || gaps[1] != 9 {
+		t.Fatalf("index gaps mismatch after deletion: have %v, want [4 9]", gaps)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ethdb.Database
+	*freezer
+}
+
+// Close implements ethdb.Database, closing both the fast key-value store as
+// well as the slow ancient tables.
+func (frdb *freezerdb) Close() {
+	if err := frdb.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	frdb.Database.Close()
+}
+
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer moving immutable chain segments into cold
+// storage.
+func NewDatabaseWithFreezer(db ethdb.Database, freezer string, namespace string) (ethdb.Database, error) {
+	frdb, err := newFreezer(freezer, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &freezerdb{
+		Database: db,
+		freezer:  frdb,
+	}, nil
+}
+
+// KeyValueStore returns the key-value store backing a database. For databases
+// created with NewDatabaseWithFreezer, the ancient tables are stripped off,
+// every other database is returned as is.
+func KeyValueStore(db ethdb.Database) ethdb.Database {
+	if frdb, ok := db.(*freezerdb); ok {
+		return frdb.Database
+	}
+	return db
+}
+
+// DatabaseStat is the number and the total size of the entries belonging to a
+// single data category of the database.
+type DatabaseStat struct {
+	Database string `json:"database"` // Backing store of the data, key-value or ancient
+	Category string `json:"category"` // Data category as defined by the database schema
+	Count    uint64 `json:"count"`    // Number of entries in the category
+	Size     uint64 `json:"size"`     // Total size of the keys and values in bytes
+}
+
+// InspectDatabase traverses the entire database and tallies the number and the
+// size of the entries by data category, as defined by the schema prefixes. The
+// ancient tables of freezer backed databases are also reported, item counts and
+// file sizes being retrieved from the freezer.
+func InspectDatabase(db ethdb.Database) ([]*DatabaseStat, error) {
+	var (
+		headers         = &DatabaseStat{Database: "key-value", Category: "headers"}
+		bodies          = &DatabaseStat{Database: "key-value", Category: "bodies"}
+		receipts        = &DatabaseStat{Database: "key-value", Category: "receipts"}
+		tds             = &DatabaseStat{Database: "key-value", Category: "difficulties"}
+		numHashPairs    = &DatabaseStat{Database: "key-value", Category: "canonical-hashes"}
+		hashNumPairs    = &DatabaseStat{Database: "key-value", Category: "header-numbers"}
+		txLookups       = &DatabaseStat{Database: "key-value", Category: "tx-lookups"}
+		bloomBits       = &DatabaseStat{Database: "key-value", Category: "bloombits"}
+		bloomBitsIndex  = &DatabaseStat{Database: "key-value", Category: "bloombits-index"}
+		internalTxs     = &DatabaseStat{Database: "key-value", Category: "internal-txs"}
+		internalTxIndex = &DatabaseStat{Database: "key-value", Category: "internal-txs-index"}
+		accountSnaps    = &DatabaseStat{Database: "key-value", Category: "account-snapshots"}
+		storageSnaps    = &DatabaseStat{Database: "key-value", Category: "storage-snapshots"}
+		preimages       = &DatabaseStat{Database: "key-value", Category: "preimages"}
+		configs         = &DatabaseStat{Database: "key-value", Category: "configs"}
+		tries           = &DatabaseStat{Database: "key-value", Category: "trie-nodes"}
+		metadata        = &DatabaseStat{Database: "key-value", Category: "metadata"}
+		unaccounted     = &DatabaseStat{Database: "key-value", Category: "unaccounted"}
+		metadataEntries = [][]byte{
+			databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey, fastTrieProgressKey,
+			txIndexTailKey, snapshotRootKey, snapshotJournalKey, snapshotGeneratorKey, internalTxIndexTailKey,
+		}
+	)
+	it := KeyValueStore(db).NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		var (
+			key  = it.Key()
+			size = uint64(len(key) + len(it.Value()))
+			stat = unaccounted
+		)
+		switch {
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength:
+			stat = headers
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength+len(headerTDSuffix) && bytes.HasSuffix(key, headerTDSuffix):
+			stat = tds
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+len(headerHashSuffix) && bytes.HasSuffix(key, headerHashSuffix):
+			stat = numHashPairs
+		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == len(headerNumberPrefix)+common.HashLength:
+			stat = hashNumPairs
+		case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == len(blockBodyPrefix)+8+common.HashLength:
+			stat = bodies
+		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == len(blockReceiptsPrefix)+8+common.HashLength:
+			stat = receipts
+		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == len(txLookupPrefix)+common.HashLength:
+			stat = txLookups
+		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength:
+			stat = bloomBits
+		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+			stat = bloomBitsIndex
+		case bytes.HasPrefix(key, internalTxPrefix) && len(key) == len(internalTxPrefix)+common.AddressLength+8+common.HashLength:
+			stat = internalTxs
+		case bytes.HasPrefix(key, InternalTxIndexPrefix):
+			stat = internalTxIndex
+		case bytes.HasPrefix(key, internalTxGapPrefix) && len(key) == len(internalTxGapPrefix)+8+common.HashLength:
+			stat = internalTxIndex
+		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == len(SnapshotAccountPrefix)+common.HashLength:
+			stat = accountSnaps
+		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == len(SnapshotStoragePrefix)+2*common.HashLength:
+			stat = storageSnaps
+		case bytes.HasPrefix(key, preimagePrefix) && len(key) == len(preimagePrefix)+common.HashLength:
+			stat = preimages
+		case bytes.HasPrefix(key, configPrefix) && len(key) == len(configPrefix)+common.HashLength:
+			stat = configs
+		case len(key) == common.HashLength:
+			stat = tries
+		default:
+			for _, meta := range metadataEntries {
+				if bytes.Equal(key, meta) {
+					stat = metadata
+					break
+				}
+			}
+		}
+		stat.Count++
+		stat.Size += size
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	stats := []*DatabaseStat{
+		headers, bodies, receipts, tds, numHashPairs, hashNumPairs, txLookups, bloomBits, bloomBitsIndex,
+		internalTxs, internalTxIndex, accountSnaps, storageSnaps, preimages, configs, tries, metadata, unaccounted,
+	}
+	// Append the ancient table statistics if the database has a freezer
+	if frdb, ok := db.(AncientReader); ok {
+		frozen, err := frdb.Ancients()
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range freezerTables {
+			size, err := frdb.AncientSize(table)
+			if err != nil {
+				return nil, err
+			}
+			stats = append(stats, &DatabaseStat{Database: "ancient", Category: table, Count: frozen, Size: size})
+		}
+	}
+	return stats, nil
+}
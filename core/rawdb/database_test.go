@@ -0,0 +1,79 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
+)
+
+// Tests that the database inspector attributes every entry to the category it
+// belongs to according to the schema.
+func TestInspectDatabase(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	tx := types.NewTransaction(1, common.BytesToAddress([]byte{0x11}), big.NewInt(111), 1111, big.NewInt(11111), []byte{0x11, 0x11, 0x11})
+	block := types.NewBlock(&types.Header{Number: big.NewInt(314)}, []*types.Transaction{tx}, nil, nil)
+
+	WriteBlock(db, block)
+	WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+	WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(314))
+	WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	WriteTxLookupEntries(db, block)
+	WriteHeadBlockHash(db, block.Hash())
+	WriteAccountSnapshot(db, common.Hash{0x01}, []byte{0x01})
+	WritePreimages(db, 0, map[common.Hash][]byte{{0x02}: {0x02}})
+	WriteInternalTransfers(db, common.Address{0x05}, block.NumberU64(), block.Hash(), nil)
+	WriteInternalTxIndexTail(db, block.NumberU64())
+	WriteInternalTxGap(db, block.NumberU64(), block.Hash())
+	db.Put(append(append([]byte{}, InternalTxIndexPrefix...), []byte("progress")...), []byte{0x06})
+	db.Put(common.Hash{0x03}.Bytes(), []byte{0x03})
+	db.Put([]byte("unknown"), []byte{0x04})
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	want := map[string]uint64{
+		"headers":            1,
+		"bodies":             1,
+		"receipts":           1,
+		"difficulties":       1,
+		"canonical-hashes":   1,
+		"header-numbers":     1,
+		"tx-lookups":         1,
+		"account-snapshots":  1,
+		"preimages":          1,
+		"trie-nodes":         1,
+		"metadata":           2,
+		"internal-txs":       1,
+		"internal-txs-index": 2,
+		"unaccounted":        1,
+	}
+	for _, stat := range stats {
+		if stat.Count != want[stat.Category] {
+			t.Errorf("category %s: count mismatch: have %d, want %d", stat.Category, stat.Count, want[stat.Category])
+		}
+		if stat.Count > 0 && stat.Size == 0 {
+			t.Errorf("category %s: zero size for %d entries", stat.Category, stat.Count)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/metrics"
+	"github.com/golang/snappy"
+)
+
+var (
+	// errClosed is returned if an operation attempts to read from or write to the
+	// freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+// indexEntrySize is the size of a single entry in the index file: the big endian
+// encoded end offset of the item within the data file.
+const indexEntrySize = 8
+
+// freezerTable represents a single chained data table within the freezer (e.g.
+// blocks). It consists of an append-only data file containing the (optionally
+// snappy compressed) binary blobs and an index file containing the end offset
+// of every blob within the data file.
+type freezerTable struct {
+	items uint64 // Number of items stored in the table (must be first for atomic access on 32 bit)
+
+	noCompression bool     // If true, disables snappy compression
+	data          *os.File // File descriptor for the data file of the table
+	index         *os.File // File descriptor for the index file of the table
+	bytes         uint64   // Number of bytes written into the data file
+
+	readMeter  metrics.Meter // Meter for measuring the effective amount of data read
+	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
+
+	logger log.Logger   // Logger with database path and table name embedded
+	lock   sync.RWMutex // Mutex protecting the data file descriptors
+}
+
+// newTable opens a freezer table, creating the data and index files if they are
+// non existent. Both files are truncated to the shortest common length to ensure
+// they don't go out of sync.
+func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, disableSnappy bool) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	var idxName, datName string
+	if disableSnappy {
+		idxName, datName = name+".ridx", name+".rdat"
+	} else {
+		idxName, datName = name+".cidx", name+".cdat"
+	}
+	index, err := os.OpenFile(filepath.Join(path, idxName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(path, datName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	tab := &freezerTable{
+		noCompression: disableSnappy,
+		data:          data,
+		index:         index,
+		readMeter:     readMeter,
+		writeMeter:    writeMeter,
+		logger:        log.New("database", path, "table", name),
+	}
+	if err := tab.repair(); err != nil {
+		tab.Close()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// repair cross checks the data and index files and truncates them to be in sync
+// with each other after a potential crash / data loss.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	// Drop any partially written index entry
+	indexSize := stat.Size()
+	if overflow := indexSize % indexEntrySize; overflow != 0 {
+		indexSize -= overflow
+		if err := t.index.Truncate(indexSize); err != nil {
+			return err
+		}
+	}
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	// Drop any index entries pointing past the end of the data file
+	items := uint64(indexSize / indexEntrySize)
+	for items > 0 {
+		end, err := t.readOffset(items - 1)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			break
+		}
+		items--
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	// Drop any data written past the last indexed item
+	var end uint64
+	if items > 0 {
+		if end, err = t.readOffset(items - 1); err != nil {
+			return err
+		}
+	}
+	if end < dataSize {
+		if err := t.data.Truncate(int64(end)); err != nil {
+			return err
+		}
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	t.bytes = end
+	atomic.StoreUint64(&t.items, items)
+
+	t.logger.Debug("Chain freezer table opened", "items", items, "size", end)
+	return nil
+}
+
+// readOffset retrieves the end offset of the given item from the index file.
+func (t *freezerTable) readOffset(item uint64) (uint64, error) {
+	buf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buf, int64(item*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// truncate discards any recent data above the provided threshold number.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if atomic.LoadUint64(&t.items) <= items {
+		return nil
+	}
+	t.logger.Warn("Truncating freezer table", "items", atomic.LoadUint64(&t.items), "limit", items)
+
+	var end uint64
+	if items > 0 {
+		var err error
+		if end, err = t.readOffset(items - 1); err != nil {
+			return err
+		}
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.bytes = end
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// append injects a binary blob at the end of the freezer table. The item number
+// is a precautionary parameter to ensure data correctness, but the table will
+// reject already existing data.
+//
+// Note, this method will *not* flush any data to disk so be sure to explicitly
+// fsync before irreversibly deleting data from the database.
+func (t *freezerTable) append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if atomic.LoadUint64(&t.items) != item {
+		return fmt.Errorf("%v: have %d, want %d", errOutOrderInsertion, item, atomic.LoadUint64(&t.items))
+	}
+	if !t.noCompression {
+		blob = snappy.Encode(nil, blob)
+	}
+	if _, err := t.data.WriteAt(blob, int64(t.bytes)); err != nil {
+		return err
+	}
+	end := t.bytes + uint64(len(blob))
+
+	entry := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(entry, end)
+	if _, err := t.index.WriteAt(entry, int64(item*indexEntrySize)); err != nil {
+		return err
+	}
+	t.bytes = end
+	t.writeMeter.Mark(int64(len(blob) + indexEntrySize))
+	atomic.AddUint64(&t.items, 1)
+	return nil
+}
+
+// retrieve looks up the data offset of an item with the given number and
+// retrieves the raw binary blob from the data file.
+func (t *freezerTable) retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return nil, errClosed
+	}
+	if atomic.LoadUint64(&t.items) <= item {
+		return nil, errOutOfBounds
+	}
+	var start uint64
+	if item > 0 {
+		var err error
+		if start, err = t.readOffset(item - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, err := t.readOffset(item)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+
+	if t.noCompression {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// has returns an indicator whether the specified number data exists in the
+// freezer table.
+func (t *freezerTable) has(number uint64) bool {
+	return atomic.LoadUint64(&t.items) > number
+}
+
+// size returns the total data size in the freezer table.
+func (t *freezerTable) size() (uint64, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return 0, errClosed
+	}
+	return t.bytes + atomic.LoadUint64(&t.items)*indexEntrySize, nil
+}
+
+// Sync pushes any pending data from memory out to disk. This is an expensive
+// operation, so use it with care.
+func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	return t.data.Sync()
+}
+
+// Close closes all opened files.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.index = nil
+	}
+	if t.data != nil {
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.data = nil
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
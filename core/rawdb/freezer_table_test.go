@@ -0,0 +1,192 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/metrics"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+// getChunk returns a chunk of data, filled with the given byte.
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// Tests that items can be appended to and retrieved from a freezer table, both
+// with and without compression, and that the table survives a reopen.
+func TestFreezerBasics(t *testing.T) {
+	for _, disableSnappy := range []bool{true, false} {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		table, err := newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, disableSnappy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 255; i++ {
+			if err := table.append(uint64(i), getChunk(15, i)); err != nil {
+				t.Fatalf("snappy %v: failed to append item %d: %v", !disableSnappy, i, err)
+			}
+		}
+		if err := table.append(300, getChunk(15, 0)); err == nil {
+			t.Fatalf("snappy %v: out of order insertion accepted", !disableSnappy)
+		}
+		table.Close()
+
+		if table, err = newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, disableSnappy); err != nil {
+			t.Fatal(err)
+		}
+		defer table.Close()
+
+		for i := 0; i < 255; i++ {
+			blob, err := table.retrieve(uint64(i))
+			if err != nil {
+				t.Fatalf("snappy %v: failed to retrieve item %d: %v", !disableSnappy, i, err)
+			}
+			if want := getChunk(15, i); !bytes.Equal(blob, want) {
+				t.Fatalf("snappy %v: item %d mismatch: have %x, want %x", !disableSnappy, i, blob, want)
+			}
+		}
+		if _, err := table.retrieve(255); err != errOutOfBounds {
+			t.Fatalf("snappy %v: out of bounds retrieval error mismatch: have %v, want %v", !disableSnappy, err, errOutOfBounds)
+		}
+	}
+}
+
+// Tests that a table with a partially written index entry and data blob gets
+// repaired to the last fully written item upon reopening.
+func TestFreezerRepairDanglingData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		table.append(uint64(i), getChunk(20, i))
+	}
+	table.Close()
+
+	// Simulate a crash in the middle of writing the next item
+	data, err := os.OpenFile(filepath.Join(dir, "test.rdat"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Write(getChunk(7, 0xff))
+	data.Close()
+
+	index, err := os.OpenFile(filepath.Join(dir, "test.ridx"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Write([]byte{0x00, 0x00, 0x01})
+	index.Close()
+
+	if table, err = newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, true); err != nil {
+		t.Fatal(err)
+	}
+	defer table.Close()
+
+	if table.items != 10 {
+		t.Fatalf("item count mismatch: have %d, want %d", table.items, 10)
+	}
+	if err := table.append(10, getChunk(20, 10)); err != nil {
+		t.Fatalf("failed to append after repair: %v", err)
+	}
+	for i := 0; i < 11; i++ {
+		if blob, err := table.retrieve(uint64(i)); err != nil || !bytes.Equal(blob, getChunk(20, i)) {
+			t.Fatalf("item %d mismatch after repair: have %x, %v", i, blob, err)
+		}
+	}
+}
+
+// Tests that chain data moved into the freezer is transparently retrieved by
+// the accessors once it's deleted from the key-value store.
+func TestFreezerAccessorFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := NewDatabaseWithFreezer(ethdb.NewMemDatabase(), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	header := &types.Header{Number: big.NewInt(0), Extra: []byte("test header")}
+	block := types.NewBlockWithHeader(header)
+	hash, number := block.Hash(), block.NumberU64()
+
+	WriteBlock(db, block)
+	WriteCanonicalHash(db, hash, number)
+	WriteReceipts(db, hash, number, nil)
+	WriteTd(db, hash, number, big.NewInt(42))
+
+	// Move the block into the freezer and drop it from the key-value store
+	headerRLP, _ := rlp.EncodeToBytes(header)
+	if err := db.(AncientStore).AppendAncient(number, hash[:], headerRLP, ReadBodyRLP(db, hash, number), ReadReceiptsRLP(db, hash, number), ReadTdRLP(db, hash, number)); err != nil {
+		t.Fatalf("failed to freeze block: %v", err)
+	}
+	DeleteBlockWithoutNumber(db, hash, number)
+	DeleteCanonicalHash(db, number)
+
+	if has, _ := KeyValueStore(db).Has(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...)); has {
+		t.Fatalf("frozen header still in key-value store")
+	}
+	if stored := ReadCanonicalHash(db, number); stored != hash {
+		t.Fatalf("canonical hash mismatch: have %x, want %x", stored, hash)
+	}
+	if !HasHeader(db, hash, number) || !HasBody(db, hash, number) {
+		t.Fatalf("frozen block reported missing")
+	}
+	if entry := ReadBlock(db, hash, number); entry == nil || entry.Hash() != hash {
+		t.Fatalf("frozen block mismatch: have %v, want %v", entry, block)
+	}
+	if td := ReadTd(db, hash, number); td == nil || td.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("frozen total difficulty mismatch: have %v, want %v", td, 42)
+	}
+	if receipts := ReadReceipts(db, hash, number); receipts == nil {
+		t.Fatalf("frozen receipts missing")
+	}
+	// Ensure data for a different hash at the same height is not served
+	if HasHeader(db, common.Hash{0x01}, number) {
+		t.Fatalf("non-canonical header reported frozen")
+	}
+}
@@ -90,7 +90,8 @@ type (
 		account *common.Address
 	}
 	resetObjectChange struct {
-		prev *stateObject
+		prev         *stateObject
+		prevdestruct bool
 	}
 	suicideChange struct {
 		account     *common.Address
@@ -111,6 +112,10 @@ type (
 		account       *common.Address
 		key, prevalue common.Hash
 	}
+	fakeStorageChange struct {
+		account       *common.Address
+		key, prevalue common.Hash
+	}
 	codeChange struct {
 		account            *common.Address
 		prevcode, prevhash []byte
@@ -144,6 +149,9 @@ func (ch createObjectChange) dirtied() *common.Address {
 
 func (ch resetObjectChange) revert(s *StateDB) {
 	s.setStateObject(ch.prev)
+	if !ch.prevdestruct && s.snap != nil {
+		delete(s.snapDestructs, ch.prev.addrHash)
+	}
 }
 
 func (ch resetObjectChange) dirtied() *common.Address {
@@ -203,6 +211,14 @@ func (ch storageChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch fakeStorageChange) revert(s *StateDB) {
+	s.getStateObject(*ch.account).fakeStorage[ch.key] = ch.prevalue
+}
+
+func (ch fakeStorageChange) dirtied() *common.Address {
+	return ch.account
+}
+
 func (ch refundChange) revert(s *StateDB) {
 	s.refund = ch.prev
 }
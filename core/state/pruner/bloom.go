@@ -0,0 +1,167 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+// bloomHashes is the number of hash functions used by the state bloom. Since
+// all the keys inserted are already cryptographic hashes, the functions are
+// simply distinct 8 byte windows of the key.
+const bloomHashes = 4
+
+// errInvalidKey is returned if a key of unexpected length is inserted into or
+// queried from the state bloom.
+var errInvalidKey = errors.New("invalid state bloom key")
+
+// stateBloom is a bloom filter recording the hashes of all trie nodes and
+// contract codes reachable from the state root being retained. In the pruning
+// stage every entry not contained in the filter is deleted from the database.
+//
+// False positives are allowed: such entries don't belong to the retained state
+// but are not deleted either, leaving a few dangling nodes on disk. They are
+// never referenced again, so apart from the wasted space they are harmless.
+//
+// After the entire state is iterated, the bloom filter is persisted to disk. Its
+// presence indicates that the generation procedure finished and pruning can be
+// resumed from it after a crash.
+type stateBloom struct {
+	bits []uint64 // Bit vector of the filter
+	size uint64   // Number of bits in the filter
+}
+
+// newStateBloomWithSize creates a brand new state bloom for state generation.
+// The bloom filter will be created by the passing bloom filter size, in
+// megabytes.
+func newStateBloomWithSize(size uint64) *stateBloom {
+	words := size * 1024 * 1024 / 8
+	if words == 0 {
+		words = 1
+	}
+	log.Info("Initialized state bloom", "size", common.StorageSize(words*8))
+	return &stateBloom{
+		bits: make([]uint64, words),
+		size: words * 64,
+	}
+}
+
+// newStateBloomFromDisk loads the state bloom from the given file.
+// In this case the assumption is held the bloom filter is complete.
+func newStateBloomFromDisk(filename string) (*stateBloom, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 || len(blob)%8 != 0 {
+		return nil, errors.New("corrupted state bloom")
+	}
+	bits := make([]uint64, len(blob)/8)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(blob[i*8:])
+	}
+	return &stateBloom{bits: bits, size: uint64(len(bits)) * 64}, nil
+}
+
+// Commit flushes the bloom filter content into the disk and marks the bloom
+// as complete.
+func (bloom *stateBloom) Commit(filename, tempname string) error {
+	f, err := os.Create(tempname)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(f)
+
+	// Serialize the bit vector in chunks to avoid excessive small writes
+	buf := make([]byte, 0, 64*1024)
+	for i, word := range bloom.bits {
+		buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(buf[len(buf)-8:], word)
+
+		if len(buf) == cap(buf) || i == len(bloom.bits)-1 {
+			if _, err := w.Write(buf); err != nil {
+				f.Close()
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	// Ensure the file is fully flushed to disk
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	// Move the temporary file into its final location
+	return os.Rename(tempname, filename)
+}
+
+// Put implements ethdb.Putter, but only the key is recorded.
+func (bloom *stateBloom) Put(key []byte, value []byte) error {
+	if len(key) != common.HashLength {
+		return errInvalidKey
+	}
+	for i := 0; i < bloomHashes; i++ {
+		bit := binary.BigEndian.Uint64(key[i*8:]) % bloom.size
+		bloom.bits[bit/64] |= 1 << (bit % 64)
+	}
+	return nil
+}
+
+// Contain reports whether the key is contained. A positive answer means the key
+// may be contained, a negative one that it is definitely not.
+func (bloom *stateBloom) Contain(key []byte) (bool, error) {
+	if len(key) != common.HashLength {
+		return false, errInvalidKey
+	}
+	for i := 0; i < bloomHashes; i++ {
+		bit := binary.BigEndian.Uint64(key[i*8:]) % bloom.size
+		if bloom.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// falsePositiveRate estimates the false positive rate of the filter after the
+// given number of insertions.
+func (bloom *stateBloom) falsePositiveRate(items uint64) float64 {
+	return math.Pow(1-math.Exp(-float64(bloomHashes)*float64(items)/float64(bloom.size)), bloomHashes)
+}
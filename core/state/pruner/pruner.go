@@ -0,0 +1,400 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements offline pruning of stale state data from the disk.
+package pruner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+const (
+	// stateBloomFilePrefix is the filename prefix of state bloom filter.
+	stateBloomFilePrefix = "statebloom"
+
+	// stateBloomFileSuffix is the filename suffix of state bloom filter.
+	stateBloomFileSuffix = "bf.gz"
+
+	// stateBloomFileTempSuffix is the filename suffix of state bloom filter
+	// while it is being written out to detect write aborts.
+	stateBloomFileTempSuffix = ".tmp"
+
+	// recentStates is the depth of the oldest state that is flushed to disk by
+	// a cleanly stopped blockchain (the in-memory trie window).
+	recentStates = 128
+
+	// logInterval is the frequency of the progress reports.
+	logInterval = 8 * time.Second
+)
+
+var (
+	// errNoHeadState is returned if none of the recent states flushed to disk
+	// is available as the pruning target.
+	errNoHeadState = errors.New("no recent state available on disk")
+)
+
+// Pruner is an offline tool to prune the stale state with the help of a bloom
+// filter. The workflow of pruner is very simple:
+//
+// - iterate the target state trie, recording the hash of every trie node and
+//   contract code reachable from it in the bloom filter.
+// - persist the bloom filter, marking the start of the pruning itself.
+// - iterate the database and delete all trie nodes and contract codes which
+//   are not contained in the bloom filter.
+//
+// The persisted bloom filter is used to resume pruning after a crash, which is
+// done automatically when the node or the pruner is started again.
+//
+// Since trie nodes and contract codes are the only entries keyed by a plain
+// 32 byte hash in the database, any such entry not recorded in the filter is
+// stale state and can be safely deleted.
+type Pruner struct {
+	db         ethdb.Database
+	kvdb       ethdb.Database
+	stateBloom *stateBloom
+	datadir    string
+	headHeader *types.Header
+}
+
+// NewPruner creates the pruner instance with the bloom filter of the given size
+// in megabytes.
+func NewPruner(db ethdb.Database, datadir string, bloomSize uint64) (*Pruner, error) {
+	kvdb := rawdb.KeyValueStore(db)
+	headBlock := rawdb.ReadHeadBlockHash(db)
+	if headBlock == (common.Hash{}) {
+		return nil, errors.New("failed to load head block")
+	}
+	number := rawdb.ReadHeaderNumber(db, headBlock)
+	if number == nil {
+		return nil, errors.New("failed to load head block number")
+	}
+	headHeader := rawdb.ReadHeader(db, headBlock, *number)
+	if headHeader == nil {
+		return nil, errors.New("failed to load head header")
+	}
+	// Sanitize the bloom filter size if it's too small.
+	if bloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", 256)
+		bloomSize = 256
+	}
+	return &Pruner{
+		db:         db,
+		kvdb:       kvdb,
+		stateBloom: newStateBloomWithSize(bloomSize),
+		datadir:    datadir,
+		headHeader: headHeader,
+	}, nil
+}
+
+// Prune deletes all historical state nodes except the nodes belong to the
+// specified state version. If user doesn't specify the state version, the most
+// recent state persisted on disk will be used as the target (the head state, or
+// one of the states the blockchain flushes when it's stopped).
+func (p *Pruner) Prune(root common.Hash) error {
+	// If the state bloom filter is already committed previously, reuse it for
+	// pruning and ignore the requested target.
+	bloomPath, bloomRoot, err := findBloomFilter(p.datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath != "" {
+		log.Info("Resuming interrupted state pruning", "root", bloomRoot)
+		return RecoverPruning(p.datadir, p.db)
+	}
+	if root == (common.Hash{}) {
+		if root, err = p.recentRoot(); err != nil {
+			return err
+		}
+	} else if !hasState(p.db, root) {
+		return fmt.Errorf("associated state[%x] is not present", root)
+	}
+	log.Info("Selecting state as the pruning target", "root", root)
+
+	// Traverse the target state and the genesis state, committing every node
+	// reachable from them to the bloom filter.
+	start := time.Now()
+	if err := extractState(p.db, root, p.stateBloom, "target"); err != nil {
+		return err
+	}
+	if err := extractGenesis(p.db, p.stateBloom); err != nil {
+		return err
+	}
+	filterName := bloomFilterName(p.datadir, root)
+
+	log.Info("Writing state bloom to disk", "name", filterName)
+	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	log.Info("State bloom filter committed", "name", filterName)
+
+	return prune(p.kvdb, p.stateBloom, filterName, start)
+}
+
+// recentRoot returns the state root of the most recent block whose state is
+// fully present on disk.
+func (p *Pruner) recentRoot() (common.Hash, error) {
+	head := p.headHeader.Number.Uint64()
+	for _, offset := range []uint64{0, 1, recentStates - 1} {
+		if head < offset {
+			break
+		}
+		hash := rawdb.ReadCanonicalHash(p.db, head-offset)
+		header := rawdb.ReadHeader(p.db, hash, head-offset)
+		if header == nil {
+			continue
+		}
+		if hasState(p.db, header.Root) {
+			return header.Root, nil
+		}
+	}
+	return common.Hash{}, errNoHeadState
+}
+
+// RecoverPruning will resume the pruning procedure during the system restart.
+// This function is used in this case: user tries to prune state data, but the
+// system was interrupted midway because of crash or manual-kill. In this case
+// if the bloom filter for filtering active state is already constructed, the
+// pruning can be resumed. What's more if the bloom filter is constructed, the
+// pruning **has to be resumed**. Otherwise a lot of dangling nodes may be left
+// in the disk.
+func RecoverPruning(datadir string, db ethdb.Database) error {
+	bloomPath, bloomRoot, err := findBloomFilter(datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath == "" {
+		return nil // nothing to recover
+	}
+	stateBloom, err := newStateBloomFromDisk(bloomPath)
+	if err != nil {
+		return err
+	}
+	log.Info("Loaded state bloom filter", "path", bloomPath, "root", bloomRoot)
+
+	return prune(rawdb.KeyValueStore(db), stateBloom, bloomPath, time.Now())
+}
+
+// prune deletes all the 32 byte keyed entries not contained in the bloom filter
+// from the database, then removes the filter and compacts the database.
+func prune(db ethdb.Database, stateBloom *stateBloom, bloomPath string, start time.Time) error {
+	var (
+		count  int
+		size   common.StorageSize
+		pstart = time.Now()
+		logged = time.Now()
+		batch  = db.NewBatch()
+		iter   = db.NewIterator(nil, nil)
+	)
+	for iter.Next() {
+		key := iter.Key()
+
+		// All state entries don't belong to specific state and genesis are
+		// deleted here.
+		if len(key) == common.HashLength {
+			if ok, err := stateBloom.Contain(key); err != nil {
+				iter.Release()
+				return err
+			} else if ok {
+				continue
+			}
+			count++
+			size += common.StorageSize(len(key) + len(iter.Value()))
+			batch.Delete(key)
+
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					iter.Release()
+					return err
+				}
+				batch.Reset()
+			}
+			if time.Since(logged) > logInterval {
+				var eta time.Duration // Realistically will never remain uninited
+				if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+					var (
+						left  = math.MaxUint64 - done
+						speed = done/uint64(time.Since(pstart)/time.Millisecond+1) + 1 // +1 to avoid division by zero
+					)
+					eta = time.Duration(left/speed) * time.Millisecond
+				}
+				log.Info("Pruning state data", "nodes", count, "size", size,
+					"elapsed", common.PrettyDuration(time.Since(pstart)), "eta", common.PrettyDuration(eta))
+				logged = time.Now()
+			}
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	log.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Delete the state bloom, it marks the entire pruning procedure is
+	// finished. If any crashes or manual exit happens before this,
+	// `RecoverPruning` will pick it up in the next restarts to redo all
+	// the things.
+	os.RemoveAll(bloomPath)
+
+	// Start compactions, will remove the deleted data from the disk immediately.
+	// Note for small pruning, the compaction is skipped.
+	if count >= rangeCompactionThreshold {
+		cstart := time.Now()
+		for b := 0x00; b <= 0xf0; b += 0x10 {
+			var (
+				start = []byte{byte(b)}
+				end   = []byte{byte(b + 0x10)}
+			)
+			if b == 0xf0 {
+				end = nil
+			}
+			log.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
+			if err := db.Compact(start, end); err != nil {
+				log.Error("Database compaction failed", "error", err)
+				return err
+			}
+		}
+		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	log.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// rangeCompactionThreshold is the minimal deleted entry number for triggering
+// range compaction. It's a quite arbitrary number but just to avoid triggering
+// range compaction because of small deletion.
+const rangeCompactionThreshold = 100000
+
+// hasState reports whether the state trie with the given root is present on
+// disk.
+func hasState(db ethdb.Database, root common.Hash) bool {
+	_, err := state.New(root, state.NewDatabase(db), nil)
+	return err == nil
+}
+
+// extractState iterates over the state trie with the given root, including all
+// the storage tries and contract codes, and commits every entry to the bloom.
+func extractState(db ethdb.Database, root common.Hash, stateBloom *stateBloom, name string) error {
+	statedb, err := state.New(root, state.NewDatabase(db), nil)
+	if err != nil {
+		return err
+	}
+	var (
+		nodes  int
+		start  = time.Now()
+		logged = time.Now()
+		it     = state.NewNodeIterator(statedb)
+	)
+	for it.Next() {
+		// Embedded nodes have no hash of their own, they are part of the parent
+		if it.Hash == (common.Hash{}) {
+			continue
+		}
+		stateBloom.Put(it.Hash.Bytes(), nil)
+		nodes++
+
+		if time.Since(logged) > logInterval {
+			log.Info("Traversing state", "state", name, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if it.Error != nil {
+		return it.Error
+	}
+	log.Info("Traversed state", "state", name, "root", root, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)),
+		"fprate", stateBloom.falsePositiveRate(uint64(nodes)))
+	return nil
+}
+
+// extractGenesis loads the genesis state and commits all the state entries
+// into the given bloomfilter.
+func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	if genesisHash == (common.Hash{}) {
+		return errors.New("missing genesis hash")
+	}
+	genesis := rawdb.ReadBlock(db, genesisHash, 0)
+	if genesis == nil {
+		return errors.New("missing genesis block")
+	}
+	return extractState(db, genesis.Root(), stateBloom, "genesis")
+}
+
+// bloomFilterName returns the path of the bloom filter used to prune towards
+// the given state root.
+func bloomFilterName(datadir string, hash common.Hash) string {
+	return filepath.Join(datadir, fmt.Sprintf("%s.%s.%s", stateBloomFilePrefix, hash.Hex(), stateBloomFileSuffix))
+}
+
+// isBloomFilter checks whether the given file name is a committed bloom filter,
+// returning the state root it was built for.
+func isBloomFilter(filename string) (bool, common.Hash) {
+	filename = filepath.Base(filename)
+	if strings.HasPrefix(filename, stateBloomFilePrefix) && strings.HasSuffix(filename, stateBloomFileSuffix) {
+		return true, common.HexToHash(filename[len(stateBloomFilePrefix)+1 : len(filename)-len(stateBloomFileSuffix)-1])
+	}
+	return false, common.Hash{}
+}
+
+// findBloomFilter looks up a committed bloom filter in the data directory. Any
+// leftover temporary filter files from aborted generations are removed.
+func findBloomFilter(datadir string) (string, common.Hash, error) {
+	var (
+		stateBloomPath string
+		stateBloomRoot common.Hash
+	)
+	if err := filepath.Walk(datadir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != datadir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, stateBloomFileTempSuffix) {
+			log.Warn("Removing unfinished state bloom", "path", path)
+			return os.Remove(path)
+		}
+		if ok, root := isBloomFilter(path); ok {
+			stateBloomPath, stateBloomRoot = path, root
+		}
+		return nil
+	}); err != nil && !os.IsNotExist(err) {
+		return "", common.Hash{}, err
+	}
+	return stateBloomPath, stateBloomRoot, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// newTestChain creates a LevelDB backed database in a temporary folder, filled
+// with a short chain whose every state is persisted.
+func newTestChain(t *testing.T) (string, *ethdb.LDBDatabase, []*types.Block) {
+	dir, err := ioutil.TempDir("", "pruner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := ethdb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.HomesteadSigner{}
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 8, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{byte(0x10 + i)}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	for _, block := range blocks {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.WriteHeadBlockHash(db, blocks[len(blocks)-1].Hash())
+
+	return dir, db, blocks
+}
+
+// Tests that pruning retains the head and genesis states but deletes every
+// intermediate one.
+func TestPruneState(t *testing.T) {
+	dir, db, blocks := newTestChain(t)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	head := blocks[len(blocks)-1]
+	p := &Pruner{
+		db:         db,
+		kvdb:       db,
+		stateBloom: newStateBloomWithSize(1),
+		datadir:    dir,
+		headHeader: head.Header(),
+	}
+	if err := p.Prune(common.Hash{}); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if !hasState(db, head.Root()) {
+		t.Fatalf("head state pruned")
+	}
+	genesis := rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, 0), 0)
+	if !hasState(db, genesis.Root()) {
+		t.Fatalf("genesis state pruned")
+	}
+	for _, block := range blocks[:len(blocks)-1] {
+		if hasState(db, block.Root()) {
+			t.Fatalf("stale state %d retained", block.NumberU64())
+		}
+	}
+	if path, _, _ := findBloomFilter(dir); path != "" {
+		t.Fatalf("state bloom not removed after pruning: %s", path)
+	}
+}
+
+// Tests that an interrupted pruning is resumed from the persisted bloom filter,
+// whilst unfinished filters are discarded.
+func TestRecoverPruning(t *testing.T) {
+	dir, db, blocks := newTestChain(t)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	// Simulate an unfinished bloom filter, nothing should be deleted
+	head := blocks[len(blocks)-1]
+	bloom := newStateBloomWithSize(1)
+	if err := extractState(db, head.Root(), bloom, "target"); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractGenesis(db, bloom); err != nil {
+		t.Fatal(err)
+	}
+	name := bloomFilterName(dir, head.Root())
+	if err := bloom.Commit(name+stateBloomFileTempSuffix, name+".tmp2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecoverPruning(dir, db); err != nil {
+		t.Fatalf("failed to recover pruning: %v", err)
+	}
+	if _, err := os.Stat(name + stateBloomFileTempSuffix); !os.IsNotExist(err) {
+		t.Fatalf("unfinished state bloom not removed: %v", err)
+	}
+	if !hasState(db, blocks[0].Root()) {
+		t.Fatalf("state pruned without a finished bloom")
+	}
+	// Persist the bloom filter as if the pruning crashed and resume it
+	if err := bloom.Commit(name, name+stateBloomFileTempSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecoverPruning(dir, db); err != nil {
+		t.Fatalf("failed to recover pruning: %v", err)
+	}
+	if !hasState(db, head.Root()) {
+		t.Fatalf("head state pruned")
+	}
+	if hasState(db, blocks[0].Root()) {
+		t.Fatalf("stale state retained")
+	}
+}
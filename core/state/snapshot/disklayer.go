@@ -0,0 +1,183 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/rlp"
+	"github.com/vsportchain/go-vsc/trie"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// cacheItemSize is the approximate memory footprint of a cached snapshot entry,
+// used to convert the cache allowance into an item count.
+const cacheItemSize = 128
+
+// diskLayer is a low level persistent snapshot built on top of a key-value store.
+type diskLayer struct {
+	diskdb ethdb.Database // Key-value store containing the base snapshot
+	triedb *trie.Database // Trie node cache for reconstuction purposes
+	cache  *lru.Cache     // Cache to avoid hitting the disk for direct access
+
+	root  common.Hash // Root hash of the base snapshot
+	stale bool        // Signals that the layer became stale (state progressed)
+
+	genMarker  []byte                    // Marker for the state that's indexed during initial layer generation
+	genPending chan struct{}             // Notification channel when generation is done (test synchronicity)
+	genAbort   chan chan *generatorStats // Notification channel to abort generating the snapshot in this layer
+
+	lock sync.RWMutex
+}
+
+// newDiskCache creates the clean entry cache of a disk layer with the given
+// allowance in megabytes.
+func newDiskCache(size int) *lru.Cache {
+	items := size * 1024 * 1024 / cacheItemSize
+	if items < 1 {
+		items = 1
+	}
+	cache, _ := lru.New(items)
+	return cache
+}
+
+// storageCacheKey is the key of a storage slot in the clean cache. Accounts are
+// cached by their hash directly.
+func storageCacheKey(accountHash, storageHash common.Hash) string {
+	return string(append(accountHash[:], storageHash[:]...))
+}
+
+// Root returns root hash for which this snapshot was made.
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent always returns nil as there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot {
+	return nil
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+// Account directly retrieves the account associated with a particular hash in
+// the snapshot.
+func (dl *diskLayer) Account(hash common.Hash) (*Account, error) {
+	data, err := dl.AccountRLP(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 { // can be both nil and []byte{}
+		return nil, nil
+	}
+	account := new(Account)
+	if err := rlp.DecodeBytes(data, account); err != nil {
+		panic(err)
+	}
+	return account, nil
+}
+
+// AccountRLP directly retrieves the account RLP associated with a particular
+// hash in the snapshot.
+func (dl *diskLayer) AccountRLP(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	// If the layer was flattened into, consider it invalid (any live reference to
+	// the original should be marked as unusable).
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	// If the layer is being generated, ensure the requested hash has already been
+	// covered by the generator.
+	if dl.genMarker != nil && bytes.Compare(hash[:], dl.genMarker) > 0 {
+		return nil, ErrNotCoveredYet
+	}
+	// Try to retrieve the account from the memory cache
+	if blob, found := dl.cache.Get(hash); found {
+		snapshotCleanAccountHitMeter.Mark(1)
+		return blob.([]byte), nil
+	}
+	// Cache doesn't contain account, pull from disk and cache for later
+	blob := rawdb.ReadAccountSnapshot(dl.diskdb, hash)
+	dl.cache.Add(hash, blob)
+
+	snapshotCleanAccountMissMeter.Mark(1)
+	return blob, nil
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	// If the layer was flattened into, consider it invalid (any live reference to
+	// the original should be marked as unusable).
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	// If the layer is being generated, ensure the requested hash has already been
+	// covered by the generator.
+	if dl.genMarker != nil && bytes.Compare(accountHash[:], dl.genMarker) > 0 {
+		return nil, ErrNotCoveredYet
+	}
+	// Try to retrieve the storage slot from the memory cache
+	key := storageCacheKey(accountHash, storageHash)
+	if blob, found := dl.cache.Get(key); found {
+		snapshotCleanStorageHitMeter.Mark(1)
+		return blob.([]byte), nil
+	}
+	// Cache doesn't contain storage slot, pull from disk and cache for later
+	blob := rawdb.ReadStorageSnapshot(dl.diskdb, accountHash, storageHash)
+	dl.cache.Add(key, blob)
+
+	snapshotCleanStorageMissMeter.Mark(1)
+	return blob, nil
+}
+
+// StorageIterator creates an iterator over the storage slots of an account,
+// starting at a specified slot hash.
+func (dl *diskLayer) StorageIterator(accountHash, seek common.Hash) (StorageIterator, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	if dl.genMarker != nil && bytes.Compare(accountHash[:], dl.genMarker) > 0 {
+		return nil, ErrNotCoveredYet
+	}
+	return newDiskStorageIterator(dl.diskdb, accountHash, seek), nil
+}
+
+// Update creates a new layer on top of the existing snapshot diff tree with
+// the specified data items. Note, the maps are retained by the method to avoid
+// copying everything.
+func (dl *diskLayer) Update(blockHash common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	return newDiffLayer(dl, blockHash, destructs, accounts, storage)
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/rlp"
+	"github.com/vsportchain/go-vsc/trie"
+)
+
+// Extend adds the snapshot of a state on top of the snapshot of its parent state,
+// deriving the accounts and storage slots that changed in between by diffing
+// the two state tries. This links up states which were imported without their
+// parent having a snapshot (e.g. a side chain becoming canonical in a reorg),
+// as long as both tries are still available.
+func (t *Tree) Extend(root common.Hash, parentRoot common.Hash) error {
+	if root == parentRoot {
+		return errSnapshotCycle
+	}
+	if t.Snapshot(parentRoot) == nil {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	destructs, accounts, storage, err := diffState(t.triedb, parentRoot, root)
+	if err != nil {
+		return err
+	}
+	return t.Update(root, parentRoot, destructs, accounts, storage)
+}
+
+// diffState collects the accounts deleted, the accounts created or modified and
+// the storage slots changed between two state tries, in the format expected by
+// the snapshot diff layers.
+func diffState(triedb *trie.Database, parentRoot, root common.Hash) (map[common.Hash]struct{}, map[common.Hash][]byte, map[common.Hash]map[common.Hash][]byte, error) {
+	parent, err := trie.New(parentRoot, triedb)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	child, err := trie.New(root, triedb)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		destructs = make(map[common.Hash]struct{})
+		accounts  = make(map[common.Hash][]byte)
+		storage   = make(map[common.Hash]map[common.Hash][]byte)
+	)
+	// Gather all the accounts which are new or modified in the child state,
+	// along with the storage slots which changed in them
+	diff, _ := trie.NewDifferenceIterator(parent.NodeIterator(nil), child.NodeIterator(nil))
+	it := trie.NewIterator(diff)
+	for it.Next() {
+		hash := common.BytesToHash(it.Key)
+		accounts[hash] = common.CopyBytes(it.Value)
+
+		var account Account
+		if err := rlp.DecodeBytes(it.Value, &account); err != nil {
+			return nil, nil, nil, err
+		}
+		prevRoot := emptyRoot
+		if blob, err := parent.TryGet(it.Key); err != nil {
+			return nil, nil, nil, err
+		} else if len(blob) > 0 {
+			var prev Account
+			if err := rlp.DecodeBytes(blob, &prev); err != nil {
+				return nil, nil, nil, err
+			}
+			prevRoot = prev.Root
+		}
+		if account.Root == prevRoot {
+			continue
+		}
+		slots, err := diffStorage(triedb, prevRoot, account.Root)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(slots) > 0 {
+			storage[hash] = slots
+		}
+	}
+	if it.Err != nil {
+		return nil, nil, nil, it.Err
+	}
+	// Gather all the accounts which were deleted from the parent state
+	diff, _ = trie.NewDifferenceIterator(child.NodeIterator(nil), parent.NodeIterator(nil))
+	it = trie.NewIterator(diff)
+	for it.Next() {
+		if _, ok := accounts[common.BytesToHash(it.Key)]; !ok {
+			destructs[common.BytesToHash(it.Key)] = struct{}{}
+		}
+	}
+	if it.Err != nil {
+		return nil, nil, nil, it.Err
+	}
+	return destructs, accounts, storage, nil
+}
+
+// diffStorage collects the storage slots changed between two storage tries,
+// deleted slots having a nil value.
+func diffStorage(triedb *trie.Database, parentRoot, root common.Hash) (map[common.Hash][]byte, error) {
+	parent, err := trie.New(parentRoot, triedb)
+	if err != nil {
+		return nil, err
+	}
+	child, err := trie.New(root, triedb)
+	if err != nil {
+		return nil, err
+	}
+	slots := make(map[common.Hash][]byte)
+
+	diff, _ := trie.NewDifferenceIterator(parent.NodeIterator(nil), child.NodeIterator(nil))
+	it := trie.NewIterator(diff)
+	for it.Next() {
+		slots[common.BytesToHash(it.Key)] = common.CopyBytes(it.Value)
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	diff, _ = trie.NewDifferenceIterator(child.NodeIterator(nil), parent.NodeIterator(nil))
+	it = trie.NewIterator(diff)
+	for it.Next() {
+		if _, ok := slots[common.BytesToHash(it.Key)]; !ok {
+			slots[common.BytesToHash(it.Key)] = nil
+		}
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	return slots, nil
+}
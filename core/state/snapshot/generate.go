@@ -0,0 +1,318 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/rlp"
+	"github.com/vsportchain/go-vsc/trie"
+)
+
+// generatorStats is a collection of statistics gathered by the snapshot generator
+// for logging purposes.
+type generatorStats struct {
+	origin   uint64             // Origin prefix where generation started
+	start    time.Time          // Timestamp when generation started
+	accounts uint64             // Number of accounts indexed
+	slots    uint64             // Number of storage slots indexed
+	storage  common.StorageSize // Account and storage slot size
+}
+
+// Log creates an contextual log with the given message and the context pulled
+// from the internally maintained statistics.
+func (gs *generatorStats) Log(msg string, root common.Hash, marker []byte) {
+	var ctx []interface{}
+	if root != (common.Hash{}) {
+		ctx = append(ctx, []interface{}{"root", root}...)
+	}
+	// Figure out whether we're after or within an account
+	if len(marker) > 0 {
+		ctx = append(ctx, []interface{}{"at", common.BytesToHash(marker)}...)
+	}
+	// Add the usual measurements
+	ctx = append(ctx, []interface{}{
+		"accounts", gs.accounts,
+		"slots", gs.slots,
+		"storage", gs.storage,
+		"elapsed", common.PrettyDuration(time.Since(gs.start)),
+	}...)
+	// Calculate the estimated indexing time based on current stats
+	if len(marker) > 0 {
+		if done := binary.BigEndian.Uint64(marker[:8]) - gs.origin; done > 0 {
+			left := ^uint64(0) - binary.BigEndian.Uint64(marker[:8])
+
+			speed := done/uint64(time.Since(gs.start)/time.Millisecond+1) + 1 // +1 to avoid division by zero
+			ctx = append(ctx, []interface{}{
+				"eta", common.PrettyDuration(time.Duration(left/speed) * time.Millisecond),
+			}...)
+		}
+	}
+	log.Info(msg, ctx...)
+}
+
+// generateSnapshot regenerates a brand new snapshot based on an existing state
+// database and head block asynchronously. The snapshot is returned immediately
+// and generation is continued in the background until done.
+func generateSnapshot(diskdb ethdb.Database, triedb *trie.Database, cache int, root common.Hash) *diskLayer {
+	// Create a new disk layer with an initialized state marker at zero
+	var (
+		stats     = &generatorStats{start: time.Now()}
+		batch     = diskdb.NewBatch()
+		genMarker = []byte{} // Initialized but empty!
+	)
+	rawdb.WriteSnapshotRoot(batch, root)
+	journalProgress(batch, genMarker, stats)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write initialized state marker", "err", err)
+	}
+	base := &diskLayer{
+		diskdb:     diskdb,
+		triedb:     triedb,
+		root:       root,
+		cache:      newDiskCache(cache),
+		genMarker:  genMarker,
+		genPending: make(chan struct{}),
+		genAbort:   make(chan chan *generatorStats),
+	}
+	go base.generate(stats)
+	log.Debug("Start snapshot generation", "root", root)
+	return base
+}
+
+// journalProgress persists the generator stats into the database to resume later.
+func journalProgress(db ethdb.Putter, marker []byte, stats *generatorStats) {
+	// Write out the generator marker. Note it's a standalone disk layer generator
+	// which is not mixed with journal. It's ok if the generator is persisted while
+	// journal is not.
+	entry := journalGenerator{
+		Done:   marker == nil,
+		Marker: marker,
+	}
+	if stats != nil {
+		entry.Accounts = stats.accounts
+		entry.Slots = stats.slots
+		entry.Storage = uint64(stats.storage)
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	rawdb.WriteSnapshotGenerator(db, blob)
+}
+
+// nextHash returns the hash following the given marker in lexicographic order,
+// or nil if there's none (the marker is the last possible hash). An empty marker
+// is followed by the zero hash.
+func nextHash(marker []byte) []byte {
+	if len(marker) == 0 {
+		return make([]byte, common.HashLength)
+	}
+	next := new(big.Int).Add(new(big.Int).SetBytes(marker), common.Big1)
+	if next.BitLen() > 8*common.HashLength {
+		return nil
+	}
+	return common.LeftPadBytes(next.Bytes(), common.HashLength)
+}
+
+// wipe deletes all the snapshot entries of the accounts not yet covered by the
+// generator marker, which may be leftovers of an earlier snapshot or of partially
+// generated accounts. The method returns false if it was aborted midway.
+func (dl *diskLayer) wipe(marker []byte, abort func() bool) bool {
+	start := nextHash(marker)
+	if start == nil {
+		return true // Everything is covered, nothing to wipe
+	}
+	batch := dl.diskdb.NewBatch()
+	for _, prefix := range [][]byte{rawdb.SnapshotAccountPrefix, rawdb.SnapshotStoragePrefix} {
+		it := dl.diskdb.NewIterator(prefix, start)
+		for it.Next() {
+			// Skip any keys which are not snapshot entries with a colliding prefix
+			key := it.Key()
+			if len(key) != len(prefix)+common.HashLength && len(key) != len(prefix)+2*common.HashLength {
+				continue
+			}
+			batch.Delete(common.CopyBytes(key))
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed to wipe stale snapshot data", "err", err)
+				}
+				batch.Reset()
+
+				if abort() {
+					it.Release()
+					return false
+				}
+			}
+		}
+		it.Release()
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to wipe stale snapshot data", "err", err)
+	}
+	return true
+}
+
+// generate is a background thread that iterates over the state and storage tries,
+// constructing the state snapshot. All the arguments are purely for statistics
+// gathering and logging, since the method surfs the blocks as they arrive, often
+// being restarted.
+func (dl *diskLayer) generate(stats *generatorStats) {
+	dl.lock.RLock()
+	marker := dl.genMarker
+	dl.lock.RUnlock()
+
+	if stats == nil {
+		stats = &generatorStats{start: time.Now()}
+	}
+	if len(marker) > 0 {
+		stats.origin = binary.BigEndian.Uint64(marker[:8])
+	}
+	var (
+		batch  = dl.diskdb.NewBatch()
+		logged = time.Now()
+		aborts chan *generatorStats
+	)
+	// checkAbort polls the abort channel without blocking, remembering any
+	// pending abort request for the caller to answer.
+	checkAbort := func() bool {
+		select {
+		case aborts = <-dl.genAbort:
+			return true
+		default:
+			return false
+		}
+	}
+	// flush persists the accumulated batch along with the progress marker, and
+	// exposes the generated data to readers.
+	flush := func(marker []byte) {
+		journalProgress(batch, marker, stats)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write generated snapshot", "err", err)
+		}
+		batch.Reset()
+
+		dl.lock.Lock()
+		dl.genMarker = marker
+		dl.lock.Unlock()
+	}
+	// stop blocks until someone requests an abort (if not already requested) and
+	// answers it with the current statistics.
+	stop := func() {
+		if aborts == nil {
+			aborts = <-dl.genAbort
+		}
+		aborts <- stats
+	}
+	// Drop any leftover data beyond the marker before starting to generate
+	if !dl.wipe(marker, checkAbort) {
+		stop()
+		return
+	}
+	accTrie, err := trie.NewSecure(dl.root, dl.triedb, 0)
+	if err != nil {
+		// The account trie is missing (GC), surf the chain until one becomes available
+		stats.Log("Trie missing, state snapshotting paused", dl.root, marker)
+		stop()
+		return
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(nextHash(marker)))
+	for accIt.Next() {
+		var (
+			accountHash = common.BytesToHash(accIt.Key)
+			account     Account
+		)
+		if err := rlp.DecodeBytes(accIt.Value, &account); err != nil {
+			log.Crit("Invalid account encountered during snapshot creation", "err", err)
+		}
+		rawdb.WriteAccountSnapshot(batch, accountHash, accIt.Value)
+		stats.storage += common.StorageSize(1 + common.HashLength + len(accIt.Value))
+		stats.accounts++
+
+		// If the account has storage slots, iterate over them too
+		if account.Root != emptyRoot && account.Root != (common.Hash{}) {
+			storeTrie, err := trie.NewSecure(account.Root, dl.triedb, 0)
+			if err != nil {
+				stats.Log("Storage trie missing, state snapshotting paused", dl.root, marker)
+				stop()
+				return
+			}
+			storeIt := trie.NewIterator(storeTrie.NodeIterator(nil))
+			for storeIt.Next() {
+				rawdb.WriteStorageSnapshot(batch, accountHash, common.BytesToHash(storeIt.Key), storeIt.Value)
+				stats.storage += common.StorageSize(1 + 2*common.HashLength + len(storeIt.Value))
+				stats.slots++
+
+				// Flush large accounts midway, without moving the marker past them
+				if batch.ValueSize() > ethdb.IdealBatchSize {
+					flush(marker)
+					if checkAbort() {
+						stats.Log("Aborting state snapshot generation", dl.root, marker)
+						stop()
+						return
+					}
+				}
+			}
+			if storeIt.Err != nil {
+				stats.Log("Storage iteration failed, state snapshotting paused", dl.root, marker)
+				stop()
+				return
+			}
+		}
+		// The account is fully generated, move the marker past it if needed
+		marker = accountHash[:]
+
+		if aborted := checkAbort(); aborted || batch.ValueSize() > ethdb.IdealBatchSize {
+			flush(marker)
+			if aborted {
+				stats.Log("Aborting state snapshot generation", dl.root, marker)
+				stop()
+				return
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			stats.Log("Generating state snapshot", dl.root, marker)
+			logged = time.Now()
+		}
+	}
+	if accIt.Err != nil {
+		flush(marker)
+		stats.Log("Account iteration failed, state snapshotting paused", dl.root, marker)
+		stop()
+		return
+	}
+	// Snapshot fully generated, set the marker to nil
+	flush(nil)
+	log.Info("Generated state snapshot", "accounts", stats.accounts, "slots", stats.slots,
+		"storage", stats.storage, "elapsed", common.PrettyDuration(time.Since(stats.start)))
+
+	dl.lock.Lock()
+	close(dl.genPending)
+	dl.lock.Unlock()
+
+	// Someone will be looking for us, wait it out
+	if aborts == nil {
+		aborts = <-dl.genAbort
+	}
+	aborts <- nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/ethdb"
+)
+
+// StorageIterator is an iterator to step over the storage slots of a single
+// account in a snapshot, in ascending slot hash order. Deleted slots are never
+// returned.
+type StorageIterator interface {
+	// Next steps the iterator forward one element, returning false if exhausted,
+	// or an error if iteration failed for some reason (e.g. root being iterated
+	// becomes stale and garbage collected).
+	Next() bool
+
+	// Error returns any failure that occurred during iteration, which might have
+	// caused a premature iteration exit (e.g. snapshot stack becoming stale).
+	Error() error
+
+	// Hash returns the hash of the storage slot the iterator is currently at.
+	Hash() common.Hash
+
+	// Slot returns the storage slot the iterator is currently at, encoded the
+	// same way as in the storage trie.
+	Slot() []byte
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
+}
+
+// diskStorageIterator is a storage iterator that steps over the live storage
+// slots contained within a disk layer.
+type diskStorageIterator struct {
+	account common.Hash
+	prefix  int
+	it      ethdb.Iterator
+}
+
+// newDiskStorageIterator creates an iterator over the storage slots of a single
+// account persisted in the database, starting at the given slot hash.
+func newDiskStorageIterator(db ethdb.Database, account common.Hash, seek common.Hash) *diskStorageIterator {
+	prefix := rawdb.StorageSnapshotsKey(account)
+	return &diskStorageIterator{
+		account: account,
+		prefix:  len(prefix),
+		it:      db.NewIterator(prefix, seek[:]),
+	}
+}
+
+// Next steps the iterator forward one element, returning false if exhausted.
+func (it *diskStorageIterator) Next() bool {
+	// If the iterator was already exhausted or released, don't bother
+	if it.it == nil {
+		return false
+	}
+	ok := it.it.Next()
+	for ok && len(it.it.Key()) != it.prefix+common.HashLength {
+		ok = it.it.Next() // Skip any entries not matching the storage key layout
+	}
+	if !ok {
+		it.Release()
+	}
+	return ok
+}
+
+// Error returns any failure that occurred during iteration, which might have
+// caused a premature iteration exit.
+func (it *diskStorageIterator) Error() error {
+	if it.it == nil {
+		return nil // Iterator is exhausted and released
+	}
+	return it.it.Error()
+}
+
+// Hash returns the hash of the storage slot the iterator is currently at.
+func (it *diskStorageIterator) Hash() common.Hash {
+	return common.BytesToHash(it.it.Key()[it.prefix:])
+}
+
+// Slot returns the raw storage slot value the iterator is currently at.
+func (it *diskStorageIterator) Slot() []byte {
+	return it.it.Value()
+}
+
+// Release releases the database snapshot held during iteration.
+func (it *diskStorageIterator) Release() {
+	// The iterator is auto-released on exhaustion, so make sure it's still alive
+	if it.it != nil {
+		it.it.Release()
+		it.it = nil
+	}
+}
+
+// diffStorageIterator is a storage iterator that steps over the slots modified
+// in a diff layer, merged with the slots of the parent layers. Slots modified in
+// the diff layer shadow the same slots of the parents.
+type diffStorageIterator struct {
+	keys  []common.Hash // Sorted slot hashes modified in the diff layer
+	slots [][]byte      // Slot values modified in the diff layer (nil means deleted)
+
+	parent   StorageIterator // Iterator of the parent layers, nil if none
+	parentOk bool            // Whether the parent is positioned on an unconsumed slot
+	started  bool            // Whether the parent was already stepped into
+
+	curHash common.Hash // Hash of the slot the iterator is currently at
+	curSlot []byte      // Value of the slot the iterator is currently at
+	err     error       // Any failure encountered by the parent iterator
+}
+
+// newDiffStorageIterator creates a storage iterator merging the sorted slots of
+// a diff layer with an iterator over its parent.
+func newDiffStorageIterator(keys []common.Hash, slots [][]byte, parent StorageIterator) *diffStorageIterator {
+	return &diffStorageIterator{
+		keys:   keys,
+		slots:  slots,
+		parent: parent,
+	}
+}
+
+// Next steps the iterator forward one element, returning false if exhausted.
+func (it *diffStorageIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		it.parentOk = it.parent != nil && it.parent.Next()
+	}
+	for {
+		// If both the local and parent slots are exhausted, stop
+		if len(it.keys) == 0 && !it.parentOk {
+			if it.parent != nil {
+				it.err = it.parent.Error()
+				it.parent.Release()
+				it.parent = nil
+			}
+			return false
+		}
+		// If the next parent slot precedes the local one, return it
+		if it.parentOk && (len(it.keys) == 0 || bytes.Compare(it.parent.Hash().Bytes(), it.keys[0][:]) < 0) {
+			it.curHash, it.curSlot = it.parent.Hash(), common.CopyBytes(it.parent.Slot())
+			it.parentOk = it.parent.Next()
+			return true
+		}
+		// Local slot is next, skip the same slot of the parent as it's shadowed
+		if it.parentOk && it.parent.Hash() == it.keys[0] {
+			it.parentOk = it.parent.Next()
+		}
+		it.curHash, it.curSlot = it.keys[0], it.slots[0]
+		it.keys, it.slots = it.keys[1:], it.slots[1:]
+
+		// Deleted slots are only needed to shadow the parent, don't return them
+		if len(it.curSlot) > 0 {
+			return true
+		}
+	}
+}
+
+// Error returns any failure that occurred during iteration, which might have
+// caused a premature iteration exit.
+func (it *diffStorageIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	if it.parent != nil {
+		return it.parent.Error()
+	}
+	return nil
+}
+
+// Hash returns the hash of the storage slot the iterator is currently at.
+func (it *diffStorageIterator) Hash() common.Hash {
+	return it.curHash
+}
+
+// Slot returns the raw storage slot value the iterator is currently at.
+func (it *diffStorageIterator) Slot() []byte {
+	return it.curSlot
+}
+
+// Release releases any resources held by the parent iterators.
+func (it *diffStorageIterator) Release() {
+	if it.parent != nil {
+		it.parent.Release()
+		it.parent = nil
+	}
+	it.parentOk = false
+	it.keys, it.slots = nil, nil
+}
@@ -0,0 +1,347 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/rlp"
+	"github.com/vsportchain/go-vsc/trie"
+)
+
+// testHash derives a deterministic hash from a seed for the tests.
+func testHash(seed byte) common.Hash {
+	return crypto.Keccak256Hash([]byte{seed})
+}
+
+// testAccount creates the RLP encoding of an account with the given balance and
+// storage root.
+func testAccount(balance int64, root common.Hash) []byte {
+	blob, _ := rlp.EncodeToBytes(Account{Balance: big.NewInt(balance), Root: root, CodeHash: crypto.Keccak256(nil)})
+	return blob
+}
+
+// testSlot creates the RLP encoding of a storage slot value.
+func testSlot(value byte) []byte {
+	blob, _ := rlp.EncodeToBytes([]byte{value})
+	return blob
+}
+
+// newTestState creates a state trie with two accounts, the second of which has
+// a few storage slots, returning the root of the state.
+func newTestState(t *testing.T, triedb *trie.Database) common.Hash {
+	storage, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	for i := byte(1); i <= 3; i++ {
+		storage.Update([]byte{i}, testSlot(i))
+	}
+	storageRoot, err := storage.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit storage trie: %v", err)
+	}
+	accounts, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	accounts.Update([]byte{0x01}, testAccount(1, emptyRoot))
+	accounts.Update([]byte{0x02}, testAccount(2, storageRoot))
+
+	root, err := accounts.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	return root
+}
+
+// newTestTree creates a snapshot tree on top of a freshly generated state and
+// waits for the background generation to finish.
+func newTestTree(t *testing.T) (*Tree, ethdb.Database, common.Hash) {
+	var (
+		diskdb = ethdb.NewMemDatabase()
+		triedb = trie.NewDatabase(diskdb)
+		root   = newTestState(t, triedb)
+	)
+	snaps, err := New(diskdb, triedb, 16, root)
+	if err != nil {
+		t.Fatalf("failed to create snapshot tree: %v", err)
+	}
+	<-snaps.layers[root].(*diskLayer).genPending
+	return snaps, diskdb, root
+}
+
+// Tests that the snapshot generator produces the same accounts and storage
+// slots as the tries it's generated from.
+func TestGeneration(t *testing.T) {
+	snaps, _, root := newTestTree(t)
+
+	snap := snaps.Snapshot(root)
+	if snap == nil {
+		t.Fatalf("snapshot missing for root %x", root)
+	}
+	account, err := snap.Account(crypto.Keccak256Hash([]byte{0x01}))
+	if err != nil {
+		t.Fatalf("failed to retrieve account: %v", err)
+	}
+	if account == nil || account.Balance.Int64() != 1 {
+		t.Fatalf("account mismatch: have %v, want balance 1", account)
+	}
+	if account, _ := snap.Account(crypto.Keccak256Hash([]byte{0x03})); account != nil {
+		t.Fatalf("non-existent account retrieved: %v", account)
+	}
+	contract := crypto.Keccak256Hash([]byte{0x02})
+	for i := byte(1); i <= 3; i++ {
+		slot, err := snap.Storage(contract, crypto.Keccak256Hash([]byte{i}))
+		if err != nil {
+			t.Fatalf("failed to retrieve slot %d: %v", i, err)
+		}
+		if !bytes.Equal(slot, testSlot(i)) {
+			t.Errorf("slot %d mismatch: have %x, want %x", i, slot, testSlot(i))
+		}
+	}
+	// Iterate over the storage and ensure it's sorted and complete
+	it, err := snap.StorageIterator(contract, common.Hash{})
+	if err != nil {
+		t.Fatalf("failed to create storage iterator: %v", err)
+	}
+	defer it.Release()
+
+	var prev common.Hash
+	count := 0
+	for it.Next() {
+		if count > 0 && bytes.Compare(prev[:], it.Hash().Bytes()) >= 0 {
+			t.Errorf("slots out of order: %x >= %x", prev, it.Hash())
+		}
+		prev = it.Hash()
+		count++
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("storage iteration failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("iterated slot count mismatch: have %d, want 3", count)
+	}
+}
+
+// Tests that diff layers shadow the layers below them, including destructed
+// accounts and deleted storage slots.
+func TestDiffLayerLookups(t *testing.T) {
+	snaps, _, root := newTestTree(t)
+
+	var (
+		plain    = crypto.Keccak256Hash([]byte{0x01})
+		contract = crypto.Keccak256Hash([]byte{0x02})
+	)
+	// Modify the plain account and a slot in the first layer
+	storage := map[common.Hash]map[common.Hash][]byte{
+		contract: {crypto.Keccak256Hash([]byte{0x01}): nil, testHash(0xff): testSlot(0xff)},
+	}
+	if err := snaps.Update(testHash(1), root, nil, map[common.Hash][]byte{plain: testAccount(10, emptyRoot)}, storage); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	// Destruct the contract in the second layer
+	if err := snaps.Update(testHash(2), testHash(1), map[common.Hash]struct{}{contract: {}}, nil, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	first, second := snaps.Snapshot(testHash(1)), snaps.Snapshot(testHash(2))
+
+	if account, _ := second.Account(plain); account == nil || account.Balance.Int64() != 10 {
+		t.Errorf("shadowed account mismatch: have %v, want balance 10", account)
+	}
+	if slot, _ := first.Storage(contract, crypto.Keccak256Hash([]byte{0x01})); slot != nil {
+		t.Errorf("deleted slot retrieved: %x", slot)
+	}
+	if slot, _ := first.Storage(contract, crypto.Keccak256Hash([]byte{0x02})); !bytes.Equal(slot, testSlot(2)) {
+		t.Errorf("parent slot mismatch: have %x, want %x", slot, testSlot(2))
+	}
+	if blob, _ := second.AccountRLP(contract); blob != nil {
+		t.Errorf("destructed account retrieved: %x", blob)
+	}
+	if slot, _ := second.Storage(contract, crypto.Keccak256Hash([]byte{0x02})); slot != nil {
+		t.Errorf("destructed slot retrieved: %x", slot)
+	}
+	// Iterate the storage of the first layer, the deleted slot must be skipped
+	// and the new one merged in
+	it, _ := first.StorageIterator(contract, common.Hash{})
+	defer it.Release()
+
+	slots := make(map[common.Hash][]byte)
+	for it.Next() {
+		slots[it.Hash()] = it.Slot()
+	}
+	if len(slots) != 3 {
+		t.Errorf("iterated slot count mismatch: have %d, want 3", len(slots))
+	}
+	if _, ok := slots[crypto.Keccak256Hash([]byte{0x01})]; ok {
+		t.Errorf("deleted slot iterated")
+	}
+	if !bytes.Equal(slots[testHash(0xff)], testSlot(0xff)) {
+		t.Errorf("new slot mismatch: have %x, want %x", slots[testHash(0xff)], testSlot(0xff))
+	}
+	// The destructed account must not have any storage left
+	it2, _ := second.StorageIterator(contract, common.Hash{})
+	defer it2.Release()
+
+	for it2.Next() {
+		t.Errorf("destructed slot iterated: %x", it2.Hash())
+	}
+}
+
+// Tests that capping the tree flattens the diff layers below the limit into the
+// disk layer, discarding any stale siblings.
+func TestCap(t *testing.T) {
+	snaps, diskdb, root := newTestTree(t)
+
+	var (
+		plain    = crypto.Keccak256Hash([]byte{0x01})
+		contract = crypto.Keccak256Hash([]byte{0x02})
+	)
+	snaps.Update(testHash(1), root, nil, map[common.Hash][]byte{plain: testAccount(10, emptyRoot)}, nil)
+	snaps.Update(testHash(2), testHash(1), map[common.Hash]struct{}{contract: {}}, nil, nil)
+	snaps.Update(testHash(3), testHash(2), nil, map[common.Hash][]byte{plain: testAccount(30, emptyRoot)}, nil)
+	snaps.Update(testHash(4), root, nil, map[common.Hash][]byte{plain: testAccount(40, emptyRoot)}, nil) // sibling
+
+	if err := snaps.Cap(testHash(3), 1); err != nil {
+		t.Fatalf("failed to cap snapshot tree: %v", err)
+	}
+	// Layers 1 and 2 were flattened into the disk, the sibling got discarded
+	if len(snaps.layers) != 2 {
+		t.Fatalf("layer count mismatch: have %d, want 2", len(snaps.layers))
+	}
+	if _, ok := snaps.layers[testHash(2)].(*diskLayer); !ok {
+		t.Fatalf("disk layer mismatch: have %T", snaps.layers[testHash(2)])
+	}
+	if rawdb.ReadSnapshotRoot(diskdb) != testHash(2) {
+		t.Errorf("persisted root mismatch: have %x, want %x", rawdb.ReadSnapshotRoot(diskdb), testHash(2))
+	}
+	if blob := rawdb.ReadAccountSnapshot(diskdb, plain); !bytes.Equal(blob, testAccount(10, emptyRoot)) {
+		t.Errorf("persisted account mismatch: have %x, want %x", blob, testAccount(10, emptyRoot))
+	}
+	if blob := rawdb.ReadAccountSnapshot(diskdb, contract); len(blob) != 0 {
+		t.Errorf("destructed account persisted: %x", blob)
+	}
+	if blob := rawdb.ReadStorageSnapshot(diskdb, contract, crypto.Keccak256Hash([]byte{0x01})); len(blob) != 0 {
+		t.Errorf("destructed slot persisted: %x", blob)
+	}
+	// The head layer must still be usable on top of the new disk layer
+	if account, _ := snaps.Snapshot(testHash(3)).Account(plain); account == nil || account.Balance.Int64() != 30 {
+		t.Errorf("head account mismatch: have %v, want balance 30", account)
+	}
+	// Flattening everything should leave only the disk layer
+	if err := snaps.Cap(testHash(3), 0); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	if len(snaps.layers) != 1 {
+		t.Fatalf("layer count mismatch: have %d, want 1", len(snaps.layers))
+	}
+	if blob := rawdb.ReadAccountSnapshot(diskdb, plain); !bytes.Equal(blob, testAccount(30, emptyRoot)) {
+		t.Errorf("persisted account mismatch: have %x, want %x", blob, testAccount(30, emptyRoot))
+	}
+}
+
+// Tests that the diff layers journalled on shutdown are restored on startup.
+func TestJournal(t *testing.T) {
+	snaps, diskdb, root := newTestTree(t)
+
+	plain := crypto.Keccak256Hash([]byte{0x01})
+	snaps.Update(testHash(1), root, nil, map[common.Hash][]byte{plain: testAccount(10, emptyRoot)}, nil)
+	snaps.Update(testHash(2), testHash(1), nil, map[common.Hash][]byte{plain: testAccount(20, emptyRoot)}, nil)
+
+	base, err := snaps.Journal(testHash(2))
+	if err != nil {
+		t.Fatalf("failed to journal snapshot: %v", err)
+	}
+	if base != root {
+		t.Fatalf("journal base mismatch: have %x, want %x", base, root)
+	}
+	// Reload the snapshot from the journal and check the layers
+	restored, err := New(diskdb, snaps.triedb, 16, testHash(2))
+	if err != nil {
+		t.Fatalf("failed to reload snapshot: %v", err)
+	}
+	if len(restored.layers) != 3 {
+		t.Fatalf("layer count mismatch: have %d, want 3", len(restored.layers))
+	}
+	if _, ok := restored.layers[root].(*diskLayer); !ok {
+		t.Fatalf("disk layer mismatch: have %T", restored.layers[root])
+	}
+	for i, balance := range []int64{10, 20} {
+		if account, _ := restored.Snapshot(testHash(byte(i+1))).Account(plain); account == nil || account.Balance.Int64() != balance {
+			t.Errorf("layer %d: account mismatch: have %v, want balance %d", i+1, account, balance)
+		}
+	}
+	// A mismatching head should trigger a regeneration
+	regenerated, err := New(diskdb, snaps.triedb, 16, root)
+	if err != nil {
+		t.Fatalf("failed to regenerate snapshot: %v", err)
+	}
+	if len(regenerated.layers) != 1 || regenerated.Snapshot(root) == nil {
+		t.Fatalf("regenerated layers mismatch: have %d layers", len(regenerated.layers))
+	}
+	<-regenerated.layers[root].(*diskLayer).genPending
+}
+
+// Tests that a snapshot layer derived from diffing two state tries reflects the
+// accounts and storage slots created, modified and deleted in between.
+func TestExtend(t *testing.T) {
+	snaps, _, root := newTestTree(t)
+
+	// Delete the first account, modify the storage of the second and create a third
+	storage, _ := trie.NewSecure(common.Hash{}, snaps.triedb, 0)
+	storage.Update([]byte{2}, testSlot(9))
+	storage.Update([]byte{3}, testSlot(3))
+	storageRoot, err := storage.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit storage trie: %v", err)
+	}
+	accounts, _ := trie.NewSecure(common.Hash{}, snaps.triedb, 0)
+	accounts.Update([]byte{0x02}, testAccount(2, storageRoot))
+	accounts.Update([]byte{0x03}, testAccount(3, emptyRoot))
+
+	child, err := accounts.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	if err := snaps.Extend(child, root); err != nil {
+		t.Fatalf("failed to extend snapshot tree: %v", err)
+	}
+	snap := snaps.Snapshot(child)
+	if snap == nil {
+		t.Fatalf("snapshot missing for root %x", child)
+	}
+	if account, _ := snap.Account(crypto.Keccak256Hash([]byte{0x01})); account != nil {
+		t.Errorf("deleted account retrieved: %v", account)
+	}
+	if account, _ := snap.Account(crypto.Keccak256Hash([]byte{0x03})); account == nil || account.Balance.Int64() != 3 {
+		t.Errorf("created account mismatch: have %v, want balance 3", account)
+	}
+	contract := crypto.Keccak256Hash([]byte{0x02})
+	for i, want := range [][]byte{nil, testSlot(9), testSlot(3)} {
+		slot, err := snap.Storage(contract, crypto.Keccak256Hash([]byte{byte(i + 1)}))
+		if err != nil {
+			t.Fatalf("failed to retrieve slot %d: %v", i+1, err)
+		}
+		if !bytes.Equal(slot, want) {
+			t.Errorf("slot %d mismatch: have %x, want %x", i+1, slot, want)
+		}
+	}
+	// The parent snapshot must remain intact
+	if account, _ := snaps.Snapshot(root).Account(crypto.Keccak256Hash([]byte{0x01})); account == nil {
+		t.Errorf("parent account missing")
+	}
+}
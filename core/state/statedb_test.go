@@ -40,7 +40,7 @@ import (
 func TestUpdateLeaks(t *testing.T) {
 	// Create an empty state database
 	db := ethdb.NewMemDatabase()
-	state, _ := New(common.Hash{}, NewDatabase(db))
+	state, _ := New(common.Hash{}, NewDatabase(db), nil)
 
 	// Update it with some accounts
 	for i := byte(0); i < 255; i++ {
@@ -68,8 +68,8 @@ func TestIntermediateLeaks(t *testing.T) {
 	// Create two state databases, one transitioning to the final state, the other final from the beginning
 	transDb := ethdb.NewMemDatabase()
 	finalDb := ethdb.NewMemDatabase()
-	transState, _ := New(common.Hash{}, NewDatabase(transDb))
-	finalState, _ := New(common.Hash{}, NewDatabase(finalDb))
+	transState, _ := New(common.Hash{}, NewDatabase(transDb), nil)
+	finalState, _ := New(common.Hash{}, NewDatabase(finalDb), nil)
 
 	modify := func(state *StateDB, addr common.Address, i, tweak byte) {
 		state.SetBalance(addr, big.NewInt(int64(11*i)+int64(tweak)))
@@ -122,7 +122,7 @@ func TestIntermediateLeaks(t *testing.T) {
 // https://github.com/vsportchain/go-vsc/pull/15549.
 func TestCopy(t *testing.T) {
 	// Create a random state test to copy and modify "independently"
-	orig, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	orig, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()), nil)
 
 	for i := byte(0); i < 255; i++ {
 		obj := orig.GetOrNewStateObject(common.BytesToAddress([]byte{i}))
@@ -333,7 +333,7 @@ func (test *snapshotTest) String() string {
 func (test *snapshotTest) run() bool {
 	// Run all actions and create snapshots.
 	var (
-		state, _     = New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+		state, _     = New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()), nil)
 		snapshotRevs = make([]int, len(test.snapshots))
 		sindex       = 0
 	)
@@ -347,7 +347,7 @@ func (test *snapshotTest) run() bool {
 	// Revert all snapshots in reverse order. Each revert must yield a state
 	// that is equivalent to fresh state with all actions up the snapshot applied.
 	for sindex--; sindex >= 0; sindex-- {
-		checkstate, _ := New(common.Hash{}, state.Database())
+		checkstate, _ := New(common.Hash{}, state.Database(), nil)
 		for _, action := range test.actions[:test.snapshots[sindex]] {
 			action.fn(action, checkstate)
 		}
@@ -424,7 +424,7 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/vsportchain/go-vsc/pull/15225#issuecomment-380191512
 func TestCopyOfCopy(t *testing.T) {
-	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()), nil)
 	addr := common.HexToAddress("aaaa")
 	sdb.SetBalance(addr, big.NewInt(42))
 
@@ -435,3 +435,35 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that replacing the storage of an account hides all of its original slots,
+// and that the replacement is carried over to copies.
+func TestSetStorage(t *testing.T) {
+	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()), nil)
+	addr := common.HexToAddress("aaaa")
+	sdb.SetState(addr, common.Hash{1}, common.Hash{1})
+	sdb.SetState(addr, common.Hash{2}, common.Hash{2})
+
+	sdb.SetStorage(addr, map[common.Hash]common.Hash{{2}: {3}})
+	if got := sdb.GetState(addr, common.Hash{1}); got != (common.Hash{}) {
+		t.Errorf("replaced slot still visible: have %x, want %x", got, common.Hash{})
+	}
+	if got := sdb.GetState(addr, common.Hash{2}); got != (common.Hash{3}) {
+		t.Errorf("overridden slot mismatch: have %x, want %x", got, common.Hash{3})
+	}
+	sdb.SetState(addr, common.Hash{4}, common.Hash{4})
+	if got := sdb.Copy().GetState(addr, common.Hash{4}); got != (common.Hash{4}) {
+		t.Errorf("copied slot mismatch: have %x, want %x", got, common.Hash{4})
+	}
+	// Updates on top of the replacement must be revertable and must not leak into
+	// the committed view of the storage
+	snap := sdb.Snapshot()
+	sdb.SetState(addr, common.Hash{2}, common.Hash{5})
+	if got := sdb.GetCommittedState(addr, common.Hash{2}); got != (common.Hash{3}) {
+		t.Errorf("committed slot mismatch: have %x, want %x", got, common.Hash{3})
+	}
+	sdb.RevertToSnapshot(snap)
+	if got := sdb.GetState(addr, common.Hash{2}); got != (common.Hash{3}) {
+		t.Errorf("reverted slot mismatch: have %x, want %x", got, common.Hash{3})
+	}
+}
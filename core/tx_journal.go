@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/core/types"
@@ -40,6 +41,13 @@ type devNull struct{}
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
+// journalPrivateTx is the journal entry of a private transaction, retaining its
+// inclusion deadline. Public transactions are journaled bare.
+type journalPrivateTx struct {
+	Tx       *types.Transaction
+	Deadline uint64
+}
+
 // txJournal is a rotating log of transactions with the aim of storing locally
 // created transactions to allow non-executed ones to survive node restarts.
 type txJournal struct {
@@ -55,8 +63,8 @@ func newTxJournal(path string) *txJournal {
 }
 
 // load parses a transaction journal dump from disk, loading its contents into
-// the specified pool.
-func (journal *txJournal) load(add func([]*types.Transaction) []error) error {
+// the specified pool. Private transactions are loaded via the dedicated method.
+func (journal *txJournal) load(add func([]*types.Transaction) []error, addPrivate func(*types.Transaction, uint64) error) error {
 	// Skip the parsing if the journal file doens't exist at all
 	if _, err := os.Stat(journal.path); os.IsNotExist(err) {
 		return nil
@@ -93,8 +101,8 @@ func (journal *txJournal) load(add func([]*types.Transaction) []error) error {
 	)
 	for {
 		// Parse the next transaction and terminate on error
-		tx := new(types.Transaction)
-		if err = stream.Decode(tx); err != nil {
+		blob, err := stream.Raw()
+		if err != nil {
 			if err != io.EOF {
 				failure = err
 			}
@@ -103,6 +111,29 @@ func (journal *txJournal) load(add func([]*types.Transaction) []error) error {
 			}
 			break
 		}
+		tx := new(types.Transaction)
+		if err = rlp.DecodeBytes(blob, tx); err != nil {
+			// Not a public transaction, import private ones in order with the rest
+			entry := new(journalPrivateTx)
+			if rlp.DecodeBytes(blob, entry) != nil {
+				failure = err
+				if batch.Len() > 0 {
+					loadBatch(batch)
+				}
+				break
+			}
+			total++
+
+			if batch.Len() > 0 {
+				loadBatch(batch)
+				batch = batch[:0]
+			}
+			if err := addPrivate(entry.Tx, entry.Deadline); err != nil {
+				log.Debug("Failed to add journaled private transaction", "err", err)
+				dropped++
+			}
+			continue
+		}
 		// New transaction parsed, queue up for later, import if threnshold is reached
 		total++
 
@@ -116,20 +147,30 @@ func (journal *txJournal) load(add func([]*types.Transaction) []error) error {
 	return failure
 }
 
-// insert adds the specified transaction to the local disk journal.
-func (journal *txJournal) insert(tx *types.Transaction) error {
+// insert adds the specified transaction to the local disk journal. A non-zero
+// deadline marks the transaction private.
+func (journal *txJournal) insert(tx *types.Transaction, deadline uint64) error {
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
-	if err := rlp.Encode(journal.writer, tx); err != nil {
+	if err := journalEncode(journal.writer, tx, deadline); err != nil {
 		return err
 	}
 	return nil
 }
 
+// journalEncode writes a transaction into the journal, wrapping it with its
+// deadline if it's private.
+func journalEncode(w io.Writer, tx *types.Transaction, deadline uint64) error {
+	if deadline == 0 {
+		return rlp.Encode(w, tx)
+	}
+	return rlp.Encode(w, &journalPrivateTx{Tx: tx, Deadline: deadline})
+}
+
 // rotate regenerates the transaction journal based on the current contents of
-// the transaction pool.
-func (journal *txJournal) rotate(all map[common.Address]types.Transactions) error {
+// the transaction pool, retaining the deadlines of the private transactions.
+func (journal *txJournal) rotate(all map[common.Address]types.Transactions, private map[common.Hash]uint64) error {
 	// Close the current journal (if any is open)
 	if journal.writer != nil {
 		if err := journal.writer.Close(); err != nil {
@@ -145,7 +186,7 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 	journaled := 0
 	for _, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			if err = journalEncode(replacement, tx, private[tx.Hash()]); err != nil {
 				replacement.Close()
 				return err
 			}
@@ -178,3 +219,61 @@ func (journal *txJournal) close() error {
 	}
 	return err
 }
+
+// remoteJournalTx is the journal entry of a remote transaction, retaining the
+// time it arrived into the pool.
+type remoteJournalTx struct {
+	Tx      *types.Transaction
+	Arrival uint64 // Unix timestamp in nanoseconds
+}
+
+// saveRemoteJournal writes a snapshot of the remote pool entries to disk,
+// replacing any previous snapshot.
+func saveRemoteJournal(path string, entries []*TxPoolEntry) error {
+	output, err := os.OpenFile(path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = rlp.Encode(output, &remoteJournalTx{Tx: entry.Tx, Arrival: uint64(entry.Arrival.UnixNano())}); err != nil {
+			output.Close()
+			return err
+		}
+	}
+	if err = output.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".new", path)
+}
+
+// loadRemoteJournal parses a remote transaction snapshot from disk. Only the
+// transactions and their arrival times are filled into the returned entries.
+func loadRemoteJournal(path string) ([]*TxPoolEntry, error) {
+	// Skip the parsing if the journal file doesn't exist at all
+	input, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	var (
+		stream  = rlp.NewStream(input, 0)
+		entries []*TxPoolEntry
+	)
+	for {
+		entry := new(remoteJournalTx)
+		if err = stream.Decode(entry); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return entries, err
+		}
+		entries = append(entries, &TxPoolEntry{
+			Tx:      entry.Tx,
+			Arrival: time.Unix(0, int64(entry.Arrival)),
+		})
+	}
+}
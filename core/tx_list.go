@@ -245,20 +245,15 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 
 // Add tries to insert a new transaction into the list, returning whether the
 // transaction was accepted, and if yes, any previous transaction it replaced.
+// The pool policy decides whether an existing transaction may be replaced.
 //
 // If the new transaction is accepted into the list, the lists' cost and gas
 // thresholds are also potentially updated.
-func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
+func (l *txList) Add(tx *types.Transaction, policy TxPoolPolicy) (bool, *types.Transaction) {
 	// If there's an older better transaction, abort
 	old := l.txs.Get(tx.Nonce())
-	if old != nil {
-		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
-		// Have to ensure that the new gas price is higher than the old gas
-		// price as well as checking the percentage threshold to ensure that
-		// this is accurate for low (Wei-level) gas price replacements
-		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
-			return false, nil
-		}
+	if old != nil && !policy.Replace(old, tx) {
+		return false, nil
 	}
 	// Otherwise overwrite the old transaction with the current one
 	l.txs.Put(tx)
@@ -363,56 +358,53 @@ func (l *txList) Flatten() types.Transactions {
 	return l.txs.Flatten()
 }
 
-// priceHeap is a heap.Interface implementation over transactions for retrieving
-// price-sorted transactions to discard when the pool fills up.
-type priceHeap []*types.Transaction
-
-func (h priceHeap) Len() int      { return len(h) }
-func (h priceHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
-
-func (h priceHeap) Less(i, j int) bool {
-	// Sort primarily by price, returning the cheaper one
-	switch h[i].GasPrice().Cmp(h[j].GasPrice()) {
-	case -1:
-		return true
-	case 1:
-		return false
-	}
-	// If the prices match, stabilize via nonces (high nonce is worse)
-	return h[i].Nonce() > h[j].Nonce()
+// priceHeap is a heap.Interface implementation over pool entries for retrieving
+// transactions to discard when the pool fills up, in the eviction order defined
+// by the pool policy.
+type priceHeap struct {
+	policy  TxPoolPolicy
+	entries []*TxPoolEntry
 }
 
+func (h *priceHeap) Len() int           { return len(h.entries) }
+func (h *priceHeap) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *priceHeap) Less(i, j int) bool { return h.policy.Less(h.entries[i], h.entries[j]) }
+
 func (h *priceHeap) Push(x interface{}) {
-	*h = append(*h, x.(*types.Transaction))
+	h.entries = append(h.entries, x.(*TxPoolEntry))
 }
 
 func (h *priceHeap) Pop() interface{} {
-	old := *h
+	old := h.entries
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.entries = old[0 : n-1]
 	return x
 }
 
-// txPricedList is a price-sorted heap to allow operating on transactions pool
-// contents in a price-incrementing way.
+// txPricedList is a heap of the pooled transactions sorted in the eviction order
+// of the pool policy, to allow operating on the pool contents starting with the
+// least valuable transactions.
 type txPricedList struct {
-	all    *txLookup  // Pointer to the map of all transactions
-	items  *priceHeap // Heap of prices of all the stored transactions
-	stales int        // Number of stale price points to (re-heap trigger)
+	all     *txLookup                    // Pointer to the map of all transactions
+	entries map[common.Hash]*TxPoolEntry // Policy metadata of the tracked transactions
+	items   *priceHeap                   // Heap of all the stored transactions
+	stales  int                          // Number of stale price points to (re-heap trigger)
 }
 
-// newTxPricedList creates a new price-sorted transaction heap.
-func newTxPricedList(all *txLookup) *txPricedList {
+// newTxPricedList creates a new policy-sorted transaction heap.
+func newTxPricedList(all *txLookup, policy TxPoolPolicy) *txPricedList {
 	return &txPricedList{
-		all:   all,
-		items: new(priceHeap),
+		all:     all,
+		entries: make(map[common.Hash]*TxPoolEntry),
+		items:   &priceHeap{policy: policy},
 	}
 }
 
 // Put inserts a new transaction into the heap.
-func (l *txPricedList) Put(tx *types.Transaction) {
-	heap.Push(l.items, tx)
+func (l *txPricedList) Put(entry *TxPoolEntry) {
+	l.entries[entry.Tx.Hash()] = entry
+	heap.Push(l.items, entry)
 }
 
 // Removed notifies the prices transaction list that an old transaction dropped
@@ -421,100 +413,112 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 func (l *txPricedList) Removed() {
 	// Bump the stale counter, but exit if still too low (< 25%)
 	l.stales++
-	if l.stales <= len(*l.items)/4 {
+	if l.stales <= l.items.Len()/4 {
 		return
 	}
 	// Seems we've reached a critical number of stale transactions, reheap
-	reheap := make(priceHeap, 0, l.all.Count())
+	l.reheap(l.items.policy)
+}
+
+// reheap drops all the stale transactions from the heap and rebuilds it in the
+// order defined by the given policy.
+func (l *txPricedList) reheap(policy TxPoolPolicy) {
+	entries := make(map[common.Hash]*TxPoolEntry, l.all.Count())
+	reheap := &priceHeap{policy: policy, entries: make([]*TxPoolEntry, 0, l.all.Count())}
 
-	l.stales, l.items = 0, &reheap
 	l.all.Range(func(hash common.Hash, tx *types.Transaction) bool {
-		*l.items = append(*l.items, tx)
+		if entry := l.entries[hash]; entry != nil {
+			entries[hash] = entry
+			reheap.entries = append(reheap.entries, entry)
+		}
 		return true
 	})
+	l.stales, l.entries, l.items = 0, entries, reheap
 	heap.Init(l.items)
 }
 
+// pop retrieves the next non-stale entry from the heap, discarding any stale
+// ones found at the heap start. Nil is returned if the heap was depleted.
+func (l *txPricedList) pop() *TxPoolEntry {
+	for l.items.Len() > 0 {
+		entry := heap.Pop(l.items).(*TxPoolEntry)
+		if l.all.Get(entry.Tx.Hash()) == nil {
+			delete(l.entries, entry.Tx.Hash())
+			l.stales--
+			continue
+		}
+		return entry
+	}
+	return nil
+}
+
+// exempt checks whether the sender of an entry is exempt from eviction, either
+// by being local, or by being whitelisted by the pool policy.
+func (l *txPricedList) exempt(entry *TxPoolEntry, local *accountSet) bool {
+	return local.contains(entry.Sender) || l.items.policy.Exempt(entry.Sender)
+}
+
 // Cap finds all the transactions below the given price threshold, drops them
 // from the priced list and returs them for further removal from the entire pool.
 func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, 128) // Remote underpriced transactions to drop
-	save := make(types.Transactions, 0, 64)  // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 {
-		// Discard stale transactions if found during cleanup
-		tx := heap.Pop(l.items).(*types.Transaction)
-		if l.all.Get(tx.Hash()) == nil {
-			l.stales--
+	// The heap is not necessarily price ordered, so check all the transactions
+	for hash, entry := range l.entries {
+		if l.all.Get(hash) == nil || entry.Tx.GasPrice().Cmp(threshold) >= 0 || l.exempt(entry, local) {
 			continue
 		}
-		// Stop the discards if we've reached the threshold
-		if tx.GasPrice().Cmp(threshold) >= 0 {
-			save = append(save, tx)
-			break
-		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
-			save = append(save, tx)
-		} else {
-			drop = append(drop, tx)
-		}
+		drop = append(drop, entry.Tx)
+		delete(l.entries, hash)
 	}
-	for _, tx := range save {
-		heap.Push(l.items, tx)
+	if len(drop) > 0 {
+		l.reheap(l.items.policy)
 	}
 	return drop
 }
 
-// Underpriced checks whether a transaction is cheaper than (or as cheap as) the
-// lowest priced transaction currently being tracked.
-func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) bool {
+// Underpriced checks whether a transaction may not enter the full pool, because
+// the policy prefers the worst transaction currently being tracked over it.
+func (l *txPricedList) Underpriced(entry *TxPoolEntry, local *accountSet) bool {
 	// Local transactions cannot be underpriced
-	if local.containsTx(tx) {
+	if l.exempt(entry, local) {
 		return false
 	}
 	// Discard stale price points if found at the heap start
-	for len(*l.items) > 0 {
-		head := []*types.Transaction(*l.items)[0]
-		if l.all.Get(head.Hash()) == nil {
-			l.stales--
-			heap.Pop(l.items)
-			continue
-		}
-		break
-	}
-	// Check if the transaction is underpriced or not
-	if len(*l.items) == 0 {
+	worst := l.pop()
+	if worst == nil {
 		log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
 		return false
 	}
-	cheapest := []*types.Transaction(*l.items)[0]
-	return cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0
+	heap.Push(l.items, worst)
+
+	// Check if the transaction is underpriced or not
+	return !l.items.policy.Admit(entry, worst)
 }
 
-// Discard finds a number of most underpriced transactions, removes them from the
-// priced list and returns them for further removal from the entire pool.
+// Discard finds a number of the worst transactions, removes them from the priced
+// list and returns them for further removal from the entire pool.
 func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
-	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
+	save := make([]*TxPoolEntry, 0, 64)        // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 && count > 0 {
+	for count > 0 {
 		// Discard stale transactions if found during cleanup
-		tx := heap.Pop(l.items).(*types.Transaction)
-		if l.all.Get(tx.Hash()) == nil {
-			l.stales--
-			continue
+		entry := l.pop()
+		if entry == nil {
+			break
 		}
 		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
-			save = append(save, tx)
+		if l.exempt(entry, local) {
+			save = append(save, entry)
 		} else {
-			drop = append(drop, tx)
+			drop = append(drop, entry.Tx)
+			delete(l.entries, entry.Tx.Hash())
 			count--
 		}
 	}
-	for _, tx := range save {
-		heap.Push(l.items, tx)
+	for _, entry := range save {
+		heap.Push(l.items, entry)
 	}
 	return drop
 }
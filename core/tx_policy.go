@@ -0,0 +1,221 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/metrics"
+)
+
+const (
+	// TxPoolPolicyPrice evicts the cheapest transactions first and only admits
+	// transactions into a full pool if they pay more than the cheapest one.
+	TxPoolPolicyPrice = "price"
+
+	// TxPoolPolicyFairness evicts the transactions queued deepest behind their
+	// sender's other transactions first, so a few busy accounts can't crowd the
+	// pool out for everyone else.
+	TxPoolPolicyFairness = "fairness"
+
+	// TxPoolPolicyFIFO evicts the transactions that entered the pool first,
+	// always admitting new ones into a full pool.
+	TxPoolPolicyFIFO = "fifo"
+
+	// TxPoolPolicyPriority orders transactions by price, but exempts a set of
+	// priority senders from the price limit and from eviction.
+	TxPoolPolicyPriority = "priority"
+)
+
+// TxPoolEntry is a remote transaction tracked by the eviction index of the pool,
+// along with the metadata policies may order it by.
+type TxPoolEntry struct {
+	Tx      *types.Transaction
+	Sender  common.Address
+	Arrival time.Time // Time the transaction entered the pool
+	Backlog uint64    // Number of sender nonces ahead of the transaction when it entered the pool
+}
+
+// TxPoolPolicy governs the admission, replacement and eviction ordering of the
+// transactions in the pool. Local transactions are never rejected due to the
+// pricing constraints or evicted, regardless of the policy.
+//
+// Policies are called with the pool lock held, they must not call back into it.
+type TxPoolPolicy interface {
+	// Name returns the name of the policy, used for selection and metrics.
+	Name() string
+
+	// Exempt returns whether the transactions of a sender are exempt from the
+	// price limit and from eviction, just like local ones.
+	Exempt(sender common.Address) bool
+
+	// Replace returns whether a transaction may replace an old one with the same
+	// sender and nonce.
+	Replace(old, tx *types.Transaction) bool
+
+	// Less returns whether entry a is to be evicted before entry b when the pool
+	// is full. The ordering of two entries must not change while they are pooled.
+	Less(a, b *TxPoolEntry) bool
+
+	// Admit returns whether a new entry may enter a full pool at the expense of
+	// the worst entry currently pooled.
+	Admit(entry, worst *TxPoolEntry) bool
+}
+
+// newTxPoolPolicy creates the transaction pool policy selected by the config.
+func newTxPoolPolicy(config *TxPoolConfig) TxPoolPolicy {
+	base := &pricePolicy{bump: config.PriceBump}
+
+	switch config.Policy {
+	case TxPoolPolicyFairness:
+		return &fairnessPolicy{base}
+	case TxPoolPolicyFIFO:
+		return &fifoPolicy{base}
+	case TxPoolPolicyPriority:
+		senders := make(map[common.Address]struct{})
+		for _, sender := range config.PrioritySenders {
+			senders[sender] = struct{}{}
+		}
+		return &priorityPolicy{base, senders}
+	default:
+		return base
+	}
+}
+
+// txPolicyMetrics is the set of counters tracking the decisions of a policy.
+type txPolicyMetrics struct {
+	rejected   metrics.Counter // Transactions not admitted into the full pool
+	evicted    metrics.Counter // Transactions evicted to make room for new ones
+	replaced   metrics.Counter // Transactions replaced by a new one with the same nonce
+	unreplaced metrics.Counter // Replacement transactions rejected
+	exempted   metrics.Counter // Transactions of exempt senders let around the pricing constraints
+}
+
+// newTxPolicyMetrics creates (or retrieves) the counters of the named policy.
+func newTxPolicyMetrics(name string) *txPolicyMetrics {
+	prefix := "txpool/policy/" + name + "/"
+	return &txPolicyMetrics{
+		rejected:   metrics.GetOrRegisterCounter(prefix+"rejected", nil),
+		evicted:    metrics.GetOrRegisterCounter(prefix+"evicted", nil),
+		replaced:   metrics.GetOrRegisterCounter(prefix+"replaced", nil),
+		unreplaced: metrics.GetOrRegisterCounter(prefix+"unreplaced", nil),
+		exempted:   metrics.GetOrRegisterCounter(prefix+"exempted", nil),
+	}
+}
+
+// pricePolicy is the default policy of the pool, evicting the cheapest and only
+// replacing transactions by ones paying a minimum price bump percentage more.
+type pricePolicy struct {
+	bump uint64 // Minimum price bump percentage to replace a transaction
+}
+
+// Name implements TxPoolPolicy, returning the name of the price policy.
+func (p *pricePolicy) Name() string { return TxPoolPolicyPrice }
+
+// Exempt implements TxPoolPolicy, exempting no senders.
+func (p *pricePolicy) Exempt(sender common.Address) bool { return false }
+
+// Replace implements TxPoolPolicy, requiring the new transaction to pay at least
+// the configured bump percentage more than the old one.
+func (p *pricePolicy) Replace(old, tx *types.Transaction) bool {
+	threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(p.bump))), big.NewInt(100))
+	// Have to ensure that the new gas price is higher than the old gas
+	// price as well as checking the percentage threshold to ensure that
+	// this is accurate for low (Wei-level) gas price replacements
+	return old.GasPrice().Cmp(tx.GasPrice()) < 0 && threshold.Cmp(tx.GasPrice()) <= 0
+}
+
+// Less implements TxPoolPolicy, ordering the cheaper transaction first.
+func (p *pricePolicy) Less(a, b *TxPoolEntry) bool {
+	// Sort primarily by price, returning the cheaper one
+	switch a.Tx.GasPrice().Cmp(b.Tx.GasPrice()) {
+	case -1:
+		return true
+	case 1:
+		return false
+	}
+	// If the prices match, stabilize via nonces (high nonce is worse)
+	return a.Tx.Nonce() > b.Tx.Nonce()
+}
+
+// Admit implements TxPoolPolicy, admitting transactions paying more than the
+// cheapest pooled one.
+func (p *pricePolicy) Admit(entry, worst *TxPoolEntry) bool {
+	return worst.Tx.GasPrice().Cmp(entry.Tx.GasPrice()) < 0
+}
+
+// fairnessPolicy evicts the transactions with the deepest sender backlog first,
+// falling back to price ordering between equally deep ones.
+type fairnessPolicy struct {
+	*pricePolicy
+}
+
+// Name implements TxPoolPolicy, returning the name of the fairness policy.
+func (p *fairnessPolicy) Name() string { return TxPoolPolicyFairness }
+
+// Less implements TxPoolPolicy, ordering the more deeply backlogged transaction
+// first.
+func (p *fairnessPolicy) Less(a, b *TxPoolEntry) bool {
+	if a.Backlog != b.Backlog {
+		return a.Backlog > b.Backlog
+	}
+	return p.pricePolicy.Less(a, b)
+}
+
+// Admit implements TxPoolPolicy, admitting transactions that are ordered after
+// the current worst one.
+func (p *fairnessPolicy) Admit(entry, worst *TxPoolEntry) bool {
+	return p.Less(worst, entry)
+}
+
+// fifoPolicy evicts the oldest transactions first, admitting all new ones.
+type fifoPolicy struct {
+	*pricePolicy
+}
+
+// Name implements TxPoolPolicy, returning the name of the FIFO policy.
+func (p *fifoPolicy) Name() string { return TxPoolPolicyFIFO }
+
+// Less implements TxPoolPolicy, ordering the older transaction first.
+func (p *fifoPolicy) Less(a, b *TxPoolEntry) bool {
+	if !a.Arrival.Equal(b.Arrival) {
+		return a.Arrival.Before(b.Arrival)
+	}
+	return p.pricePolicy.Less(a, b)
+}
+
+// Admit implements TxPoolPolicy, always admitting the new transaction.
+func (p *fifoPolicy) Admit(entry, worst *TxPoolEntry) bool { return true }
+
+// priorityPolicy is a price policy exempting a set of priority senders from the
+// price limit and eviction.
+type priorityPolicy struct {
+	*pricePolicy
+	senders map[common.Address]struct{} // Senders exempt from the pricing constraints
+}
+
+// Name implements TxPoolPolicy, returning the name of the priority policy.
+func (p *priorityPolicy) Name() string { return TxPoolPolicyPriority }
+
+// Exempt implements TxPoolPolicy, exempting the configured priority senders.
+func (p *priorityPolicy) Exempt(sender common.Address) bool {
+	_, ok := p.senders[sender]
+	return ok
+}
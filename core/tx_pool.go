@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -38,6 +39,10 @@ import (
 const (
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+
+	// maxLifecycleEvents is the maximum number of lifecycle events waiting for
+	// delivery. Beyond it the oldest ones are dropped to keep up with the pool.
+	maxLifecycleEvents = 4096
 )
 
 var (
@@ -76,6 +81,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrPrivateExpired is returned if a private transaction is submitted with an
+	// inclusion deadline that the chain has already reached.
+	ErrPrivateExpired = errors.New("private transaction deadline expired")
 )
 
 var (
@@ -99,6 +108,13 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+
+	// Metrics for the private transactions
+	privateTxCounter        = metrics.NewRegisteredCounter("txpool/private/added", nil)
+	privateExpiredTxCounter = metrics.NewRegisteredCounter("txpool/private/expired", nil) // Dropped due to missing the deadline
+
+	// Metrics for the lifecycle events
+	lifecycleDropCounter = metrics.NewRegisteredCounter("txpool/lifecycle/dropped", nil) // Undelivered due to slow subscribers
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -111,6 +127,47 @@ const (
 	TxStatusIncluded
 )
 
+// TxLifecycle is a state change of a transaction in the pool.
+type TxLifecycle uint
+
+const (
+	TxAdded    TxLifecycle = iota // Transaction entered the pool
+	TxPromoted                    // Transaction became executable
+	TxReplaced                    // Transaction replaced by another with the same nonce
+	TxDropped                     // Transaction removed from the pool for the attached reason
+	TxMined                       // Transaction included in the chain
+)
+
+// String implements fmt.Stringer, returning the name of the lifecycle change.
+func (l TxLifecycle) String() string {
+	switch l {
+	case TxAdded:
+		return "added"
+	case TxPromoted:
+		return "promoted"
+	case TxReplaced:
+		return "replaced"
+	case TxDropped:
+		return "dropped"
+	case TxMined:
+		return "mined"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint(l))
+	}
+}
+
+// Reasons for dropping transactions from the pool, attached to the lifecycle
+// events of the dropped transactions.
+const (
+	TxDropUnderpriced  = "underpriced"   // Outpriced in the full pool, or below the price limit
+	TxDropNonceTooLow  = "nonce-too-low" // Nonce used up by a different transaction
+	TxDropUnpayable    = "unpayable"     // Sender balance or block gas limit too low
+	TxDropAccountLimit = "account-limit" // Exceeding the queue limit of the account
+	TxDropPoolLimit    = "pool-limit"    // Exceeding the global pending or queue limits
+	TxDropExpired      = "expired"       // Queued for longer than the pool lifetime
+	TxDropDeadline     = "deadline"      // Private transaction not included by its deadline block
+)
+
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
@@ -127,9 +184,16 @@ type TxPoolConfig struct {
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
 
+	RemoteJournal string // Snapshot of remote transactions to survive node restarts (disabled if empty)
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
+	Policy          string           // Policy governing admission, replacement and eviction (price, fairness, fifo, priority)
+	PrioritySenders []common.Address // Senders exempt from pricing constraints under the priority policy
+
+	PrivateLifetime uint64 // Default number of blocks private transactions are kept for inclusion
+
 	AccountSlots uint64 // Minimum number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
@@ -147,6 +211,10 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	PriceLimit: 1,
 	PriceBump:  10,
 
+	Policy: TxPoolPolicyPrice,
+
+	PrivateLifetime: 25,
+
 	AccountSlots: 16,
 	GlobalSlots:  4096,
 	AccountQueue: 64,
@@ -171,6 +239,16 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.PrivateLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool private lifetime", "provided", conf.PrivateLifetime, "updated", DefaultTxPoolConfig.PrivateLifetime)
+		conf.PrivateLifetime = DefaultTxPoolConfig.PrivateLifetime
+	}
+	switch conf.Policy {
+	case TxPoolPolicyPrice, TxPoolPolicyFairness, TxPoolPolicyFIFO, TxPoolPolicyPriority:
+	default:
+		log.Warn("Sanitizing invalid txpool policy", "provided", conf.Policy, "updated", DefaultTxPoolConfig.Policy)
+		conf.Policy = DefaultTxPoolConfig.Policy
+	}
 	return conf
 }
 
@@ -187,6 +265,7 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	txEventFeed  event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -204,7 +283,17 @@ type TxPool struct {
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
 	all     *txLookup                    // All transactions to allow lookups
-	priced  *txPricedList                // All transactions sorted by price
+	priced  *txPricedList                // All transactions sorted by the pool policy
+	private map[common.Hash]uint64       // Deadline blocks of the private (non-propagated) transactions
+	mined   map[common.Hash]struct{}     // Transactions included by the chain head being reset to
+
+	policy        TxPoolPolicy     // Policy governing admission, replacement and eviction
+	policyMetrics *txPolicyMetrics // Metrics tracking the decisions of the policy
+
+	events    []NewTxsEvent // Lifecycle events waiting to be delivered, in order
+	eventLock sync.Mutex    // Mutex protecting the lifecycle event queue
+	eventWake chan struct{} // Channel waking the lifecycle event dispatcher
+	eventQuit chan struct{} // Channel terminating the lifecycle event dispatcher
 
 	wg sync.WaitGroup // for shutdown sync
 
@@ -227,30 +316,40 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
 		all:         newTxLookup(),
+		private:     make(map[common.Hash]uint64),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		eventWake:   make(chan struct{}, 1),
+		eventQuit:   make(chan struct{}),
 	}
 	pool.locals = newAccountSet(pool.signer)
-	pool.priced = newTxPricedList(pool.all)
+	pool.policy = newTxPoolPolicy(&config)
+	pool.policyMetrics = newTxPolicyMetrics(pool.policy.Name())
+	pool.priced = newTxPricedList(pool.all, pool.policy)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 
-		if err := pool.journal.load(pool.AddLocals); err != nil {
+		if err := pool.journal.load(pool.AddLocals, pool.AddPrivate); err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
 		}
-		if err := pool.journal.rotate(pool.local()); err != nil {
+		if err := pool.journal.rotate(pool.journaled(), pool.private); err != nil {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote journaling is enabled, load the previous snapshot from disk
+	if config.RemoteJournal != "" {
+		pool.loadRemotes()
+	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
-	// Start the event loop and return
-	pool.wg.Add(1)
+	// Start the event loops and return
+	pool.wg.Add(2)
 	go pool.loop()
+	go pool.eventLoop()
 
 	return pool
 }
@@ -317,9 +416,11 @@ func (pool *TxPool) loop() {
 				}
 				// Any non-locals old enough should be removed
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
-					for _, tx := range pool.queue[addr].Flatten() {
+					txs := pool.queue[addr].Flatten()
+					for _, tx := range txs {
 						pool.removeTx(tx.Hash(), true)
 					}
+					pool.notify(TxDropped, TxDropExpired, txs...)
 				}
 			}
 			pool.mu.Unlock()
@@ -328,7 +429,7 @@ func (pool *TxPool) loop() {
 		case <-journal.C:
 			if pool.journal != nil {
 				pool.mu.Lock()
-				if err := pool.journal.rotate(pool.local()); err != nil {
+				if err := pool.journal.rotate(pool.journaled(), pool.private); err != nil {
 					log.Warn("Failed to rotate local tx journal", "err", err)
 				}
 				pool.mu.Unlock()
@@ -350,7 +451,7 @@ func (pool *TxPool) lockedReset(oldHead, newHead *types.Header) {
 // of the transaction pool is valid with regard to the chain state.
 func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
-	var reinject types.Transactions
+	var reinject, included types.Transactions
 
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
@@ -361,7 +462,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 			log.Debug("Skipping deep transaction reorg", "depth", depth)
 		} else {
 			// Reorg seems shallow enough to pull in all transactions into memory
-			var discarded, included types.Transactions
+			var discarded types.Transactions
 
 			var (
 				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number.Uint64())
@@ -395,7 +496,18 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 			}
 			reinject = types.TxDifference(discarded, included)
 		}
+	} else if oldHead != nil {
+		// Plain chain extension, only the new head block has included transactions
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
+	// Track the included transactions to report them mined instead of dropped
+	pool.mined = make(map[common.Hash]struct{}, len(included))
+	for _, tx := range included {
+		pool.mined[tx.Hash()] = struct{}{}
 	}
+	defer func() { pool.mined = nil }()
 	// Initialize the internal state to the current head
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
@@ -427,6 +539,37 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Check the queue and move transactions over to the pending if possible
 	// or remove those that have become invalid
 	pool.promoteExecutables(nil)
+
+	// Drop any private transactions that missed their inclusion deadline
+	pool.expirePrivates(newHead.Number.Uint64())
+}
+
+// expirePrivates forgets the private transactions that already left the pool
+// and drops the ones that weren't included until their deadline block.
+//
+// The private markers are only removed here instead of together with the
+// transactions, so that lifecycle events about them are still flagged private.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expirePrivates(head uint64) {
+	var expired types.Transactions
+	for hash, deadline := range pool.private {
+		tx := pool.all.Get(hash)
+		if tx == nil {
+			delete(pool.private, hash)
+			continue
+		}
+		if deadline <= head {
+			log.Debug("Dropping expired private transaction", "hash", hash, "deadline", deadline)
+			pool.removeTx(hash, true)
+			expired = append(expired, tx)
+			privateExpiredTxCounter.Inc(1)
+		}
+	}
+	pool.notify(TxDropped, TxDropDeadline, expired...)
+	for _, tx := range expired {
+		delete(pool.private, tx.Hash())
+	}
 }
 
 // Stop terminates the transaction pool.
@@ -436,11 +579,15 @@ func (pool *TxPool) Stop() {
 
 	// Unsubscribe subscriptions registered from blockchain
 	pool.chainHeadSub.Unsubscribe()
+	close(pool.eventQuit)
 	pool.wg.Wait()
 
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.config.RemoteJournal != "" {
+		pool.saveRemotes()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -450,6 +597,121 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeTxLifecycleEvent registers a subscription of the lifecycle events of
+// the pooled transactions and starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxLifecycleEvent(ch chan<- NewTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.txEventFeed.Subscribe(ch))
+}
+
+// notify queues a lifecycle event about a batch of transactions for delivery,
+// splitting off the private ones into a separate batch flagged as such. If the
+// subscribers fall behind too much, the oldest events are dropped.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) notify(kind TxLifecycle, reason string, txs ...*types.Transaction) {
+	public, private := pool.splitPrivate(txs)
+
+	pool.eventLock.Lock()
+	if len(public) > 0 {
+		pool.events = append(pool.events, NewTxsEvent{Txs: public, Kind: kind, Reason: reason})
+	}
+	if len(private) > 0 {
+		pool.events = append(pool.events, NewTxsEvent{Txs: private, Kind: kind, Reason: reason, Private: true})
+	}
+	if drop := len(pool.events) - maxLifecycleEvents; drop > 0 {
+		log.Debug("Dropping undelivered lifecycle events", "count", drop)
+		for i := 0; i < drop; i++ {
+			pool.events[i] = NewTxsEvent{}
+		}
+		pool.events = pool.events[drop:]
+		lifecycleDropCounter.Inc(int64(drop))
+	}
+	pool.eventLock.Unlock()
+
+	select {
+	case pool.eventWake <- struct{}{}:
+	default:
+	}
+}
+
+// splitPrivate separates a batch of transactions into the public and the private
+// ones, preserving their order.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) splitPrivate(txs types.Transactions) (types.Transactions, types.Transactions) {
+	if len(pool.private) == 0 {
+		return txs, nil
+	}
+	var public, private types.Transactions
+	for _, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; ok {
+			private = append(private, tx)
+		} else {
+			public = append(public, tx)
+		}
+	}
+	return public, private
+}
+
+// eventLoop delivers the queued lifecycle events to the subscribers one by one,
+// preserving their order without blocking the pool on slow subscribers.
+func (pool *TxPool) eventLoop() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case <-pool.eventWake:
+			for {
+				pool.eventLock.Lock()
+				if len(pool.events) == 0 {
+					pool.eventLock.Unlock()
+					break
+				}
+				ev := pool.events[0]
+				pool.events[0] = NewTxsEvent{}
+				pool.events = pool.events[1:]
+				pool.eventLock.Unlock()
+
+				pool.txEventFeed.Send(ev)
+			}
+		case <-pool.eventQuit:
+			return
+		}
+	}
+}
+
+// announce posts the new executable transactions to the subscribers, splitting off
+// the private ones into a separate batch flagged as such. The decision is taken
+// here as the private markers may be gone by the time the events are delivered.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) announce(txs types.Transactions) {
+	public, private := pool.splitPrivate(txs)
+	if len(public) > 0 {
+		go pool.txFeed.Send(NewTxsEvent{Txs: public, Kind: TxPromoted})
+	}
+	if len(private) > 0 {
+		go pool.txFeed.Send(NewTxsEvent{Txs: private, Kind: TxPromoted, Private: true})
+	}
+}
+
+// notifyStale posts the lifecycle events of transactions dropped due to their
+// nonces being used up, reporting the ones included by the new head as mined.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) notifyStale(txs types.Transactions) {
+	var mined, stale types.Transactions
+	for _, tx := range txs {
+		if _, ok := pool.mined[tx.Hash()]; ok {
+			mined = append(mined, tx)
+		} else {
+			stale = append(stale, tx)
+		}
+	}
+	pool.notify(TxMined, "", mined...)
+	pool.notify(TxDropped, TxDropNonceTooLow, stale...)
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -465,9 +727,11 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	defer pool.mu.Unlock()
 
 	pool.gasPrice = price
-	for _, tx := range pool.priced.Cap(price, pool.locals) {
+	drop := pool.priced.Cap(price, pool.locals)
+	for _, tx := range drop {
 		pool.removeTx(tx.Hash(), false)
 	}
+	pool.notify(TxDropped, TxDropUnderpriced, drop...)
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
@@ -504,21 +768,71 @@ func (pool *TxPool) stats() (int, int) {
 
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
+// Private transactions are omitted.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
 	pending := make(map[common.Address]types.Transactions)
 	for addr, list := range pool.pending {
-		pending[addr] = list.Flatten()
+		if txs, _ := pool.splitPrivate(list.Flatten()); len(txs) > 0 {
+			pending[addr] = txs
+		}
 	}
 	queued := make(map[common.Address]types.Transactions)
 	for addr, list := range pool.queue {
-		queued[addr] = list.Flatten()
+		if txs, _ := pool.splitPrivate(list.Flatten()); len(txs) > 0 {
+			queued[addr] = txs
+		}
 	}
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool, returning the
+// pending as well as queued transactions of a single account, sorted by nonce.
+// Private transactions are omitted.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var pending, queued types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		pending, _ = pool.splitPrivate(list.Flatten())
+	}
+	if list, ok := pool.queue[addr]; ok {
+		queued, _ = pool.splitPrivate(list.Flatten())
+	}
+	return pending, queued
+}
+
+// Senders retrieves the addresses of all the accounts with pending or queued
+// transactions in the pool, sorted in ascending byte order. Accounts with only
+// private transactions are omitted.
+func (pool *TxPool) Senders() []common.Address {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	public := func(list *txList) bool {
+		txs, _ := pool.splitPrivate(list.Flatten())
+		return len(txs) > 0
+	}
+	senders := make([]common.Address, 0, len(pool.pending)+len(pool.queue))
+	for addr, list := range pool.pending {
+		if public(list) {
+			senders = append(senders, addr)
+		}
+	}
+	for addr, list := range pool.queue {
+		if pending, ok := pool.pending[addr]; (!ok || !public(pending)) && public(list) {
+			senders = append(senders, addr)
+		}
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+	return senders
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -549,6 +863,120 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// journaled retrieves all the transactions that need to be persisted into the
+// journal: the ones of the local accounts and any private ones, grouped by account.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) journaled() map[common.Address]types.Transactions {
+	txs := pool.local()
+	for hash := range pool.private {
+		tx := pool.all.Get(hash)
+		if tx == nil {
+			continue
+		}
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		if !pool.locals.contains(from) {
+			txs[from] = append(txs[from], tx)
+		}
+	}
+	return txs
+}
+
+// remote retrieves the pool entries of all the remote, non-private transactions,
+// ordered by account and nonce.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) remote() []*TxPoolEntry {
+	var entries []*TxPoolEntry
+	for _, lists := range []map[common.Address]*txList{pool.pending, pool.queue} {
+		for addr, list := range lists {
+			if pool.locals.contains(addr) {
+				continue
+			}
+			for _, tx := range list.Flatten() {
+				// Private transactions must never be reloaded as gossipable ones
+				if _, ok := pool.private[tx.Hash()]; ok {
+					continue
+				}
+				entry := pool.priced.entries[tx.Hash()]
+				if entry == nil {
+					entry = &TxPoolEntry{Tx: tx, Sender: addr, Arrival: time.Now()}
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if cmp := bytes.Compare(entries[i].Sender[:], entries[j].Sender[:]); cmp != 0 {
+			return cmp < 0
+		}
+		return entries[i].Tx.Nonce() < entries[j].Tx.Nonce()
+	})
+	return entries
+}
+
+// saveRemotes snapshots the remote transactions into the remote journal.
+func (pool *TxPool) saveRemotes() {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	entries := pool.remote()
+	if err := saveRemoteJournal(pool.config.RemoteJournal, entries); err != nil {
+		log.Warn("Failed to save remote transaction journal", "err", err)
+		return
+	}
+	log.Info("Saved remote transaction journal", "transactions", len(entries))
+}
+
+// loadRemotes injects the remote transactions of the previous snapshot into the
+// pool, revalidating them against the current state. The original arrival times
+// are retained, so the queue lifetime of the transactions spans restarts.
+func (pool *TxPool) loadRemotes() {
+	entries, err := loadRemoteJournal(pool.config.RemoteJournal)
+	if err != nil {
+		log.Warn("Failed to load remote transaction journal", "err", err)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	txs := make([]*types.Transaction, len(entries))
+	for i, entry := range entries {
+		txs[i] = entry.Tx
+	}
+	var (
+		dropped int
+		latest  = make(map[common.Address]time.Time)
+	)
+	for i, err := range pool.addTxsLocked(txs, false) {
+		if err != nil {
+			log.Debug("Failed to add journaled remote transaction", "err", err)
+			dropped++
+			continue
+		}
+		// Restore the arrival time of the transaction
+		if entry := pool.priced.entries[txs[i].Hash()]; entry != nil {
+			entry.Arrival = entries[i].Arrival
+		}
+		addr, _ := types.Sender(pool.signer, txs[i]) // already validated
+		if latest[addr].Before(entries[i].Arrival) {
+			latest[addr] = entries[i].Arrival
+		}
+	}
+	// Rewind the heartbeats of the promoted accounts to their latest arrival
+	for addr, arrival := range latest {
+		if beat, ok := pool.beats[addr]; ok && beat.After(arrival) {
+			pool.beats[addr] = arrival
+		}
+	}
+	// Arrival times changed, reorder the pool
+	pool.priced.reheap(pool.policy)
+
+	log.Info("Loaded remote transaction journal", "transactions", len(entries), "dropped", dropped)
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -573,7 +1001,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
-		return ErrUnderpriced
+		if !pool.policy.Exempt(from) {
+			return ErrUnderpriced
+		}
+		pool.policyMetrics.exempted.Inc(1)
 	}
 	// Ensure the transaction adheres to nonce ordering
 	if pool.currentState.GetNonce(from) > tx.Nonce() {
@@ -615,12 +1046,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	entry := pool.newEntry(tx)
+
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
-		if !local && pool.priced.Underpriced(tx, pool.locals) {
-			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
+		if !local && pool.priced.Underpriced(entry, pool.locals) {
+			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice(), "policy", pool.policy.Name())
 			underpricedTxCounter.Inc(1)
+			pool.policyMetrics.rejected.Inc(1)
 			return false, ErrUnderpriced
 		}
 		// New transaction is better than our worse ones, make room for it
@@ -628,16 +1062,19 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
+			pool.policyMetrics.evicted.Inc(1)
 			pool.removeTx(tx.Hash(), false)
 		}
+		pool.notify(TxDropped, TxDropUnderpriced, drop...)
 	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
+	from := entry.Sender
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
-		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		// Nonce already pending, check if the policy allows the replacement
+		inserted, old := list.Add(tx, pool.policy)
 		if !inserted {
 			pendingDiscardCounter.Inc(1)
+			pool.policyMetrics.unreplaced.Inc(1)
 			return false, ErrReplaceUnderpriced
 		}
 		// New transaction is better, replace old one
@@ -645,15 +1082,18 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.policyMetrics.replaced.Inc(1)
+			pool.notify(TxReplaced, "", old)
 		}
 		pool.all.Add(tx)
-		pool.priced.Put(tx)
+		pool.priced.Put(entry)
+		pool.notify(TxAdded, "", tx)
 		pool.journalTx(from, tx)
 
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// We've directly injected a replacement transaction, notify subsystems
-		go pool.txFeed.Send(NewTxsEvent{types.Transactions{tx}})
+		pool.announce(types.Transactions{tx})
 
 		return old != nil, nil
 	}
@@ -662,6 +1102,8 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	pool.notify(TxAdded, "", tx)
+
 	// Mark local addresses and journal local transactions
 	if local {
 		pool.locals.add(from)
@@ -681,10 +1123,11 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.policy)
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardCounter.Inc(1)
+		pool.policyMetrics.unreplaced.Inc(1)
 		return false, ErrReplaceUnderpriced
 	}
 	// Discard any previous transaction and mark this
@@ -692,22 +1135,67 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.policyMetrics.replaced.Inc(1)
+		pool.notify(TxReplaced, "", old)
 	}
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
-		pool.priced.Put(tx)
+		pool.priced.Put(pool.newEntry(tx))
 	}
 	return old != nil, nil
 }
 
+// newEntry wraps a validated transaction into a pool entry, gathering the
+// metadata the pool policy may order it by.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) newEntry(tx *types.Transaction) *TxPoolEntry {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+
+	entry := &TxPoolEntry{
+		Tx:      tx,
+		Sender:  from,
+		Arrival: time.Now(),
+	}
+	if nonce := pool.currentState.GetNonce(from); tx.Nonce() > nonce {
+		entry.Backlog = tx.Nonce() - nonce
+	}
+	return entry
+}
+
+// Policy returns the policy governing the admission, replacement and eviction
+// of the transactions in the pool.
+func (pool *TxPool) Policy() TxPoolPolicy {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.policy
+}
+
+// SetPolicy swaps out the policy governing the admission, replacement and
+// eviction of the transactions in the pool, reordering all pooled ones.
+func (pool *TxPool) SetPolicy(policy TxPoolPolicy) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.policy = policy
+	pool.policyMetrics = newTxPolicyMetrics(policy.Name())
+	pool.priced.reheap(policy)
+
+	log.Info("Transaction pool policy updated", "policy", policy.Name())
+}
+
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	// Only journal if it's enabled and the transaction is local or private
+	if pool.journal == nil {
+		return
+	}
+	if _, private := pool.private[tx.Hash()]; !private && !pool.locals.contains(from) {
 		return
 	}
-	if err := pool.journal.insert(tx); err != nil {
+	if err := pool.journal.insert(tx, pool.private[tx.Hash()]); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
 }
@@ -723,11 +1211,12 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
+	inserted, old := list.Add(tx, pool.policy)
 	if !inserted {
 		// An older transaction was better, discard this
 		pool.all.Remove(hash)
 		pool.priced.Removed()
+		pool.notify(TxDropped, TxDropUnderpriced, tx)
 
 		pendingDiscardCounter.Inc(1)
 		return false
@@ -736,13 +1225,14 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	if old != nil {
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
+		pool.notify(TxReplaced, "", old)
 
 		pendingReplaceCounter.Inc(1)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
-		pool.priced.Put(tx)
+		pool.priced.Put(pool.newEntry(tx))
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.beats[addr] = time.Now()
@@ -779,6 +1269,59 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 	return pool.addTxs(txs, false)
 }
 
+// AddPrivate enqueues a single transaction into the pool and marks it private: it
+// is never announced to the network, only made available to the local miner. If
+// the transaction isn't included by the deadline block, it's dropped from the pool.
+// A zero deadline selects the configured private lifetime.
+//
+// Private transactions are journaled like local ones, but their senders are not
+// marked local, so they remain subject to the pricing and eviction rules.
+func (pool *TxPool) AddPrivate(tx *types.Transaction, deadline uint64) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	head := pool.chain.CurrentBlock().NumberU64()
+	if deadline == 0 {
+		deadline = head + pool.config.PrivateLifetime
+	}
+	if deadline <= head {
+		return ErrPrivateExpired
+	}
+	// Don't hijack already known (and thus possibly announced) transactions
+	hash := tx.Hash()
+	if pool.all.Get(hash) != nil {
+		log.Trace("Discarding already known transaction", "hash", hash)
+		return fmt.Errorf("known transaction: %x", hash)
+	}
+	pool.private[hash] = deadline
+	if err := pool.addTxsLocked([]*types.Transaction{tx}, false)[0]; err != nil {
+		delete(pool.private, hash)
+		return err
+	}
+	privateTxCounter.Inc(1)
+	return nil
+}
+
+// IsPrivate returns whether a pooled transaction is private, meaning that it
+// must not be propagated to the network.
+func (pool *TxPool) IsPrivate(hash common.Hash) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	_, ok := pool.private[hash]
+	return ok
+}
+
+// FilterPrivate returns the subset of the given transactions which are not private,
+// i.e. which may be propagated to the network.
+func (pool *TxPool) FilterPrivate(txs types.Transactions) types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	public, _ := pool.splitPrivate(txs)
+	return public
+}
+
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	pool.mu.Lock()
@@ -922,12 +1465,15 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			continue // Just in case someone calls with a non existing account
 		}
 		// Drop all transactions that are deemed too old (low nonce)
-		for _, tx := range list.Forward(pool.currentState.GetNonce(addr)) {
+		olds := list.Forward(pool.currentState.GetNonce(addr))
+		for _, tx := range olds {
 			hash := tx.Hash()
 			log.Trace("Removed old queued transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
+		pool.notifyStale(olds)
+
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
 		for _, tx := range drops {
@@ -937,6 +1483,8 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
 		}
+		pool.notify(TxDropped, TxDropUnpayable, drops...)
+
 		// Gather all executable transactions and promote them
 		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
 			hash := tx.Hash()
@@ -947,13 +1495,15 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 		// Drop all transactions over the allowed limit
 		if !pool.locals.contains(addr) {
-			for _, tx := range list.Cap(int(pool.config.AccountQueue)) {
+			caps := list.Cap(int(pool.config.AccountQueue))
+			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			pool.notify(TxDropped, TxDropAccountLimit, caps...)
 		}
 		// Delete the entire queue entry if it became empty.
 		if list.Empty() {
@@ -962,7 +1512,8 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	}
 	// Notify subsystem for new promoted transactions.
 	if len(promoted) > 0 {
-		go pool.txFeed.Send(NewTxsEvent{promoted})
+		pool.announce(promoted)
+		pool.notify(TxPromoted, "", promoted...)
 	}
 	// If the pending limit is overflown, start equalizing allowances
 	pending := uint64(0)
@@ -995,7 +1546,8 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				for pending > pool.config.GlobalSlots && pool.pending[offenders[len(offenders)-2]].Len() > threshold {
 					for i := 0; i < len(offenders)-1; i++ {
 						list := pool.pending[offenders[i]]
-						for _, tx := range list.Cap(list.Len() - 1) {
+						caps := list.Cap(list.Len() - 1)
+						for _, tx := range caps {
 							// Drop the transaction from the global pools too
 							hash := tx.Hash()
 							pool.all.Remove(hash)
@@ -1007,6 +1559,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							}
 							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 						}
+						pool.notify(TxDropped, TxDropPoolLimit, caps...)
 						pending--
 					}
 				}
@@ -1017,7 +1570,8 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			for pending > pool.config.GlobalSlots && uint64(pool.pending[offenders[len(offenders)-1]].Len()) > pool.config.AccountSlots {
 				for _, addr := range offenders {
 					list := pool.pending[addr]
-					for _, tx := range list.Cap(list.Len() - 1) {
+					caps := list.Cap(list.Len() - 1)
+					for _, tx := range caps {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
@@ -1029,6 +1583,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 						}
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
+					pool.notify(TxDropped, TxDropPoolLimit, caps...)
 					pending--
 				}
 			}
@@ -1059,9 +1614,11 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 			// Drop all transactions if they are less than the overflow
 			if size := uint64(list.Len()); size <= drop {
-				for _, tx := range list.Flatten() {
+				txs := list.Flatten()
+				for _, tx := range txs {
 					pool.removeTx(tx.Hash(), true)
 				}
+				pool.notify(TxDropped, TxDropPoolLimit, txs...)
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
 				continue
@@ -1070,6 +1627,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 				pool.removeTx(txs[i].Hash(), true)
+				pool.notify(TxDropped, TxDropPoolLimit, txs[i])
 				drop--
 				queuedRateLimitCounter.Inc(1)
 			}
@@ -1086,12 +1644,15 @@ func (pool *TxPool) demoteUnexecutables() {
 		nonce := pool.currentState.GetNonce(addr)
 
 		// Drop all transactions that are deemed too old (low nonce)
-		for _, tx := range list.Forward(nonce) {
+		olds := list.Forward(nonce)
+		for _, tx := range olds {
 			hash := tx.Hash()
 			log.Trace("Removed old pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
+		pool.notifyStale(olds)
+
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
 		for _, tx := range drops {
@@ -1101,6 +1662,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
 		}
+		pool.notify(TxDropped, TxDropUnpayable, drops...)
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
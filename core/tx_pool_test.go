@@ -17,12 +17,14 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -78,7 +80,7 @@ func pricedTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ec
 }
 
 func setupTxPool() (*TxPool, *ecdsa.PrivateKey) {
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	key, _ := crypto.GenerateKey()
@@ -163,7 +165,7 @@ func (c *testChain) State() (*state.StateDB, error) {
 	// a state change between those fetches.
 	stdb := c.statedb
 	if *c.trigger {
-		c.statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		c.statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 		// simulate that the new head block included tx0 and tx1
 		c.statedb.SetNonce(c.address, 2)
 		c.statedb.SetBalance(c.address, new(big.Int).SetUint64(params.Ether))
@@ -181,7 +183,7 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T) {
 	var (
 		key, _     = crypto.GenerateKey()
 		address    = crypto.PubkeyToAddress(key.PublicKey)
-		statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 		trigger    = false
 	)
 
@@ -335,7 +337,7 @@ func TestTransactionChainFork(t *testing.T) {
 
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
-		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 		statedb.AddBalance(addr, big.NewInt(100000000000000))
 
 		pool.chain = &testBlockChain{statedb, 1000000, new(event.Feed)}
@@ -364,7 +366,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
-		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 		statedb.AddBalance(addr, big.NewInt(100000000000000))
 
 		pool.chain = &testBlockChain{statedb, 1000000, new(event.Feed)}
@@ -554,7 +556,7 @@ func TestTransactionPostponing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the postponing with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -769,7 +771,7 @@ func testTransactionQueueGlobalLimiting(t *testing.T, nolocals bool) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -857,7 +859,7 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals bool) {
 	evictionInterval = time.Second
 
 	// Create the pool to test the non-expiration enforcement
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1011,7 +1013,7 @@ func TestTransactionPendingGlobalLimiting(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1057,7 +1059,7 @@ func TestTransactionCapClearsFromAll(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1091,7 +1093,7 @@ func TestTransactionPendingMinimumAllowance(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the limit enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1139,7 +1141,7 @@ func TestTransactionPoolRepricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1260,7 +1262,7 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1322,7 +1324,7 @@ func TestTransactionPoolUnderpricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1428,7 +1430,7 @@ func TestTransactionPoolStableUnderpricing(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1488,13 +1490,184 @@ func TestTransactionPoolStableUnderpricing(t *testing.T) {
 	}
 }
 
+// Tests that the fairness policy evicts the transactions queued deepest behind
+// their sender's others to make room for shallower ones, regardless of price.
+func TestTransactionPoolFairnessPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the fairness policy with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+	config.Policy = TxPoolPolicyFairness
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create a number of test accounts and fund them
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	// Fill the pool up with a single busy account
+	txs := types.Transactions{}
+	for i := uint64(0); i < 4; i++ {
+		txs = append(txs, pricedTransaction(i, 100000, big.NewInt(2), keys[0]))
+	}
+	for i, err := range pool.AddRemotes(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	// Ensure a cheaper transaction of a fresh account evicts the deepest one
+	tx := pricedTransaction(0, 100000, big.NewInt(1), keys[1])
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add fair transaction: %v", err)
+	}
+	if pool.Get(txs[3].Hash()) != nil {
+		t.Errorf("deepest transaction not evicted")
+	}
+	if pool.Get(tx.Hash()) == nil {
+		t.Errorf("fair transaction not pooled")
+	}
+	// Ensure a transaction queued deeper than all the pooled ones is rejected
+	if err := pool.AddRemote(pricedTransaction(5, 100000, big.NewInt(5), keys[1])); err != ErrUnderpriced {
+		t.Fatalf("adding deep transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the FIFO policy evicts the oldest transactions first, admitting new
+// ones into the full pool regardless of price.
+func TestTransactionPoolFIFOPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the FIFO policy with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+	config.Policy = TxPoolPolicyFIFO
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create a number of test accounts and fund them
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	// Fill the pool up with ever cheaper transactions
+	txs := types.Transactions{}
+	for i := 0; i < 4; i++ {
+		tx := pricedTransaction(0, 100000, big.NewInt(int64(4-i)), keys[i])
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+		txs = append(txs, tx)
+	}
+	// Ensure the cheapest transaction is admitted, evicting the oldest one
+	tx := pricedTransaction(0, 100000, big.NewInt(1), keys[4])
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add new transaction: %v", err)
+	}
+	if pool.Get(txs[0].Hash()) != nil {
+		t.Errorf("oldest transaction not evicted")
+	}
+	for i, tx := range txs[1:] {
+		if pool.Get(tx.Hash()) == nil {
+			t.Errorf("tx %d: newer transaction evicted", i+1)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the priority policy lets the transactions of the priority senders
+// around the price limit and never evicts them.
+func TestTransactionPoolPriorityPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Create a number of test accounts
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	// Create the pool to test the priority policy with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+	config.Policy = TxPoolPolicyPriority
+	config.PrioritySenders = []common.Address{crypto.PubkeyToAddress(keys[0].PublicKey)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	for _, key := range keys {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	}
+	// Ensure priority transactions are let around the price limit, others not
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(0), keys[1])); err != ErrUnderpriced {
+		t.Fatalf("adding underpriced transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	prio := types.Transactions{pricedTransaction(0, 100000, big.NewInt(0), keys[0])}
+	if err := pool.AddRemote(prio[0]); err != nil {
+		t.Fatalf("failed to add underpriced priority transaction: %v", err)
+	}
+	// Fill the pool up and ensure new transactions evict around the priority one
+	txs := types.Transactions{}
+	for i := 1; i < 4; i++ {
+		tx := pricedTransaction(0, 100000, big.NewInt(int64(i)), keys[i])
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+		txs = append(txs, tx)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(5), keys[4])); err != nil {
+		t.Fatalf("failed to add well priced transaction: %v", err)
+	}
+	prio = append(prio, pricedTransaction(1, 100000, big.NewInt(0), keys[0]))
+	if err := pool.AddRemote(prio[1]); err != nil {
+		t.Fatalf("failed to add priority transaction into full pool: %v", err)
+	}
+	for i, tx := range prio {
+		if pool.Get(tx.Hash()) == nil {
+			t.Errorf("priority tx %d: evicted", i)
+		}
+	}
+	for i, tx := range txs[:2] {
+		if pool.Get(tx.Hash()) != nil {
+			t.Errorf("tx %d: cheap transaction not evicted", i)
+		}
+	}
+	if pool.Get(txs[2].Hash()) == nil {
+		t.Errorf("well priced transaction evicted")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that the pool rejects replacement transactions that don't meet the minimum
 // price bump required.
 func TestTransactionReplacement(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the pricing enforcement with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
@@ -1588,7 +1761,7 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	os.Remove(journal)
 
 	// Create the original pool to inject transaction into the journal
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	config := testTxPoolConfig
@@ -1680,13 +1853,388 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that the lifecycle events of the transactions are posted as they move
+// through the pool, with the drop reasons attached.
+func TestTransactionLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	events := make(chan NewTxsEvent, 32)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	type lifecycle struct {
+		hash    common.Hash
+		kind    TxLifecycle
+		reason  string
+		private bool
+	}
+	expect := func(want ...lifecycle) {
+		t.Helper()
+
+		var have []lifecycle
+		for len(have) < len(want) {
+			select {
+			case ev := <-events:
+				for _, tx := range ev.Txs {
+					have = append(have, lifecycle{tx.Hash(), ev.Kind, ev.Reason, ev.Private})
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("events missing: have %v, want %v", have, want)
+			}
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("event mismatch: have %v, want %v", have, want)
+		}
+	}
+	// Add a private transaction, which must be reported flagged as such
+	secret, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(secret.PublicKey), big.NewInt(1000000))
+
+	private := pricedTransaction(0, 100000, big.NewInt(5), secret)
+	if err := pool.AddPrivate(private, 2); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	expect(lifecycle{private.Hash(), TxAdded, "", true}, lifecycle{private.Hash(), TxPromoted, "", true})
+
+	// Queue up a future transaction, then fill the gap to promote both
+	tx0, tx1 := transaction(0, 100000, key), transaction(1, 100000, key)
+	if err := pool.AddRemote(tx1); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	expect(lifecycle{tx1.Hash(), TxAdded, "", false})
+
+	if err := pool.AddRemote(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	expect(lifecycle{tx0.Hash(), TxAdded, "", false}, lifecycle{tx0.Hash(), TxPromoted, "", false}, lifecycle{tx1.Hash(), TxPromoted, "", false})
+
+	// Replace a pending transaction
+	tx1b := pricedTransaction(1, 100000, big.NewInt(2), key)
+	if err := pool.AddRemote(tx1b); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	expect(lifecycle{tx1.Hash(), TxReplaced, "", false}, lifecycle{tx1b.Hash(), TxAdded, "", false})
+
+	// Use up the nonce of the first transaction and raise the price over the second
+	pool.currentState.SetNonce(account, 1)
+	pool.lockedReset(nil, nil)
+	expect(lifecycle{tx0.Hash(), TxDropped, TxDropNonceTooLow, false})
+
+	pool.SetGasPrice(big.NewInt(3))
+	expect(lifecycle{tx1b.Hash(), TxDropped, TxDropUnderpriced, false})
+
+	// Reach the deadline of the private transaction
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(2), GasLimit: 1000000})
+	expect(lifecycle{private.Hash(), TxDropped, TxDropDeadline, true})
+
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Fatalf("transactions remained: %d pending, %d queued", pending, queued)
+	}
+}
+
+// Tests that the lifecycle events waiting for slow subscribers are capped, dropping
+// the oldest ones.
+func TestTransactionLifecycleEventLimit(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	// Subscribe without ever reading the events to stall the delivery
+	sub := pool.SubscribeTxLifecycleEvent(make(chan NewTxsEvent))
+	defer sub.Unsubscribe()
+
+	pool.mu.Lock()
+	for i := 0; i < 2*maxLifecycleEvents; i++ {
+		pool.notify(TxAdded, "", transaction(uint64(i), 100000, key))
+	}
+	pool.mu.Unlock()
+
+	pool.eventLock.Lock()
+	defer pool.eventLock.Unlock()
+
+	if len(pool.events) > maxLifecycleEvents {
+		t.Fatalf("queued event count mismatch: have %d, want at most %d", len(pool.events), maxLifecycleEvents)
+	}
+	if last := pool.events[len(pool.events)-1].Txs[0].Nonce(); last != 2*maxLifecycleEvents-1 {
+		t.Errorf("last queued event mismatch: have nonce %d, want %d", last, 2*maxLifecycleEvents-1)
+	}
+}
+
+// Tests that the contents of individual accounts can be retrieved from the pool.
+func TestTransactionContentFrom(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupTxPool()
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	pool.AddRemotes(types.Transactions{
+		transaction(0, 100000, keys[0]),
+		transaction(1, 100000, keys[0]),
+		transaction(3, 100000, keys[0]),
+		transaction(1, 100000, keys[1]),
+	})
+	pending, queued := pool.ContentFrom(crypto.PubkeyToAddress(keys[0].PublicKey))
+	if len(pending) != 2 || len(queued) != 1 {
+		t.Errorf("content mismatch: have %d pending, %d queued, want %d, %d", len(pending), len(queued), 2, 1)
+	}
+	pending, queued = pool.ContentFrom(crypto.PubkeyToAddress(keys[2].PublicKey))
+	if len(pending) != 0 || len(queued) != 0 {
+		t.Errorf("content of idle account: have %d pending, %d queued", len(pending), len(queued))
+	}
+	senders := pool.Senders()
+	if len(senders) != 2 {
+		t.Fatalf("sender count mismatch: have %d, want %d", len(senders), 2)
+	}
+	if bytes.Compare(senders[0][:], senders[1][:]) >= 0 {
+		t.Errorf("senders not sorted: %v", senders)
+	}
+}
+
+// Tests that remote transactions are snapshotted on shutdown when remote journaling
+// is enabled, and revalidated with their original arrival times on startup.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the remote journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original pool to inject remote transactions into
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.RemoteJournal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	private, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(private.PublicKey), big.NewInt(1000000000))
+
+	// Add a local and a few pending and queued remote transactions
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), remote),
+		pricedTransaction(1, 100000, big.NewInt(1), remote),
+		pricedTransaction(3, 100000, big.NewInt(1), remote),
+	}
+	for i, err := range pool.AddRemotes(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add remote transaction: %v", i, err)
+		}
+	}
+	arrival := pool.priced.entries[txs[2].Hash()].Arrival
+
+	// Add a private transaction too, which must never end up in the remote journal
+	secret := pricedTransaction(0, 100000, big.NewInt(1), private)
+	if err := pool.AddPrivate(secret, 0); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	// Terminate the old pool, bump the remote nonce, create a new pool and ensure
+	// the still valid remote transactions survive, but the local and private ones do not
+	pool.Stop()
+	statedb.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 1)
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if pool.Get(txs[0].Hash()) != nil {
+		t.Errorf("stale remote transaction reloaded")
+	}
+	if pool.Get(secret.Hash()) != nil {
+		t.Errorf("private transaction reloaded as remote")
+	}
+	if entry := pool.priced.entries[txs[2].Hash()]; entry == nil || !entry.Arrival.Equal(arrival) {
+		t.Errorf("arrival time mismatch: have %v, want %v", entry, arrival)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that private transactions are tracked as such, survive restarts via the
+// journal along with their deadlines and are dropped once they expire.
+func TestTransactionPrivate(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original pool to inject private transactions into
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	events := make(chan NewTxsEvent, 8)
+	sub := pool.SubscribeNewTxsEvent(events)
+	defer sub.Unsubscribe()
+
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Add a public and two private transactions with different deadlines
+	public := pricedTransaction(0, 100000, big.NewInt(1), keys[0])
+	if err := pool.AddLocal(public); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	short := pricedTransaction(1, 100000, big.NewInt(1), keys[0])
+	if err := pool.AddPrivate(short, 3); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	long := pricedTransaction(0, 100000, big.NewInt(1), keys[1])
+	if err := pool.AddPrivate(long, 0); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(public, 0); err == nil {
+		t.Fatalf("known public transaction made private")
+	}
+	check := func(pool *TxPool, private types.Transactions, public types.Transactions) {
+		for i, tx := range private {
+			if !pool.IsPrivate(tx.Hash()) {
+				t.Errorf("private tx %d: not private", i)
+			}
+		}
+		for i, tx := range public {
+			if pool.IsPrivate(tx.Hash()) {
+				t.Errorf("public tx %d: private", i)
+			}
+		}
+		if filtered := pool.FilterPrivate(append(append(types.Transactions{}, private...), public...)); len(filtered) != len(public) {
+			t.Errorf("filtered transaction count mismatch: have %d, want %d", len(filtered), len(public))
+		}
+		// Private transactions must not turn their senders into local accounts
+		if pool.locals.contains(crypto.PubkeyToAddress(keys[1].PublicKey)) {
+			t.Errorf("private sender marked local")
+		}
+		// Private transactions must not be disclosed by the content queries
+		content := make(map[common.Hash]bool)
+		pending, queued := pool.Content()
+		for _, batches := range []map[common.Address]types.Transactions{pending, queued} {
+			for _, txs := range batches {
+				for _, tx := range txs {
+					content[tx.Hash()] = true
+				}
+			}
+		}
+		for i, tx := range private {
+			if content[tx.Hash()] {
+				t.Errorf("private tx %d: disclosed in content", i)
+			}
+		}
+		for i, tx := range public {
+			if pool.Get(tx.Hash()) != nil && !content[tx.Hash()] {
+				t.Errorf("public tx %d: missing from content", i)
+			}
+		}
+		if pending, queued := pool.ContentFrom(crypto.PubkeyToAddress(keys[1].PublicKey)); len(pending)+len(queued) != 0 {
+			t.Errorf("private content disclosed: %d pending, %d queued", len(pending), len(queued))
+		}
+		for _, sender := range pool.Senders() {
+			if sender == crypto.PubkeyToAddress(keys[1].PublicKey) {
+				t.Errorf("private sender disclosed")
+			}
+		}
+		if err := validateTxPoolInternals(pool); err != nil {
+			t.Fatalf("pool internal state corrupted: %v", err)
+		}
+	}
+	check(pool, types.Transactions{short, long}, types.Transactions{public})
+
+	// Ensure the announcements flag the private transactions, independent of the
+	// markers still being around by the time they are delivered
+	private := map[common.Hash]bool{public.Hash(): false, short.Hash(): true, long.Hash(): true}
+	for i := 0; i < len(private); i++ {
+		select {
+		case ev := <-events:
+			for _, tx := range ev.Txs {
+				if want, ok := private[tx.Hash()]; !ok || ev.Private != want {
+					t.Errorf("tx %x: private flag mismatch: have %v, want %v", tx.Hash(), ev.Private, want)
+				}
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: announcement timeout", i)
+		}
+	}
+	// Restart the pool and ensure the private markers survive
+	pool.Stop()
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	check(pool, types.Transactions{short, long}, types.Transactions{public})
+
+	// Reach the deadline of the short lived transaction and ensure it's dropped
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(3), GasLimit: 1000000})
+
+	if pool.Get(short.Hash()) != nil {
+		t.Errorf("expired private transaction not dropped")
+	}
+	if pool.Get(public.Hash()) == nil || pool.Get(long.Hash()) == nil {
+		t.Errorf("live transactions dropped")
+	}
+	check(pool, types.Transactions{long}, types.Transactions{public, short})
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
 	t.Parallel()
 
 	// Create the pool to test the status retrievals with
-	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
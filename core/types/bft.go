@@ -0,0 +1,106 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"io"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/rlp"
+)
+
+var (
+	// BFTDigest is the fixed mix digest of blocks sealed by the BFT consensus
+	// engine, identifying headers whose hash must ignore the committed seals.
+	BFTDigest = common.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
+
+	// ErrInvalidBFTHeaderExtra is returned if the extra-data of a header can't be
+	// decoded as BFT extra-data.
+	ErrInvalidBFTHeaderExtra = errors.New("invalid bft header extra-data")
+)
+
+const (
+	BFTExtraVanity = 32 // Fixed number of extra-data prefix bytes reserved for proposer vanity
+	BFTExtraSeal   = 65 // Fixed number of bytes of a proposer or committed seal
+)
+
+// BFTExtra is the consensus section of the extra-data of BFT sealed headers,
+// following the vanity prefix.
+type BFTExtra struct {
+	Validators    []common.Address // Validator set, only present on checkpoint blocks
+	Seal          []byte           // Signature of the proposer over the block
+	CommittedSeal [][]byte         // Signatures of the validators committing the block
+}
+
+// EncodeRLP serializes the BFT extra-data into the VSportChain RLP format.
+func (e *BFTExtra) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []interface{}{
+		e.Validators,
+		e.Seal,
+		e.CommittedSeal,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder, and loads the BFT extra-data fields from a
+// RLP stream.
+func (e *BFTExtra) DecodeRLP(s *rlp.Stream) error {
+	var extra struct {
+		Validators    []common.Address
+		Seal          []byte
+		CommittedSeal [][]byte
+	}
+	if err := s.Decode(&extra); err != nil {
+		return err
+	}
+	e.Validators, e.Seal, e.CommittedSeal = extra.Validators, extra.Seal, extra.CommittedSeal
+	return nil
+}
+
+// ExtractBFTExtra decodes the consensus section of a BFT header's extra-data.
+func ExtractBFTExtra(h *Header) (*BFTExtra, error) {
+	if len(h.Extra) < BFTExtraVanity {
+		return nil, ErrInvalidBFTHeaderExtra
+	}
+	extra := new(BFTExtra)
+	if err := rlp.DecodeBytes(h.Extra[BFTExtraVanity:], extra); err != nil {
+		return nil, ErrInvalidBFTHeaderExtra
+	}
+	return extra, nil
+}
+
+// BFTFilteredHeader returns a copy of a BFT header with the committed seals, and
+// optionally the proposer seal, removed from its extra-data. It returns nil if
+// the extra-data can't be decoded.
+func BFTFilteredHeader(h *Header, keepSeal bool) *Header {
+	extra, err := ExtractBFTExtra(h)
+	if err != nil {
+		return nil
+	}
+	if !keepSeal {
+		extra.Seal = []byte{}
+	}
+	extra.CommittedSeal = [][]byte{}
+
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return nil
+	}
+	cpy := CopyHeader(h)
+	cpy.Extra = append(cpy.Extra[:BFTExtraVanity:BFTExtraVanity], payload...)
+	return cpy
+}
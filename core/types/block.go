@@ -97,8 +97,15 @@ type headerMarshaling struct {
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
-// RLP encoding.
+// RLP encoding. For BFT sealed headers the committed seals are left out.
 func (h *Header) Hash() common.Hash {
+	// BFT blocks are committed by collecting validator seals over the block hash,
+	// so the hash can't cover them: each node may gather a different quorum.
+	if h.MixDigest == BFTDigest {
+		if filtered := BFTFilteredHeader(h, true); filtered != nil {
+			return rlpHash(filtered)
+		}
+	}
 	return rlpHash(h)
 }
 
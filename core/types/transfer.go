@@ -0,0 +1,35 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/vsportchain/go-vsc/common"
+)
+
+// InternalTransfer represents a value transfer made by a contract during the
+// execution of a transaction. These are not visible in the logs, but are
+// recorded and indexed by the node if requested.
+type InternalTransfer struct {
+	TxHash  common.Hash    // Hash of the transaction making the transfer
+	TxIndex uint           // Index of the transaction in the block
+	Type    string         // Opcode making the transfer (CALL, CREATE or SELFDESTRUCT)
+	From    common.Address // Contract sending the value
+	To      common.Address // Account receiving the value
+	Value   *big.Int       // Amount of value transferred
+}
@@ -18,30 +18,79 @@ package vm
 
 import (
 	"math/big"
+	"sync/atomic"
 
+	"github.com/hashicorp/golang-lru"
 	"github.com/vsportchain/go-vsc/common"
 )
 
+const (
+	// analysisCacheSize is the number of contracts whose JUMPDEST analysis is
+	// retained across EVM instances.
+	analysisCacheSize = 4096
+
+	// decodedCacheSize is the maximum number of contracts whose pre-decoded
+	// instruction stream is retained across EVM instances.
+	decodedCacheSize = 4096
+
+	// decodedCacheLimit is the approximate memory allowance in bytes of all the
+	// pre-decoded instruction streams retained across EVM instances.
+	decodedCacheLimit = 32 * 1024 * 1024
+)
+
+var (
+	analysisCache, _ = lru.New(analysisCacheSize)                       // JUMPDEST analysis of recently executed contracts
+	decodedCache, _  = lru.NewWithEvict(decodedCacheSize, evictDecoded) // Pre-decoded code of recently executed contracts
+
+	decodedBytes int64 // Approximate memory used by decodedCache (atomic access)
+)
+
+// evictDecoded releases the memory allowance of an evicted decoded code.
+func evictDecoded(key, value interface{}) {
+	atomic.AddInt64(&decodedBytes, -int64(value.(decodedCode).size()))
+}
+
 // destinations stores one map per contract (keyed by hash of code).
 // The maps contain an entry for each location of a JUMPDEST
 // instruction.
 type destinations map[common.Hash]bitvec
 
-// has checks whether code has a JUMPDEST at dest.
-func (d destinations) has(codehash common.Hash, code []byte, dest *big.Int) bool {
-	// PC cannot go beyond len(code) and certainly can't be bigger than 63bits.
-	// Don't bother checking for JUMPDEST in that case.
-	udest := dest.Uint64()
-	if dest.BitLen() >= 63 || udest >= uint64(len(code)) {
-		return false
-	}
-
+// analysis returns the JUMPDEST analysis of the code, analysing it only once
+// within the context of a transaction.
+func (d destinations) analysis(codehash common.Hash, code []byte) bitvec {
 	m, analysed := d[codehash]
 	if !analysed {
-		m = codeBitmap(code)
+		m = analyse(codehash, code)
 		d[codehash] = m
 	}
-	return OpCode(code[udest]) == JUMPDEST && m.codeSegment(udest)
+	return m
+}
+
+// analyse returns the JUMPDEST analysis of the given code, reusing the analysis
+// done by any previous EVM instance if the code was executed recently.
+func analyse(codehash common.Hash, code []byte) bitvec {
+	// Code without a known hash can't be cached, analyse it on the fly
+	if codehash == (common.Hash{}) {
+		return codeBitmap(code)
+	}
+	// The code hash might not belong to the code (e.g. a caller supplied hash),
+	// so only use the cached analysis if it was done for code of the same size
+	if cached, ok := analysisCache.Get(codehash); ok {
+		if analysis := cached.(*codeAnalysis); analysis.size == len(code) {
+			return analysis.bits
+		}
+		return codeBitmap(code)
+	}
+	bits := codeBitmap(code)
+	analysisCache.Add(codehash, &codeAnalysis{size: len(code), bits: bits})
+	return bits
+}
+
+// codeAnalysis is a cached JUMPDEST analysis along with the size of the code it
+// was done for.
+type codeAnalysis struct {
+	size int
+	bits bitvec
 }
 
 // bitvec is a bit vector which maps bytes in a program.
@@ -88,3 +137,75 @@ func codeBitmap(code []byte) bitvec {
 	}
 	return bits
 }
+
+// decodedCode is the pre-decoded instruction stream of a contract, indexed by
+// program counter. The operands of the PUSH instructions are fused into their
+// opcodes, so they don't need to be parsed from the code on every execution.
+// Positions not holding a PUSH instruction are nil.
+//
+// The operands are shared across EVM instances and must never be modified.
+type decodedCode []*big.Int
+
+// decode returns the pre-decoded instruction stream of the given code, reusing
+// the one decoded by any previous EVM instance if the code was executed recently.
+func decode(codehash common.Hash, code []byte) decodedCode {
+	// Code without a known hash can't be cached, decode it on the fly
+	if codehash == (common.Hash{}) {
+		return decodeCode(code)
+	}
+	// The code hash might not belong to the code (e.g. a caller supplied hash),
+	// so only use the cached stream if it was decoded from code of the same size
+	if cached, ok := decodedCache.Get(codehash); ok {
+		if ops := cached.(decodedCode); len(ops) == len(code) {
+			return ops
+		}
+		return decodeCode(code)
+	}
+	ops := decodeCode(code)
+	if exists, _ := decodedCache.ContainsOrAdd(codehash, ops); !exists {
+		// Drop the least recently used streams until the cache fits its allowance
+		atomic.AddInt64(&decodedBytes, int64(ops.size()))
+		for atomic.LoadInt64(&decodedBytes) > decodedCacheLimit && decodedCache.Len() > 0 {
+			decodedCache.RemoveOldest()
+		}
+	}
+	return ops
+}
+
+// size returns the approximate memory used by the decoded instruction stream,
+// counting the stream itself and the big integers of the operands.
+func (ops decodedCode) size() int {
+	size := len(ops) * 8
+	for _, op := range ops {
+		if op != nil {
+			size += 32 + len(op.Bits())*8
+		}
+	}
+	return size
+}
+
+// decodeCode parses the operands of all the PUSH instructions in the code. The
+// operands running past the end of the code are right padded with zeroes.
+func decodeCode(code []byte) decodedCode {
+	ops := make(decodedCode, len(code))
+	for pc := 0; pc < len(code); {
+		op := OpCode(code[pc])
+
+		if op >= PUSH1 && op <= PUSH32 {
+			size := int(op - PUSH1 + 1)
+
+			start, end := pc+1, pc+1+size
+			if start > len(code) {
+				start = len(code)
+			}
+			if end > len(code) {
+				end = len(code)
+			}
+			ops[pc] = new(big.Int).SetBytes(common.RightPadBytes(code[start:end], size))
+			pc += size + 1
+		} else {
+			pc++
+		}
+	}
+	return ops
+}
@@ -16,7 +16,14 @@
 
 package vm
 
-import "testing"
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/crypto"
+)
 
 func TestJumpDestAnalysis(t *testing.T) {
 	tests := []struct {
@@ -51,3 +58,101 @@ func TestJumpDestAnalysis(t *testing.T) {
 	}
 
 }
+
+func TestDecodeCode(t *testing.T) {
+	code := []byte{byte(PUSH2), 0x01, 0x02, byte(ADD), byte(PUSH1), byte(PUSH1), byte(JUMPDEST), byte(PUSH3), 0x03}
+	ops := decodeCode(code)
+
+	want := map[int]*big.Int{
+		0: big.NewInt(0x0102),
+		4: big.NewInt(int64(PUSH1)),
+		7: big.NewInt(0x030000), // truncated operand, right padded
+	}
+	for pc, op := range ops {
+		if want[pc] == nil {
+			if op != nil {
+				t.Errorf("pc %d: unexpected operand %v", pc, op)
+			}
+			continue
+		}
+		if op == nil || op.Cmp(want[pc]) != 0 {
+			t.Errorf("pc %d: operand mismatch: have %v, want %v", pc, op, want[pc])
+		}
+	}
+}
+
+// benchmarkCode returns a contract code of maximum size, mostly made of PUSH
+// instructions as that's the worst case for the JUMPDEST analysis.
+func benchmarkCode() []byte {
+	code := make([]byte, 0, 24576)
+	for len(code) < cap(code)-33 {
+		code = append(code, byte(PUSH32))
+		code = append(code, make([]byte, 32)...)
+		code = append(code, byte(JUMPDEST))
+	}
+	return code
+}
+
+// Tests that the shared caches don't serve entries created for a different code
+// under the same hash, and that the decoded code cache tracks its memory use.
+func TestCodeCacheMismatch(t *testing.T) {
+	var (
+		hash  = common.Hash{0x01}
+		short = []byte{byte(PUSH1), 0x01}
+		long  = []byte{byte(PUSH1), 0x01, byte(PUSH2), 0x02, 0x03, byte(JUMPDEST)}
+	)
+	defer decodedCache.Remove(hash)
+	defer analysisCache.Remove(hash)
+
+	analyse(hash, short)
+	if bits := analyse(hash, long); len(bits) != len(codeBitmap(long)) || bits.codeSegment(3) {
+		t.Errorf("stale analysis served for mismatching code")
+	}
+	before := atomic.LoadInt64(&decodedBytes)
+
+	decode(hash, short)
+	if ops := decode(hash, long); len(ops) != len(long) || ops[2].Cmp(big.NewInt(0x0203)) != 0 {
+		t.Errorf("stale decoded code served for mismatching code")
+	}
+	if have, want := atomic.LoadInt64(&decodedBytes)-before, int64(decodeCode(short).size()); have != want {
+		t.Errorf("decoded cache size mismatch: have %d, want %d", have, want)
+	}
+	decodedCache.Remove(hash)
+	if have := atomic.LoadInt64(&decodedBytes); have != before {
+		t.Errorf("decoded cache size not released: have %d, want %d", have, before)
+	}
+}
+
+func BenchmarkJumpdestAnalysis(b *testing.B) {
+	code := benchmarkCode()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		codeBitmap(code)
+	}
+}
+
+func BenchmarkJumpdestCachedAnalysis(b *testing.B) {
+	code := benchmarkCode()
+	hash := crypto.Keccak256Hash(code)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Every call runs with its own destinations, hitting the shared cache
+		if bits := make(destinations).analysis(hash, code); !bits.codeSegment(33) {
+			b.Fatal("jump destination not found")
+		}
+	}
+}
+
+func BenchmarkJumpdestUnhashedAnalysis(b *testing.B) {
+	code := benchmarkCode()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Code without a hash is analysed on every call
+		if bits := make(destinations).analysis(common.Hash{}, code); !bits.codeSegment(33) {
+			b.Fatal("jump destination not found")
+		}
+	}
+}
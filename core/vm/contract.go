@@ -50,6 +50,8 @@ type Contract struct {
 	self          ContractRef
 
 	jumpdests destinations // result of JUMPDEST analysis.
+	analysis  bitvec       // JUMPDEST analysis of the code, once the first jump is made
+	decoded   decodedCode  // pre-decoded instruction stream, nil if disabled
 
 	Code     []byte
 	CodeHash common.Hash
@@ -84,6 +86,25 @@ func NewContract(caller ContractRef, object ContractRef, value *big.Int, gas uin
 	return c
 }
 
+// validJumpdest checks whether the code has a JUMPDEST at dest.
+func (c *Contract) validJumpdest(dest *big.Int) bool {
+	// PC cannot go beyond len(code) and certainly can't be bigger than 63bits.
+	// Don't bother checking for JUMPDEST in that case.
+	udest := dest.Uint64()
+	if dest.BitLen() >= 63 || udest >= uint64(len(c.Code)) {
+		return false
+	}
+	// Only JUMPDESTs allowed for destinations
+	if OpCode(c.Code[udest]) != JUMPDEST {
+		return false
+	}
+	// Retrieve the code analysis on the first jump, reusing it afterwards
+	if c.analysis == nil {
+		c.analysis = c.jumpdests.analysis(c.CodeHash, c.Code)
+	}
+	return c.analysis.codeSegment(udest)
+}
+
 // AsDelegate sets the contract to be a delegate call and returns the current
 // contract (for chaining calls)
 func (c *Contract) AsDelegate() *Contract {
@@ -26,6 +26,7 @@ import (
 	"github.com/vsportchain/go-vsc/crypto"
 	"github.com/vsportchain/go-vsc/crypto/bn256"
 	"github.com/vsportchain/go-vsc/params"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -62,10 +63,17 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	gas := p.RequiredGas(input)
-	if contract.UseGas(gas) {
-		return p.Run(input)
+	if !contract.UseGas(gas) {
+		return nil, ErrOutOfGas
 	}
-	return nil, ErrOutOfGas
+	if m, ok := p.(MeteredPrecompiledContract); ok {
+		ret, used, err := m.RunMetered(input, contract.Gas)
+		if !contract.UseGas(used) {
+			return nil, ErrOutOfGas
+		}
+		return ret, err
+	}
+	return p.Run(input)
 }
 
 // ECRECOVER implemented as a native contract.
@@ -358,3 +366,30 @@ func (c *bn256Pairing) Run(input []byte) ([]byte, error) {
 	}
 	return false32Byte, nil
 }
+
+// ed25519Verify implements an ed25519 signature verification native contract,
+// available to chain configs under the name "ed25519".
+type ed25519Verify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *ed25519Verify) RequiredGas(input []byte) uint64 {
+	return uint64(len(input)+31)/32*params.Ed25519VerifyPerWordGas + params.Ed25519VerifyBaseGas
+}
+
+// Run verifies the signature of the input, which is (pubkey, signature, message)
+// with the key being 32 bytes and the signature 64 bytes. Malformed input is
+// treated as an invalid signature.
+func (c *ed25519Verify) Run(input []byte) ([]byte, error) {
+	if len(input) < ed25519.PublicKeySize+ed25519.SignatureSize {
+		return false32Byte, nil
+	}
+	var (
+		pubkey = ed25519.PublicKey(input[:ed25519.PublicKeySize])
+		sig    = input[ed25519.PublicKeySize : ed25519.PublicKeySize+ed25519.SignatureSize]
+		msg    = input[ed25519.PublicKeySize+ed25519.SignatureSize:]
+	)
+	if ed25519.Verify(pubkey, msg, sig) {
+		return true32Byte, nil
+	}
+	return false32Byte, nil
+}
@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -336,8 +338,40 @@ var bn256PairingTests = []precompiledTest{
 	},
 }
 
+// ed25519Tests are the test and benchmark data for the ed25519 precompiled contract,
+// taken from RFC 8032.
+var ed25519Tests = []precompiledTest{
+	{
+		input: "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" +
+			"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		expected: "0000000000000000000000000000000000000000000000000000000000000001",
+		name:     "rfc8032_1",
+	}, {
+		input: "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c" +
+			"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00" +
+			"72",
+		expected: "0000000000000000000000000000000000000000000000000000000000000001",
+		name:     "rfc8032_2",
+	}, {
+		input: "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c" +
+			"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00" +
+			"73",
+		expected:    "0000000000000000000000000000000000000000000000000000000000000000",
+		name:        "wrong_message",
+		noBenchmark: true,
+	}, {
+		input:       "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		expected:    "0000000000000000000000000000000000000000000000000000000000000000",
+		name:        "short_input",
+		noBenchmark: true,
+	},
+}
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	testPrecompiledContract(PrecompiledContractsByzantium[common.HexToAddress(addr)], test, t)
+}
+
+func testPrecompiledContract(p PrecompiledContract, test precompiledTest, t *testing.T) {
 	in := common.Hex2Bytes(test.input)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
 		nil, new(big.Int), p.RequiredGas(in))
@@ -351,10 +385,13 @@ func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
 }
 
 func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
+	benchmarkPrecompiledContract(PrecompiledContractsByzantium[common.HexToAddress(addr)], test, bench)
+}
+
+func benchmarkPrecompiledContract(p PrecompiledContract, test precompiledTest, bench *testing.B) {
 	if test.noBenchmark {
 		return
 	}
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
 	reqGas := p.RequiredGas(in)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
@@ -481,3 +518,114 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+// Tests the sample inputs from RFC 8032 against the ed25519 precompiled contract.
+func TestPrecompiledEd25519(t *testing.T) {
+	for _, test := range ed25519Tests {
+		testPrecompiledContract(&ed25519Verify{}, test, t)
+	}
+}
+
+// Benchmarks the sample inputs from RFC 8032 against the ed25519 precompiled contract.
+func BenchmarkPrecompiledEd25519(bench *testing.B) {
+	for _, test := range ed25519Tests {
+		benchmarkPrecompiledContract(&ed25519Verify{}, test, bench)
+	}
+}
+
+// meteredEcho is a metered test precompile returning its input and charging one
+// gas per byte while running.
+type meteredEcho struct{}
+
+func (c *meteredEcho) RequiredGas(input []byte) uint64 { return 10 }
+func (c *meteredEcho) Run(input []byte) ([]byte, error) {
+	ret, _, err := c.RunMetered(input, 0)
+	return ret, err
+}
+func (c *meteredEcho) RunMetered(input []byte, gas uint64) ([]byte, uint64, error) {
+	return input, uint64(len(input)), nil
+}
+
+func init() {
+	RegisterPrecompile("test-echo", func(*params.PrecompileConfig) (PrecompiledContract, error) {
+		return &meteredEcho{}, nil
+	})
+}
+
+// Tests that chain specific precompiles are activated at their configured block.
+func TestActivePrecompiles(t *testing.T) {
+	var (
+		echo   = common.BytesToAddress([]byte{0x01, 0x00})
+		ed     = common.BytesToAddress([]byte{0x01, 0x01})
+		config = &params.ChainConfig{
+			ByzantiumBlock: big.NewInt(0),
+			Precompiles: map[common.Address]*params.PrecompileConfig{
+				echo: {Name: "test-echo", Block: big.NewInt(5)},
+				ed:   {Name: "ed25519", Block: big.NewInt(10), Gas: &params.PrecompileGasConfig{Base: 100, PerWord: 1}},
+			},
+		}
+	)
+	if err := CheckPrecompiles(config); err != nil {
+		t.Fatalf("failed to check precompiles: %v", err)
+	}
+	tests := []struct {
+		number   int64
+		echo, ed bool
+	}{
+		{0, false, false},
+		{5, true, false},
+		{10, true, true},
+	}
+	for _, tt := range tests {
+		evm := NewEVM(Context{BlockNumber: big.NewInt(tt.number)}, nil, config, Config{})
+		if !evm.IsPrecompiled(common.BytesToAddress([]byte{8})) {
+			t.Errorf("block %d: protocol precompile inactive", tt.number)
+		}
+		if have := evm.IsPrecompiled(echo); have != tt.echo {
+			t.Errorf("block %d: echo precompile active mismatch: have %v, want %v", tt.number, have, tt.echo)
+		}
+		if have := evm.IsPrecompiled(ed); have != tt.ed {
+			t.Errorf("block %d: ed25519 precompile active mismatch: have %v, want %v", tt.number, have, tt.ed)
+		}
+	}
+	// Check that the configured gas schedule overrides the implementation's
+	p := ActivePrecompiles(config, big.NewInt(10))[ed]
+	if gas := p.RequiredGas(make([]byte, 96)); gas != 103 {
+		t.Errorf("repriced gas mismatch: have %d, want %d", gas, 103)
+	}
+	// Check that the configured gas schedule saturates instead of overflowing
+	pricey := &repricedContract{p, &params.PrecompileGasConfig{Base: 1, PerWord: math.MaxInt64}}
+	if gas := pricey.RequiredGas(make([]byte, 96)); gas != math.MaxUint64 {
+		t.Errorf("overflowing repriced gas mismatch: have %d, want %d", gas, uint64(math.MaxUint64))
+	}
+	// Check that metered precompiles are charged for the gas used while running
+	input := make([]byte, 20)
+	contract := NewContract(AccountRef(common.Address{}), nil, new(big.Int), 100)
+	if _, err := RunPrecompiledContract(ActivePrecompiles(config, big.NewInt(10))[echo], input, contract); err != nil {
+		t.Fatalf("failed to run metered precompile: %v", err)
+	}
+	if contract.Gas != 70 {
+		t.Errorf("metered gas left mismatch: have %d, want %d", contract.Gas, 70)
+	}
+	contract = NewContract(AccountRef(common.Address{}), nil, new(big.Int), 25)
+	if _, err := RunPrecompiledContract(ActivePrecompiles(config, big.NewInt(10))[echo], input, contract); err != ErrOutOfGas {
+		t.Errorf("metered precompile error mismatch: have %v, want %v", err, ErrOutOfGas)
+	}
+}
+
+// Tests that invalid precompile configurations are rejected.
+func TestCheckPrecompiles(t *testing.T) {
+	tests := map[common.Address]*params.PrecompileConfig{
+		common.BytesToAddress([]byte{0x01, 0x00}): {Name: "unknown", Block: big.NewInt(0)},
+		common.BytesToAddress([]byte{0x01}):       {Name: "ed25519", Block: big.NewInt(0)},
+		common.BytesToAddress([]byte{0x01, 0x01}): nil,
+		common.BytesToAddress([]byte{0x01, 0x02}): {Name: "ed25519", Block: big.NewInt(0), Gas: &params.PrecompileGasConfig{Base: math.MaxUint64}},
+		common.BytesToAddress([]byte{0x01, 0x03}): {Name: "ed25519", Block: big.NewInt(0), Gas: &params.PrecompileGasConfig{PerWord: math.MaxInt64 + 1}},
+	}
+	for addr, precompile := range tests {
+		config := &params.ChainConfig{Precompiles: map[common.Address]*params.PrecompileConfig{addr: precompile}}
+		if err := CheckPrecompiles(config); err == nil {
+			t.Errorf("precompile %x: expected error", addr)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+)
+
+var (
+	// ErrDebuggerFinished is returned when trying to resume or inspect the
+	// execution of a debugger which has already run to completion.
+	ErrDebuggerFinished = errors.New("execution finished")
+
+	// errDebuggerStopped is returned by the tracer hooks of a debugger which was
+	// stopped before the execution finished, aborting it.
+	errDebuggerStopped = errors.New("debugger stopped")
+)
+
+// Breakpoint decides whether a Debugger should pause the execution before
+// running the operation at the given program counter and call depth.
+type Breakpoint func(pc uint64, op OpCode, depth int) bool
+
+// StepBreakpoint pauses before every operation.
+func StepBreakpoint(pc uint64, op OpCode, depth int) bool { return true }
+
+// PcBreakpoint pauses before running the operation at the given program counter.
+func PcBreakpoint(target uint64) Breakpoint {
+	return func(pc uint64, op OpCode, depth int) bool { return pc == target }
+}
+
+// OpBreakpoint pauses before running any operation of the given type.
+func OpBreakpoint(target OpCode) Breakpoint {
+	return func(pc uint64, op OpCode, depth int) bool { return op == target }
+}
+
+// DepthBreakpoint pauses before running any operation at the given call depth.
+func DepthBreakpoint(target int) Breakpoint {
+	return func(pc uint64, op OpCode, depth int) bool { return depth == target }
+}
+
+// DebugResult contains the outcome of an execution run by a Debugger.
+type DebugResult struct {
+	Output  []byte
+	GasUsed uint64
+	Time    time.Duration
+	Err     error
+}
+
+// Debugger is an EVM Tracer pausing the execution at breakpoints, allowing the
+// state of the virtual machine to be inspected step by step.
+//
+// The execution itself runs on a background goroutine started by Start, while
+// the methods of the debugger are meant to be called from a single controlling
+// goroutine, apart from Interrupt. Inspection is only possible while the
+// execution is paused.
+type Debugger struct {
+	breakpoint Breakpoint // Breakpoint to pause the execution at, nil when stopped
+
+	paused chan *StructLog // Channel to report the step the execution paused at
+	resume chan Breakpoint // Channel to resume the execution until the next breakpoint
+	done   chan struct{}   // Channel closed when the execution finished
+	stop   sync.Once       // Ensures the execution is only aborted once
+	step   *StructLog      // Step the execution is currently paused at
+	env    *EVM            // Environment of the paused execution
+	addr   common.Address  // Address of the contract the execution is paused in
+	result *DebugResult    // Outcome of the execution once finished
+	err    error           // Error returned by the executing function, if any
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Reason for the interruption
+}
+
+// NewDebugger creates a new step debugger, pausing before the first operation.
+func NewDebugger() *Debugger {
+	return &Debugger{
+		breakpoint: StepBreakpoint,
+		paused:     make(chan *StructLog),
+		resume:     make(chan Breakpoint),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs fn on a background goroutine and waits for the execution to pause
+// at the first operation. The function is expected to run an EVM configured to
+// use the debugger as its tracer. If the execution finishes without running any
+// operation, ErrDebuggerFinished is returned.
+func (d *Debugger) Start(fn func() error) (*StructLog, error) {
+	go func() {
+		defer close(d.done)
+		d.err = fn()
+	}()
+	return d.wait()
+}
+
+// Step resumes the execution for a single operation.
+func (d *Debugger) Step() (*StructLog, error) {
+	return d.Continue(StepBreakpoint)
+}
+
+// Continue resumes the execution until the breakpoint hits. If the execution
+// finishes instead, ErrDebuggerFinished is returned.
+func (d *Debugger) Continue(breakpoint Breakpoint) (*StructLog, error) {
+	if d.step == nil {
+		return nil, ErrDebuggerFinished
+	}
+	d.step, d.env = nil, nil
+	d.resume <- breakpoint
+	return d.wait()
+}
+
+// wait blocks until the execution pauses or finishes.
+func (d *Debugger) wait() (*StructLog, error) {
+	select {
+	case step := <-d.paused:
+		d.step = step
+		return step, nil
+	case <-d.done:
+		if atomic.LoadUint32(&d.interrupt) > 0 {
+			return nil, d.reason
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, ErrDebuggerFinished
+	}
+}
+
+// Interrupt aborts a running execution before the next operation, making the
+// pending Start, Step or Continue return the given error. Contrary to the other
+// methods, it may be called concurrently.
+func (d *Debugger) Interrupt(err error) {
+	d.reason = err
+	atomic.StoreUint32(&d.interrupt, 1)
+}
+
+// Stop aborts a paused execution and waits for it to terminate. It is a noop if
+// the execution already finished. The debugger must have been started.
+func (d *Debugger) Stop() {
+	d.stop.Do(func() { close(d.resume) })
+	<-d.done
+	d.step, d.env = nil, nil
+}
+
+// Current returns the step the execution is paused at, or nil if it finished.
+func (d *Debugger) Current() *StructLog {
+	return d.step
+}
+
+// Contract returns the address of the contract the execution is paused in.
+func (d *Debugger) Contract() (common.Address, error) {
+	if d.step == nil {
+		return common.Address{}, ErrDebuggerFinished
+	}
+	return d.addr, nil
+}
+
+// Storage returns the value of a storage slot of the contract the execution is
+// paused in, as seen by the paused operation.
+func (d *Debugger) Storage(key common.Hash) (common.Hash, error) {
+	if d.step == nil {
+		return common.Hash{}, ErrDebuggerFinished
+	}
+	return d.env.StateDB.GetState(d.addr, key), nil
+}
+
+// Result returns the outcome of the execution, or nil if it did not finish yet.
+func (d *Debugger) Result() *DebugResult {
+	select {
+	case <-d.done:
+		return d.result
+	default:
+		return nil
+	}
+}
+
+// CaptureStart implements the Tracer interface, doing nothing.
+func (d *Debugger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements the Tracer interface, pausing the execution if the
+// current breakpoint hits.
+func (d *Debugger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	if d.breakpoint == nil {
+		return errDebuggerStopped
+	}
+	if atomic.LoadUint32(&d.interrupt) > 0 {
+		// The debugger was interrupted, abort the execution
+		d.breakpoint = nil
+		env.Cancel()
+		return errDebuggerStopped
+	}
+	if !d.breakpoint(pc, op, depth) {
+		return nil
+	}
+	// Breakpoint hit, snapshot the current step and wait for instructions
+	step := &StructLog{
+		Pc:         pc,
+		Op:         op,
+		Gas:        gas,
+		GasCost:    cost,
+		Memory:     make([]byte, len(memory.Data())),
+		MemorySize: memory.Len(),
+		Stack:      make([]*big.Int, len(stack.Data())),
+		Depth:      depth,
+		Err:        err,
+	}
+	copy(step.Memory, memory.Data())
+	for i, item := range stack.Data() {
+		step.Stack[i] = new(big.Int).Set(item)
+	}
+	d.env, d.addr = env, contract.Address()
+	d.paused <- step
+
+	breakpoint, ok := <-d.resume
+	if !ok {
+		// The debugger was stopped, abort the execution
+		d.breakpoint = nil
+		env.Cancel()
+		return errDebuggerStopped
+	}
+	d.breakpoint = breakpoint
+	return nil
+}
+
+// CaptureFault implements the Tracer interface, doing nothing. Faults are
+// reported through the result of the execution.
+func (d *Debugger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface, recording the outcome of the
+// execution.
+func (d *Debugger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	d.result = &DebugResult{Output: common.CopyBytes(output), GasUsed: gasUsed, Time: t, Err: err}
+	return nil
+}
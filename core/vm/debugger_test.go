@@ -0,0 +1,67 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// Tests that a debugger running towards a breakpoint that never hits can be
+// interrupted from another goroutine.
+func TestDebuggerInterrupt(t *testing.T) {
+	// Deploy an endless loop: JUMPDEST, PUSH1 0, JUMP
+	address := common.BytesToAddress([]byte("contract"))
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, hexutil.MustDecode("0x5b600056"))
+
+	debugger := NewDebugger()
+	vmctx := Context{
+		BlockNumber: new(big.Int),
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	vmenv := NewEVM(vmctx, statedb, params.AllEthashProtocolChanges, Config{Debug: true, Tracer: debugger})
+
+	if _, err := debugger.Start(func() error {
+		_, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, math.MaxUint64/2, new(big.Int))
+		return err
+	}); err != nil {
+		t.Fatalf("failed to start execution: %v", err)
+	}
+	reason := errors.New("interrupted")
+	time.AfterFunc(50*time.Millisecond, func() { debugger.Interrupt(reason) })
+
+	if _, err := debugger.Continue(PcBreakpoint(100)); err != reason {
+		t.Fatalf("error mismatch: have %v, want %v", err, reason)
+	}
+	if step := debugger.Current(); step != nil {
+		t.Errorf("interrupted execution paused at pc %d", step.Pc)
+	}
+	debugger.Stop()
+}
@@ -16,7 +16,16 @@
 
 package vm
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
 
 func TestMemoryGasCost(t *testing.T) {
 	//size := uint64(math.MaxUint64 - 64)
@@ -34,3 +43,68 @@ func TestMemoryGasCost(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+var eip1283Tests = []struct {
+	original byte
+	code     string
+	used     uint64
+	refund   uint64
+}{
+	{0, "0x60006000556000600055", 412, 0},
+	{0, "0x60006000556001600055", 20212, 0},
+	{0, "0x60016000556000600055", 20212, 19800},
+	{0, "0x60016000556002600055", 20212, 0},
+	{0, "0x60016000556001600055", 20212, 0},
+	{1, "0x60006000556000600055", 5212, 15000},
+	{1, "0x60006000556001600055", 5212, 4800},
+	{1, "0x60006000556002600055", 5212, 0},
+	{1, "0x60026000556000600055", 5212, 15000},
+	{1, "0x60026000556003600055", 5212, 0},
+	{1, "0x60026000556001600055", 5212, 4800},
+	{1, "0x60026000556002600055", 5212, 0},
+	{1, "0x60016000556000600055", 5212, 15000},
+	{1, "0x60016000556002600055", 5212, 0},
+	{1, "0x60016000556001600055", 412, 0},
+	{0, "0x600160005560006000556001600055", 40218, 19800},
+	{1, "0x600060005560016000556000600055", 10218, 19800},
+}
+
+// Tests the net gas metering of SSTORE against the test cases of EIP-1283.
+func TestEIP1283(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainId:             big.NewInt(1),
+		HomesteadBlock:      new(big.Int),
+		EIP150Block:         new(big.Int),
+		EIP155Block:         new(big.Int),
+		EIP158Block:         new(big.Int),
+		ByzantiumBlock:      new(big.Int),
+		ConstantinopleBlock: new(big.Int),
+	}
+	for i, tt := range eip1283Tests {
+		address := common.BytesToAddress([]byte("contract"))
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(tt.code))
+		statedb.SetState(address, common.Hash{}, common.BytesToHash([]byte{tt.original}))
+		statedb.Finalise(true) // Push the state into the "original" slot
+
+		vmctx := Context{
+			BlockNumber: new(big.Int),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+		vmenv := NewEVM(vmctx, statedb, config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, tt.used, new(big.Int))
+		if err != nil {
+			t.Errorf("test %d: execution failed: %v", i, err)
+		}
+		if gas != 0 {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, tt.used-gas, tt.used)
+		}
+		if refund := vmenv.StateDB.GetRefund(); refund != tt.refund {
+			t.Errorf("test %d: gas refund mismatch: have %v, want %v", i, refund, tt.refund)
+		}
+	}
+}
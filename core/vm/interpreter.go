@@ -35,6 +35,9 @@ type Config struct {
 	NoRecursion bool
 	// Enable recording of SHA3/keccak preimages
 	EnablePreimageRecording bool
+	// Enable execution from a cached pre-decoded instruction
+	// stream with the PUSH operands fused into their opcodes
+	EnableCodeDecoding bool
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
@@ -81,7 +84,7 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	}
 }
 
-func (in *Interpreter) enforceRestrictions(op OpCode, operation operation, stack *Stack) error {
+func (in *Interpreter) enforceRestrictions(op OpCode, operation *operation, stack *Stack) error {
 	if in.evm.chainRules.IsByzantium {
 		if in.readOnly {
 			// If the interpreter is operating in readonly mode, make sure no
@@ -133,6 +136,9 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err er
 	)
 	contract.Input = input
 
+	if in.cfg.EnableCodeDecoding && contract.decoded == nil {
+		contract.decoded = decode(contract.CodeHash, contract.Code)
+	}
 	if in.cfg.Debug {
 		defer func() {
 			if err != nil {
@@ -157,7 +163,7 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err er
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
-		operation := in.cfg.JumpTable[op]
+		operation := &in.cfg.JumpTable[op]
 		if !operation.valid {
 			return nil, fmt.Errorf("invalid opcode 0x%x", int(op))
 		}
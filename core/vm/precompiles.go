@@ -0,0 +1,199 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// maxPrecompilePrice is the highest base or per word price a chain config may
+// set for a precompiled contract. Anything above can never be paid for anyway.
+const maxPrecompilePrice = math.MaxInt64
+
+// PrecompileFactory creates a chain specific precompiled contract from its
+// configuration in the chain config. Factories are invoked for every EVM
+// the contract is active in, so they should be cheap to call.
+type PrecompileFactory func(config *params.PrecompileConfig) (PrecompiledContract, error)
+
+// MeteredPrecompiledContract is a precompiled contract whose gas cost cannot be
+// computed from the input alone. RequiredGas is charged upfront as usual, after
+// which RunMetered may consume some of the remaining gas.
+type MeteredPrecompiledContract interface {
+	PrecompiledContract
+
+	// RunMetered runs the precompiled contract with the gas left after paying
+	// for RequiredGas, returning the output and the additional gas used.
+	RunMetered(input []byte, gas uint64) ([]byte, uint64, error)
+}
+
+var (
+	precompileLock      sync.RWMutex
+	precompileFactories = make(map[string]PrecompileFactory)
+)
+
+func init() {
+	RegisterPrecompile("ed25519", func(*params.PrecompileConfig) (PrecompiledContract, error) {
+		return &ed25519Verify{}, nil
+	})
+}
+
+// RegisterPrecompile makes a precompiled contract implementation available under
+// the given name, to be activated by chain configs at an address of their own
+// choosing. It panics if the name is already taken.
+func RegisterPrecompile(name string, factory PrecompileFactory) {
+	precompileLock.Lock()
+	defer precompileLock.Unlock()
+
+	if factory == nil {
+		panic("vm: precompile factory is nil")
+	}
+	if _, ok := precompileFactories[name]; ok {
+		panic("vm: precompile " + name + " registered twice")
+	}
+	precompileFactories[name] = factory
+}
+
+// RegisteredPrecompiles returns the sorted names of all registered precompiled
+// contract implementations.
+func RegisteredPrecompiles() []string {
+	precompileLock.RLock()
+	defer precompileLock.RUnlock()
+
+	names := make([]string, 0, len(precompileFactories))
+	for name := range precompileFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newPrecompile instantiates the precompiled contract for the given config,
+// applying the configured gas schedule on top if any.
+func newPrecompile(config *params.PrecompileConfig) (PrecompiledContract, error) {
+	precompileLock.RLock()
+	factory, ok := precompileFactories[config.Name]
+	precompileLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown precompile %q", config.Name)
+	}
+	p, err := factory(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Gas != nil {
+		if m, ok := p.(MeteredPrecompiledContract); ok {
+			return &repricedMeteredContract{m, config.Gas}, nil
+		}
+		return &repricedContract{p, config.Gas}, nil
+	}
+	return p, nil
+}
+
+// CheckPrecompiles verifies that all the precompiled contracts configured in the
+// chain config are registered, can be instantiated, are sanely priced and don't
+// shadow the ones defined by the protocol.
+func CheckPrecompiles(config *params.ChainConfig) error {
+	for addr, precompile := range config.Precompiles {
+		if precompile == nil {
+			return fmt.Errorf("precompile %x: missing config", addr)
+		}
+		if _, ok := PrecompiledContractsByzantium[addr]; ok {
+			return fmt.Errorf("precompile %x: address reserved by the protocol", addr)
+		}
+		if gas := precompile.Gas; gas != nil && (gas.Base > maxPrecompilePrice || gas.PerWord > maxPrecompilePrice) {
+			return fmt.Errorf("precompile %x: gas price above %d", addr, uint64(maxPrecompilePrice))
+		}
+		if _, err := newPrecompile(precompile); err != nil {
+			return fmt.Errorf("precompile %x: %v", addr, err)
+		}
+	}
+	return nil
+}
+
+// ActivePrecompiles returns the precompiled contracts active at the given block,
+// both the protocol defined ones and those configured by the chain config.
+func ActivePrecompiles(config *params.ChainConfig, num *big.Int) map[common.Address]PrecompiledContract {
+	precompiles := PrecompiledContractsHomestead
+	if config.IsByzantium(num) {
+		precompiles = PrecompiledContractsByzantium
+	}
+	if len(config.Precompiles) == 0 {
+		return precompiles
+	}
+	active := make(map[common.Address]PrecompiledContract, len(precompiles)+len(config.Precompiles))
+	for addr, p := range precompiles {
+		active[addr] = p
+	}
+	for addr, precompile := range config.Precompiles {
+		if _, ok := active[addr]; ok || !config.IsPrecompileActive(addr, num) {
+			continue
+		}
+		// The config is checked before the chain is set up, so a failure here is a
+		// bug. Never run the address as a plain account, that would fork the node.
+		p, err := newPrecompile(precompile)
+		if err != nil {
+			panic(fmt.Sprintf("precompile %x: %v", addr, err))
+		}
+		active[addr] = p
+	}
+	return active
+}
+
+// repricedContract wraps a precompiled contract, replacing its gas schedule with
+// the one configured in the chain config.
+type repricedContract struct {
+	PrecompiledContract
+	gas *params.PrecompileGasConfig
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *repricedContract) RequiredGas(input []byte) uint64 {
+	return repricedGas(c.gas, input)
+}
+
+// repricedMeteredContract is a repricedContract retaining the metering hook of
+// the wrapped contract.
+type repricedMeteredContract struct {
+	MeteredPrecompiledContract
+	gas *params.PrecompileGasConfig
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *repricedMeteredContract) RequiredGas(input []byte) uint64 {
+	return repricedGas(c.gas, input)
+}
+
+// repricedGas calculates the gas required by a precompiled contract for the given
+// input according to a configured gas schedule, capping it on overflow.
+func repricedGas(gas *params.PrecompileGasConfig, input []byte) uint64 {
+	cost, overflow := math.SafeMul(toWordSize(uint64(len(input))), gas.PerWord)
+	if overflow {
+		return math.MaxUint64
+	}
+	if cost, overflow = math.SafeAdd(cost, gas.Base); overflow {
+		return math.MaxUint64
+	}
+	return cost
+}
@@ -17,6 +17,7 @@
 package runtime
 
 import (
+	"bytes"
 	"math/big"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/core/state"
 	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
 	"github.com/vsportchain/go-vsc/ethdb"
 )
 
@@ -93,8 +95,152 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestCreate2ExtCodeHash(t *testing.T) {
+	// Init code deploying a single INVALID opcode as the runtime code
+	initcode := []byte{
+		byte(vm.PUSH1), 0xfe,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE8),
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	// Deploy the init code with CREATE2 and return the code hash of the result
+	code := append([]byte{byte(vm.PUSH10)}, initcode...)
+	code = append(code, []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 42, // salt
+		byte(vm.PUSH1), byte(len(initcode)),
+		byte(vm.PUSH1), byte(32 - len(initcode)),
+		byte(vm.PUSH1), 0, // endowment
+		byte(vm.CREATE2),
+		byte(vm.EXTCODEHASH),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}...)
+	ret, statedb, err := Execute(code, nil, nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if have, want := common.BytesToHash(ret), crypto.Keccak256Hash([]byte{0xfe}); have != want {
+		t.Errorf("code hash mismatch: have %x, want %x", have, want)
+	}
+	address := crypto.CreateAddress2(common.BytesToAddress([]byte("contract")), common.BigToHash(big.NewInt(42)), crypto.Keccak256(initcode))
+	if code := statedb.GetCode(address); !bytes.Equal(code, []byte{0xfe}) {
+		t.Errorf("deployed code mismatch: have %x, want %x", code, []byte{0xfe})
+	}
+	// The code hash of non-existent accounts is zero
+	ret, _, err = Execute([]byte{
+		byte(vm.PUSH1), 0xff,
+		byte(vm.EXTCODEHASH),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}, nil, nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if have := common.BytesToHash(ret); have != (common.Hash{}) {
+		t.Errorf("code hash mismatch: have %x, want zero", have)
+	}
+}
+
+func TestDebugger(t *testing.T) {
+	debugger := vm.NewDebugger()
+	cfg := &Config{EVMConfig: vm.Config{Debug: true, Tracer: debugger}}
+
+	code := []byte{
+		byte(vm.PUSH1), 0x2a,
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.STOP),
+	}
+	step, err := debugger.Start(func() error {
+		_, _, err := Execute(code, nil, cfg)
+		return err
+	})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if step.Pc != 0 || step.Op != vm.PUSH1 {
+		t.Fatalf("start step mismatch: have %d/%v, want 0/PUSH1", step.Pc, step.Op)
+	}
+	if step, err = debugger.Step(); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if step.Pc != 2 || len(step.Stack) != 1 || step.Stack[0].Uint64() != 0x2a {
+		t.Fatalf("step mismatch: have pc %d, stack %v", step.Pc, step.Stack)
+	}
+	// Run until the memory store, the storage write should be visible by then
+	if step, err = debugger.Continue(vm.OpBreakpoint(vm.MSTORE)); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if step.Pc != 9 || len(step.Stack) != 2 {
+		t.Fatalf("breakpoint mismatch: have pc %d, stack %v", step.Pc, step.Stack)
+	}
+	value, err := debugger.Storage(common.Hash{})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if value != common.BigToHash(big.NewInt(0x2a)) {
+		t.Errorf("storage mismatch: have %x, want 0x2a", value)
+	}
+	if step, err = debugger.Step(); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if step.Op != vm.STOP || step.MemorySize != 32 || step.Memory[31] != 1 {
+		t.Fatalf("memory mismatch: have op %v, memory %x", step.Op, step.Memory)
+	}
+	// Finish the execution and ensure the debugger reports it
+	if _, err = debugger.Step(); err != vm.ErrDebuggerFinished {
+		t.Fatalf("error mismatch: have %v, want %v", err, vm.ErrDebuggerFinished)
+	}
+	if result := debugger.Result(); result == nil || result.Err != nil {
+		t.Fatalf("result mismatch: have %v", result)
+	}
+	if _, err = debugger.Storage(common.Hash{}); err != vm.ErrDebuggerFinished {
+		t.Fatalf("error mismatch: have %v, want %v", err, vm.ErrDebuggerFinished)
+	}
+	debugger.Stop()
+}
+
+func TestDebuggerStop(t *testing.T) {
+	debugger := vm.NewDebugger()
+	cfg := &Config{EVMConfig: vm.Config{Debug: true, Tracer: debugger}}
+
+	// Infinite loop, which only terminates if the debugger aborts it
+	code := []byte{
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), 0,
+		byte(vm.JUMP),
+	}
+	if _, err := debugger.Start(func() error {
+		_, _, err := Execute(code, nil, cfg)
+		return err
+	}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if _, err := debugger.Continue(vm.PcBreakpoint(3)); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	debugger.Stop()
+
+	if _, err := debugger.Step(); err != vm.ErrDebuggerFinished {
+		t.Fatalf("error mismatch: have %v, want %v", err, vm.ErrDebuggerFinished)
+	}
+}
+
 func TestCall(t *testing.T) {
-	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()), nil)
 	address := common.HexToAddress("0x0a")
 	state.SetCode(address, []byte{
 		byte(vm.PUSH1), 10,
@@ -148,3 +294,39 @@ func BenchmarkCall(b *testing.B) {
 		}
 	}
 }
+
+// loopCode is a contract looping 10000 times over a PUSH32 and a JUMPI.
+var loopCode = common.Hex2Bytes("6127105b7f" + strings.Repeat("ff", 32) + "50600190038060035700")
+
+func TestCodeDecoding(t *testing.T) {
+	// Run a contract returning the sum of some PUSH operands, with and without
+	// pre-decoding the code
+	code := common.Hex2Bytes("6101026a0102030405060708090a0b0160005260206000f3")
+
+	plain, _, err := Execute(code, nil, nil)
+	if err != nil {
+		t.Fatalf("plain execution failed: %v", err)
+	}
+	decoded, _, err := Execute(code, nil, &Config{EVMConfig: vm.Config{EnableCodeDecoding: true}})
+	if err != nil {
+		t.Fatalf("decoded execution failed: %v", err)
+	}
+	if !bytes.Equal(plain, decoded) {
+		t.Errorf("result mismatch: have %x, want %x", decoded, plain)
+	}
+	if want := new(big.Int).Add(big.NewInt(0x0102), new(big.Int).SetBytes(common.Hex2Bytes("0102030405060708090a0b"))); new(big.Int).SetBytes(decoded).Cmp(want) != 0 {
+		t.Errorf("result mismatch: have %x, want %x", decoded, want)
+	}
+}
+
+func benchmarkLoop(b *testing.B, cfg vm.Config) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Execute(loopCode, nil, &Config{EVMConfig: cfg}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoop(b *testing.B)        { benchmarkLoop(b, vm.Config{}) }
+func BenchmarkLoopDecoded(b *testing.B) { benchmarkLoop(b, vm.Config{EnableCodeDecoding: true}) }
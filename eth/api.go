@@ -25,13 +25,16 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/hexutil"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/rawdb"
 	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/state/snapshot"
 	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/crypto"
 	"github.com/vsportchain/go-vsc/log"
 	"github.com/vsportchain/go-vsc/miner"
 	"github.com/vsportchain/go-vsc/params"
@@ -335,12 +338,19 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 type PrivateDebugAPI struct {
 	config *params.ChainConfig
 	eth    *VSportChain
+
+	sessions     map[rpc.ID]*debugSession // Interactive debug sessions currently alive
+	sessionsLock sync.Mutex               // Protects the debug session registry
 }
 
 // NewPrivateDebugAPI creates a new API definition for the full node-related
 // private debug methods of the VSportChain service.
 func NewPrivateDebugAPI(config *params.ChainConfig, eth *VSportChain) *PrivateDebugAPI {
-	return &PrivateDebugAPI{config: config, eth: eth}
+	return &PrivateDebugAPI{
+		config:   config,
+		eth:      eth,
+		sessions: make(map[rpc.ID]*debugSession),
+	}
 }
 
 // Preimage is a debug API function that returns the preimage for a sha3 hash, if known.
@@ -380,9 +390,41 @@ func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common
 	if st == nil {
 		return StorageRangeResult{}, fmt.Errorf("account %x doesn't exist", contractAddress)
 	}
+	// If the storage before the first transaction is requested and the parent
+	// state is covered by the snapshot, iterate the flat storage instead
+	if txIndex == 0 {
+		if snap := api.parentSnapshot(blockHash); snap != nil {
+			var seek common.Hash
+			copy(seek[:], keyStart)
+
+			if it, err := snap.StorageIterator(crypto.Keccak256Hash(contractAddress[:]), seek); err == nil {
+				if result, err := storageRangeAtSnapshot(it, st, maxResult); err == nil {
+					return result, nil
+				}
+			}
+		}
+	}
 	return storageRangeAt(st, keyStart, maxResult)
 }
 
+// parentSnapshot retrieves the state snapshot of the parent of the given block,
+// or nil if snapshots are disabled or don't cover it.
+func (api *PrivateDebugAPI) parentSnapshot(blockHash common.Hash) snapshot.Snapshot {
+	snaps := api.eth.blockchain.Snapshot()
+	if snaps == nil {
+		return nil
+	}
+	block := api.eth.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil
+	}
+	parent := api.eth.blockchain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil
+	}
+	return snaps.Snapshot(parent.Root)
+}
+
 func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeResult, error) {
 	it := trie.NewIterator(st.NodeIterator(start))
 	result := StorageRangeResult{Storage: storageMap{}}
@@ -406,6 +448,36 @@ func storageRangeAt(st state.Trie, start []byte, maxResult int) (StorageRangeRes
 	return result, nil
 }
 
+// storageRangeAtSnapshot is the equivalent of storageRangeAt, iterating over the
+// flat storage of a snapshot. The trie is only used to resolve key preimages.
+func storageRangeAtSnapshot(it snapshot.StorageIterator, st state.Trie, maxResult int) (StorageRangeResult, error) {
+	defer it.Release()
+
+	result := StorageRangeResult{Storage: storageMap{}}
+	for i := 0; i < maxResult && it.Next(); i++ {
+		_, content, _, err := rlp.Split(it.Slot())
+		if err != nil {
+			return StorageRangeResult{}, err
+		}
+		hash := it.Hash()
+		e := storageEntry{Value: common.BytesToHash(content)}
+		if preimage := st.GetKey(hash[:]); preimage != nil {
+			preimage := common.BytesToHash(preimage)
+			e.Key = &preimage
+		}
+		result.Storage[hash] = e
+	}
+	// Add the 'next key' so clients can continue downloading.
+	if it.Next() {
+		next := it.Hash()
+		result.NextKey = &next
+	}
+	if err := it.Error(); err != nil {
+		return StorageRangeResult{}, err
+	}
+	return result, nil
+}
+
 // GetModifiedAccountsByumber returns all accounts that have changed between the
 // two blocks specified. A change is defined as a difference in nonce, balance,
 // code hash, or storage hash.
@@ -488,3 +560,90 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 	}
 	return dirty, nil
 }
+
+// RPCInternalTransfer is an internal value transfer made by a contract, along
+// with the position of the transaction making it.
+type RPCInternalTransfer struct {
+	BlockHash        common.Hash    `json:"blockHash"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	Type             string         `json:"type"`
+	From             common.Address `json:"from"`
+	To               common.Address `json:"to"`
+	Value            *hexutil.Big   `json:"value"`
+}
+
+// PublicInternalTxAPI provides an API to access the internal value transfers
+// recorded by the internal transaction indexer.
+type PublicInternalTxAPI struct {
+	eth *VSportChain
+}
+
+// NewPublicInternalTxAPI creates a new API definition for the internal
+// transaction methods of the VSportChain service.
+func NewPublicInternalTxAPI(eth *VSportChain) *PublicInternalTxAPI {
+	return &PublicInternalTxAPI{eth: eth}
+}
+
+// GetInternalTransactions returns the internal value transfers an account took
+// part in within the canonical blocks of the given range. Ranges starting before
+// the oldest indexed block or containing a block which couldn't be indexed are
+// refused.
+func (api *PublicInternalTxAPI) GetInternalTransactions(ctx context.Context, address common.Address, fromBlock, toBlock rpc.BlockNumber) ([]*RPCInternalTransfer, error) {
+	// Resolve the requested range against the indexed one
+	head := api.eth.blockchain.CurrentBlock().NumberU64()
+
+	from, to := head, head
+	if fromBlock >= 0 {
+		from = uint64(fromBlock)
+	}
+	if toBlock >= 0 {
+		to = uint64(toBlock)
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range #%d-#%d", from, to)
+	}
+	tail := rawdb.ReadInternalTxIndexTail(api.eth.chainDb)
+	if tail == nil {
+		return nil, errors.New("internal transactions not indexed")
+	}
+	if from < *tail {
+		return nil, fmt.Errorf("internal transactions not indexed before block #%d", *tail)
+	}
+	if sections, _, _ := api.eth.internalTxIndexer.Sections(); to >= sections {
+		to = sections - 1
+	}
+	var gap *uint64
+	rawdb.IterateInternalTxGaps(api.eth.chainDb, from, to, func(number uint64, hash common.Hash) bool {
+		if rawdb.ReadCanonicalHash(api.eth.chainDb, number) != hash {
+			return true
+		}
+		gap = &number
+		return false
+	})
+	if gap != nil {
+		return nil, fmt.Errorf("internal transactions not indexed in block #%d", *gap)
+	}
+	// Gather the transfers of the canonical blocks
+	transfers := []*RPCInternalTransfer{}
+	rawdb.IterateInternalTransfers(api.eth.chainDb, address, from, to, func(number uint64, hash common.Hash, batch []*types.InternalTransfer) bool {
+		if rawdb.ReadCanonicalHash(api.eth.chainDb, number) != hash {
+			return true
+		}
+		for _, transfer := range batch {
+			transfers = append(transfers, &RPCInternalTransfer{
+				BlockHash:        hash,
+				BlockNumber:      hexutil.Uint64(number),
+				TransactionHash:  transfer.TxHash,
+				TransactionIndex: hexutil.Uint(transfer.TxIndex),
+				Type:             transfer.Type,
+				From:             transfer.From,
+				To:               transfer.To,
+				Value:            (*hexutil.Big)(transfer.Value),
+			})
+		}
+		return true
+	})
+	return transfers, nil
+}
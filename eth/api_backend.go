@@ -19,10 +19,10 @@ package eth
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/vsportchain/go-vsc/accounts"
 	"github.com/vsportchain/go-vsc/common"
-	"github.com/vsportchain/go-vsc/common/math"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/bloombits"
 	"github.com/vsportchain/go-vsc/core/rawdb"
@@ -33,6 +33,7 @@ import (
 	"github.com/vsportchain/go-vsc/eth/gasprice"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/event"
+	"github.com/vsportchain/go-vsc/internal/ethapi"
 	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rpc"
 )
@@ -128,14 +129,18 @@ func (b *EthAPIBackend) GetTd(blockHash common.Hash) *big.Int {
 	return b.eth.blockchain.GetTdByHash(blockHash)
 }
 
-func (b *EthAPIBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
-	state.SetBalance(msg.From(), math.MaxBig256)
+func (b *EthAPIBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config, overrides *ethapi.BlockOverrides) (*vm.EVM, func() error, error) {
 	vmError := func() error { return nil }
 
 	context := core.NewEVMContext(msg, header, b.eth.BlockChain(), nil)
+	overrides.Apply(&context)
 	return vm.NewEVM(context, state, b.eth.chainConfig, vmCfg), vmError, nil
 }
 
+func (b *EthAPIBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int) (core.Message, vm.Context, *state.StateDB, error) {
+	return NewPrivateDebugAPI(b.eth.chainConfig, b.eth).computeTxEnv(block.Hash(), txIndex, defaultTraceReexec)
+}
+
 func (b *EthAPIBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeRemovedLogsEvent(ch)
 }
@@ -160,6 +165,10 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.AddLocal(signedTx)
 }
 
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, deadline uint64) error {
+	return b.eth.txPool.AddPrivate(signedTx, deadline)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eth.txPool.Pending()
 	if err != nil {
@@ -188,10 +197,34 @@ func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.TxPool().Content()
 }
 
+func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.eth.TxPool().ContentFrom(addr)
+}
+
+func (b *EthAPIBackend) TxPoolSenders() []common.Address {
+	return b.eth.TxPool().Senders()
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeTxLifecycleEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.eth.TxPool().SubscribeTxLifecycleEvent(ch)
+}
+
+func (b *EthAPIBackend) TxIndexProgress() (core.TxIndexProgress, error) {
+	return b.eth.blockchain.TxIndexProgress(), nil
+}
+
+func (b *EthAPIBackend) RPCGasCap() uint64 {
+	return b.eth.config.RPCGasCap
+}
+
+func (b *EthAPIBackend) RPCEVMTimeout() time.Duration {
+	return b.eth.config.RPCEVMTimeout
+}
+
 func (b *EthAPIBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
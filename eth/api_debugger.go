@@ -0,0 +1,390 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+const (
+	// defaultDebugSessionTimeout is the amount of time a debug session is kept
+	// alive by default without being accessed before it's torn down.
+	defaultDebugSessionTimeout = 5 * time.Minute
+
+	// maxDebugSessions is the maximum number of debug sessions alive at the same
+	// time, each of them holding on to a paused execution and its state.
+	maxDebugSessions = 16
+)
+
+// DebugSessionConfig holds extra parameters to debug sessions.
+type DebugSessionConfig struct {
+	Timeout *string
+	Reexec  *uint64
+}
+
+// DebugTarget is the breakpoint to resume a debug session to. Exactly one of the
+// fields must be set.
+type DebugTarget struct {
+	Pc    *hexutil.Uint64 `json:"pc"`
+	Op    *string         `json:"op"`
+	Depth *int            `json:"depth"`
+}
+
+// breakpoint converts the target into a breakpoint for the EVM debugger.
+func (t *DebugTarget) breakpoint() (vm.Breakpoint, error) {
+	switch {
+	case t.Pc != nil && t.Op == nil && t.Depth == nil:
+		return vm.PcBreakpoint(uint64(*t.Pc)), nil
+
+	case t.Pc == nil && t.Op != nil && t.Depth == nil:
+		name := strings.ToUpper(*t.Op)
+		op := vm.StringToOp(name)
+		if op == vm.STOP && name != "STOP" {
+			return nil, fmt.Errorf("unknown opcode %q", *t.Op)
+		}
+		return vm.OpBreakpoint(op), nil
+
+	case t.Pc == nil && t.Op == nil && t.Depth != nil:
+		return vm.DepthBreakpoint(*t.Depth), nil
+
+	default:
+		return nil, errors.New("exactly one of pc, op or depth must be specified")
+	}
+}
+
+// debugStep is the operation a debug session is paused at.
+type debugStep struct {
+	Pc         uint64         `json:"pc"`
+	Op         string         `json:"op"`
+	Gas        uint64         `json:"gas"`
+	GasCost    uint64         `json:"gasCost"`
+	Depth      int            `json:"depth"`
+	Contract   common.Address `json:"contract"`
+	MemorySize int            `json:"memSize"`
+	StackSize  int            `json:"stackSize"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// debugResult is the outcome of the transaction replayed by a debug session.
+type debugResult struct {
+	Gas         uint64 `json:"gas"`
+	Failed      bool   `json:"failed"`
+	ReturnValue string `json:"returnValue"`
+	Error       string `json:"error,omitempty"`
+}
+
+// debugSessionStatus is reported after every move of a debug session. Exactly
+// one of step and result is set, depending on whether the execution finished.
+type debugSessionStatus struct {
+	Session rpc.ID       `json:"session"`
+	Step    *debugStep   `json:"step,omitempty"`
+	Result  *debugResult `json:"result,omitempty"`
+}
+
+// debugSession is a transaction replay paused by the EVM debugger, waiting for
+// instructions from the RPC user.
+type debugSession struct {
+	debugger *vm.Debugger
+	timeout  time.Duration // Inactivity period after which the session is torn down
+	expiry   *time.Timer   // Timer tearing down the session once it expires
+	closed   bool          // Whether the session was already torn down
+	lock     sync.Mutex    // Serializes access to the debugger
+
+	ret    []byte // Return value of the transaction, set once finished
+	gas    uint64 // Gas used by the transaction, set once finished
+	failed bool   // Whether the transaction failed, set once finished
+}
+
+// status assembles the status report of the session after the debugger moved
+// to the given step.
+func (s *debugSession) status(id rpc.ID, step *vm.StructLog, err error) (*debugSessionStatus, error) {
+	if err != nil && err != vm.ErrDebuggerFinished {
+		return nil, err
+	}
+	status := &debugSessionStatus{Session: id}
+	if step != nil {
+		addr, _ := s.debugger.Contract()
+		status.Step = &debugStep{
+			Pc:         step.Pc,
+			Op:         step.Op.String(),
+			Gas:        step.Gas,
+			GasCost:    step.GasCost,
+			Depth:      step.Depth,
+			Contract:   addr,
+			MemorySize: step.MemorySize,
+			StackSize:  len(step.Stack),
+		}
+		if step.Err != nil {
+			status.Step.Error = step.Err.Error()
+		}
+		return status, nil
+	}
+	status.Result = &debugResult{
+		Gas:         s.gas,
+		Failed:      s.failed,
+		ReturnValue: fmt.Sprintf("%x", s.ret),
+	}
+	if result := s.debugger.Result(); result != nil && result.Err != nil {
+		status.Result.Error = result.Err.Error()
+	}
+	return status, nil
+}
+
+// close aborts the execution of the session and marks it torn down. The caller
+// must hold the session lock.
+func (s *debugSession) close() {
+	s.closed = true
+	s.expiry.Stop()
+	s.debugger.Stop()
+}
+
+// StartSession replays a transaction under the EVM debugger, pausing before its
+// first operation. The returned session id can be used to step through the
+// execution and inspect it, until the session is stopped or times out.
+func (api *PrivateDebugAPI) StartSession(ctx context.Context, hash common.Hash, config *DebugSessionConfig) (*debugSessionStatus, error) {
+	// Retrieve the transaction and assemble its EVM context
+	tx, blockHash, _, index := rawdb.ReadTransaction(api.eth.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	var (
+		reexec  = defaultTraceReexec
+		timeout = defaultDebugSessionTimeout
+		err     error
+	)
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	if config != nil && config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), reexec)
+	if err != nil {
+		return nil, err
+	}
+	// Replay the transaction under the debugger up to its first operation
+	session := &debugSession{
+		debugger: vm.NewDebugger(),
+		timeout:  timeout,
+	}
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: session.debugger})
+
+	step, err := session.debugger.Start(func() error {
+		ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+		if err != nil {
+			return fmt.Errorf("tracing failed: %v", err)
+		}
+		session.ret, session.gas, session.failed = ret, gas, failed
+		return nil
+	})
+	if err != nil && err != vm.ErrDebuggerFinished {
+		return nil, err
+	}
+	// Execution started, register the session unless there are too many
+	api.sessionsLock.Lock()
+	if len(api.sessions) >= maxDebugSessions {
+		api.sessionsLock.Unlock()
+		session.debugger.Stop()
+		return nil, fmt.Errorf("too many debug sessions (max %d)", maxDebugSessions)
+	}
+	id := rpc.NewID()
+	api.sessions[id] = session
+	session.expiry = time.AfterFunc(timeout, func() {
+		log.Debug("Debug session expired", "id", id)
+		api.StopSession(id)
+	})
+	api.sessionsLock.Unlock()
+
+	log.Debug("Debug session started", "id", id, "tx", hash, "timeout", timeout)
+	return session.status(id, step, err)
+}
+
+// session retrieves a live debug session and locks it, extending its lifetime.
+// The caller is responsible for unlocking the session.
+func (api *PrivateDebugAPI) session(id rpc.ID) (*debugSession, error) {
+	api.sessionsLock.Lock()
+	session, ok := api.sessions[id]
+	api.sessionsLock.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("debug session %s not found", id)
+	}
+	session.lock.Lock()
+	if session.closed {
+		session.lock.Unlock()
+		return nil, fmt.Errorf("debug session %s not found", id)
+	}
+	session.expiry.Reset(session.timeout)
+	return session, nil
+}
+
+// Step resumes a debug session for a single operation.
+func (api *PrivateDebugAPI) Step(ctx context.Context, id rpc.ID) (*debugSessionStatus, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	defer session.lock.Unlock()
+
+	return api.resume(ctx, id, session, vm.StepBreakpoint)
+}
+
+// ContinueTo resumes a debug session until the execution reaches the given
+// program counter, opcode or call depth, or finishes.
+func (api *PrivateDebugAPI) ContinueTo(ctx context.Context, id rpc.ID, target DebugTarget) (*debugSessionStatus, error) {
+	breakpoint, err := target.breakpoint()
+	if err != nil {
+		return nil, err
+	}
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	defer session.lock.Unlock()
+
+	return api.resume(ctx, id, session, breakpoint)
+}
+
+// resume runs the execution of a locked debug session until the breakpoint hits.
+// If the request is cancelled or the session expires before, the execution is
+// aborted and the session torn down.
+func (api *PrivateDebugAPI) resume(ctx context.Context, id rpc.ID, session *debugSession, breakpoint vm.Breakpoint) (*debugSessionStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, session.timeout)
+	defer cancel()
+
+	var (
+		done        = make(chan struct{})
+		interrupted = make(chan bool, 1)
+	)
+	go func() {
+		select {
+		case <-ctx.Done():
+			err := ctx.Err()
+			if err == context.DeadlineExceeded {
+				err = fmt.Errorf("debug session %s expired", id)
+			}
+			session.debugger.Interrupt(err)
+			interrupted <- true
+		case <-done:
+			interrupted <- false
+		}
+	}()
+	step, err := session.debugger.Continue(breakpoint)
+	close(done)
+
+	if <-interrupted {
+		api.sessionsLock.Lock()
+		delete(api.sessions, id)
+		api.sessionsLock.Unlock()
+
+		session.close()
+		log.Debug("Debug session interrupted", "id", id, "err", err)
+		if err == nil || err == vm.ErrDebuggerFinished {
+			err = fmt.Errorf("debug session %s interrupted", id)
+		}
+		return nil, err
+	}
+	return session.status(id, step, err)
+}
+
+// InspectMemory returns a slice of the memory of the paused execution. Ranges
+// reaching beyond the allocated memory are truncated.
+func (api *PrivateDebugAPI) InspectMemory(id rpc.ID, offset, size uint64) (hexutil.Bytes, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	defer session.lock.Unlock()
+
+	step := session.debugger.Current()
+	if step == nil {
+		return nil, vm.ErrDebuggerFinished
+	}
+	memory := uint64(len(step.Memory))
+	if offset > memory {
+		offset = memory
+	}
+	if size > memory-offset {
+		size = memory - offset
+	}
+	return common.CopyBytes(step.Memory[offset : offset+size]), nil
+}
+
+// InspectStack returns the stack of the paused execution, the top of the stack
+// being the last item.
+func (api *PrivateDebugAPI) InspectStack(id rpc.ID) ([]string, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return nil, err
+	}
+	defer session.lock.Unlock()
+
+	step := session.debugger.Current()
+	if step == nil {
+		return nil, vm.ErrDebuggerFinished
+	}
+	stack := make([]string, len(step.Stack))
+	for i, item := range step.Stack {
+		stack[i] = fmt.Sprintf("%x", math.PaddedBigBytes(item, 32))
+	}
+	return stack, nil
+}
+
+// InspectStorage returns the value of a storage slot of the contract the paused
+// execution is running in, including any modification made so far.
+func (api *PrivateDebugAPI) InspectStorage(id rpc.ID, key common.Hash) (common.Hash, error) {
+	session, err := api.session(id)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer session.lock.Unlock()
+
+	return session.debugger.Storage(key)
+}
+
+// StopSession aborts the execution of a debug session and releases it.
+func (api *PrivateDebugAPI) StopSession(id rpc.ID) error {
+	api.sessionsLock.Lock()
+	session, ok := api.sessions[id]
+	delete(api.sessions, id)
+	api.sessionsLock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("debug session %s not found", id)
+	}
+	session.lock.Lock()
+	defer session.lock.Unlock()
+
+	session.close()
+	return nil
+}
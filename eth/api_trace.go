@@ -0,0 +1,390 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+// traceCallTracer is the name of the tracer the trace API assembles its flat
+// traces from.
+var traceCallTracer = "callTracer"
+
+// maxTraceFilterBlocks is the maximum number of blocks a single trace filter
+// request is allowed to trace.
+const maxTraceFilterBlocks = 100
+
+// TraceAction is the action performed by a single call of a transaction.
+type TraceAction struct {
+	CallType      string          `json:"callType,omitempty"`      // Call opcode of call traces
+	From          *common.Address `json:"from,omitempty"`          // Caller of call and create traces
+	To            *common.Address `json:"to,omitempty"`            // Callee of call traces
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`           // Gas allowance of the call, if known
+	Input         *hexutil.Bytes  `json:"input,omitempty"`         // Input data of call traces
+	Init          *hexutil.Bytes  `json:"init,omitempty"`          // Init code of create traces
+	Value         *hexutil.Big    `json:"value,omitempty"`         // Value transferred by the call
+	Address       *common.Address `json:"address,omitempty"`       // Self destructed contract of suicide traces
+	RefundAddress *common.Address `json:"refundAddress,omitempty"` // Beneficiary of suicide traces
+	Balance       *hexutil.Big    `json:"balance,omitempty"`       // Balance refunded by suicide traces
+}
+
+// TraceOutcome is the outcome of a single successful call of a transaction.
+type TraceOutcome struct {
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"` // Gas used by the call, if known
+	Output  *hexutil.Bytes  `json:"output,omitempty"`  // Return data of call traces
+	Address *common.Address `json:"address,omitempty"` // Created contract of create traces
+	Code    *hexutil.Bytes  `json:"code,omitempty"`    // Deployed code of create traces
+}
+
+// FlatTrace is a single call of a transaction, addressed by its position within
+// the call tree of the transaction.
+type FlatTrace struct {
+	Action              *TraceAction  `json:"action"`
+	BlockHash           *common.Hash  `json:"blockHash,omitempty"`
+	BlockNumber         *uint64       `json:"blockNumber,omitempty"`
+	Error               string        `json:"error,omitempty"`
+	Result              *TraceOutcome `json:"result,omitempty"`
+	Subtraces           int           `json:"subtraces"`
+	TraceAddress        []int         `json:"traceAddress"`
+	TransactionHash     *common.Hash  `json:"transactionHash,omitempty"`
+	TransactionPosition *uint64       `json:"transactionPosition,omitempty"`
+	Type                string        `json:"type"`
+}
+
+// TraceReplayResult is the result of replaying a single transaction of a block.
+type TraceReplayResult struct {
+	Output          hexutil.Bytes `json:"output"`
+	StateDiff       interface{}   `json:"stateDiff"` // Unsupported, always null
+	Trace           []*FlatTrace  `json:"trace"`
+	VMTrace         interface{}   `json:"vmTrace"` // Unsupported, always null
+	TransactionHash common.Hash   `json:"transactionHash"`
+}
+
+// TraceFilterArgs are the criteria to filter the traces of a block range by.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`   // First block of the range, latest if omitted
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`     // Last block of the range, latest if omitted
+	FromAddress []common.Address `json:"fromAddress"` // Callers to match, any if empty
+	ToAddress   []common.Address `json:"toAddress"`   // Callees to match, any if empty
+	After       *uint64          `json:"after"`       // Number of matching traces to skip
+	Count       *uint64          `json:"count"`       // Maximum number of traces to return
+}
+
+// callFrame is a single call of the call tree assembled by the callTracer.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      common.Address  `json:"to"`
+	Value   *hexutil.Big    `json:"value"`
+	Gas     *hexutil.Uint64 `json:"gas"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output"`
+	Error   string          `json:"error"`
+	Calls   []*callFrame    `json:"calls"`
+}
+
+// flatten converts the call tree rooted at the given call into flat traces in
+// depth first order, appending them to traces. The executing address is the
+// account whose code the parent call runs in, needed to report self destructs.
+func (call *callFrame) flatten(address []int, executing common.Address, traces []*FlatTrace) []*FlatTrace {
+	trace := &FlatTrace{
+		Action:       new(TraceAction),
+		Error:        call.Error,
+		Subtraces:    len(call.Calls),
+		TraceAddress: append(make([]int, 0, len(address)), address...),
+	}
+	switch call.Type {
+	case "CREATE", "CREATE2":
+		from, input := call.From, call.Input
+		trace.Type = "create"
+		trace.Action.From, trace.Action.Gas, trace.Action.Init, trace.Action.Value = &from, call.Gas, &input, call.Value
+		if call.Error == "" {
+			to, output := call.To, call.Output
+			trace.Result = &TraceOutcome{GasUsed: call.GasUsed, Address: &to, Code: &output}
+		}
+		executing = call.To
+
+	case "SELFDESTRUCT":
+		refund := call.To
+		trace.Type = "suicide"
+		trace.Action.Address, trace.Action.RefundAddress, trace.Action.Balance = &executing, &refund, call.Value
+
+	default:
+		from, to, input := call.From, call.To, call.Input
+		trace.Type = "call"
+		trace.Action.CallType = strings.ToLower(call.Type)
+		trace.Action.From, trace.Action.To, trace.Action.Gas, trace.Action.Input, trace.Action.Value = &from, &to, call.Gas, &input, call.Value
+		if call.Error == "" {
+			output := call.Output
+			trace.Result = &TraceOutcome{GasUsed: call.GasUsed, Output: &output}
+		}
+		// Delegated code runs in the context of the caller
+		if call.Type != "DELEGATECALL" && call.Type != "CALLCODE" {
+			executing = call.To
+		}
+	}
+	traces = append(traces, trace)
+	for i, inner := range call.Calls {
+		traces = inner.flatten(append(address, i), executing, traces)
+	}
+	return traces
+}
+
+// PrivateTraceAPI is the collection of VSportChain APIs exposing the internal
+// calls of transactions as flat, addressable traces.
+type PrivateTraceAPI struct {
+	debug *PrivateDebugAPI
+}
+
+// NewPrivateTraceAPI creates a new API definition for the trace methods, built
+// on top of the tracing facilities of the debug API.
+func NewPrivateTraceAPI(debug *PrivateDebugAPI) *PrivateTraceAPI {
+	return &PrivateTraceAPI{debug: debug}
+}
+
+// Block returns the flat traces of all the transactions within a block.
+func (api *PrivateTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*FlatTrace, error) {
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	calls, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	var traces []*FlatTrace
+	for i, call := range calls {
+		traces = append(traces, blockTraces(call, block, i)...)
+	}
+	return traces, nil
+}
+
+// Transaction returns the flat traces of a single transaction.
+func (api *PrivateTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*FlatTrace, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(api.debug.eth.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.debug.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.debug.traceTx(ctx, msg, vmctx, statedb, &TraceConfig{Tracer: &traceCallTracer})
+	if err != nil {
+		return nil, err
+	}
+	call := new(callFrame)
+	if err := json.Unmarshal(res.(json.RawMessage), call); err != nil {
+		return nil, err
+	}
+	return annotateTraces(call.flatten(nil, call.To, nil), blockHash, blockNumber, hash, index), nil
+}
+
+// ReplayBlockTransactions replays all the transactions within a block, returning
+// the requested kinds of traces of each. Only the "trace" kind is supported.
+func (api *PrivateTraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, kinds []string) ([]*TraceReplayResult, error) {
+	trace := false
+	for _, kind := range kinds {
+		if kind != "trace" {
+			return nil, fmt.Errorf("unsupported trace type %q", kind)
+		}
+		trace = true
+	}
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	calls, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TraceReplayResult, len(calls))
+	for i, call := range calls {
+		results[i] = &TraceReplayResult{
+			Output:          call.Output,
+			Trace:           []*FlatTrace{},
+			TransactionHash: block.Transactions()[i].Hash(),
+		}
+		if trace {
+			results[i].Trace = call.flatten(nil, call.To, nil)
+		}
+	}
+	return results, nil
+}
+
+// Filter returns the flat traces of all the transactions within a block range,
+// matching the given caller and callee addresses.
+func (api *PrivateTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*FlatTrace, error) {
+	// Resolve the block range to filter
+	var (
+		from = api.debug.eth.blockchain.CurrentBlock().NumberU64()
+		to   = from
+	)
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 {
+		to = uint64(*args.ToBlock)
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range #%d-#%d", from, to)
+	}
+	if to-from >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range #%d-#%d exceeds the limit of %d blocks", from, to, maxTraceFilterBlocks)
+	}
+	// Assemble the address sets to match against
+	var (
+		senders   = make(map[common.Address]struct{})
+		receivers = make(map[common.Address]struct{})
+	)
+	for _, addr := range args.FromAddress {
+		senders[addr] = struct{}{}
+	}
+	for _, addr := range args.ToAddress {
+		receivers[addr] = struct{}{}
+	}
+	// Trace all the blocks in the range, gathering the matching traces
+	var (
+		traces  []*FlatTrace
+		skipped uint64
+	)
+	for number := from; number <= to; number++ {
+		// Stop tracing if the request was cancelled or timed out
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		block := api.debug.eth.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		// Skip empty blocks, they have no traces (and the genesis can't be traced)
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		calls, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for i, call := range calls {
+			for _, trace := range blockTraces(call, block, i) {
+				if len(senders) > 0 && !matchTrace(senders, trace.Action.From, trace.Action.Address) {
+					continue
+				}
+				if len(receivers) > 0 && !matchTrace(receivers, trace.Action.To, trace.Action.RefundAddress, resultAddress(trace)) {
+					continue
+				}
+				if args.After != nil && skipped < *args.After {
+					skipped++
+					continue
+				}
+				traces = append(traces, trace)
+				if args.Count != nil && uint64(len(traces)) >= *args.Count {
+					return traces, nil
+				}
+			}
+		}
+	}
+	return traces, nil
+}
+
+// blockByNumber retrieves a block by number, resolving the pending and latest
+// special numbers.
+func (api *PrivateTraceAPI) blockByNumber(number rpc.BlockNumber) (*types.Block, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		block = api.debug.eth.miner.PendingBlock()
+	case rpc.LatestBlockNumber:
+		block = api.debug.eth.blockchain.CurrentBlock()
+	default:
+		block = api.debug.eth.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return block, nil
+}
+
+// traceBlock executes all the transactions of a block with the call tracer,
+// returning the call tree of each.
+func (api *PrivateTraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]*callFrame, error) {
+	results, err := api.debug.traceBlock(ctx, block, &TraceConfig{Tracer: &traceCallTracer})
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]*callFrame, len(results))
+	for i, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction %x trace failed: %s", block.Transactions()[i].Hash(), result.Error)
+		}
+		calls[i] = new(callFrame)
+		if err := json.Unmarshal(result.Result.(json.RawMessage), calls[i]); err != nil {
+			return nil, err
+		}
+	}
+	return calls, nil
+}
+
+// blockTraces flattens the call tree of the index-th transaction of a block,
+// annotating the traces with their position within the chain.
+func blockTraces(call *callFrame, block *types.Block, index int) []*FlatTrace {
+	traces := call.flatten(nil, call.To, nil)
+	return annotateTraces(traces, block.Hash(), block.NumberU64(), block.Transactions()[index].Hash(), uint64(index))
+}
+
+// annotateTraces sets the block and transaction position of a set of traces.
+func annotateTraces(traces []*FlatTrace, blockHash common.Hash, blockNumber uint64, txHash common.Hash, index uint64) []*FlatTrace {
+	for _, trace := range traces {
+		trace.BlockHash, trace.BlockNumber = &blockHash, &blockNumber
+		trace.TransactionHash, trace.TransactionPosition = &txHash, &index
+	}
+	return traces
+}
+
+// matchTrace returns whether any of the given trace addresses is in the set.
+func matchTrace(set map[common.Address]struct{}, addrs ...*common.Address) bool {
+	for _, addr := range addrs {
+		if addr == nil {
+			continue
+		}
+		if _, ok := set[*addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resultAddress returns the contract created by a trace, if any.
+func resultAddress(trace *FlatTrace) *common.Address {
+	if trace.Result == nil {
+		return nil
+	}
+	return trace.Result.Address
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+// Tests that call trees are flattened into correctly addressed traces.
+func TestTraceFlatten(t *testing.T) {
+	blob := `{
+		"type": "CALL", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002",
+		"value": "0x0", "gas": "0x10000", "gasUsed": "0x5000", "input": "0x", "output": "0x01",
+		"calls": [{
+			"type": "DELEGATECALL", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000003",
+			"gas": "0x1000", "gasUsed": "0x500", "input": "0x02", "output": "0x",
+			"calls": [{"type": "SELFDESTRUCT", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000005", "value": "0x7"}]
+		}, {
+			"type": "CREATE", "from": "0x0000000000000000000000000000000000000002",
+			"value": "0x1", "gas": "0x2000", "gasUsed": "0x2000", "input": "0x6000", "error": "out of gas"
+		}, {
+			"type": "CREATE2", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000004",
+			"value": "0x0", "gas": "0x3000", "gasUsed": "0x1000", "input": "0x6001", "output": "0x00"
+		}]
+	}`
+	call := new(callFrame)
+	if err := json.Unmarshal([]byte(blob), call); err != nil {
+		t.Fatalf("failed to decode call tree: %v", err)
+	}
+	traces := call.flatten(nil, call.To, nil)
+
+	if len(traces) != 5 {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), 5)
+	}
+	tests := []struct {
+		kind      string
+		address   []int
+		subtraces int
+		result    bool
+	}{
+		{"call", []int{}, 3, true},
+		{"call", []int{0}, 1, true},
+		{"suicide", []int{0, 0}, 0, false},
+		{"create", []int{1}, 0, false}, // failed, no result
+		{"create", []int{2}, 0, true},
+	}
+	for i, tt := range tests {
+		if traces[i].Type != tt.kind {
+			t.Errorf("trace %d: type mismatch: have %s, want %s", i, traces[i].Type, tt.kind)
+		}
+		if !reflect.DeepEqual(traces[i].TraceAddress, tt.address) {
+			t.Errorf("trace %d: address mismatch: have %v, want %v", i, traces[i].TraceAddress, tt.address)
+		}
+		if traces[i].Subtraces != tt.subtraces {
+			t.Errorf("trace %d: subtraces mismatch: have %d, want %d", i, traces[i].Subtraces, tt.subtraces)
+		}
+		if result := traces[i].Result != nil; result != tt.result {
+			t.Errorf("trace %d: result presence mismatch: have %v, want %v", i, result, tt.result)
+		}
+	}
+	if traces[1].Action.CallType != "delegatecall" {
+		t.Errorf("call type mismatch: have %s, want %s", traces[1].Action.CallType, "delegatecall")
+	}
+	// Delegated code runs in the caller's context, so that's what self destructs
+	if want := common.HexToAddress("0x02"); *traces[2].Action.Address != want {
+		t.Errorf("self destructed address mismatch: have %x, want %x", *traces[2].Action.Address, want)
+	}
+	if want := common.HexToAddress("0x05"); *traces[2].Action.RefundAddress != want {
+		t.Errorf("refund address mismatch: have %x, want %x", *traces[2].Action.RefundAddress, want)
+	}
+	if balance := traces[2].Action.Balance.ToInt(); balance.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("refunded balance mismatch: have %v, want %v", balance, 7)
+	}
+	if traces[3].Error != "out of gas" {
+		t.Errorf("error mismatch: have %q, want %q", traces[3].Error, "out of gas")
+	}
+	// Salted creations are reported the same way as plain ones
+	if want := common.HexToAddress("0x04"); *traces[4].Result.Address != want {
+		t.Errorf("created address mismatch: have %x, want %x", *traces[4].Result.Address, want)
+	}
+	if init, code := *traces[4].Action.Init, *traces[4].Result.Code; init.String() != "0x6001" || code.String() != "0x00" {
+		t.Errorf("creation code mismatch: have init %s code %s, want init %s code %s", init, code, "0x6001", "0x00")
+	}
+}
+
+// Tests that trace filtering reports self destructs along with their refunds,
+// refuses oversized block ranges and stops on cancelled requests.
+func TestTraceFilter(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+
+		sink       = common.Address{0xff}
+		destructor = common.Address{0x01}
+	)
+	// destructor self destructs to sink
+	destruct := append(append([]byte{0x73}, sink.Bytes()...), 0xff)
+
+	var (
+		db    = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				sender:     {Balance: big.NewInt(params.Ether)},
+				destructor: {Code: destruct, Balance: big.NewInt(5)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(0, destructor, big.NewInt(100), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		gen.AddTx(tx)
+	})
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth := &VSportChain{blockchain: chain, chainDb: db, engine: ethash.NewFaker()}
+	api := NewPrivateTraceAPI(NewPrivateDebugAPI(gspec.Config, eth))
+
+	// Filter the self destruct by its beneficiary and check the refund
+	var (
+		from = rpc.BlockNumber(0)
+		to   = rpc.BlockNumber(1)
+	)
+	traces, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{sink}})
+	if err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), 1)
+	}
+	if traces[0].Type != "suicide" {
+		t.Errorf("type mismatch: have %s, want %s", traces[0].Type, "suicide")
+	}
+	if action := traces[0].Action; *action.Address != destructor || *action.RefundAddress != sink || action.Balance.ToInt().Cmp(big.NewInt(105)) != 0 {
+		t.Errorf("action mismatch: have address %x refund %x balance %v, want address %x refund %x balance %v",
+			*action.Address, *action.RefundAddress, action.Balance, destructor, sink, 105)
+	}
+	// Ensure oversized ranges and cancelled requests are refused
+	to = rpc.BlockNumber(maxTraceFilterBlocks)
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to}); err == nil {
+		t.Errorf("oversized block range accepted")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	to = rpc.BlockNumber(1)
+	if _, err := api.Filter(ctx, TraceFilterArgs{FromBlock: &from, ToBlock: &to}); err != context.Canceled {
+		t.Errorf("cancellation error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
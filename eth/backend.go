@@ -29,11 +29,14 @@ import (
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/hexutil"
 	"github.com/vsportchain/go-vsc/consensus"
+	"github.com/vsportchain/go-vsc/consensus/bft"
 	"github.com/vsportchain/go-vsc/consensus/clique"
 	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/consensus/switcher"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/bloombits"
 	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/state/pruner"
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/core/vm"
 	"github.com/vsportchain/go-vsc/eth/downloader"
@@ -82,6 +85,8 @@ type VSportChain struct {
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
 
+	internalTxIndexer *core.ChainIndexer // Internal transaction indexer operating during block imports, nil if disabled
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -108,10 +113,16 @@ func New(ctx *node.ServiceContext, config *Config) (*VSportChain, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateDBWithFreezer(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
+	// Resume any state pruning interrupted by a crash, it cannot be left half done
+	if datadir := ctx.ResolvePath(""); datadir != "" {
+		if err := pruner.RecoverPruning(datadir, chainDb); err != nil {
+			log.Error("Failed to recover state", "error", err)
+		}
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -143,8 +154,11 @@ func New(ctx *node.ServiceContext, config *Config) (*VSportChain, error) {
 		rawdb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
 	}
 	var (
-		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		vmConfig = vm.Config{
+			EnablePreimageRecording: config.EnablePreimageRecording,
+			EnableCodeDecoding:      config.EnableCodeDecoding,
+		}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, SnapshotLimit: config.SnapshotCache, FreezeThreshold: config.FreezeThreshold, TxLookupLimit: config.TxLookupLimit}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig)
 	if err != nil {
@@ -158,9 +172,17 @@ func New(ctx *node.ServiceContext, config *Config) (*VSportChain, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if config.InternalTxIndex {
+		eth.internalTxIndexer = NewInternalTxIndexer(chainDb, eth.blockchain)
+		eth.internalTxIndexer.Start(eth.blockchain)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = ctx.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain)
 
 	if eth.protocolManager, err = NewProtocolManager(eth.chainConfig, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb); err != nil {
@@ -208,12 +230,63 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ethdb.Data
 	return db, nil
 }
 
+// CreateDBWithFreezer creates the chain database, moving ancient chain segments
+// into the freezer configured by the user.
+func CreateDBWithFreezer(ctx *node.ServiceContext, config *Config, name string) (ethdb.Database, error) {
+	db, err := ctx.OpenDatabaseWithFreezer(name, config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/")
+	if err != nil {
+		return nil, err
+	}
+	if kvdb, ok := rawdb.KeyValueStore(db).(*ethdb.LDBDatabase); ok {
+		kvdb.Meter("eth/db/chaindata/")
+	}
+	return db, nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an VSportChain service
 func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chainConfig *params.ChainConfig, db ethdb.Database) consensus.Engine {
+	engine := createConsensusEngine(ctx, config, chainConfig, db)
+
+	// If a transition to another engine is scheduled, switch over at the fork block
+	if chainConfig.EngineSwitch != nil {
+		return CreateSwitchEngine(engine, chainConfig.EngineSwitch, db)
+	}
+	return engine
+}
+
+// CreateSwitchEngine creates a consensus engine switching from the given one to
+// the engine configured for the scheduled transition at its fork block. The
+// config must have passed params.ChainConfig.CheckConfig.
+func CreateSwitchEngine(before consensus.Engine, config *params.EngineSwitchConfig, db ethdb.Database) consensus.Engine {
+	var after consensus.Engine
+	switch {
+	case config.Clique != nil:
+		engine := clique.New(config.Clique, db)
+		engine.Transition(config.Block.Uint64(), config.Signers)
+		after = engine
+	case config.BFT != nil:
+		engine := bft.New(config.BFT, db)
+		engine.Transition(config.Block.Uint64(), config.Signers)
+		after = engine
+	default:
+		// The config is checked before the chain is set up, so this is a bug. Never
+		// carry on with the old engine, that would fork the node at the switch.
+		panic(fmt.Sprintf("consensus engine switch at block %v without a new engine", config.Block))
+	}
+	log.Info("Scheduled consensus engine switch", "block", config.Block, "engine", config)
+	return switcher.New(before, after, config.Block.Uint64())
+}
+
+// createConsensusEngine creates the consensus engine the chain was started with.
+func createConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chainConfig *params.ChainConfig, db ethdb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
 	if chainConfig.Clique != nil {
 		return clique.New(chainConfig.Clique, db)
 	}
+	// If byzantine fault tolerance is requested, set it up
+	if chainConfig.BFT != nil {
+		return bft.New(chainConfig.BFT, db)
+	}
 	// Otherwise assume proof-of-work
 	switch config.PowMode {
 	case ethash.ModeFake:
@@ -243,10 +316,20 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chai
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *VSportChain) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.APIBackend)
+	debug := NewPrivateDebugAPI(s.chainConfig, s)
 
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the internal transaction API if the index is maintained
+	if s.internalTxIndexer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "vsc",
+			Version:   "1.0",
+			Service:   NewPublicInternalTxAPI(s),
+			Public:    true,
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -286,7 +369,11 @@ func (s *VSportChain) APIs() []rpc.API {
 		}, {
 			Namespace: "debug",
 			Version:   "1.0",
-			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+			Service:   debug,
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPrivateTraceAPI(debug),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -338,13 +425,27 @@ func (s *VSportChain) StartMining(local bool) error {
 		log.Error("Cannot start mining without vscbase", "err", err)
 		return fmt.Errorf("vscbase missing: %v", err)
 	}
-	if clique, ok := s.engine.(*clique.Clique); ok {
-		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
-		if wallet == nil || err != nil {
-			log.Error("Etherbase account unavailable locally", "err", err)
-			return fmt.Errorf("signer missing: %v", err)
+	for _, engine := range s.engines() {
+		if clique, ok := engine.(*clique.Clique); ok {
+			wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+			if wallet == nil || err != nil {
+				log.Error("Etherbase account unavailable locally", "err", err)
+				return fmt.Errorf("signer missing: %v", err)
+			}
+			clique.Authorize(eb, wallet.SignHash)
+		}
+		if bft, ok := engine.(*bft.BFT); ok {
+			wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+			if wallet == nil || err != nil {
+				log.Error("Etherbase account unavailable locally", "err", err)
+				return fmt.Errorf("validator missing: %v", err)
+			}
+			bft.Authorize(eb, wallet.SignHash)
+			if err := bft.Start(s.blockchain, s.blockchain.VerifyBlock, s.blockchain.InsertChain); err != nil {
+				log.Error("Failed to start consensus", "err", err)
+				return err
+			}
 		}
-		clique.Authorize(eb, wallet.SignHash)
 	}
 	if local {
 		// If local (CPU) mining is started, we can disable the transaction rejection
@@ -357,7 +458,31 @@ func (s *VSportChain) StartMining(local bool) error {
 	return nil
 }
 
-func (s *VSportChain) StopMining()         { s.miner.Stop() }
+func (s *VSportChain) StopMining() {
+	s.miner.Stop()
+	s.stopConsensus()
+}
+
+// engines returns the consensus engines in use, unwrapping any scheduled switch
+// from one engine to another.
+func (s *VSportChain) engines() []consensus.Engine {
+	if switcher, ok := s.engine.(*switcher.Switcher); ok {
+		before, after := switcher.Engines()
+		return []consensus.Engine{before, after}
+	}
+	return []consensus.Engine{s.engine}
+}
+
+// stopConsensus terminates any consensus engine taking part in a messaging
+// protocol of its own.
+func (s *VSportChain) stopConsensus() {
+	for _, engine := range s.engines() {
+		if bft, ok := engine.(*bft.BFT); ok {
+			bft.Stop()
+		}
+	}
+}
+
 func (s *VSportChain) IsMining() bool      { return s.miner.Mining() }
 func (s *VSportChain) Miner() *miner.Miner { return s.miner }
 
@@ -375,10 +500,14 @@ func (s *VSportChain) Downloader() *downloader.Downloader { return s.protocolMan
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *VSportChain) Protocols() []p2p.Protocol {
+	protos := s.protocolManager.SubProtocols
+	if handler, ok := s.engine.(consensus.Handler); ok {
+		protos = append(protos, handler.Protocols()...)
+	}
 	if s.lesServer == nil {
-		return s.protocolManager.SubProtocols
+		return protos
 	}
-	return append(s.protocolManager.SubProtocols, s.lesServer.Protocols()...)
+	return append(protos, s.lesServer.Protocols()...)
 }
 
 // Start implements node.Service, starting all internal goroutines needed by the
@@ -410,6 +539,10 @@ func (s *VSportChain) Start(srvr *p2p.Server) error {
 // VSportChain protocol.
 func (s *VSportChain) Stop() error {
 	s.bloomIndexer.Close()
+	if s.internalTxIndexer != nil {
+		s.internalTxIndexer.Close()
+	}
+	s.stopConsensus()
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
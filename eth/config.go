@@ -55,6 +55,8 @@ var DefaultConfig = Config{
 		Blocks:     20,
 		Percentile: 60,
 	},
+	RPCGasCap:     50000000,
+	RPCEVMTimeout: 5 * time.Second,
 }
 
 func init() {
@@ -91,8 +93,13 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string
+	FreezeThreshold    uint64 `toml:",omitempty"`
+	TxLookupLimit      uint64 `toml:",omitempty"` // Number of recent blocks to index transactions for, 0 indexes all
+	InternalTxIndex    bool   `toml:",omitempty"` // Whether to index the internal value transfers of new blocks
 	TrieCache          int
 	TrieTimeout        time.Duration
+	SnapshotCache      int `toml:",omitempty"` // Megabytes for the state snapshot cache, 0 disables snapshots
 
 	// Mining-related options
 	Etherbase    common.Address `toml:",omitempty"`
@@ -112,6 +119,13 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables execution from pre-decoded contract code in the VM
+	EnableCodeDecoding bool
+
+	// RPC options
+	RPCGasCap     uint64        `toml:",omitempty"` // Gas limit of the calls executed over RPC, 0 means no cap
+	RPCEVMTimeout time.Duration `toml:",omitempty"` // Execution time limit of the calls executed over RPC, 0 means no timeout
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 }
@@ -349,6 +349,10 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 			}
 		}
 	case core.NewTxsEvent:
+		// Private transactions are not disclosed to the subscribers
+		if e.Private {
+			break
+		}
 		hashes := make([]common.Hash, 0, len(e.Txs))
 		for _, tx := range e.Txs {
 			hashes = append(hashes, tx.Hash())
@@ -4,6 +4,7 @@ package eth
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/hexutil"
@@ -25,6 +26,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SkipBcVersionCheck      bool `toml:"-"`
 		DatabaseHandles         int  `toml:"-"`
 		DatabaseCache           int
+		DatabaseFreezer         string
+		FreezeThreshold         uint64         `toml:",omitempty"`
+		TxLookupLimit           uint64         `toml:",omitempty"`
+		InternalTxIndex         bool           `toml:",omitempty"`
+		SnapshotCache           int            `toml:",omitempty"`
 		Etherbase               common.Address `toml:",omitempty"`
 		MinerThreads            int            `toml:",omitempty"`
 		ExtraData               hexutil.Bytes  `toml:",omitempty"`
@@ -33,7 +39,10 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
+		EnableCodeDecoding      bool
+		RPCGasCap               uint64        `toml:",omitempty"`
+		RPCEVMTimeout           time.Duration `toml:",omitempty"`
+		DocRoot                 string        `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -44,6 +53,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.FreezeThreshold = c.FreezeThreshold
+	enc.TxLookupLimit = c.TxLookupLimit
+	enc.InternalTxIndex = c.InternalTxIndex
+	enc.SnapshotCache = c.SnapshotCache
 	enc.Etherbase = c.Etherbase
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
@@ -52,6 +66,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.EnableCodeDecoding = c.EnableCodeDecoding
+	enc.RPCGasCap = c.RPCGasCap
+	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
 }
@@ -66,6 +83,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SkipBcVersionCheck      *bool `toml:"-"`
 		DatabaseHandles         *int  `toml:"-"`
 		DatabaseCache           *int
+		DatabaseFreezer         *string
+		FreezeThreshold         *uint64         `toml:",omitempty"`
+		TxLookupLimit           *uint64         `toml:",omitempty"`
+		InternalTxIndex         *bool           `toml:",omitempty"`
+		SnapshotCache           *int            `toml:",omitempty"`
 		Etherbase               *common.Address `toml:",omitempty"`
 		MinerThreads            *int            `toml:",omitempty"`
 		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
@@ -74,7 +96,10 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
+		EnableCodeDecoding      *bool
+		RPCGasCap               *uint64        `toml:",omitempty"`
+		RPCEVMTimeout           *time.Duration `toml:",omitempty"`
+		DocRoot                 *string        `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -104,6 +129,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
+	if dec.FreezeThreshold != nil {
+		c.FreezeThreshold = *dec.FreezeThreshold
+	}
+	if dec.TxLookupLimit != nil {
+		c.TxLookupLimit = *dec.TxLookupLimit
+	}
+	if dec.InternalTxIndex != nil {
+		c.InternalTxIndex = *dec.InternalTxIndex
+	}
+	if dec.SnapshotCache != nil {
+		c.SnapshotCache = *dec.SnapshotCache
+	}
 	if dec.Etherbase != nil {
 		c.Etherbase = *dec.Etherbase
 	}
@@ -128,6 +168,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.EnableCodeDecoding != nil {
+		c.EnableCodeDecoding = *dec.EnableCodeDecoding
+	}
+	if dec.RPCGasCap != nil {
+		c.RPCGasCap = *dec.RPCGasCap
+	}
+	if dec.RPCEVMTimeout != nil {
+		c.RPCEVMTimeout = *dec.RPCEVMTimeout
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
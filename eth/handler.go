@@ -747,7 +747,10 @@ func (pm *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-pm.txsCh:
-			pm.BroadcastTxs(event.Txs)
+			// Private transactions are only for our own miner, never announce them
+			if !event.Private {
+				pm.BroadcastTxs(event.Txs)
+			}
 
 		// Err() channel will be closed when unsubscribing.
 		case <-pm.txsSub.Err():
@@ -124,6 +124,11 @@ func (p *testTxPool) Pending() (map[common.Address]types.Transactions, error) {
 	return batches, nil
 }
 
+// FilterPrivate returns the given transactions, as none of them are private.
+func (p *testTxPool) FilterPrivate(txs types.Transactions) types.Transactions {
+	return txs
+}
+
 func (p *testTxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return p.txFeed.Subscribe(ch)
 }
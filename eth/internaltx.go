@@ -0,0 +1,276 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus/misc"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+const (
+	// internalTxConfirms is the number of confirmation blocks before a block is
+	// considered probably final and its internal transfers are indexed.
+	internalTxConfirms = 2
+
+	// internalTxThrottling is the time to wait between indexing two consecutive
+	// blocks, preventing a catch up from hogging resources.
+	internalTxThrottling = 10 * time.Millisecond
+)
+
+// transferFrame is a call in progress, gathering the value transfers made by it
+// and its inner calls. The transfers only take effect if the call succeeds.
+type transferFrame struct {
+	create    *types.InternalTransfer   // Value transfer of a contract creation, awaiting the contract address
+	transfers []*types.InternalTransfer // Transfers made by the call and its completed inner calls
+}
+
+// transferTracer is a vm.Tracer recording the value transfers made by contracts
+// during the execution of a transaction, dropping the ones reverted later.
+type transferTracer struct {
+	frames    []*transferFrame          // Calls in progress, the first one being the transaction itself
+	transfers []*types.InternalTransfer // Transfers made by the successfully executed transaction
+}
+
+// CaptureStart implements vm.Tracer, starting the tracing of a transaction.
+func (t *transferTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.frames, t.transfers = []*transferFrame{new(transferFrame)}, nil
+	return nil
+}
+
+// CaptureState implements vm.Tracer, tracking the calls made and the value they
+// transfer.
+func (t *transferTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	// If inner calls returned, merge the transfers of the successful ones into
+	// their parents. The result of the call is at the top of the stack.
+	for len(t.frames) > depth {
+		frame := t.frames[len(t.frames)-1]
+		t.frames = t.frames[:len(t.frames)-1]
+
+		if ret := stack.Back(0); ret.Sign() != 0 {
+			if frame.create != nil {
+				frame.create.To = common.BigToAddress(ret)
+			}
+			parent := t.frames[len(t.frames)-1]
+			parent.transfers = append(parent.transfers, frame.transfers...)
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	// Track any new inner call, along with the value it transfers
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		frame := new(transferFrame)
+		if value := stack.Back(2); op == vm.CALL && value.Sign() > 0 {
+			frame.transfers = append(frame.transfers, &types.InternalTransfer{
+				Type:  op.String(),
+				From:  contract.Address(),
+				To:    common.BigToAddress(stack.Back(1)),
+				Value: new(big.Int).Set(value),
+			})
+		}
+		t.frames = append(t.frames, frame)
+
+	case vm.CREATE, vm.CREATE2:
+		frame := new(transferFrame)
+		if value := stack.Back(0); value.Sign() > 0 {
+			frame.create = &types.InternalTransfer{
+				Type:  op.String(),
+				From:  contract.Address(),
+				Value: new(big.Int).Set(value),
+			}
+			frame.transfers = append(frame.transfers, frame.create)
+		}
+		t.frames = append(t.frames, frame)
+
+	case vm.SELFDESTRUCT:
+		if balance := env.StateDB.GetBalance(contract.Address()); balance.Sign() > 0 {
+			frame := t.frames[len(t.frames)-1]
+			frame.transfers = append(frame.transfers, &types.InternalTransfer{
+				Type:  op.String(),
+				From:  contract.Address(),
+				To:    common.BigToAddress(stack.Back(0)),
+				Value: new(big.Int).Set(balance),
+			})
+		}
+	}
+	return nil
+}
+
+// CaptureFault implements vm.Tracer, ignoring faults as the failure of the call
+// is detected upon returning to its parent.
+func (t *transferTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer, finalizing the transfers of the transaction
+// if it succeeded.
+func (t *transferTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if err == nil && len(t.frames) > 0 {
+		t.transfers = t.frames[0].transfers
+	}
+	t.frames = nil
+	return nil
+}
+
+// InternalTxIndexer implements a core.ChainIndexer, re-executing every new block
+// to record the internal value transfers made by contracts, indexed by account.
+type InternalTxIndexer struct {
+	chain *core.BlockChain
+	db    ethdb.Database // Database instance to write index data into
+
+	number    uint64                                       // Number of the block being processed
+	hash      common.Hash                                  // Hash of the block being processed
+	skipped   bool                                         // Whether the block couldn't be indexed
+	transfers map[common.Address][]*types.InternalTransfer // Transfers of the block by account
+	accounts  []common.Address                             // Accounts in the order of their first transfer
+}
+
+// NewInternalTxIndexer returns a chain indexer that records the internal value
+// transfers of the canonical chain. Only blocks imported after the index was
+// first enabled are indexed, as historical state is needed for re-execution.
+func NewInternalTxIndexer(db ethdb.Database, chain *core.BlockChain) *core.ChainIndexer {
+	backend := &InternalTxIndexer{
+		chain: chain,
+		db:    db,
+	}
+	table := ethdb.NewTable(db, string(rawdb.InternalTxIndexPrefix))
+	indexer := core.NewChainIndexer(db, table, backend, 1, internalTxConfirms, internalTxThrottling, "internaltx")
+
+	if rawdb.ReadInternalTxIndexTail(db) == nil {
+		head := chain.CurrentBlock()
+		indexer.AddKnownSectionHead(head.NumberU64(), head.Hash())
+		rawdb.WriteInternalTxIndexTail(db, head.NumberU64()+1)
+
+		log.Info("Enabled internal transaction indexing", "tail", head.NumberU64()+1)
+	}
+	return indexer
+}
+
+// Reset implements core.ChainIndexerBackend, starting the indexing of a new block.
+func (b *InternalTxIndexer) Reset(section uint64, lastSectionHead common.Hash) error {
+	b.number, b.hash, b.skipped = section, common.Hash{}, false
+	b.transfers, b.accounts = make(map[common.Address][]*types.InternalTransfer), nil
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, re-executing the block on top of
+// its parent state and gathering the internal transfers. Blocks whose parent
+// state is no longer available are skipped, leaving a gap in the index.
+func (b *InternalTxIndexer) Process(header *types.Header) {
+	b.hash = header.Hash()
+
+	block := b.chain.GetBlock(b.hash, b.number)
+	if block == nil {
+		b.skip(errors.New("unknown block"))
+		return
+	}
+	if len(block.Transactions()) == 0 {
+		return
+	}
+	parent := b.chain.GetHeader(block.ParentHash(), b.number-1)
+	if parent == nil {
+		b.skip(errors.New("unknown parent"))
+		return
+	}
+	statedb, err := b.chain.StateAt(parent.Root)
+	if err != nil {
+		b.skip(err)
+		return
+	}
+	transfers, err := traceTransfers(b.chain, block, statedb)
+	if err != nil {
+		b.skip(err)
+		return
+	}
+	for _, transfer := range transfers {
+		b.add(transfer.From, transfer)
+		if transfer.To != transfer.From {
+			b.add(transfer.To, transfer)
+		}
+	}
+}
+
+// skip marks the block being processed as not indexable.
+func (b *InternalTxIndexer) skip(err error) {
+	log.Warn("Internal transaction indexing skipped block", "number", b.number, "hash", b.hash, "err", err)
+	b.skipped = true
+}
+
+// add records a transfer an account took part in.
+func (b *InternalTxIndexer) add(addr common.Address, transfer *types.InternalTransfer) {
+	if _, ok := b.transfers[addr]; !ok {
+		b.accounts = append(b.accounts, addr)
+	}
+	b.transfers[addr] = append(b.transfers[addr], transfer)
+}
+
+// Commit implements core.ChainIndexerBackend, writing the transfers of the block
+// out into the database. If the block was skipped, it is recorded as a gap in the
+// index instead, so that its missing transfers are never served as complete.
+func (b *InternalTxIndexer) Commit() error {
+	batch := b.db.NewBatch()
+	if b.skipped {
+		rawdb.WriteInternalTxGap(batch, b.number, b.hash)
+		return batch.Write()
+	}
+	for _, addr := range b.accounts {
+		rawdb.WriteInternalTransfers(batch, addr, b.number, b.hash, b.transfers[addr])
+	}
+	rawdb.DeleteInternalTxGap(batch, b.number, b.hash)
+	return batch.Write()
+}
+
+// traceTransfers re-executes all the transactions of a block on top of the given
+// state, returning the internal value transfers made by them.
+func traceTransfers(chain *core.BlockChain, block *types.Block, statedb *state.StateDB) ([]*types.InternalTransfer, error) {
+	var (
+		config    = chain.Config()
+		header    = block.Header()
+		gp        = new(core.GasPool).AddGas(block.GasLimit())
+		usedGas   = new(uint64)
+		transfers []*types.InternalTransfer
+	)
+	// Mutate the the block and state according to any hard-fork specs
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	for i, tx := range block.Transactions() {
+		tracer := new(transferTracer)
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		if _, _, err := core.ApplyTransaction(config, chain, nil, gp, statedb, header, tx, usedGas, vm.Config{Debug: true, Tracer: tracer}); err != nil {
+			return nil, err
+		}
+		for _, transfer := range tracer.transfers {
+			transfer.TxHash, transfer.TxIndex = tx.Hash(), uint(i)
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, statedb.Error()
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+)
+
+// Tests that the internal value transfers made by contracts are gathered when
+// re-executing a block, dropping the ones reverted afterwards.
+func TestInternalTransferTracing(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+
+		sink       = common.Address{0xff}
+		forwarder  = common.Address{0x01}
+		reverter   = common.Address{0x02}
+		destructor = common.Address{0x03}
+	)
+	// forwarder calls sink with the received value, reverter does the same and
+	// reverts afterwards, while destructor self destructs to sink
+	call := append(append([]byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x34, 0x73}, sink.Bytes()...), 0x5a, 0xf1)
+	forward := append(append([]byte{}, call...), 0x00)
+	revert := append(append([]byte{}, call...), 0x60, 0x00, 0x60, 0x00, 0xfd)
+	destruct := append(append([]byte{0x73}, sink.Bytes()...), 0xff)
+
+	var (
+		db    = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				sender:     {Balance: big.NewInt(params.Ether)},
+				forwarder:  {Code: forward, Balance: new(big.Int)},
+				reverter:   {Code: revert, Balance: new(big.Int)},
+				destructor: {Code: destruct, Balance: big.NewInt(5)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
+		for nonce, to := range []common.Address{forwarder, reverter, destructor} {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), to, big.NewInt(100), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+			gen.AddTx(tx)
+		}
+	})
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, err := chain.StateAt(genesis.Root())
+	if err != nil {
+		t.Fatalf("failed to retrieve genesis state: %v", err)
+	}
+	transfers, err := traceTransfers(chain, blocks[0], statedb)
+	if err != nil {
+		t.Fatalf("failed to trace transfers: %v", err)
+	}
+	want := []*types.InternalTransfer{
+		{TxHash: blocks[0].Transactions()[0].Hash(), TxIndex: 0, Type: "CALL", From: forwarder, To: sink, Value: big.NewInt(100)},
+		{TxHash: blocks[0].Transactions()[2].Hash(), TxIndex: 2, Type: "SELFDESTRUCT", From: destructor, To: sink, Value: big.NewInt(105)},
+	}
+	if len(transfers) != len(want) {
+		t.Fatalf("transfer count mismatch: have %d, want %d", len(transfers), len(want))
+	}
+	for i, transfer := range transfers {
+		if transfer.TxHash != want[i].TxHash || transfer.TxIndex != want[i].TxIndex || transfer.Type != want[i].Type ||
+			transfer.From != want[i].From || transfer.To != want[i].To || transfer.Value.Cmp(want[i].Value) != 0 {
+			t.Errorf("transfer %d: mismatch: have %+v, want %+v", i, transfer, want[i])
+		}
+	}
+}
+
+// Tests that blocks which can't be re-executed for lack of state are recorded as
+// gaps in the internal transaction index, keeping the earlier indexed history.
+func TestInternalTxIndexerSkip(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+
+		gendb = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+		}
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, 2, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		gen.AddTx(tx)
+	})
+	// Import the blocks without their state, leaving only the genesis executable
+	db := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	for _, block := range blocks {
+		rawdb.WriteBlock(db, block)
+	}
+	rawdb.WriteInternalTxIndexTail(db, 1)
+
+	indexer := &InternalTxIndexer{chain: chain, db: db}
+	for _, block := range blocks {
+		indexer.Reset(block.NumberU64(), block.ParentHash())
+		indexer.Process(block.Header())
+		if err := indexer.Commit(); err != nil {
+			t.Fatalf("block %d: failed to commit: %v", block.NumberU64(), err)
+		}
+	}
+	// The first block runs on the genesis state, the second one has none
+	if tail := rawdb.ReadInternalTxIndexTail(db); tail == nil || *tail != 1 {
+		t.Fatalf("index tail mismatch: have %v, want %d", tail, 1)
+	}
+	var gaps []uint64
+	rawdb.IterateInternalTxGaps(db, 0, 100, func(number uint64, hash common.Hash) bool {
+		if hash != blocks[number-1].Hash() {
+			t.Errorf("block %d: gap hash mismatch: have %x, want %x", number, hash, blocks[number-1].Hash())
+		}
+		gaps = append(gaps, number)
+		return true
+	})
+	if len(gaps) != 1 || gaps[0] != 2 {
+		t.Fatalf("index gaps mismatch: have %v, want [2]", gaps)
+	}
+}
@@ -103,6 +103,10 @@ type txPool interface {
 	// The slice should be modifiable by the caller.
 	Pending() (map[common.Address]types.Transactions, error)
 
+	// FilterPrivate should return the given transactions without the ones that
+	// must not be propagated.
+	FilterPrivate(txs types.Transactions) types.Transactions
+
 	// SubscribeNewTxsEvent should return an event subscription of
 	// NewTxsEvent and send events to the given channel.
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
@@ -49,6 +49,7 @@ func (pm *ProtocolManager) syncTransactions(p *peer) {
 	for _, batch := range pending {
 		txs = append(txs, batch...)
 	}
+	txs = pm.txpool.FilterPrivate(txs)
 	if len(txs) == 0 {
 		return
 	}
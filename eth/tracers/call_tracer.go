@@ -0,0 +1,295 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/vsportchain/go-vsc/accounts/abi"
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/core/vm"
+)
+
+// callFrame is a single call reported by the call tracer. The field order is
+// the serialization order of the JavaScript tracer, which doesn't report the
+// decoded revert reason.
+type callFrame struct {
+	Type         string       `json:"type"`
+	From         string       `json:"from,omitempty"`
+	To           string       `json:"to,omitempty"`
+	Value        string       `json:"value,omitempty"`
+	Gas          string       `json:"gas,omitempty"`
+	GasUsed      string       `json:"gasUsed,omitempty"`
+	Input        string       `json:"input,omitempty"`
+	Output       string       `json:"output,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	RevertReason string       `json:"revertReason,omitempty"`
+	Time         string       `json:"time,omitempty"`
+	Calls        []*callFrame `json:"calls,omitempty"`
+
+	gasIn   uint64   // Gas available before the call opcode
+	gasCost uint64   // Cost of the call opcode
+	gas     *big.Int // True gas allowance within the call, nil if unknown
+	outOff  *big.Int // Memory offset of the call output
+	outLen  *big.Int // Memory length of the call output
+}
+
+// finish formats the gas allowance of the call and drops the bookkeeping fields
+// not needed any more once the call returned.
+func (call *callFrame) finish() {
+	if call.gas != nil {
+		call.Gas = hexBig(call.gas)
+	}
+	call.outOff, call.outLen = nil, nil
+}
+
+// callTracer is the native implementation of the JavaScript callTracer, which
+// extracts and reports all the internal calls made by a transaction.
+type callTracer struct {
+	callstack []*callFrame // Current recursive call stack of the EVM execution
+	descended bool         // Whether we've just descended into an inner call
+
+	ctx callFrame // Transaction context gathered throughout execution
+	err error     // Error, if one has occurred
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newCallTracer creates a new native call tracer.
+func newCallTracer() ResultTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// Stop implements ResultTracer, interrupting the tracing with the given error.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.ctx.Type = "CALL"
+	if create {
+		t.ctx.Type = "CREATE"
+	}
+	t.ctx.From = hexutil.Encode(from[:])
+	t.ctx.To = hexutil.Encode(to[:])
+	t.ctx.Value = hexBig(value)
+	t.ctx.Gas = hexBig(new(big.Int).SetUint64(gas))
+	t.ctx.Input = hexutil.Encode(input)
+
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return nil
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(gas, err)
+		return nil
+	}
+	peek := (&stackWrapper{stack}).peek
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		// If a new contract is being created, add to the call stack
+		address := contract.Address()
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(address[:]),
+			Input:   hexutil.Encode(memorySlice(memory, peek(1), peek(2))),
+			Value:   hexBig(peek(0)),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return nil
+
+	case vm.SELFDESTRUCT:
+		// If a contract is being self destructed, gather that as a subcall too
+		address, beneficiary := contract.Address(), common.BigToAddress(peek(0))
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &callFrame{
+			Type:  op.String(),
+			From:  hexutil.Encode(address[:]),
+			To:    hexutil.Encode(beneficiary[:]),
+			Value: hexBig(env.StateDB.GetBalance(address)),
+		})
+		return nil
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.BigToAddress(peek(1))
+		if env.IsPrecompiled(to) {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		address := contract.Address()
+		call := &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(address[:]),
+			To:      hexutil.Encode(to[:]),
+			Input:   hexutil.Encode(memorySlice(memory, peek(2+off), peek(3+off))),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  new(big.Int).Set(peek(4 + off)),
+			outLen:  new(big.Int).Set(peek(5 + off)),
+		}
+		if op != vm.DELEGATECALL && op != vm.STATICCALL {
+			call.Value = hexBig(peek(2))
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	if t.descended {
+		if depth >= len(t.callstack) {
+			t.callstack[len(t.callstack)-1].gas = new(big.Int).SetUint64(gas)
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if op == vm.REVERT {
+		call := t.callstack[len(t.callstack)-1]
+		call.Error = "execution reverted"
+		if reason, err := abi.UnpackRevert(memorySlice(memory, peek(0), peek(1))); err == nil {
+			call.RevertReason = reason
+		}
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		ret := peek(0)
+		if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+			// If the call was a CREATE, retrieve the contract address and output code
+			used := new(big.Int).SetUint64(call.gasIn)
+			used.Sub(used, new(big.Int).SetUint64(call.gasCost))
+			call.GasUsed = hexBig(used.Sub(used, new(big.Int).SetUint64(gas)))
+
+			if ret.Sign() != 0 {
+				address := common.BigToAddress(ret)
+				call.To = hexutil.Encode(address[:])
+				call.Output = hexutil.Encode(env.StateDB.GetCode(address))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else if call.gas != nil {
+			// If the call was a contract call, retrieve the gas usage and output
+			used := new(big.Int).SetUint64(call.gasIn)
+			used.Sub(used, new(big.Int).SetUint64(call.gasCost))
+			used.Add(used, call.gas)
+			call.GasUsed = hexBig(used.Sub(used, new(big.Int).SetUint64(gas)))
+
+			if ret.Sign() != 0 {
+				call.Output = hexutil.Encode(memorySlice(memory, call.outOff, call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		call.finish()
+
+		// Inject the call into the previous one
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.err == nil {
+		t.fault(gas, err)
+	}
+	return nil
+}
+
+// fault handles the failure of the topmost call.
+func (t *callTracer) fault(gas uint64, err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+
+	// Consume all available gas and clean any leftovers
+	call.finish()
+	if call.gas != nil {
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.ctx.GasUsed = hexBig(new(big.Int).SetUint64(gasUsed))
+	t.ctx.Output = hexutil.Encode(output)
+	t.ctx.Time = d.String()
+	if err != nil {
+		t.ctx.Error = err.Error()
+	}
+	return nil
+}
+
+// GetResult implements ResultTracer, returning the top level call with all the
+// internal calls nested within, or any error that occurred while tracing.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	result := t.ctx
+	result.Calls = t.callstack[0].Calls
+	if t.callstack[0].Error != "" {
+		result.Error, result.RevertReason = t.callstack[0].Error, t.callstack[0].RevertReason
+	}
+	if result.Error != "" {
+		result.Output = ""
+	}
+	return marshalResult(&result)
+}
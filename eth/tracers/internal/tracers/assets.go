@@ -92,7 +92,7 @@ func _4byte_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x59\xdf\x6f\x1b\x37\xf2\x7f\x96\xfe\x8a\x49\x1e\x6a\x09\x51\x24\x27\xe9\xb7\x5f\xc0\xae\x7a\xd0\x39\x4a\x6a\xc0\x8d\x03\x5b\x69\x10\x04\x79\xa0\x76\x67\x25\xd6\x5c\x72\x4b\x72\x2d\xef\xa5\xfe\xdf\x0f\x33\xe4\xae\x56\x3f\xec\xe8\x7a\xb8\x43\xef\x45\xd0\x2e\x67\x86\xc3\x99\xcf\xfc\xe2\x8e\x46\x70\x66\x8a\xca\xca\xc5\xd2\xc3\xcb\xe3\x17\xff\x0f\xb3\x25\xc2\xc2\x3c\x47\xbf\x44\x8b\x65\x0e\x93\xd2\x2f\x8d\x75\xdd\xd1\x08\x66\x4b\xe9\x20\x93\x0a\x41\x3a\x28\x84\xf5\x60\x32\xf0\x5b\xf4\x4a\xce\xad\xb0\xd5\xb0\x3b\x1a\x05\x9e\xbd\xcb\x24\x21\xb3\x88\xe0\x4c\xe6\x57\xc2\xe2\x09\x54\xa6\x84\x44\x68\xb0\x98\x4a\xe7\xad\x9c\x97\x1e\x41\x7a\x10\x3a\x1d\x19\x0b\xb9\x49\x65\x56\x91\x48\xe9\xa1\xd4\x29\x5a\xde\xda\xa3\xcd\x5d\xad\xc7\xdb\x77\x1f\xe0\x02\x9d\x43\x0b\x6f\x51\xa3\x15\x0a\xde\x97\x73\x25\x13\xb8\x90\x09\x6a\x87\x20\x1c\x14\xf4\xc6\x2d\x31\x85\x39\x8b\x23\xc6\x37\xa4\xca\x75\x54\x05\xde\x98\x52\xa7\xc2\x4b\xa3\x07\x80\x92\x34\x87\x5b\xb4\x4e\x1a\x0d\xaf\xea\xad\xa2\xc0\x01\x18\x4b\x42\x7a\xc2\xd3\x01\x2c\x98\x82\xf8\xfa\x20\x74\x05\x4a\xf8\x35\xeb\x01\x06\x59\x9f\x3b\x05\xa9\x79\x9b\xa5\x29\x10\xfc\x52\x78\x3a\xf5\x4a\x2a\x05\x73\x84\xd2\x61\x56\xaa\x01\x49\x9b\x97\x1e\x3e\x9e\xcf\x7e\xbe\xfc\x30\x83\xc9\xbb\x4f\xf0\x71\x72\x75\x35\x79\x37\xfb\x74\x0a\x2b\xe9\x97\xa6\xf4\x80\xb7\x18\x44\xc9\xbc\x50\x12\x53\x58\x09\x6b\x85\xf6\x15\x98\x8c\x24\xfc\x32\xbd\x3a\xfb\x79\xf2\x6e\x36\xf9\xfb\xf9\xc5\xf9\xec\x13\x18\x0b\x6f\xce\x67\xef\xa6\xd7\xd7\xf0\xe6\xf2\x0a\x26\xf0\x7e\x72\x35\x3b\x3f\xfb\x70\x31\xb9\x82\xf7\x1f\xae\xde\x5f\x5e\x4f\x87\x70\x8d\xa4\x15\x12\xff\xb7\x6d\x9e\xb1\xf7\x2c\x42\x8a\x5e\x48\xe5\x6a\x4b\x7c\x32\x25\xb8\xa5\x29\x55\x0a\x4b\x71\x8b\x60\x31\x41\x79\x8b\x29\x08\x48\x4c\x51\x1d\xec\x54\x92\x25\x94\xd1\x0b\x3e\xf3\x83\x80\x84\xf3\x0c\xb4\xf1\x03\x70\x88\xf0\xe3\xd2\xfb\xe2\x64\x34\x5a\xad\x56\xc3\x85\x2e\x87\xc6\x2e\x46\x2a\x88\x73\xa3\x9f\x86\x5d\x92\x99\x08\xa5\x66\x56\x24\x68\xc9\x39\x02\xb2\x92\xcc\xaf\xcc\x4a\x83\xb7\x42\x3b\x91\x90\xab\xe9\x7f\xc2\x60\x14\x1e\xf0\x8e\x9e\xbc\x23\xd0\x82\xc5\xc2\x58\xfa\xaf\x54\x8d\x33\xa9\x3d\x5a\x2d\x14\xcb\x76\x90\x8b\x14\x61\x5e\x81\x68\x0b\x1c\xb4\x0f\x43\x30\x0a\xee\x06\xa9\x33\x63\x73\x86\xe5\xb0\xfb\xb5\xdb\x89\x1a\x3a\x2f\x92\x1b\x52\x90\xe4\x27\xa5\xb5\xa8\x3d\x99\xb2\xb4\x4e\xde\x22\x93\x40\xa0\x89\xf6\x9c\xfe\xfa\x0b\xe0\x1d\x26\x65\x90\xd4\x69\x84\x9c\xc0\xe7\xaf\xf7\x5f\x06\x5d\x16\x9d\xa2\x4b\x50\xa7\x98\xf2\xf9\x6e\x1c\xac\x96\x6c\x51\x58\xe1\xd1\x2d\xc2\x6f\xa5\xf3\x2d\x9a\xcc\x9a\x1c\x84\x06\x53\x12\xe2\xdb\xd6\x91\xda\x1b\x16\x28\xe8\xbf\x46\xcb\x1a\x0d\xbb\x9d\x86\xf9\x04\x32\xa1\x1c\xc6\x7d\x9d\xc7\x82\x4e\x23\xf5\xad\xb9\x21\xc9\xc6\x12\x84\x6d\x05\xa6\x48\x4c\x1a\x83\x81\xce\xd1\x1c\x03\xdd\xb0\xdb\x21\xbe\x13\xc8\x4a\xcd\xdb\xf6\x94\x59\x0c\x20\x9d\xf7\xe1\x6b\xb7\x43\x62\xcf\x44\xe1\x4b\x8b\x6c\x4f\xb4\xd6\x58\x07\x32\xcf\x31\x95\xc2\xa3\xaa\xba\x9d\xce\xad\xb0\x61\x01\xc6\xa0\xcc\x62\xb8\x40\x3f\xa5\xc7\x5e\xff\xb4\xdb\xe9\xc8\x0c\x7a\x61\xf5\xc9\x78\xcc\xd9\x27\x93\x1a\xd3\x20\xbe\xe3\x97\xd2\x0d\x33\x51\x2a\xdf\xec\x4b\x4c\x1d\x8b\xbe\xb4\x9a\xfe\xde\x07\x2d\x3e\x22\x18\xad\x2a\x48\x28\xcb\x88\x39\x85\xa7\xab\x9c\xc7\x3c\x1e\xce\x0d\x20\x13\x8e\x4c\x28\x33\x58\x21\x14\x16\x9f\x27\x4b\x24\xdf\xe9\x04\xa3\x96\xae\x72\xec\xd4\x31\xd0\x6e\x43\x53\x0c\xbd\x79\x57\xe6\x73\xb4\xbd\x3e\x7c\x07\xc7\x77\xd9\x71\x1f\xc6\x63\xfe\x53\xeb\x1e\x79\xa2\xbe\x24\xc5\x14\xf1\xa0\xcc\x7f\xed\xad\xd4\x8b\x70\xd6\xa8\xeb\x79\x06\x02\x34\xae\x20\x31\x9a\x41\x4d\x5e\x99\xa3\xd4\x0b\x48\x2c\x0a\x8f\xe9\x00\x44\x9a\x82\x37\x01\x79\x0d\xce\x36\xb7\x84\xef\xbe\xe3\xbd\xc6\x70\x74\x76\x35\x9d\xcc\xa6\x47\x2d\x25\xa4\xbe\xcc\xb2\xa8\x07\xf3\x0e\x0b\xc4\x9b\xde\x8b\xfe\xf0\x56\xa8\x12\x2f\xb3\xa0\x51\xa4\x9d\xea\x14\xc6\x91\xe7\xd9\x36\xcf\xcb\x0d\x1e\x62\x1a\x8d\x60\xe2\x1c\xe6\x73\x85\xbb\xb1\x17\x83\x93\xe3\xd4\x79\x4a\x4e\x04\xb4\xc4\xe4\x85\x42\x02\x50\xbd\x6b\xb4\x34\x6b\xdc\xf1\x55\x81\x27\x00\x00\xa6\x18\xf0\x0b\x82\x3d\xbf\xf0\xe6\x67\xbc\x63\x77\xd4\xd6\x22\x00\x4d\xd2\xd4\xa2\x73\xbd\x7e\x3f\x90\x4b\x5d\x94\xfe\x64\x83\x3c\xc7\xdc\xd8\x6a\xe8\x28\xf7\xf4\xf8\x68\x83\x70\xd2\x9a\x67\x21\xdc\xb9\x26\x9e\x08\xca\xb7\xc2\xf5\xd6\x4b\x67\xc6\xf9\x93\x7a\x89\x1e\xea\x35\xb6\x05\xb1\x1d\x1d\xdf\x1d\xed\x5a\xeb\xb8\xbf\x76\xfa\x8b\x1f\xfa\xc4\x72\x7f\xda\x40\xb9\xc9\x08\xc3\xa2\x74\xcb\x1e\x23\x67\xbd\xba\x8e\xfa\x31\x78\x5b\xe2\x5e\xa4\x33\x7a\x76\x91\xe3\x50\x65\x94\x36\xbc\x2d\x13\x46\xd0\x42\x70\x52\xe1\xa0\x16\x94\x64\x5d\x39\x67\x9b\x7b\x63\x1e\x04\xd2\xf5\xf4\xe2\xcd\xeb\xe9\xf5\xec\xea\xc3\xd9\xac\x0d\x27\x85\x99\x27\xa5\x36\xcf\xa0\x50\x2f\xfc\x92\xf5\x27\x71\x9b\xab\x9f\x89\xe7\xf9\x8b\x2f\xe1\x0d\x8c\xf7\x44\x77\xe7\x71\x0e\xf8\xfc\x85\x65\xdf\xef\x9a\x6f\x93\x34\x18\xf3\x6b\x00\x91\x29\xee\xdb\x39\x62\x4f\xd8\xe5\xe8\x97\x26\xe5\x3c\x98\x88\x90\x4a\x6b\x2b\xa6\x46\xe3\xc1\xc1\xd7\xab\xa3\x6f\x72\x71\x71\x04\x7f\xfc\x01\xad\xe7\xb3\xcb\xd7\xd3\xf6\xbb\xd7\xd3\x8b\xe9\xdb\xc9\x6c\xba\x4d\x7b\x3d\x9b\xcc\xce\xcf\xf8\x6d\x3f\x5a\x65\x34\x82\xeb\x1b\x59\x70\x42\xe5\x34\x65\xf2\x82\x3b\xc3\x46\x5f\x37\x00\xbf\x34\xd4\x73\xd9\x58\x2f\x32\xa1\x93\x3a\x8f\xbb\xda\x69\xde\x90\xcb\x4c\x1d\x2b\xbb\xa9\xa0\x0d\xd4\x7e\xe3\x46\xe9\xde\x5b\x8c\x9b\xa6\x3d\x6f\x6a\xbd\xd6\x06\x0d\x1e\xe1\x5c\xc7\x49\xa6\x77\xf8\x21\xe1\x6f\x70\x0c\x27\xf0\x22\x66\x92\x47\x52\xd5\x4b\x78\x46\xe2\xff\x44\xc2\x7a\xb5\x87\xf3\xaf\x99\xb6\xbc\x61\xe2\x9a\xdc\x9b\xff\x7e\x3a\x33\xa5\xbf\xcc\xb2\x13\xd8\x36\xe2\xf7\x3b\x46\x6c\xe8\x2f\x50\xef\xd2\xff\xdf\x0e\xfd\x3a\xf5\x11\xaa\x4c\x01\x4f\x76\x20\x12\x12\xcf\x93\xad\x38\x88\xc6\xe5\x6e\x86\xa5\xc1\xf8\x81\x64\xfb\x72\x13\xc3\x0f\x65\x8b\x7f\x2b\xd9\xee\xed\xca\xa8\xf7\xda\xec\xbb\x06\x60\xd1\x5b\x89\xb7\x34\x59\x1d\x39\x16\x49\xfd\xa9\x59\x09\x9d\xe0\x10\x3e\x62\x90\xa8\x11\x39\xb9\xc4\x7e\x96\xda\x11\x6e\xf1\xa8\x27\x8d\x93\x09\x43\x4c\x70\xdb\x69\x11\x72\x51\xd1\x64\x92\x95\xfa\xa6\x82\x85\x70\x90\x56\x5a\xe4\x32\x71\x41\x1e\xf7\xb2\x16\x17\xc2\xb2\x58\x8b\xbf\x97\xe8\x68\xcc\x21\x20\x8b\xc4\x97\x42\xa9\x0a\x16\x92\x66\x15\xe2\xee\xbd\x7c\x75\x7c\x0c\xce\xcb\x02\x75\x3a\x80\x1f\x5e\x8d\x7e\xf8\x1e\x6c\xa9\xb0\x3f\xec\xb6\xd2\x78\x73\xd4\xe8\x0d\x5a\x88\xe8\x79\x8d\x85\x5f\xf6\xfa\xf0\xd3\x03\xf5\xe0\x81\xe4\xbe\x97\x16\x9e\xc3\x8b\x2f\x43\xd2\x6b\xbc\x81\xdb\xe0\x49\x40\xe5\x30\x4a\xa3\xf9\xee\xf2\xf5\x65\xef\x46\x58\xa1\xc4\x1c\xfb\x27\x3c\xef\xb1\xad\x56\x22\x36\xfc\xe4\x14\x28\x94\x90\x1a\x44\x92\x98\x52\x7b\x32\x7c\xdd\xbb\xab\x8a\xf2\xfb\x91\xaf\xe5\xf1\x68\x24\x92\x04\x9d\xab\xd3\x3d\x7b\x8d\xd4\x11\x39\x71\x83\xd4\x4e\xa6\xd8\xf2\x0a\x65\x07\xc3\xa9\x39\x52\xd0\xe4\x58\x0b\xcc\x8d\xa3\x4d\xe6\x08\x2b\x4b\x73\x86\x93\x3a\xe1\x41\x3b\x45\xb2\xb6\x03\xa3\x41\x80\x32\x3c\xdd\x73\x8c\x83\xb0\x0b\x37\x0c\xf9\x9e\xb6\xa5\x9c\xa3\xcd\x6a\xb8\x09\xe4\x36\x54\xb9\xa3\xdf\x6a\x07\x34\xe0\x9d\x74\x9e\x1b\x48\xd2\x52\x3a\x08\x48\x96\x7a\x31\x80\xc2\x14\x9c\xa7\x0f\xec\x25\xaf\xa6\xbf\x4e\xaf\x9a\xe2\x7f\xb8\x13\xeb\x16\xff\x69\x33\x01\x81\xa5\xf1\xc2\x63\xfa\x74\x4f\xcf\xbe\x07\x50\xe3\x07\x00\x45\xf2\xd7\xb5\xf1\x7d\xeb\x38\x4a\x38\xbf\x76\xcc\x02\xc3\xf8\xd2\x56\xc0\x95\xca\xbb\xad\xdc\xbd\x9d\x1c\x4c\x51\x57\x08\x52\x8a\xd3\x0e\x25\xf6\x3d\x9d\x75\x34\xb8\x6f\x03\x4f\x40\xa0\x69\x25\x00\x5e\xaf\x3b\x34\x11\x72\x3e\x6b\x68\x4a\x4f\x4e\xa7\x2a\xbd\x4e\x71\x0b\xe1\x3e\x38\xf6\x6d\x4c\x72\x73\xb9\x38\xd7\xbe\x57\x2f\x9e\x6b\x78\x0e\xf5\x03\xa5\x6e\x78\xbe\x11\x2b\x7b\x72\x60\x27\x45\x85\x1e\x61\x2d\xe2\x14\xb6\x5e\x91\xa0\x70\x68\x36\x8d\x45\xbf\x5b\x82\x8f\xa3\x34\x32\xcb\x13\x8b\x7e\x88\xbf\x97\x42\xb9\xde\x71\xd3\x12\x84\x13\x78\xc3\x45\x6c\xdc\x94\xb1\xba\xce\x11\xcf\x46\x93\x11\x05\x06\xb6\x68\x8d\x9a\x2d\x9d\x87\xda\x94\xe2\xa3\x12\xa2\x88\x98\x1c\x1a\x8f\x45\xf8\xed\xeb\x32\x3b\x6d\x02\x78\xda\x94\xfd\x4c\x48\x55\x5a\x7c\x7a\x0a\x7b\x92\x8b\x2b\x6d\x26\x12\xf6\xa5\x43\xe0\x11\xd4\x81\x33\x39\x2e\xcd\x2a\x28\xb0\x2f\x45\xed\x82\xa3\xc1\xc1\x56\x91\xe0\xbb\x14\xe1\xa0\x74\x62\x81\x2d\x70\x34\x06\xaf\x1d\xb5\x77\x2e\xfe\xd3\xd0\x79\xd6\x3c\x7e\x03\x45\x61\x97\x6f\x42\xe3\x31\x6c\xec\xf5\xf2\x4e\x2f\x53\x13\x71\x47\xd3\x7a\xa8\x55\x0d\x0d\x47\x83\x9c\x7f\xc5\xef\xff\x19\xc7\x07\xcf\xc7\xdf\x43\x03\x6d\x9b\x36\x9c\x71\x93\x38\x9c\x74\xdd\xc4\x7c\x1b\x05\xcd\xea\x43\x00\x78\xa8\x3f\x22\xa8\xea\xdf\x30\xf1\x6b\xb8\x72\x4b\x43\x4f\x85\xc5\x5b\x69\x4a\xaa\x56\xf8\xbf\x34\xff\x35\xfd\xdd\x7d\xb7\x73\x1f\xef\xbc\xd8\x7d\xed\x4b\xaf\xd5\x32\xde\xd9\x86\xd6\xa8\x55\x2b\x0c\x17\xd2\x78\x15\x96\x85\xdb\xd4\x0e\xf3\x3f\x72\xf9\x15\xe3\xdd\x9b\x82\x6a\x7f\x2c\x45\xca\xa2\x48\xab\xa6\xfa\x0d\x42\xd7\x01\x4b\xa1\xd3\x38\x79\x88\x34\x95\x24\x8f\xb1\x48\x1a\x8a\x85\x90\xba\xbb\xd7\x8c\xdf\x2c\xb9\xfb\x90\xb1\xd3\xc8\xb6\xab\x66\x9c\x18\x69\xbc\x63\x8d\xbb\x07\x54\xc7\xad\x58\xda\xbe\xc7\x8b\x57\x81\x46\xbb\x32\xe7\xb6\x17\xc4\xad\x90\x4a\xd0\xa8\xc5\xed\x94\x4e\x21\x51\x28\x74\xb8\xbd\xc7\xcc\x9b\x5b\xb4\xae\x7b\x00\xc8\xff\x0c\xc6\xb7\x92\x63\xfd\x18\xcd\x71\x78\xcc\x1e\x1a\xb1\xe1\xf8\x6f\x94\xf0\x3e\xc2\xab\x65\xde\x10\x59\xd2\xf3\x87\x1d\xd4\xbe\x7b\x58\x48\x71\x83\x44\x34\x3f\xc1\x71\xab\x09\xff\xab\x04\xd9\x2e\xc4\x2e\x9a\x66\x2c\x1e\xde\x1b\x33\x00\x85\x82\x47\xa2\xfa\xb3\x4b\xdd\x7c\x3e\x36\xa1\xd5\xd1\x1b\xda\xb7\x9d\xf0\xe5\x4b\xac\x25\xd6\xd7\x1d\xa1\x8f\x9f\x23\x6a\x90\x1e\xad\xa0\xe1\x87\xd0\x15\xbf\x14\x90\x96\x8e\xc5\xb1\x5f\x24\x05\x5d\x14\x1c\xaf\xed\xa9\x3e\x4b\xbd\x18\x76\x3b\xe1\x7d\x2b\xde\x13\x7f\xb7\x8e\xf7\x50\x0c\x99\x33\x5e\x00\x34\xf3\x7f\xe2\xef\xb8\x67\xe4\x19\x79\xeb\x12\x80\xd6\xe8\x55\x18\xa0\xb7\x46\x7e\x66\x8c\x63\xff\xf6\xcd\x22\xad\xf1\xbb\x0d\x80\x33\xe9\x42\xb8\x20\x66\x2b\x24\xfc\xdd\x6e\x44\xd4\x0c\x14\x0c\x27\xfb\x19\x68\x69\x0f\xd3\xd6\x35\x04\x11\xf3\xab\xb0\x1a\x0a\xfb\x49\x7b\x35\xbc\x8a\x07\x95\x79\xcb\x36\x32\x67\xdb\xdc\x9f\xee\x4f\x72\xc7\x35\x1e\xf7\x27\x33\xb2\x79\x03\xd8\x07\x58\xdb\x83\xc5\x2e\xc9\x63\xa9\x92\xa5\xd7\x99\xed\x01\x56\x96\xde\x6a\x3d\xfc\xdd\xe1\x22\x1b\xe2\xb6\x8a\x1b\x34\xfb\x84\xc4\x3c\x13\xe9\x82\x65\x6b\x01\x01\xd5\x41\x57\x46\xb4\xfc\x07\x46\x89\xed\xf8\xa9\x97\xc0\x62\xf8\xb0\xc0\x0d\x29\x85\x8f\x99\x73\xf1\x2f\x1d\xcd\x8c\xeb\xb8\x48\xd1\x49\x8b\x29\x64\x12\x55\x0a\x26\x45\xcb\x13\xe9\x6f\xce\xe8\xf0\x09\x09\xad\x24\x89\xe1\x53\x59\xf8\x6a\xcd\x1f\xf0\xb4\x4c\xd0\x57\x90\xa1\xe0\x6f\x41\xde\x40\x21\x9c\x83\x1c\x05\xcd\xa0\x59\xa9\x54\x05\xc6\xa6\x48\xc2\x9b\xa1\x8c\x42\xd2\x40\xe9\xd0\x3a\x58\x2d\x4d\x2c\x93\xdc\xa5\x15\xd4\x74\x4a\x3f\x88\xf7\x2e\xd2\x15\x4a\x54\x20\x3d\x95\xe4\x78\xa8\x76\x94\x36\x1f\x60\xf8\x2b\x8e\xa1\xaa\xbb\x1b\xa2\xf5\x5c\xb7\x19\xa3\xfc\x9a\x9e\x36\xa3\x33\xce\x35\x9b\x71\xb9\xbe\x91\xda\x0c\xc2\xba\x6c\x6c\x46\x5a\xbb\x08\x6d\x86\x13\xaf\xf0\xd3\x66\x20\xb5\xfa\x65\x5e\x60\x70\x34\x0c\xfc\xb4\x15\x5a\xac\x65\x8c\xad\xf0\xb9\xb1\x21\xe7\xa7\x41\x04\x0c\x79\xb1\x47\xc6\xb9\xc1\x8a\x32\x71\xb0\x51\xab\xac\x84\x17\x9f\x6f\xb0\xfa\xb2\xbf\x8a\x44\x38\xb6\xe8\x9a\xb2\x51\x43\x3a\xac\x3d\x12\xc8\x8d\x16\x72\x7c\x7c\x0a\xf2\xc7\x36\x43\x5d\xf9\x40\x3e\x7b\x56\xef\xd9\x5e\xff\x2c\xbf\xd4\xd1\xd9\x20\x7e\x6b\xbd\xbf\xa1\x51\x8c\x91\x40\x43\x41\xd1\xbd\xef\xfe\x33\x00\x00\xff\xff\xb5\x25\x8b\x4d\x94\x21\x00\x00")
+var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd5\x5a\x6d\x73\xdb\x36\x12\xfe\x6c\xff\x0a\xa4\x1f\x62\xa9\x56\x64\xd9\x4e\xd3\xd4\x8e\xd3\x71\x1d\x39\xf5\x8d\x1b\x67\xfc\xd2\x4c\x26\x93\x0f\x10\x09\x4a\x8c\x29\x42\x47\x90\x96\x75\xad\xff\xfb\x3d\xbb\x00\x49\x90\x92\x1d\xa7\x37\x77\xd3\xcb\x4c\x6b\x11\x2f\x8b\xc5\xee\xb3\xaf\xe4\xd6\x96\x38\xd2\xb3\x45\x16\x8f\x27\xb9\xd8\x19\x6c\xff\x28\x2e\x27\x4a\x8c\xf5\xb3\x1b\x13\x88\xc3\x22\x9f\xe8\xcc\xac\x6f\x6d\x61\x34\x36\x22\x8a\x13\x25\xf0\x77\x26\xb3\x5c\xe8\x48\xe4\xf5\xd2\x24\x1e\x65\x32\x5b\xf4\xb1\xd6\x2e\x6f\xcf\xd0\xbe\x28\x53\x4a\x18\x1d\xe5\x73\x99\xa9\x3d\xb1\xd0\x85\x08\x64\x2a\x32\x15\xc6\x26\xcf\xe2\x51\x91\x83\x7c\x2e\x64\x1a\x6e\xe9\x4c\x4c\x75\x18\x47\x0b\xa2\x86\xb1\x22\x0d\x55\xc6\x07\xe6\x2a\x9b\x9a\xf2\xf4\xb7\xef\xae\xc4\xa9\x32\x06\x73\x6f\x55\xaa\x32\x99\x88\xf7\xc5\x28\x89\x03\x71\x1a\x07\x2a\x35\x4a\x48\xb0\x4b\x23\x66\xa2\x42\x31\x62\x72\xb4\xf1\x98\x58\xb9\x70\xac\x88\x63\x0d\xfa\x32\x8f\x75\xda\x13\x2a\xc6\x7c\x26\x6e\x54\x66\xf0\x2c\x76\xcb\xa3\x1c\xc1\x9e\xd0\x19\x11\xe9\xc8\x9c\x2e\x90\x09\x3d\xa3\x7d\x5d\x70\xbd\x10\x89\xcc\xeb\xad\x0f\xcb\xa2\xbe\x72\x28\xe2\x94\x4f\x98\xe8\x19\xae\x37\x01\x61\x5c\x78\x1e\x27\x89\x18\x29\x51\x18\x15\x15\x49\x8f\x08\x61\xb1\xf8\x70\x72\xf9\xeb\xd9\xd5\xa5\x38\x7c\xf7\x51\x7c\x38\x3c\x3f\x3f\x7c\x77\xf9\x71\x1f\x8b\xa1\x28\xcc\xaa\x1b\x65\x49\xc5\xd3\x59\x12\x83\x32\x6e\x97\xc9\x34\x5f\xe0\x12\x44\xe1\xb7\xe1\xf9\xd1\xaf\xd8\x72\xf8\xcb\xc9\xe9\xc9\xe5\x47\x5c\x45\x1c\x9f\x5c\xbe\x1b\x5e\x5c\x88\xe3\xb3\x73\x71\x28\xde\x1f\x9e\x5f\x9e\x1c\x5d\x9d\x1e\x9e\x8b\xf7\x57\xe7\xef\xcf\x2e\x86\x7d\x71\xa1\x88\x2b\x45\xfb\xbf\x2e\xee\x88\x15\x07\x91\x86\x2a\x97\x71\x62\x4a\x21\x7c\x84\xae\x0d\x78\x4c\x42\x31\x91\x37\x0a\x3a\x0f\x54\x7c\x03\x0e\xa5\x08\x80\xbf\x47\xeb\x93\x68\xc9\x44\xa7\x63\xbe\xf3\x2a\x04\x8a\x93\x48\xa4\x3a\xef\x09\x03\xbe\x5f\x4d\xf2\x7c\xb6\xb7\xb5\x35\x9f\xcf\xfb\xe3\xb4\xe8\xeb\x6c\xbc\x95\x58\x4a\x66\xeb\x75\x7f\x9d\xc8\x05\x32\x49\x2e\x33\x19\xe0\x4c\xe8\x45\x0a\x88\x1b\x92\x4f\xf4\x1c\xa2\x84\xf0\x8c\x0c\x48\xc1\xf4\x3b\x60\x08\x42\x3f\xea\x96\x9e\x72\x43\x50\xc5\x55\x66\x3a\xa3\xdf\x49\x52\xa2\x2b\x4e\x81\x83\x14\xcc\x13\x6d\x23\xa6\x32\x54\xc0\x1e\x68\x7b\x04\x7b\xfe\x3d\x08\x3c\x56\xd3\xd8\x0b\x19\x4e\x19\x8c\xfd\xf5\x3f\xd6\xd7\x1c\x87\x26\x97\xc1\x35\x31\x48\xf4\x83\x22\xcb\x54\x9a\x93\x14\x0b\x60\x0d\xf2\xa4\x25\xc2\xae\x71\xa2\x1c\xfe\xfe\x1b\xf8\xc4\x02\x4b\x69\xad\x22\xb2\x27\x3e\xfd\x71\xf7\xb9\xb7\xce\xa4\x43\x65\x20\x8d\x10\x8a\xa0\x1b\x5d\x1b\x31\x9f\x28\xc6\xff\x5c\x6d\x80\xec\x97\xc2\xe4\xde\x9a\x28\xd3\x53\xf0\x2a\x80\x35\x12\x85\x27\x1d\xdc\x58\x33\x41\x49\xbf\xa1\x39\xe6\x08\xc7\x56\x9b\xf7\x44\x24\x13\xd8\x8f\x3d\xd7\xe4\x6a\x46\xb7\x89\xd3\x1b\x7d\x4d\x94\x81\x1b\xa0\x17\xb6\xa1\x67\x81\x0e\x9d\x1d\xd0\x3d\xaa\x6b\x28\x80\x69\x8d\xf6\x81\x52\x91\xf2\xb1\x9d\x44\x8f\x7b\x22\x1c\x75\x05\x04\x45\x64\x8f\xe4\x2c\x2f\x80\x3e\x92\xa7\xca\x32\x38\x2f\x98\xc2\x14\xfe\x05\x86\x99\x2c\xb0\xe6\x46\x66\x76\x42\x1c\x08\x6c\xee\x8f\x55\x3e\xa4\xc7\x4e\x77\x1f\xb3\x71\x24\x3a\x76\xf6\xc9\xc1\x01\xfb\x9c\x28\x4e\x55\x68\xc9\xaf\xe5\xf0\x81\xfd\x48\x16\x49\x5e\x9d\x4b\x9b\xd6\x32\x85\x33\x53\xfa\x79\x67\xb9\xf8\xa0\x84\x4e\x93\x05\x44\x40\xac\x8c\xc8\x32\xcd\x02\x9c\x4f\xdd\xe5\x4c\x0f\xb2\x30\x24\x42\x1c\x38\x57\x62\x96\xa9\x67\xc1\x44\x91\xee\xd2\x40\x39\x2e\xb1\x83\x95\x7a\x20\xe8\xb4\xbe\x9e\xf5\x73\xfd\xae\x98\x8e\x14\x78\x15\x4f\xc5\xe0\x36\x1a\x74\x05\xb8\xa4\x1f\x25\xef\x6e\x8f\xe3\x97\xa8\xe8\x99\xbb\x28\xef\xbf\x80\xcb\x49\xc7\xf6\xae\x8e\x57\x58\x8b\x14\xa9\x9a\xc3\x0c\x53\x06\x35\x69\x65\xa4\xb0\x4c\x04\x99\x82\xd8\x42\x00\x35\x04\x3c\xb4\x45\x5e\x85\xb3\xe6\x91\xe2\xe9\x53\xd1\xa1\xc3\x0e\xc4\xc6\xd1\xf9\xf0\xf0\x72\xb8\x21\xfe\xfc\x53\x34\x46\x76\x36\xba\x1e\x67\x71\x7a\x16\x45\x8e\x39\x26\xd8\x9f\x29\x75\xdd\xd9\xee\xf6\x6f\x64\x52\xa8\xb3\xc8\xb2\xe9\xd6\x0e\x61\x68\x07\x6e\xcf\x66\x7b\xcf\x4e\x63\x0f\x6d\xc2\xc5\x0e\xe1\x45\xa6\xa3\x44\x2d\x1b\xa4\xb3\x58\x36\x5e\x93\x93\xb3\x22\xf4\x05\x1a\x3e\x53\x11\xaa\xca\x53\x9d\xf8\x99\xe3\xb5\x7c\x31\x43\xc8\xc2\x3f\x3d\xeb\xf1\x00\xd9\x02\x0f\xe4\xfa\x57\x75\xcb\x3a\x2a\x45\x48\xa8\x3a\x0c\xc3\x0c\x8e\xac\xd3\xed\xda\xe5\x71\x3a\x2b\xf2\xbd\xc6\xf2\xa9\x82\xa7\x5c\xf4\x0d\x39\xa4\x0e\x5f\xad\x67\x6f\x5a\xee\x19\x4b\x73\x92\xd2\x1e\x87\xd4\xb7\x12\xf4\xaa\xa9\x23\x6d\x40\xd0\x4d\xd1\x43\x39\xc7\xb2\xa0\x6d\x1b\x83\xdb\x8d\x65\x69\x0d\xba\x35\x12\xb6\x5f\x74\x69\xcb\xdd\x7e\x85\xef\xca\x4d\xf4\x67\x85\x99\x74\x18\x4e\xf5\x6c\xed\x0a\x0e\x60\xfe\x85\x5a\x09\x7f\x86\xd4\x32\x9c\x8c\x4a\x22\xf2\x25\xd8\x17\x30\xac\xc6\x92\x3d\x0d\x5b\xba\x24\xcf\x6b\x8a\x11\xcb\x3c\xd7\x7a\x19\x5d\x0e\x4a\x17\xc3\xd3\xe3\x37\xc3\x8b\xcb\xf3\xab\xa3\xcb\x0d\x0f\x4e\x89\x8a\x72\x62\xaa\x79\x87\x44\xa5\xe3\x7c\xc2\xfc\x13\xb9\xe6\xec\x27\xda\xf3\x6c\xfb\xb3\x1d\x01\xf5\x65\x93\x5f\x7b\x78\x87\xf8\xf4\x99\x69\xdf\xad\x7f\x65\xa9\x15\x66\x03\x49\x2d\x1c\x3d\x0e\x45\xb9\xf6\x20\x97\xeb\x72\xfa\x61\x0d\x77\x9b\xb8\x70\xa8\x08\x47\x74\xc0\x2f\x32\x91\xf0\x39\x0f\x1c\xbc\x0c\x16\xdf\xeb\xad\x70\x24\x53\x04\x10\x1d\xb2\x67\x0f\xa4\x0d\x0e\x25\x04\x42\x9d\xaa\x6f\x77\x27\x87\xa7\xa7\x0d\x67\x82\xe7\xa3\xb3\x37\x0d\x07\xf3\x66\x78\x3a\x7c\x0b\x17\xd3\x5e\x7b\x71\x79\x88\x7c\x86\x47\x4b\xdf\x03\x56\x2f\xae\xe3\x19\x87\x08\x76\xbc\xb0\x7b\xce\x6b\x2b\x7e\xe1\x9e\x71\x03\xca\x1d\x33\x17\x01\x23\xc8\xa8\x8c\x4c\xa6\x44\x1c\xae\x00\xbc\xdd\xa7\x83\xed\x96\x0e\x2a\x0c\xc6\xe6\x3d\xc2\xb6\x3d\x34\x84\x0e\x4b\xbe\x6a\x81\x5a\x38\xb1\xf7\x66\x0f\xd9\x79\xfc\x25\xc5\xcf\x62\x20\xf6\xc4\xb6\x73\x83\x0f\xf8\xd9\x1d\x40\x00\xe4\xff\x82\xb7\xdd\x5d\xb1\xf3\xef\xe9\x73\x9d\xb5\xd4\xf6\xf2\xbf\xf7\xc5\x88\xfd\xa0\xb5\x27\xda\x42\x7c\xbe\x24\xc4\x6a\xfd\xa9\x4a\x97\xd7\xff\xb0\xb4\xbe\xf6\xdb\x84\x2a\x40\xe1\xc9\x12\x44\xac\xd7\x7c\xd2\xb2\x03\x27\x5c\xce\xcf\x98\x1a\xe4\xbd\x3a\x52\xec\x34\x31\x7c\x9f\xab\xfb\x8f\x22\xc5\xca\x3c\x93\xb2\xc9\x66\x26\xd9\x03\x80\xc0\x08\x52\x44\x14\x47\x1b\x86\x49\x52\xc6\xad\xe7\xe4\xbe\xfa\x48\xb9\x2c\xc5\x54\x29\x76\x2e\x2e\x43\xa7\x04\x8b\x93\x56\xca\xb2\x5d\x99\xc5\x10\x93\x9c\x48\x03\x86\x53\xb9\xa0\x32\x0b\x19\xe5\xf5\x02\x11\x09\x85\xd9\x22\x95\xd3\x38\x30\x96\x1e\x67\xe7\x99\x1a\xcb\x8c\xc9\x66\xea\x9f\x05\x22\x18\xd5\x2d\x00\x32\x0e\x28\x40\x0c\xfb\x62\x2a\xbc\x68\x77\x67\x67\x77\x30\x00\xc2\xe3\x19\x6e\xd2\x13\x2f\x76\xb7\x5e\x3c\x17\x59\x91\xa8\x6e\x7f\xdd\x8b\x41\xd5\x55\x9d\x36\x68\xc2\xa1\xe7\x8d\x9a\xe5\x13\xa4\x78\xaf\xef\x09\x66\xf7\x44\xa6\x95\x6b\xc5\x33\x81\x08\x44\x7c\x1d\x34\x70\x6b\x35\x29\x14\xf2\x71\x47\x8d\xea\xd4\xb3\x37\x67\x9d\x6b\x89\x9a\x4b\x8e\x54\x77\x8f\xeb\x56\x96\xd5\x5c\xba\x12\x86\x94\x22\x66\x89\x84\x20\x65\x10\xa0\x66\xce\x49\xf0\x65\x35\x02\x39\xc0\xbf\x6f\xe4\x25\x3d\xae\xf3\xb0\x0e\x16\x59\xba\x7b\xd6\x1a\xb1\x23\xa7\xb4\x1b\xfa\x35\x71\xa8\x3c\xad\x90\x77\xd0\xec\x9a\xdd\x0a\x2a\x83\x4b\x82\x53\xd8\x55\xc2\xda\x9a\x67\x54\x39\x99\x18\xaa\xa7\x5a\x39\x54\x24\x6d\x83\xec\x19\xfc\x25\x9a\x7b\x13\x6c\xe3\xf0\xe0\x63\xd3\xb7\xfe\x9e\x8e\x25\x9f\x93\xea\x79\xbf\x09\x64\x1f\xaa\x5c\xa3\xb4\x72\x99\x14\x68\x42\xb5\xce\x29\x31\x71\x89\x70\x66\x91\x8c\x91\x9e\x98\xc1\xc4\xc8\x4f\x7f\x2d\x9c\x39\x67\x7d\x3e\xfc\x7d\x78\xde\xc8\x5c\x9c\xcb\x7b\xb4\x3e\x59\x79\x6c\xbd\x65\x21\xf3\x5d\x55\xe7\x81\x31\x14\x51\xc0\xe7\x77\x75\x00\xb0\x2e\x68\x39\x02\x0c\x56\xf9\x7e\x2c\xb6\xce\xdf\xed\xda\x7c\x54\x7e\x8e\x42\xc1\xe0\x70\x77\x09\xcb\xc3\x39\x8f\x2d\x3b\x57\x4b\xb8\xe7\x4e\xf2\x02\xa3\x25\xd2\x70\x4f\x3e\x25\x50\xb7\x2b\x6a\x37\xd4\xf4\x27\x2b\x6c\xe8\xe0\x1e\x1b\x22\x39\xd6\xe9\xc0\x7b\x4f\x83\x09\xea\xb2\x1a\x8b\x20\xc5\xa3\xbe\x7c\x0d\xea\x3f\xf3\xa0\xee\xfa\x80\x44\x19\x14\x89\x29\xbe\x0a\xc5\xb2\x76\x75\xb4\x6a\x62\xa7\x72\xd0\x16\x7d\xb9\x6f\x85\x52\xd8\x45\x9e\x37\xe4\xf9\x32\xd7\x96\x36\x00\x32\xef\x90\x2f\x59\x00\xa5\x2c\xb5\x40\x61\x04\x57\x86\x81\xee\x3c\xfe\x28\x1e\x9f\xa4\x79\xa7\x9c\x3c\x49\x21\x9a\xf2\x81\xe2\x18\x1e\x7d\xc7\xb1\x22\x20\xa0\xc2\x47\x08\x57\xa2\x26\xb1\x2f\x5a\x43\x44\xc8\x8a\xc3\xa1\x25\x5f\x85\x46\x4b\x8d\x04\xf6\x04\x2b\xfa\xf0\xb4\xb0\x45\x8c\x97\xf2\xb0\x37\x80\x27\xa1\x7f\x07\x4b\x39\x30\xed\x69\x66\xbd\xfb\xde\x36\x27\x8d\x72\x9b\x4d\x7e\x8f\x20\x9b\x07\x29\x38\x12\xce\x53\x56\xba\x74\x76\xb7\xaa\x5e\x68\x19\x66\x95\x03\x45\x32\x4e\x8a\x4c\x7d\xb7\x2f\x56\x78\x5a\x53\x64\x91\x0c\x58\x97\xd4\x42\xa3\x0e\x83\x81\x1f\x9c\xaa\x89\x9e\x5b\x06\x56\xf9\xeb\x65\x70\x54\x38\x68\x45\x4c\xee\x92\x61\x45\x61\xe4\x58\x79\xe0\xa8\x04\x5e\x2a\x6a\x65\xdb\xe3\x2f\x43\x67\xb3\x7a\xfc\x0a\x8a\xec\x29\x5f\x85\xc6\x43\xd8\x58\xa9\xe5\x25\xdf\x53\x2e\x62\x07\xe4\x3d\x94\xac\xda\xec\xab\x42\xce\xb7\xe8\xfd\xbf\xa3\x78\xab\x79\xf7\xff\xc7\x1a\x5a\x7b\xad\xbd\x63\x73\xb1\xbd\x69\xed\x4a\xbf\x8e\x82\x6a\xf6\x3e\x00\xdc\x97\x2c\x12\x54\xd3\x2f\x2a\xc8\x6b\xb8\x72\x7e\x47\x4f\x28\xc0\x6e\x62\x5d\x50\xe8\x56\xff\x4f\x95\x7c\x95\xec\x62\xfd\x9d\x6b\x69\xb2\xfa\xfc\x9e\xe6\x7c\xe2\xba\xf1\x36\x4f\xf4\xa2\x88\xe6\xac\xc2\x75\x3a\x23\xdb\x27\x5f\xe3\xfd\x0f\xf4\x36\x9d\xbd\xe7\x7a\x46\x89\x90\x0b\x52\x09\x62\x62\xb8\xa8\xc2\x7e\xcf\xa6\x60\xc8\xbd\xd2\xd0\x95\x61\x88\x09\x31\xd1\x63\x2c\x12\x87\x72\x8c\x04\x6e\x7d\xa5\x18\x1f\x48\x22\xef\x6f\x8b\x2e\x65\xf5\x7e\x3c\x75\xe5\x33\xd5\xba\xcc\xf1\xfa\x23\xe2\x66\xcb\x96\xda\x6d\x5a\xd7\xe9\x45\x9d\x5e\x4c\xb9\x06\x10\xf2\x06\x07\x48\xaa\x3b\x39\xb7\x84\x7f\x0b\x12\x05\x01\xf3\x2b\x19\x28\x4f\xd3\x1b\x99\xf5\x47\x80\xfc\xaf\x60\xbc\xe5\x1c\xcb\x47\x27\x8e\xc7\xdb\xec\x63\x2d\xd6\x5e\xff\x38\x91\x79\xee\xe0\xe5\x89\xd7\x5a\x56\x9c\xf3\x3b\x3a\xe4\xe4\xeb\x8f\x33\x29\x4e\x9d\x68\xcd\x6b\x31\xf0\x2a\x92\xbf\x8b\x91\x2d\x43\xec\xb4\x4a\xd3\xdc\xe5\x73\xad\x7b\xb8\xa6\xe4\xfa\xb0\x7c\xa1\x56\x66\xe2\x0f\x95\xab\xa5\xf5\xda\xc4\x6e\xc9\x7c\xb9\x1d\x09\x52\xae\xf7\x63\x8b\x9a\x91\xc2\x4c\x0c\x07\x4f\xed\x71\x41\xe8\x72\x2f\x82\x88\x4b\xc3\xe4\x58\x2f\x31\x19\x9d\x23\xec\xde\xca\x50\x7c\x06\x7a\x60\xee\x76\xdc\xb3\xf7\x20\xbf\xad\xed\xdd\x06\x43\xde\xe9\xba\x21\x55\x33\x04\xeb\x38\x69\xe4\x86\x41\xab\x23\x42\x73\x34\x64\xbb\x09\xad\xfe\x07\x6f\x74\x3d\x90\x76\x8f\x98\xe6\x78\xac\x01\x70\x5e\x0a\x8c\x5a\x32\x2d\x93\xc0\x8e\x25\x8b\x28\x37\x90\x31\xec\xad\xde\x40\x53\x2b\x36\xb5\x7a\x32\xb4\x98\x87\xec\xac\x0d\xec\x7b\xfe\xac\x1d\x72\x17\x8d\xa7\x9e\x6c\xf0\x40\xa3\x77\xfb\xab\x9d\xdc\xa0\xc4\xe3\x6a\x67\x46\x32\xaf\x00\x7b\xcf\x56\xbf\xe4\x58\x5e\xf2\x90\xab\x64\xea\xa5\x67\xbb\x67\xeb\xbe\xb7\xb4\x55\x01\x2d\xef\xf0\x17\x30\x5b\x5e\xce\x02\x61\x3c\x9a\x97\x6a\xb1\x7f\xb7\xc6\x9a\x55\x44\x9c\x83\x72\xeb\xac\x4a\x4a\x02\xd6\x1c\x2c\xcb\x6c\x0a\xf1\xbf\x94\xa3\xd8\x34\x3c\xef\x86\xa1\xb2\x66\x46\xa6\xe2\x8a\x4a\xc3\x38\xe1\x90\x59\x85\x39\xe7\xeb\x22\xb7\x8e\xce\x51\xa1\x7d\x9f\x29\x73\x69\x5f\xe3\x42\x42\x69\x48\x6d\x1b\x1b\x38\x2c\x99\x2e\xfc\xe2\x22\xd1\x32\xa4\xd7\xf8\xf5\x6d\x84\xa6\x66\xd0\x3c\x36\x8a\x0d\xb3\x66\xc8\x33\x4f\xa2\x5c\xc5\xe2\x21\x82\x4f\x66\xe3\x6b\x79\x22\x52\x99\x28\xbe\x25\xbf\x51\xbe\x98\x6e\x1e\x6c\x20\xaa\x20\xd7\x99\x13\x2d\x6d\x2b\x03\xec\x2b\xf1\x9c\xca\x41\x1a\x82\x4a\xa9\x61\x37\xb8\x1d\xbc\xac\x86\xb6\xdd\x50\xb0\x5b\x0d\xed\xb8\xa1\x1f\x7f\xaa\x86\x76\xdd\x90\x1c\x34\xe2\x72\x7d\x49\xcf\x7d\x9e\x2b\xa3\x13\x57\x18\xcc\xb4\xe1\xfc\x80\x5d\x98\x63\xc8\x39\xab\xc3\x5f\x4e\x84\x4a\x49\x23\xa1\x53\xc3\x7a\xd5\x97\x36\x2a\xaf\xeb\x7d\x19\x7e\xd0\x59\xc8\x77\xea\x89\xe7\xd5\xab\xd4\x72\x99\x0f\x1c\xe2\xd7\x35\x3f\x69\x6e\x53\xec\xee\x20\xea\x78\xd2\xf8\x0a\xf7\xfc\x6a\x34\xa7\x0f\x50\x0e\xda\x74\x38\x36\xf2\x3c\x90\x76\x0f\x73\x76\xeb\x33\xac\xae\xb8\xb4\xab\xdb\x3c\xda\x85\x9b\x96\xd6\xb7\x30\xe8\xc3\xde\xc2\xf9\xea\xf2\xf8\xa5\x7f\x7c\xaf\x41\xbc\x69\x0b\x96\x59\xfe\x61\xad\x00\xe2\x19\x2d\x60\x62\x70\xa2\xd0\x45\x18\x23\xad\x99\xd3\x0a\xf7\x76\xdc\x76\x1e\x4b\x1d\xf6\xea\xc6\x14\xd3\xab\x6f\x84\x8b\x72\xdf\x94\xde\x45\x43\xbb\x23\xfa\xe0\xc5\x90\x0d\x20\x70\x8a\x44\x66\x63\xee\xee\x8d\x90\x4f\x09\x84\x81\x38\x14\xb6\x6e\x72\xe2\x65\xab\xb0\xac\xb5\x2c\x82\xfa\x5f\xc6\x4a\x84\x64\x89\x87\x6f\x57\x29\x75\xe5\x3a\xfc\xe2\x01\x4a\x03\x85\x7d\xfc\x78\x25\x2c\xa9\x9d\x17\x78\xda\xdc\xf4\x92\x12\x06\x7b\x6c\xc1\xde\x7c\x75\xd2\xa4\xcc\xa4\x4b\xc4\xb0\xc8\xb0\x61\x7f\xc5\x71\xe5\x29\xd5\x99\x40\x92\x77\xa6\xdb\xca\x7f\xbe\x17\x3b\x3f\xbc\xa0\x77\x67\x96\x87\x96\xca\x69\x89\xa7\xcc\x5a\xf9\x54\x98\x93\x4f\x21\xb7\xc4\xca\xcc\x64\x3a\x56\xae\xcb\xed\x2c\x8b\x54\x37\x4b\x38\x39\x70\x1f\x42\x20\x0f\x61\x4d\xd0\x8e\xf5\xaa\x17\x7d\xb5\x79\x7c\x7c\xfc\xc6\xa6\x37\x12\xf9\xef\x5c\x2e\xc4\x5b\x8d\xb4\x5f\x19\x9b\xb1\xb0\xc1\x12\x95\x92\xb2\x3d\xe7\x1f\x17\x67\xef\xf8\x73\x8c\x92\xa9\x25\x45\x3a\x6c\x02\x64\x75\x0a\x02\x0a\x94\x0b\x6f\x2c\xc9\x8d\x17\x5b\xa1\x61\xc3\xbe\xf0\xfa\x99\x23\x4c\x3b\x01\xf5\x4a\x53\xdc\x46\x72\x46\xaf\xcb\x06\xb7\x2f\x07\x3d\x31\x89\xf9\xe7\x28\xaa\xca\xb9\x11\xb5\xbb\xe1\xe1\x76\xa8\x53\x3a\x12\xaf\xe8\x21\x8c\x4a\xf5\x3a\x22\x3b\x7e\xdb\xda\xdb\xa5\x06\xde\x2e\xd5\xde\xb5\x5b\xf7\x95\x46\xf6\x7b\x09\x45\xb8\x29\xf9\x91\x83\x7d\x71\xb7\xb4\x82\x44\x50\xb2\xf9\x53\xe4\x56\x2c\x9f\x1c\xf9\x27\x47\xcf\x5b\x27\x3f\x5f\x3a\x39\xf2\x4f\xfe\x69\xd5\xc9\x4c\xa4\x3c\xf9\xe5\xfd\x27\xbf\x1c\xb4\x0e\x1b\x34\x0a\xee\xdf\x09\x39\xc8\x4e\xab\xf6\x60\x9c\x16\xf6\x5d\x2c\xa1\x89\xbf\x3f\x49\x12\xc2\xc6\x88\xbe\x18\x62\x18\x5a\xf8\xa9\x29\x7d\x57\x14\x4c\x64\x56\xb5\x38\xa9\x46\x3d\x70\x8a\x84\x26\xc5\xcf\xb8\xf0\x1e\xfe\x7b\x2a\x3a\xe0\x37\x12\xaf\x5f\x3b\x1f\xba\x29\xb6\x5d\xeb\xa4\x82\xca\x17\xd2\xfd\x3e\xfe\xbc\x62\x02\xf8\x55\xd9\x95\x25\x5e\x61\x05\xbb\xbf\x7c\xae\x05\xc6\xcf\x74\x55\xa0\x8b\x5b\xa5\xa0\xd0\xf9\x52\x32\x00\x09\xee\x39\x21\xf0\xdc\x6b\x6f\x0e\xb2\xdb\x63\x70\xd5\xcd\xa1\x86\x88\xd6\xd6\x46\xf0\x65\xd7\xfb\x5e\x63\xc5\x5d\x91\xff\x7c\x2f\x5e\x50\x58\xe9\x04\xfc\x8d\xcd\x6e\xd4\x6d\xf6\x48\x4a\x31\x54\xe2\x77\x7b\x49\x12\x91\xf5\x3b\xe5\x71\xdb\xde\x4e\xb1\x69\x25\x58\xbd\x29\x7d\x53\x5c\xe7\x72\xa6\x36\x0c\xbf\x9e\x3a\x82\xbc\xa9\x19\xc9\xaf\x4b\x66\xf0\x13\xfc\x0d\x1a\x93\x9e\x69\x98\x2f\x14\x96\x6a\xfb\x6e\x8b\x44\x8b\xdc\x23\xd3\x63\xa8\x97\xfb\xd0\x64\xa3\x20\x6f\xf3\xe9\xbe\x4f\xae\x43\x14\xba\x2d\x2f\x85\xe5\x9e\x93\x2a\x13\x33\xfa\x94\x8c\x3f\xf7\xe1\x3e\x22\x65\x57\x7a\xc4\x3d\x9b\xc2\x10\x4c\xea\x72\x06\xd9\x59\x9c\x11\x1f\xb1\x4a\x90\x37\xd1\x57\x99\xc4\xd2\x17\x43\xef\x70\xe9\xc3\x2e\x95\xc5\x44\xd1\x7e\xc0\x66\xbf\x1b\xe5\x7c\x2c\x8d\x03\x95\x2f\x44\x84\x43\x38\x75\xd2\x48\xc5\x8c\x41\x9c\x91\x14\xae\xe8\xc2\x88\x36\x19\xe8\xa9\xb0\xee\xb2\x53\x25\xa5\xe9\xcb\xb8\x8c\xbc\x9b\x76\xdd\x0d\x6e\xae\xcd\xa8\x57\x18\xc3\x61\xd9\x77\x87\xb1\x01\x7c\xe1\x34\x29\x56\x95\x97\xf2\x8b\xab\xea\xb3\x28\x76\x6b\x9a\xb3\xc8\x76\x65\xc5\xff\xaa\xa6\x7c\xb3\xbe\xaa\xe7\x68\xa8\x59\x5e\x79\xfb\x74\xb3\xba\xaa\x67\x78\xa8\x59\x4f\xd5\x93\x18\x6a\x56\x4e\x8d\x29\x1a\x6a\xd6\x48\xf5\x34\x0f\x35\x4b\xa4\x7a\xd2\x0e\xf1\x2c\xa7\xf0\xcd\xad\x3c\xd4\xb3\x81\xd9\xcf\x78\x97\xde\xbc\x34\x4b\x2c\xef\xb2\xae\xd0\xb2\x9f\x16\x36\x89\xf3\x50\xaf\x1d\xe0\xaf\x15\x85\x35\x27\x7e\x2f\xa6\xdb\x81\x4f\x98\xfe\xbc\xba\xaf\xe0\xea\x0c\x6f\x5d\x23\xc4\xd7\x34\x1e\x28\xed\xea\xf8\x75\x00\xcf\x1b\xbf\xf2\x37\x94\xbd\x10\x2f\xee\xaf\xf9\xf3\x94\x70\x1c\xb4\x4a\x99\xd6\x7c\xb7\xc1\x51\x69\x6c\xbc\x86\xec\x6d\xfd\x6e\xfd\xdf\x35\xb2\x9f\x72\x6c\x2d\x00\x00")
 
 func call_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -172,7 +172,7 @@ func opcount_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x57\xdd\x6f\x1b\xb9\x11\x7f\xde\xfd\x2b\xa6\x7e\x91\x84\x53\x56\xce\x15\xb8\x02\x72\x5d\x60\xa3\x28\x89\x00\x9d\x6d\x48\x4a\x5d\xf7\x70\x0f\x5c\x72\x76\xc5\x13\x45\x2e\x48\xae\x3e\x10\xf8\x7f\x2f\x86\xfb\x21\xcb\x67\x27\x6e\xeb\x27\x2f\x39\xfc\xcd\xf7\x6f\x46\xa3\x11\x4c\x4c\x79\xb4\xb2\x58\x7b\xf8\xf9\xf2\xfd\xdf\x60\xb5\x46\x28\xcc\x3b\xf4\x6b\xb4\x58\x6d\x21\xad\xfc\xda\x58\x17\x8f\x46\xb0\x5a\x4b\x07\xb9\x54\x08\xd2\x41\xc9\xac\x07\x93\x83\x7f\x26\xaf\x64\x66\x99\x3d\x26\xf1\x68\x54\xbf\x79\xf1\x9a\x10\x72\x8b\x08\xce\xe4\x7e\xcf\x2c\x8e\xe1\x68\x2a\xe0\x4c\x83\x45\x21\x9d\xb7\x32\xab\x3c\x82\xf4\xc0\xb4\x18\x19\x0b\x5b\x23\x64\x7e\x24\x48\xe9\xa1\xd2\x02\x6d\x50\xed\xd1\x6e\x5d\x6b\xc7\xe7\x9b\xaf\x30\x47\xe7\xd0\xc2\x67\xd4\x68\x99\x82\xbb\x2a\x53\x92\xc3\x5c\x72\xd4\x0e\x81\x39\x28\xe9\xc4\xad\x51\x40\x16\xe0\xe8\xe1\x27\x32\x65\xd9\x98\x02\x9f\x4c\xa5\x05\xf3\xd2\xe8\x21\xa0\x24\xcb\x61\x87\xd6\x49\xa3\xe1\xaf\xad\xaa\x06\x70\x08\xc6\x12\x48\x9f\x79\x72\xc0\x82\x29\xe9\xdd\x00\x98\x3e\x82\x62\xfe\xf4\xf4\x0d\x01\x39\xf9\x2d\x40\xea\xa0\x66\x6d\x4a\x04\xbf\x66\x9e\xbc\xde\x4b\xa5\x20\x43\xa8\x1c\xe6\x95\x1a\x12\x5a\x56\x79\xb8\x9f\xad\xbe\xdc\x7e\x5d\x41\x7a\xf3\x00\xf7\xe9\x62\x91\xde\xac\x1e\xae\x60\x2f\xfd\xda\x54\x1e\x70\x87\x35\x94\xdc\x96\x4a\xa2\x80\x3d\xb3\x96\x69\x7f\x04\x93\x13\xc2\xaf\xd3\xc5\xe4\x4b\x7a\xb3\x4a\x3f\xcc\xe6\xb3\xd5\x03\x18\x0b\x9f\x66\xab\x9b\xe9\x72\x09\x9f\x6e\x17\x90\xc2\x5d\xba\x58\xcd\x26\x5f\xe7\xe9\x02\xee\xbe\x2e\xee\x6e\x97\xd3\x04\x96\x48\x56\x21\xbd\xff\x71\xcc\xf3\x90\x3d\x8b\x20\xd0\x33\xa9\x5c\x1b\x89\x07\x53\x81\x5b\x9b\x4a\x09\x58\xb3\x1d\x82\x45\x8e\x72\x87\x02\x18\x70\x53\x1e\xdf\x9c\x54\xc2\x62\xca\xe8\x22\xf8\xfc\x6a\x41\xc2\x2c\x07\x6d\xfc\x10\x1c\x22\xfc\x7d\xed\x7d\x39\x1e\x8d\xf6\xfb\x7d\x52\xe8\x2a\x31\xb6\x18\xa9\x1a\xce\x8d\xfe\x91\xc4\x84\x59\x5a\x74\x9e\x79\x5c\x59\xc6\xd1\x82\xa9\x7c\x59\x79\x07\xae\xca\x73\xc9\x25\x6a\x0f\x52\xe7\xc6\x6e\x43\xa5\x80\x37\xc0\x2d\x32\x8f\xc0\x40\x19\xce\x14\xe0\x01\x79\x15\xee\xea\x48\x87\x72\xb5\x4c\x3b\xc6\xc3\x69\x6e\xcd\x96\x7c\xad\x9c\xa7\x7f\x9c\xc3\x6d\xa6\x50\x40\x81\x1a\x9d\x74\x90\x29\xc3\x37\x49\xfc\x2d\x8e\x9e\x18\x43\x75\x12\x3c\x6c\x84\x42\x6d\xec\xb1\x67\x11\xb2\x4a\x2a\x21\x75\x91\xc4\x51\x2b\x3d\x06\x5d\x29\x35\x8c\x03\x84\x32\x66\x53\x95\x29\xe7\xa6\x0a\xb6\xff\x81\xdc\xd7\x60\xae\x44\x2e\x73\x2a\x0e\xd6\xdd\x7a\x13\xae\x3a\xbd\x26\x23\xf9\x24\x8e\xce\x60\xc6\x90\x57\x3a\xb8\xd3\x67\x42\xd8\x21\x88\x6c\xf0\x2d\x8e\xa2\x1d\xb3\x84\x05\xd7\xe0\xcd\x17\x3c\x84\xcb\xc1\x55\x1c\x45\x32\x87\xbe\x5f\x4b\x97\xb4\xc0\xbf\x31\xce\x7f\x87\xeb\xeb\xeb\xd0\xd4\xb9\xd4\x28\x06\x40\x10\xd1\x4b\x62\xf5\x4d\x94\x31\xc5\x34\xc7\x31\xf4\x2e\x0f\x3d\xf8\x09\x44\x96\x14\xe8\x3f\xd4\xa7\xb5\xb2\xc4\x9b\xa5\xb7\x52\x17\xfd\xf7\xbf\x0c\x86\xe1\x95\x36\xe1\x0d\x34\xe2\x37\xa6\x13\xae\xef\xb9\x11\xe1\xba\xb1\xb9\x96\x9a\x18\xd1\x08\x35\x52\xce\x1b\xcb\x0a\x1c\xc3\xb7\x47\xfa\x7e\x24\xaf\x1e\xe3\xe8\xf1\x2c\xca\xcb\x5a\xe8\x95\x28\x37\x10\x80\xda\xdb\xae\xce\x0b\x49\x9d\xfa\x34\x01\x01\xef\x7b\x49\x58\xb6\xa6\x3c\x4b\xc2\x06\x8f\x3f\xce\x04\x5d\x48\x71\xe8\x2e\x36\x78\x1c\x5c\xc5\xaf\xa6\x28\x69\x8c\xfe\x4d\x8a\xc3\xcb\xf9\x22\xc0\x1d\x53\x1d\x60\x1d\xbf\x25\x21\x9c\xec\x1a\x04\xdd\x41\x07\xc9\xfe\xe5\x1a\x2e\x2e\x0f\x97\xff\xe7\xdf\x45\x63\xc1\x0b\x25\xf3\xcc\xec\x37\x98\xf6\x78\x9e\x4f\x8b\xae\x52\x9e\xda\x4e\xea\x9d\xd9\x10\x81\xae\x29\x4f\x4a\x85\xd4\x98\x92\xaa\xc6\xd5\x0c\x96\x21\x6a\x90\x1e\x2d\x23\x0a\x37\x3b\xb4\x34\xbd\xc0\xa2\xaf\xac\x76\x5d\x3a\x73\xa9\x99\x6a\x81\x9b\xec\x7b\xcb\x78\xdd\xbb\xf5\xf9\x93\x9c\x72\x7f\x08\xd9\x0c\x3e\x8e\x46\x90\x7a\x20\x3f\xa1\x34\x52\xfb\x21\xec\x11\x34\xa2\x20\x02\x12\x28\x2a\xee\x03\x5e\x6f\xc7\x54\x85\xbd\x9a\x64\x88\xaa\xc3\x53\x53\xd1\x44\x7a\x42\x42\xc3\x60\xe0\xd6\xec\xc2\xa8\xcd\x18\xdf\x40\xd3\xf8\xc6\xca\x42\xea\xb8\x89\xe9\x59\xd3\x93\x45\x09\x01\x07\xb3\x42\xcd\x50\xee\xe9\xe4\x43\xc8\x7f\x26\x8b\x99\xf6\xcf\x8a\xa8\x8e\x7c\xfb\x74\xf0\x7b\xd2\x34\x71\xe2\x88\x78\xfb\x3f\x0f\x86\xf0\xfe\x97\xae\x32\xbd\x21\x28\xf8\x31\x98\x37\xaf\x43\xc5\xcf\x2b\xe2\xe5\x67\x41\x0d\x31\xc9\x4f\x41\x6b\xe2\xaa\x8c\xd2\x51\xfb\x19\xe2\x78\xce\x26\x57\xdf\xc1\x3d\xf7\xad\xc5\x6d\x42\x93\x30\x21\x5e\x07\xad\x53\xf4\x11\xb9\xc5\x2d\x4d\x17\xca\x02\x67\x4a\xa1\xed\x39\x08\xdc\x35\x6c\xca\x29\xe4\x0b\xb7\xa5\x3f\xb6\x33\xc7\x33\x5b\xa0\x77\x3f\x36\x2c\xe0\xbc\x7b\xd7\x52\x71\x08\xc5\xb1\x44\xb8\xbe\x86\xde\x64\x31\x4d\x57\xd3\x5e\xd3\x4c\xa3\x11\xdc\x63\xd8\xc8\x32\x25\x33\xa1\x8e\x20\x50\xa1\xc7\xda\x2e\xa3\x43\x88\x3a\x6a\x1a\xd2\x6a\x45\x4b\x0f\x1e\xa4\xf3\x52\x17\x50\x33\xd6\x9e\xe6\x7b\x03\x17\x7a\x84\xb3\xca\x51\xb5\x3e\x1b\x86\xde\xd0\x66\x63\x91\xf8\x8d\xe6\x50\x68\x37\xa6\x64\xb7\x09\xe5\xd2\x3a\x0f\xa5\x62\x1c\x13\xc2\xeb\x8c\x79\x3d\xbf\x0d\x33\x93\xea\x45\x68\xc1\x00\x74\x1a\xb4\x4c\xd1\xa0\x26\xf5\x0e\xfa\x2d\xc6\x20\x8e\x22\xdb\x4a\x3f\xc1\xbe\x3a\x51\x82\xf3\x58\x3e\x25\x04\x5a\x70\x70\x87\x44\xe5\x81\x0d\xea\xa1\x4c\xba\xfe\xf9\x6b\xb3\x05\xa0\x4b\xe2\x88\xde\x3d\xe9\x6b\x65\x8a\xf3\xbe\x16\x75\x58\x78\x65\x2d\xe5\xbf\x1b\x05\x39\xf5\xf8\x1f\x95\xf3\x14\x53\x4b\xe1\x69\xd8\xe2\x25\xb2\x0e\xd4\x4c\x53\x7f\xf0\xe7\x21\x4a\xf3\x33\xcc\x2b\x52\xd7\x4c\xcb\x7a\xab\x2c\x8d\x47\xed\x25\x53\xea\x48\x79\xd8\x5b\x5a\xa7\x68\x81\x1a\x82\x93\x24\x15\x18\x27\x88\x4a\xcd\x55\x25\xea\x32\x08\x75\xdc\xe0\xb9\x60\xf3\xf9\x1e\xb6\x45\xe7\x58\x81\x09\x55\x52\x2e\x0f\xcd\x26\xab\xa1\x57\x93\x5c\x7f\xd0\x4b\x3a\x23\xcf\x29\x46\x99\x22\x69\x8b\x8c\xb8\x3a\x15\xc2\xa2\x73\xfd\x41\xc3\x39\x5d\x66\xef\xd7\xa8\x29\xf8\xa0\x71\x0f\xdd\x8a\xc4\x38\xa7\x95\x51\x0c\x81\x09\x41\xd4\xf6\x6c\x9d\x89\xa3\xc8\xed\xa5\xe7\x6b\x08\x9a\x4c\x79\xea\xc5\x41\x53\xff\x9c\x39\x84\x8b\xe9\xbf\x56\x93\xdb\x8f\xd3\xc9\xed\xdd\xc3\xc5\x18\xce\xce\x96\xb3\x7f\x4f\xbb\xb3\x0f\xe9\x3c\xbd\x99\x4c\x2f\xc6\xa7\x39\x74\xee\x90\x37\xad\x0b\xa4\xd0\x79\xc6\x37\x49\x89\xb8\xe9\x5f\x9e\xf3\xc0\xc9\xc1\x28\xca\x2c\xb2\xcd\xd5\xc9\x98\xba\x41\x1b\x1d\x2d\xe5\xc2\x35\xbc\x1a\xac\xab\xd7\xad\x99\x34\xf2\xfd\x96\xc8\x4f\x2b\x51\xa0\x8a\xef\xda\x91\xce\xe7\x9d\xe7\xf4\x41\xe1\xe8\x0e\x3e\x4e\xe7\xd3\xcf\xe9\x6a\x7a\x26\xb5\x5c\xa5\xab\xd9\xa4\x3e\xfa\xaf\x43\xf4\xfe\xcd\x21\xea\x2d\x97\xab\xdb\xc5\xb4\x37\x6e\xbe\xe6\xb7\xe9\xc7\xde\x9f\x14\x36\x7b\xd3\xf7\x8a\xcc\x9b\x7b\x63\xc5\xff\x92\xab\x27\xbb\x43\xce\x5e\x5a\x1d\x02\x09\x71\x5f\x3d\xfb\x89\x00\x4c\xb7\xfc\x91\xd7\x3f\x93\xa2\xf0\xfe\x45\xc6\x78\x8c\x1f\xe3\xff\x04\x00\x00\xff\xff\xb5\x44\x89\xaf\xbc\x0f\x00\x00")
+var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x57\x4b\x6f\xe3\x38\x12\x3e\x4b\xbf\xa2\xb6\x2f\xb6\xd1\x6e\x39\xc9\x00\xb3\x40\xb2\x59\x40\xed\x76\x77\x02\x78\x92\xc0\x76\x6f\x36\x3b\x98\x03\x45\x96\x64\x8e\x69\x52\x20\x29\x3f\xd0\xc8\x7f\x5f\x14\x25\xf9\x91\x67\xef\x4e\x4e\x31\x59\xfc\xea\xfd\x55\x69\x30\x80\xa1\x29\xb7\x56\x16\x73\x0f\x67\x27\xa7\x7f\x87\xd9\x1c\xa1\x30\x9f\x56\x8e\x43\x5a\xf9\xb9\xb1\x2e\x1e\x0c\x60\x36\x97\x0e\x72\xa9\x10\xa4\x83\x92\x59\x0f\x26\x07\xbf\x17\x55\x32\xb3\xcc\x6e\x93\x78\x30\xa8\xc5\x9f\xde\xd0\xbb\xdc\x22\x82\x33\xb9\x5f\x33\x8b\xe7\xb0\x35\x15\x70\xa6\xc1\xa2\x90\xce\x5b\x99\x55\x1e\x41\x7a\x60\x5a\x0c\x8c\x85\xa5\x11\x32\xdf\x12\x9a\xf4\x50\x69\x81\x36\x28\xf4\x68\x97\xae\xd5\xfe\xed\xe6\x3b\x8c\xd1\x39\xb4\xf0\x0d\x35\x5a\xa6\xe0\xae\xca\x94\xe4\x30\x96\x1c\xb5\x43\x60\x0e\x4a\x3a\x71\x73\x14\x90\x05\x38\x7a\xf8\x95\x4c\x99\x36\xa6\xc0\x57\x53\x69\xc1\xbc\x34\xba\x0f\x28\xfd\x1c\x2d\xac\xd0\x3a\x69\x34\xfc\xd2\xaa\x6a\x00\xfb\x60\x2c\x81\x74\x99\x27\x07\x2c\x98\x92\xde\xf5\x80\xe9\x2d\x28\xe6\xf7\x4f\xdf\x8e\xc5\xde\x65\x01\x52\x07\xcf\xe6\xa6\x44\xf0\x73\xe6\x29\x08\x6b\xa9\x14\x64\x08\x95\xc3\xbc\x52\x7d\x02\xca\x2a\x0f\xf7\xd7\xb3\xab\xdb\xef\x33\x48\x6f\x1e\xe0\x3e\x9d\x4c\xd2\x9b\xd9\xc3\x05\xac\xa5\x9f\x9b\xca\x03\xae\xb0\x86\x92\xcb\x52\x49\x14\xb0\x66\xd6\x32\xed\xb7\x60\x72\x42\xf8\x6d\x34\x19\x5e\xa5\x37\xb3\xf4\xf3\xf5\xf8\x7a\xf6\x00\xc6\xc2\xd7\xeb\xd9\xcd\x68\x3a\x85\xaf\xb7\x13\x48\xe1\x2e\x9d\xcc\xae\x87\xdf\xc7\xe9\x04\xee\xbe\x4f\xee\x6e\xa7\xa3\x04\xa6\x48\x56\x21\xbd\x7f\x3f\xdc\x79\x48\x9c\x45\x10\xe8\x99\x54\xae\x0d\xc2\x83\xa9\xc0\xcd\x4d\xa5\x04\xcc\xd9\x0a\xc1\x22\x47\xb9\x42\x01\x0c\xb8\x29\xb7\x3f\x9d\x4f\xc2\x62\xca\xe8\x22\xf8\xfc\x52\x05\xc2\x75\x0e\xda\xf8\x3e\x38\x44\xf8\xc7\xdc\xfb\xf2\x7c\x30\x58\xaf\xd7\x49\xa1\xab\xc4\xd8\x62\xa0\x6a\x24\x37\xf8\x67\x12\x13\x5c\x69\xd1\x79\xe6\x71\x66\x19\x47\x0b\xa6\xf2\x65\xe5\x1d\xb8\x2a\xcf\x25\x97\xa8\x3d\x48\x9d\x1b\xbb\x0c\xf5\x01\xde\x00\xb7\xc8\x3c\x02\x03\x65\x38\x53\x80\x1b\xe4\x55\xb8\xab\x83\x4c\x36\x79\xcb\xb4\x63\x3c\x9c\xe6\xd6\x2c\xc9\xcd\xca\x79\xfa\xc7\x39\x5c\x66\x0a\x05\x14\xa8\xd1\x49\x07\x99\x32\x7c\x91\xc4\x3f\xe2\xe8\xc0\x18\x2a\x11\x02\x6a\x85\x42\x59\xac\xb1\x63\x11\xb2\x4a\x2a\x21\x75\x91\xc4\x51\x2b\x7d\x0e\xba\x52\xaa\x1f\x07\x08\x65\xcc\xa2\x2a\x53\xce\x4d\x15\x6c\xff\x13\xb9\x27\x00\x04\x57\x22\x97\x39\xd5\x05\xdb\xdd\x7a\x13\xae\x76\x7a\x4d\x46\xf2\x49\x1c\x1d\xc1\x9c\x43\x5e\xe9\xe0\x4e\x97\x09\x61\xfb\x20\xb2\xde\x8f\x38\x8a\x56\xcc\x02\xe3\x1c\x2e\xc1\x9b\x2b\xdc\x84\xcb\xde\x45\x1c\x45\x32\x87\xae\x9f\x4b\x97\xb4\xc0\xbf\x33\xce\xff\x80\xcb\xcb\xcb\xd0\xca\xb9\xd4\x28\x7a\x40\x10\xd1\x4b\x62\xf5\x4d\x94\x31\xc5\x34\xc7\x73\xe8\x9c\x6c\x3a\xf0\x11\x44\x96\x14\xe8\x3f\xd7\xa7\xb5\xb2\xc4\x9b\xa9\xb7\x52\x17\xdd\xd3\x5f\x7b\xfd\xf0\x4a\x9b\xf0\x06\x1a\xf1\x1b\xb3\x13\xae\xef\xb9\x11\xe1\xba\xb1\xb9\x96\x1a\x1a\xd1\x08\x35\x52\xce\x1b\xcb\x0a\x3c\x87\x1f\x8f\xf4\xfb\x91\xbc\x7a\x8c\xa3\xc7\xa3\x28\x4f\x6b\xa1\x57\xa2\xdc\x40\x00\x6a\x6f\x77\x25\x5e\x48\x6a\xd2\xc3\x04\x04\xbc\xb7\x92\xd0\x68\x79\x96\x84\x05\x6e\xdf\xcf\x04\xa5\x48\x8a\xcd\xee\x62\x81\xdb\xde\x45\xfc\x6a\x8a\x92\xc6\xe8\xdf\xa5\xd8\xbc\x9c\x2f\x02\x5c\x31\xb5\x03\xac\xe3\x37\x25\x84\xbd\x5d\xbd\x50\x05\x41\x07\xc9\xfe\xed\x12\x3e\x9c\x6c\x4e\xfe\xe2\xdf\x87\xc6\x82\xe8\x5d\xb3\x7f\xc2\xb4\xc7\xe3\x7c\x5a\x74\x95\xf2\xd4\x76\x52\xaf\xcc\x82\xb8\x73\x4e\x79\x52\x2a\x64\xcd\x94\x54\x35\xae\x26\xaf\x0c\x51\x83\xf4\x68\x19\xb1\xb7\x59\xa1\xa5\x99\x05\x16\x7d\x65\xb5\xdb\xa5\x33\x97\x9a\xa9\x16\xb8\xc9\xbe\xb7\x8c\xd7\xbd\x5b\x9f\x1f\xe4\x94\xfb\x4d\xc8\x66\xf0\x71\x30\x80\xd4\x03\xf9\x09\xa5\x91\xda\xf7\x61\x8d\xa0\x11\x05\x11\x90\x40\x51\x71\xba\x45\xe8\xac\x98\xaa\xb0\x53\x93\x0c\xb1\x74\x44\xda\x4d\xe5\xd1\x1e\x92\x50\x3f\x18\xb8\x34\xab\x30\x60\x33\xc6\x17\xd0\x34\xbe\xb1\xb2\x90\x3a\x6e\xda\xf0\xa8\xe9\xbb\xdc\x6f\x12\x02\x0e\x66\x85\x9a\xa1\xdc\xd3\xc9\xe7\x90\xff\x4c\x16\xd7\xda\x3f\x29\xa2\x3a\xf2\xed\xd3\xde\x1f\x49\xd3\xc4\x89\x23\xe2\xed\x9e\xf5\xfa\x70\xfa\xeb\xae\x32\xbd\x21\x28\x78\x1f\xcc\x9b\xd7\xa1\x5a\xeb\xdf\x79\x16\xd4\x10\x93\x7c\x0c\x5a\x13\x57\x65\x94\x0e\x1f\x04\x43\x1c\x8f\xd9\xe4\xe2\x0d\xdc\x63\xdf\x5a\xdc\x26\x34\x09\x13\xe2\x75\xd0\x3a\xbb\x5f\x90\x5b\x5c\xd2\x74\xa1\x2c\x70\xa6\x14\xda\x8e\x83\xc0\x5d\xfd\xa6\x9c\x42\xbe\x70\x59\xfa\x6d\x3b\x73\x3c\xb3\x05\x7a\xf7\xbe\x61\x01\xe7\xd3\xa7\x96\x8a\xc9\x18\xbf\x2d\x11\x2e\x2f\xa1\x33\x9c\x8c\xd2\xd9\xa8\xd3\x34\xd3\x60\x00\xf7\x64\x80\x86\x4c\xc9\x4c\xa8\x2d\x08\x54\xe8\xc3\xcc\x07\x6e\x74\x08\xd1\x8e\x9a\xfa\xb4\x50\xd1\xaa\x83\x1b\xe9\xbc\xd4\x05\x84\x63\x58\xd3\x68\x6f\xe0\x42\x8f\x70\x56\x39\x14\xcf\x86\xa1\x37\xb4\xd4\x58\xa4\x21\x43\x73\x28\xb4\x1b\x53\x72\xb7\x04\xe5\xd2\x3a\x0f\xa5\x62\x1c\x13\xc2\xdb\x19\xf3\xb2\xbb\x54\x16\x0d\x33\x53\x54\x27\xa1\x05\x03\xd0\x7e\xd0\x32\x45\x83\x9a\xd4\x3b\xe8\xb6\x18\xbd\x38\x8a\x6c\x2b\x7d\x80\x7d\xb1\xa7\x04\xe7\xb1\x3c\x24\x04\xda\x6d\x70\x85\x44\xe5\x81\x0d\xea\x5d\x8d\x74\xfd\xeb\xb7\x66\x0b\x40\x97\xc4\x11\xbd\x3b\xe8\x6b\x65\x8a\xe3\xbe\x16\x75\x58\x78\x65\x2d\xe5\x7f\x37\x0a\x72\xea\xf1\x3f\x2b\xe7\x29\xa6\x96\xa8\xa5\x61\x8b\x97\xc8\x3a\x50\x33\x4d\xfd\xde\xf3\x21\x4a\xf3\x33\xcc\x2b\xf2\xa2\x99\x96\xf5\x42\x59\x1a\x8f\xda\x4b\xa6\xd4\x96\xf2\xb0\xb6\xb4\x49\xcd\xd1\x62\x1f\x9c\x24\x29\xc2\xa9\x45\xa5\xe6\xaa\x12\x74\x82\x10\x9a\xa3\xc1\x73\xc1\xe6\xe3\x15\x6c\x89\xce\xb1\x02\x13\xaa\xa4\x5c\x6e\x9a\x25\x56\x43\xa7\x26\xb9\x6e\xaf\x93\xc4\xd1\x8b\x14\xa3\x4c\x91\xb4\x45\x46\x63\x24\x15\xc2\xa2\x73\xdd\x5e\xc3\x39\xbb\xcc\xde\xcf\x51\x53\xf0\x41\xe3\xba\xa9\x39\xe9\x68\xe2\xd1\xb6\x28\xfa\xc0\x84\x20\x6a\x7b\xb2\xce\xc4\x51\xe4\xd6\xd2\xf3\x39\x04\x4d\xa6\xdc\xf7\x62\xaf\xa9\x7f\xce\x1c\xc2\x87\xd1\xbf\x67\xc3\xdb\x2f\xa3\xe1\xed\xdd\xc3\x87\x73\x38\x3a\x9b\x5e\xff\x67\xf4\xf4\xec\x2a\x9d\x5e\xed\xce\x3e\xa7\xe3\xf4\x66\x38\xfa\x70\x1e\x47\x2f\x3b\xe9\x4d\xeb\x16\x19\xe1\x3c\xe3\x8b\xa4\x44\x5c\x74\x4f\x8e\xb9\x61\xef\x74\x14\x65\x16\xd9\xe2\x62\x6f\x60\xdd\xb4\x8d\x8e\x96\x86\xe1\x12\x5e\x0d\xe0\xc5\xeb\xd6\x0c\x1b\xf9\x6e\x4b\xee\xfb\x35\x89\x4e\x7e\xc2\x8e\xb3\xff\xd9\x10\x32\x59\xea\xdb\x3c\x6f\x44\x0f\xa2\x70\xda\xab\x89\xf2\x36\x3f\x16\x1e\x69\x01\x97\xcd\xa3\x8f\x4f\x1f\x9d\x3d\x7b\xf4\xa6\xab\x67\x8d\xaf\x4f\x50\x7e\x39\x4e\x40\x3f\x68\x59\xe2\xd2\xd8\x6d\x33\x64\x82\xfa\x7e\x6d\xcd\xdb\x81\x49\xc7\xe3\x5d\x49\x0c\xd3\xf1\x98\xea\x64\x77\xf0\x65\x34\x1e\x7d\x4b\x67\xa3\x23\xa9\xe9\x2c\x9d\x5d\x0f\xeb\xa3\xd7\x5d\x68\x03\xf9\xc4\xf4\xd3\x9f\xae\x9d\xce\x74\x3a\xbb\x9d\x8c\x3a\xe7\xcd\xaf\xf1\x6d\xfa\xa5\xf3\x4c\x61\xb3\x64\xbe\xd5\x91\xde\xdc\x1b\x2b\xfe\x9f\x22\x3e\x58\xb4\x72\xf6\xd2\x9e\x45\x2c\xc2\xb8\xaf\x9e\x7c\x4f\x01\xd3\x2d\xd9\xe6\xf5\xe7\x64\x94\xb3\xe3\xb5\x69\x4f\xaf\x8f\xf1\x63\xfc\xdf\x01\x00\x3a\xbc\x2c\x05\xd0\x10\x00\x00")
 
 func prestate_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -38,7 +38,7 @@
 			var op = log.op.toString();
 		}
 		// If a new contract is being created, add to the call stack
-		if (syscall && op == 'CREATE') {
+		if (syscall && (op == 'CREATE' || op == 'CREATE2')) {
 			var inOff = log.stack.peek(1).valueOf();
 			var inEnd = inOff + log.stack.peek(2).valueOf();
 
@@ -61,7 +61,12 @@
 			if (this.callstack[left-1].calls === undefined) {
 				this.callstack[left-1].calls = [];
 			}
-			this.callstack[left-1].calls.push({type: op});
+			this.callstack[left-1].calls.push({
+				type:  op,
+				from:  toHex(log.contract.getAddress()),
+				to:    toHex(toAddress(log.stack.peek(0).toString(16))),
+				value: '0x' + db.getBalance(log.contract.getAddress()).toString(16)
+			});
 			return
 		}
 		// If a new method invocation is being done, add to the call stack
@@ -109,14 +114,22 @@
 		}
 		// If an existing call is returning, pop off the call stack
 		if (syscall && op == 'REVERT') {
-			this.callstack[this.callstack.length - 1].error = "execution reverted";
+			var call = this.callstack[this.callstack.length - 1];
+			call.error = "execution reverted";
+
+			var outOff = log.stack.peek(0).valueOf();
+			var outEnd = outOff + log.stack.peek(1).valueOf();
+			var reason = this.revertReason(log.memory.slice(outOff, outEnd));
+			if (reason) {
+				call.revertReason = reason;
+			}
 			return;
 		}
 		if (log.getDepth() == this.callstack.length - 1) {
 			// Pop off the last call and get the execution results
 			var call = this.callstack.pop();
 
-			if (call.type == 'CREATE') {
+			if (call.type == 'CREATE' || call.type == 'CREATE2') {
 				// If the call was a CREATE, retrieve the contract address and output code
 				call.gasUsed = '0x' + bigInt(call.gasIn - call.gasCost - log.getGas()).toString(16);
 				delete call.gasIn; delete call.gasCost;
@@ -205,6 +218,7 @@
 		}
 		if (this.callstack[0].error !== undefined) {
 			result.error = this.callstack[0].error;
+			result.revertReason = this.callstack[0].revertReason;
 		} else if (ctx.error !== undefined) {
 			result.error = ctx.error;
 		}
@@ -214,22 +228,103 @@
 		return this.finalize(result);
 	},
 
+	// revertReason decodes the reason string of a reverted call if the returned
+	// data is a standard Error(string) payload, or undefined otherwise.
+	revertReason: function(data) {
+		// Ensure the data is prefixed with the Error(string) selector
+		if (data.length < 4 || data[0] != 0x08 || data[1] != 0xc3 || data[2] != 0x79 || data[3] != 0xa0) {
+			return undefined;
+		}
+		// Resolve the position and length of the ABI encoded string
+		var offset = this.readWord(data, 4);
+		if (offset === undefined || 4 + offset + 32 > data.length) {
+			return undefined;
+		}
+		var start = 4 + offset + 32;
+
+		var size = this.readWord(data, start - 32);
+		if (size === undefined || start + size > data.length) {
+			return undefined;
+		}
+		return this.decodeUTF8(data, start, start + size);
+	},
+
+	// readWord reads the 32 byte big endian word at the given position, returning
+	// undefined if it's out of bounds or too large to be a valid memory offset.
+	readWord: function(data, pos) {
+		if (pos + 32 > data.length) {
+			return undefined;
+		}
+		for (var i = pos; i < pos + 26; i++) {
+			if (data[i] != 0) {
+				return undefined;
+			}
+		}
+		var word = 0;
+		for (var i = pos + 26; i < pos + 32; i++) {
+			word = word * 256 + data[i];
+		}
+		return word;
+	},
+
+	// decodeUTF8 converts a byte range into a string, replacing every invalid byte
+	// with U+FFFD the same way Go does when encoding a string into JSON.
+	decodeUTF8: function(data, start, end) {
+		var str = '';
+		for (var i = start; i < end; ) {
+			var b = data[i], size = 1, lo = 0x80, hi = 0xbf;
+			if (b >= 0xc2 && b <= 0xdf) {
+				size = 2;
+			} else if (b >= 0xe0 && b <= 0xef) {
+				size = 3;
+				if (b == 0xe0) { lo = 0xa0; }
+				if (b == 0xed) { hi = 0x9f; }
+			} else if (b >= 0xf0 && b <= 0xf4) {
+				size = 4;
+				if (b == 0xf0) { lo = 0x90; }
+				if (b == 0xf4) { hi = 0x8f; }
+			} else if (b >= 0x80) {
+				size = 0;
+			}
+			// Validate the continuation bytes, falling back to a replacement char
+			var code = size == 1 ? b : b & (0xff >> (size + 1));
+			for (var j = 1; j < size; j++) {
+				var c = data[i + j];
+				if (i + j >= end || c < (j == 1 ? lo : 0x80) || c > (j == 1 ? hi : 0xbf)) {
+					size = 0;
+					break;
+				}
+				code = code * 64 + (c & 0x3f);
+			}
+			if (size == 0) {
+				code = 0xfffd;
+				size = 1;
+			}
+			i += size;
+
+			// Duktape's fromCharCode accepts full code points, no need for surrogates
+			str += String.fromCharCode(code);
+		}
+		return str;
+	},
+
 	// finalize recreates a call object using the final desired field oder for json
 	// serialization. This is a nicety feature to pass meaningfully ordered results
 	// to users who don't interpret it, just display it.
 	finalize: function(call) {
 		var sorted = {
-			type:    call.type,
-			from:    call.from,
-			to:      call.to,
-			value:   call.value,
-			gas:     call.gas,
-			gasUsed: call.gasUsed,
-			input:   call.input,
-			output:  call.output,
-			error:   call.error,
-			time:    call.time,
-			calls:   call.calls,
+			type:         call.type,
+			from:         call.from,
+			to:           call.to,
+			value:        call.value,
+			gas:          call.gas,
+			gasUsed:      call.gasUsed,
+			input:        call.input,
+			output:       call.output,
+			error:        call.error,
+			revertReason: call.revertReason,
+			time:         call.time,
+			calls:        call.calls,
 		}
 		for (var key in sorted) {
 			if (sorted[key] === undefined) {
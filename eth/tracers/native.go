@@ -0,0 +1,91 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/log"
+)
+
+// ResultTracer is a vm.Tracer assembling a JSON result throughout the execution,
+// which can be interrupted from a different goroutine. Both the JavaScript and
+// the native tracers implement it.
+type ResultTracer interface {
+	vm.Tracer
+
+	// GetResult returns the result assembled by the tracer, or any error that
+	// occurred (or interrupted) while tracing.
+	GetResult() (json.RawMessage, error)
+
+	// Stop interrupts the tracing, failing it with the given error.
+	Stop(err error)
+}
+
+// native contains the built in tracers implemented in Go by name. They produce
+// the exact same output as their JavaScript counterparts, just a lot faster.
+var native = map[string]func() ResultTracer{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+}
+
+// NewTracer instantiates a new tracer instance. code either names a built in
+// tracer, in which case the native implementation is preferred over the
+// JavaScript one, or specifies a JavaScript snippet as accepted by New.
+func NewTracer(code string) (ResultTracer, error) {
+	if constructor, ok := native[code]; ok {
+		return constructor(), nil
+	}
+	tracer, err := New(code)
+	if err != nil {
+		return nil, err
+	}
+	return tracer, nil
+}
+
+// memorySlice returns the requested range of memory as a byte slice, or nil if
+// the range is out of bounds, the same way the JavaScript wrapper does.
+func memorySlice(memory *vm.Memory, offset, size *big.Int) []byte {
+	end := new(big.Int).Add(offset, size)
+	if !end.IsInt64() {
+		log.Warn("Tracer accessed out of bound memory", "available", memory.Len(), "offset", offset, "size", size)
+		return nil
+	}
+	return (&memoryWrapper{memory}).slice(offset.Int64(), end.Int64())
+}
+
+// hexBig formats a number the same way the JavaScript tracers do, as a 0x
+// prefixed hexadecimal string without leading zeroes (sign after the prefix).
+func hexBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+// marshalResult encodes a native tracer result the same way the JavaScript VM
+// does, without escaping HTML characters and without a trailing newline.
+func marshalResult(v interface{}) (json.RawMessage, error) {
+	buf := new(bytes.Buffer)
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
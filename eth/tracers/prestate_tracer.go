@@ -0,0 +1,218 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/crypto"
+)
+
+// errNoPrestate is returned if the prestate tracer is asked for its result of a
+// transaction which didn't execute any code, so no state was ever accessed.
+var errNoPrestate = errors.New("no state accessed by the transaction")
+
+// prestateAccount is the state of an account before the traced transaction.
+type prestateAccount struct {
+	balance *big.Int
+	nonce   int64
+	code    []byte
+	keys    []common.Hash // Storage slots in the order of access
+	storage map[common.Hash]common.Hash
+}
+
+// prestateTracer is the native implementation of the JavaScript prestateTracer,
+// which outputs sufficient information to create a local execution of the
+// transaction from a custom assembled genesis block.
+type prestateTracer struct {
+	accounts []common.Address // Accounts in the order of access
+	prestate map[common.Address]*prestateAccount
+
+	db     vm.StateDB // State database of the last executed step
+	create bool       // Whether the traced transaction is a contract creation
+	from   common.Address
+	to     common.Address
+	value  *big.Int
+
+	err error // Error, if one has occurred
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newPrestateTracer creates a new native prestate tracer.
+func newPrestateTracer() ResultTracer {
+	return &prestateTracer{prestate: make(map[common.Address]*prestateAccount)}
+}
+
+// Stop implements ResultTracer, interrupting the tracing with the given error.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	t.accounts = append(t.accounts, addr)
+	t.prestate[addr] = &prestateAccount{
+		balance: new(big.Int).Set(t.db.GetBalance(addr)),
+		nonce:   int64(t.db.GetNonce(addr)),
+		code:    t.db.GetCode(addr),
+		storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate, if it's not empty.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+
+	account := t.prestate[addr]
+	if _, ok := account.storage[key]; ok {
+		return
+	}
+	if val := t.db.GetState(addr, key); val != (common.Hash{}) {
+		account.keys = append(account.keys, key)
+		account.storage[key] = val
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create = create
+	t.from, t.to = from, to
+	t.value = new(big.Int).Set(value)
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return nil
+	}
+	// Add the current account if we just started tracing. Balance will potentially
+	// be wrong here, since this will include the value sent along with the message.
+	// We fix that in GetResult.
+	if t.db == nil {
+		t.db = env.StateDB
+		t.lookupAccount(contract.Address())
+	}
+	t.db = env.StateDB
+
+	// Whenever new state is accessed, add it to the prestate
+	peek := (&stackWrapper{stack}).peek
+
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE:
+		t.lookupAccount(common.BigToAddress(peek(0)))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+	case vm.CREATE2:
+		from := contract.Address()
+		code := memorySlice(memory, peek(1), peek(2))
+		t.lookupAccount(crypto.CreateAddress2(from, common.BigToHash(peek(3)), crypto.Keccak256(code)))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BigToAddress(peek(1)))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.BigToHash(peek(0)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult implements ResultTracer, returning the assembled allocations of the
+// accounts accessed by the transaction, or any error that occurred while tracing.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.db == nil {
+		return nil, errNoPrestate
+	}
+	// At this point, we need to deduct the 'value' from the outer transaction,
+	// and move it back to the origin
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	t.prestate[t.to].balance.Sub(t.prestate[t.to].balance, t.value)
+	t.prestate[t.from].balance.Add(t.prestate[t.from].balance, t.value)
+
+	// Decrement the caller's nonce, and remove empty create targets
+	t.prestate[t.from].nonce--
+	if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		delete(t.prestate, t.to)
+	}
+	// Assemble the allocations in the order of access, the same as the JavaScript
+	// tracer would serialize its prestate object
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for _, addr := range t.accounts {
+		account, ok := t.prestate[addr]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"` + hexutil.Encode(addr[:]) + `":{`)
+		buf.WriteString(`"balance":"` + hexBig(account.balance) + `",`)
+		buf.WriteString(`"nonce":` + strconv.FormatInt(account.nonce, 10) + `,`)
+		buf.WriteString(`"code":"` + hexutil.Encode(account.code) + `",`)
+		buf.WriteString(`"storage":{`)
+		for i, key := range account.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			val := account.storage[key]
+			buf.WriteString(`"` + hexutil.Encode(key[:]) + `":"` + hexutil.Encode(val[:]) + `"`)
+		}
+		buf.WriteString(`}}`)
+	}
+	buf.WriteByte('}')
+
+	return json.RawMessage(buf.Bytes()), nil
+}
@@ -289,6 +289,7 @@ type Tracer struct {
 	memoryWrapper   *memoryWrapper   // Wrapper around the VM memory
 	contractWrapper *contractWrapper // Wrapper around the contract object
 	dbWrapper       *dbWrapper       // Wrapper around the VM environment
+	env             *vm.EVM          // VM environment, used to resolve active precompiles
 
 	pcValue    *uint   // Swappable pc value wrapped by a log accessor
 	gasValue   *uint   // Swappable gas value wrapped by a log accessor
@@ -365,8 +366,35 @@ func New(code string) (*Tracer, error) {
 		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
 		return 1
 	})
+	tracer.vm.PushGlobalGoFunction("toContract2", func(ctx *duktape.Context) int {
+		var from common.Address
+		if ptr, size := ctx.GetBuffer(-3); ptr != nil {
+			from = common.BytesToAddress(makeSlice(ptr, size))
+		} else {
+			from = common.HexToAddress(ctx.GetString(-3))
+		}
+		// Retrieve the salt hex string and the init code from the js stack
+		salt := common.HexToHash(ctx.GetString(-2))
+
+		var code []byte
+		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
+			code = common.CopyBytes(makeSlice(ptr, size))
+		} else {
+			code = common.FromHex(ctx.GetString(-1))
+		}
+		ctx.Pop3()
+
+		contract := crypto.CreateAddress2(from, salt, crypto.Keccak256(code))
+		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
+		return 1
+	})
 	tracer.vm.PushGlobalGoFunction("isPrecompiled", func(ctx *duktape.Context) int {
-		_, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(popSlice(ctx))]
+		addr := common.BytesToAddress(popSlice(ctx))
+		if tracer.env != nil {
+			ctx.PushBoolean(tracer.env.IsPrecompiled(addr))
+			return 1
+		}
+		_, ok := vm.PrecompiledContractsByzantium[addr]
 		ctx.PushBoolean(ok)
 		return 1
 	})
@@ -515,6 +543,7 @@ func (jst *Tracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 		// Initialize the context if it wasn't done yet
 		if !jst.inited {
 			jst.ctx["block"] = env.BlockNumber.Uint64()
+			jst.env = env
 			jst.inited = true
 		}
 		// If tracing was interrupted, set the error and stop
@@ -17,6 +17,7 @@
 package tracers
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"math/big"
@@ -24,6 +25,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/hexutil"
@@ -32,6 +34,7 @@ import (
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/core/vm"
 	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rlp"
 	"github.com/vsportchain/go-vsc/tests"
 )
@@ -86,18 +89,51 @@ var makeTest = function(tx, rewind) {
 }
 */
 
+// teeTracer is a vm.Tracer forwarding all events to a set of tracers, used to
+// trace the same execution by multiple tracers at once.
+type teeTracer []vm.Tracer
+
+func (tee teeTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	for _, tracer := range tee {
+		tracer.CaptureStart(from, to, create, input, gas, value)
+	}
+	return nil
+}
+
+func (tee teeTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	for _, tracer := range tee {
+		tracer.CaptureState(env, pc, op, gas, cost, memory, stack, contract, depth, err)
+	}
+	return nil
+}
+
+func (tee teeTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	for _, tracer := range tee {
+		tracer.CaptureFault(env, pc, op, gas, cost, memory, stack, contract, depth, err)
+	}
+	return nil
+}
+
+func (tee teeTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	for _, tracer := range tee {
+		tracer.CaptureEnd(output, gasUsed, t, err)
+	}
+	return nil
+}
+
 // callTrace is the result of a callTracer run.
 type callTrace struct {
-	Type    string          `json:"type"`
-	From    common.Address  `json:"from"`
-	To      common.Address  `json:"to"`
-	Input   hexutil.Bytes   `json:"input"`
-	Output  hexutil.Bytes   `json:"output"`
-	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
-	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
-	Value   *hexutil.Big    `json:"value,omitempty"`
-	Error   string          `json:"error,omitempty"`
-	Calls   []callTrace     `json:"calls,omitempty"`
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           common.Address  `json:"to"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output"`
+	Gas          *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed      *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []callTrace     `json:"calls,omitempty"`
 }
 
 type callContext struct {
@@ -161,12 +197,19 @@ func TestCallTracer(t *testing.T) {
 			}
 			statedb := tests.MakePreState(ethdb.NewMemDatabase(), test.Genesis.Alloc)
 
-			// Create the tracer, the EVM environment and run it
+			// Create the JavaScript and native tracers, the EVM environment and run them
 			tracer, err := New("callTracer")
 			if err != nil {
 				t.Fatalf("failed to create call tracer: %v", err)
 			}
-			evm := vm.NewEVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+			prestate, err := New("prestateTracer")
+			if err != nil {
+				t.Fatalf("failed to create prestate tracer: %v", err)
+			}
+			nativeTracer, nativePrestate := newCallTracer(), newPrestateTracer()
+
+			tee := teeTracer{tracer, prestate, nativeTracer, nativePrestate}
+			evm := vm.NewEVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tee})
 
 			msg, err := tx.AsMessage(signer)
 			if err != nil {
@@ -188,6 +231,74 @@ func TestCallTracer(t *testing.T) {
 			if !reflect.DeepEqual(ret, test.Result) {
 				t.Fatalf("trace mismatch: have %+v, want %+v", ret, test.Result)
 			}
+			// Ensure the native tracers produce the exact same output
+			nativeRes, err := nativeTracer.GetResult()
+			if err != nil {
+				t.Fatalf("failed to retrieve native trace result: %v", err)
+			}
+			if !bytes.Equal(nativeRes, res) {
+				t.Fatalf("native trace mismatch: have %s, want %s", nativeRes, res)
+			}
+			prestateRes, err := prestate.GetResult()
+			if err != nil {
+				t.Fatalf("failed to retrieve prestate result: %v", err)
+			}
+			nativePrestateRes, err := nativePrestate.GetResult()
+			if err != nil {
+				t.Fatalf("failed to retrieve native prestate result: %v", err)
+			}
+			if !bytes.Equal(nativePrestateRes, prestateRes) {
+				t.Fatalf("native prestate mismatch: have %s, want %s", nativePrestateRes, prestateRes)
+			}
 		})
 	}
 }
+
+// Tests that the native call tracer decodes the reason of reverted calls.
+func TestCallTracerRevertReason(t *testing.T) {
+	// Error("revert reason") payload, returned by a contract copying it from its code
+	reason := common.Hex2Bytes("08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000")
+	code := append([]byte{0x60, byte(len(reason)), 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, byte(len(reason)), 0x60, 0x00, 0xfd}, reason...)
+
+	var (
+		from     = common.Address{0x01}
+		contract = common.Address{0x02}
+	)
+	statedb := tests.MakePreState(ethdb.NewMemDatabase(), core.GenesisAlloc{
+		from:     {Balance: big.NewInt(params.Ether)},
+		contract: {Code: code, Balance: new(big.Int)},
+	})
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      from,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    uint64(1000000),
+		GasPrice:    big.NewInt(1),
+	}
+	tracer := newCallTracer()
+	evm := vm.NewEVM(context, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+
+	if _, _, err := evm.Call(vm.AccountRef(from), contract, nil, 100000, new(big.Int)); err == nil {
+		t.Fatalf("call succeeded, expected revert")
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	var ret struct {
+		Error        string `json:"error"`
+		RevertReason string `json:"revertReason"`
+	}
+	if err := json.Unmarshal(res, &ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if ret.Error != "execution reverted" {
+		t.Errorf("error mismatch: have %q, want %q", ret.Error, "execution reverted")
+	}
+	if ret.RevertReason != "revert reason" {
+		t.Errorf("revert reason mismatch: have %q, want %q", ret.RevertReason, "revert reason")
+	}
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
@@ -121,13 +120,32 @@ func (db *LDBDatabase) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
-func (db *LDBDatabase) NewIterator() iterator.Iterator {
-	return db.db.NewIterator(nil, nil)
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *LDBDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
 }
 
-// NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
-func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
-	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
+// NewSnapshot creates a database snapshot based on the current state.
+func (db *LDBDatabase) NewSnapshot() (Snapshot, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &ldbSnapshot{db: snap}, nil
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *LDBDatabase) Stat(property string) (string, error) {
+	return db.db.GetProperty(property)
+}
+
+// Compact flattens the underlying data store for the given key range. In essence,
+// deleted and overwritten versions are discarded, and the data is rearranged to
+// reduce the cost of operations needed to access them.
+func (db *LDBDatabase) Compact(start []byte, limit []byte) error {
+	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
 func (db *LDBDatabase) Close() {
@@ -382,6 +400,12 @@ func (b *ldbBatch) Put(key, value []byte) error {
 	return nil
 }
 
+func (b *ldbBatch) Delete(key []byte) error {
+	b.b.Delete(key)
+	b.size += 1
+	return nil
+}
+
 func (b *ldbBatch) Write() error {
 	return b.db.Write(b.b, nil)
 }
@@ -395,6 +419,35 @@ func (b *ldbBatch) Reset() {
 	b.size = 0
 }
 
+// ldbSnapshot wraps a LevelDB snapshot for implementing the Snapshot interface.
+type ldbSnapshot struct {
+	db *leveldb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *ldbSnapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key, nil)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *ldbSnapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key, nil)
+}
+
+// Release releases associated resources.
+func (snap *ldbSnapshot) Release() {
+	snap.db.Release()
+}
+
+// bytesPrefixRange returns key range that satisfy
+// - the given prefix, and
+// - the given seek position
+func bytesPrefixRange(prefix, start []byte) *util.Range {
+	r := util.BytesPrefix(prefix)
+	r.Start = append(r.Start, start...)
+	return r
+}
+
 type table struct {
 	db     Database
 	prefix string
@@ -429,6 +482,87 @@ func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+// NewIterator creates a binary-alphabetical iterator over a subset of the table
+// content with a particular key prefix, starting at a particular initial key.
+// The table prefix is stripped from the keys returned by the iterator.
+func (dt *table) NewIterator(prefix []byte, start []byte) Iterator {
+	it := dt.db.NewIterator(append([]byte(dt.prefix), prefix...), start)
+	return &tableIterator{it: it, prefix: len(dt.prefix)}
+}
+
+// NewSnapshot creates a snapshot of the underlying database, with all lookups
+// confined to the table.
+func (dt *table) NewSnapshot() (Snapshot, error) {
+	snap, err := dt.db.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &tableSnapshot{snap: snap, prefix: dt.prefix}, nil
+}
+
+// Stat returns a particular internal stat of the underlying database.
+func (dt *table) Stat(property string) (string, error) {
+	return dt.db.Stat(property)
+}
+
+// Compact flattens the given key range of the table in the underlying database.
+func (dt *table) Compact(start []byte, limit []byte) error {
+	// If no start was specified, use the table prefix as the first value
+	if start == nil {
+		start = []byte(dt.prefix)
+	} else {
+		start = append([]byte(dt.prefix), start...)
+	}
+	// If no limit was specified, use the first element not matching the prefix
+	// as the limit
+	if limit == nil {
+		limit = util.BytesPrefix([]byte(dt.prefix)).Limit
+	} else {
+		limit = append([]byte(dt.prefix), limit...)
+	}
+	return dt.db.Compact(start, limit)
+}
+
+// tableIterator is a wrapper around a database iterator that strips the table
+// prefix from the returned keys.
+type tableIterator struct {
+	it     Iterator
+	prefix int
+}
+
+func (it *tableIterator) Next() bool { return it.it.Next() }
+
+func (it *tableIterator) Error() error { return it.it.Error() }
+
+func (it *tableIterator) Key() []byte {
+	key := it.it.Key()
+	if key == nil {
+		return nil
+	}
+	return key[it.prefix:]
+}
+
+func (it *tableIterator) Value() []byte { return it.it.Value() }
+
+func (it *tableIterator) Release() { it.it.Release() }
+
+// tableSnapshot is a wrapper around a database snapshot that prefixes all the
+// looked up keys with the table prefix.
+type tableSnapshot struct {
+	snap   Snapshot
+	prefix string
+}
+
+func (s *tableSnapshot) Has(key []byte) (bool, error) {
+	return s.snap.Has(append([]byte(s.prefix), key...))
+}
+
+func (s *tableSnapshot) Get(key []byte) ([]byte, error) {
+	return s.snap.Get(append([]byte(s.prefix), key...))
+}
+
+func (s *tableSnapshot) Release() { s.snap.Release() }
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
@@ -447,6 +581,10 @@ func (tb *tableBatch) Put(key, value []byte) error {
 	return tb.batch.Put(append([]byte(tb.prefix), key...), value)
 }
 
+func (tb *tableBatch) Delete(key []byte) error {
+	return tb.batch.Delete(append([]byte(tb.prefix), key...))
+}
+
 func (tb *tableBatch) Write() error {
 	return tb.batch.Write()
 }
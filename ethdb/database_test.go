@@ -44,6 +44,22 @@ func newTestLDB() (*ethdb.LDBDatabase, func()) {
 	}
 }
 
+func newTestLogDB() (*ethdb.LogDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := ethdb.NewLogDatabase(dirname, 0, 0)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
 var test_values = []string{"", "a", "1251", "\x00123\x00"}
 
 func TestLDB_PutGet(t *testing.T) {
@@ -56,6 +72,12 @@ func TestMemoryDB_PutGet(t *testing.T) {
 	testPutGet(ethdb.NewMemDatabase(), t)
 }
 
+func TestLogDB_PutGet(t *testing.T) {
+	db, remove := newTestLogDB()
+	defer remove()
+	testPutGet(db, t)
+}
+
 func testPutGet(db ethdb.Database, t *testing.T) {
 	t.Parallel()
 
@@ -133,6 +155,12 @@ func TestMemoryDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(ethdb.NewMemDatabase(), t)
 }
 
+func TestLogDB_ParallelPutGet(t *testing.T) {
+	db, remove := newTestLogDB()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
 func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	const n = 8
 	var pending sync.WaitGroup
@@ -188,3 +216,123 @@ func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_Iterator(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterator(db, t)
+}
+
+func TestMemoryDB_Iterator(t *testing.T) {
+	testIterator(ethdb.NewMemDatabase(), t)
+}
+
+func TestLogDB_Iterator(t *testing.T) {
+	db, remove := newTestLogDB()
+	defer remove()
+	testIterator(db, t)
+}
+
+func TestTable_Iterator(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("0"), []byte("outside"))
+	db.Put([]byte("u"), []byte("outside"))
+
+	testIterator(ethdb.NewTable(db, "t"), t)
+}
+
+func testIterator(db ethdb.Database, t *testing.T) {
+	keys := []string{"1", "2", "3", "4", "6", "10", "11", "12", "20", "21", "22"}
+	for _, k := range keys {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	tests := []struct {
+		prefix string
+		start  string
+		order  []string
+	}{
+		// Empty prefix and start should iterate over everything
+		{"", "", []string{"1", "10", "11", "12", "2", "20", "21", "22", "3", "4", "6"}},
+		// Empty prefix with start should iterate from the start key
+		{"", "5", []string{"6"}},
+		{"", "2", []string{"2", "20", "21", "22", "3", "4", "6"}},
+		// Prefix without start should iterate over the prefixed keys only
+		{"1", "", []string{"1", "10", "11", "12"}},
+		{"5", "", nil},
+		// Prefix with start should iterate the prefixed keys from the relative start
+		{"1", "1", []string{"11", "12"}},
+		{"2", "1", []string{"21", "22"}},
+		{"2", "3", nil},
+	}
+	for i, tt := range tests {
+		it := db.NewIterator([]byte(tt.prefix), []byte(tt.start))
+
+		var have []string
+		for it.Next() {
+			if want := "v" + string(it.Key()); string(it.Value()) != want {
+				t.Errorf("test %d: value mismatch for key %q: have %q, want %q", i, it.Key(), it.Value(), want)
+			}
+			have = append(have, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Errorf("test %d: iteration failed: %v", i, err)
+		}
+		it.Release()
+
+		if fmt.Sprint(have) != fmt.Sprint(tt.order) {
+			t.Errorf("test %d: iteration order mismatch: have %v, want %v", i, have, tt.order)
+		}
+	}
+}
+
+func TestLDB_Snapshot(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testSnapshot(db, t)
+}
+
+func TestMemoryDB_Snapshot(t *testing.T) {
+	testSnapshot(ethdb.NewMemDatabase(), t)
+}
+
+func TestLogDB_Snapshot(t *testing.T) {
+	db, remove := newTestLogDB()
+	defer remove()
+	testSnapshot(db, t)
+}
+
+func testSnapshot(db ethdb.Database, t *testing.T) {
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	defer snap.Release()
+
+	// Modify the database after the snapshot was taken
+	db.Put([]byte("a"), []byte("3"))
+	db.Delete([]byte("b"))
+	db.Put([]byte("c"), []byte("4"))
+
+	for key, want := range map[string]string{"a": "1", "b": "2"} {
+		if have, err := snap.Get([]byte(key)); err != nil || string(have) != want {
+			t.Errorf("snapshot key %q: have %q (err %v), want %q", key, have, err, want)
+		}
+	}
+	if ok, _ := snap.Has([]byte("c")); ok {
+		t.Errorf("snapshot contains key written after its creation")
+	}
+	if _, err := snap.Get([]byte("c")); err == nil {
+		t.Errorf("snapshot retrieved key written after its creation")
+	}
+	if have, err := db.Get([]byte("a")); err != nil || string(have) != "3" {
+		t.Errorf("database key %q: have %q (err %v), want %q", "a", have, err, "3")
+	}
+	if ok, _ := db.Has([]byte("b")); ok {
+		t.Errorf("database contains deleted key")
+	}
+}
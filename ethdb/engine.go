@@ -0,0 +1,77 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// LevelDBEngine is the name of the goleveldb backed database engine.
+	LevelDBEngine = "leveldb"
+
+	// LogDBEngine is the name of the pure Go, log-structured database engine.
+	LogDBEngine = "logdb"
+)
+
+// NewDiskDatabase opens (or creates) a persistent database at the given path,
+// backed by the requested engine. If no engine is specified, the one used by any
+// pre-existing database is picked, defaulting to LevelDB for new databases.
+func NewDiskDatabase(engine string, file string, cache int, handles int) (Database, error) {
+	existing := PreexistingEngine(file)
+	if engine == "" {
+		engine = existing
+	}
+	if engine == "" {
+		engine = LevelDBEngine
+	}
+	if existing != "" && existing != engine {
+		return nil, fmt.Errorf("database at %s was created with engine %q, not %q", file, existing, engine)
+	}
+	switch engine {
+	case LevelDBEngine:
+		db, err := NewLDBDatabase(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+
+	case LogDBEngine:
+		db, err := NewLogDatabase(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+
+	default:
+		return nil, fmt.Errorf("unknown database engine %q", engine)
+	}
+}
+
+// PreexistingEngine checks whether a database already exists at the given path,
+// returning the name of its engine, or an empty string if none is found.
+func PreexistingEngine(file string) string {
+	if _, err := os.Stat(filepath.Join(file, "CURRENT")); err == nil {
+		return LevelDBEngine
+	}
+	if _, err := os.Stat(filepath.Join(file, logMarkerFile)); err == nil {
+		return LogDBEngine
+	}
+	return ""
+}
@@ -0,0 +1,1550 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/log"
+	"github.com/prometheus/prometheus/util/flock"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+)
+
+const (
+	// logSegmentSize is the size after which the active segment of a log database
+	// is sealed and a fresh one is started.
+	logSegmentSize = 256 * 1024 * 1024
+
+	// logRecordHeaderSize is the size of a record header in a segment file: the
+	// crc32 checksum and the length of the payload.
+	logRecordHeaderSize = 8
+
+	// logPointerSize is the size of an index entry: segment id, value offset,
+	// value length and sequence number.
+	logPointerSize = 20
+
+	// logTombstone is the value length marking a deleted key in the index while
+	// the segments are being replayed at startup.
+	logTombstone = math.MaxUint32
+
+	// logMarkerFile is the name of the file identifying a log database directory.
+	logMarkerFile = "LOGDB"
+
+	// logLockFile is the name of the file lock guarding a log database directory.
+	logLockFile = "FLOCK"
+
+	// logObsoleteFile is the name of the manifest listing the segments made
+	// obsolete by compaction, which are discarded on startup if still present.
+	logObsoleteFile = "OBSOLETE"
+)
+
+const (
+	logEntryDelete byte = iota // Entry deleting a key
+	logEntryPut                // Entry inserting or updating a key
+)
+
+var (
+	// errLogDBClosed is returned if a log database is accessed after being closed.
+	errLogDBClosed = errors.New("log database closed")
+
+	// errLogDBNotFound is returned if a key is not present in a log database.
+	errLogDBNotFound = errors.New("not found")
+
+	// errLogRecordCorrupted is returned if a segment record fails to decode.
+	errLogRecordCorrupted = errors.New("corrupted log record")
+
+	// errLogRecordTooLarge is returned if a batch doesn't fit into a segment.
+	errLogRecordTooLarge = errors.New("log record too large")
+
+	// logCRCTable is the checksum table used for segment records and hint files.
+	logCRCTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// LogDatabase is a pure Go, log-structured key-value store. All writes are
+// appended as checksummed records to the active segment file, while an ordered
+// in-memory index maps every live key to the location of its latest value on
+// disk. Once the active segment grows past a threshold it's sealed, with a hint
+// file listing its entries written next to it to avoid replaying the segment at
+// startup. Sealed segments holding overwritten or deleted data are rewritten by
+// compaction, which is triggered automatically as the garbage accumulates.
+//
+// Every entry carries a sequence number, so the index can be reconstructed
+// irrespective of the order in which compaction rewrote the segments.
+//
+// Note, contrary to LevelDB's LSM tree, all the keys are held in memory, so the
+// store is best suited for databases whose key set fits comfortably in RAM. Batch
+// writes are synced to disk, whereas individual puts and deletes are not.
+type LogDatabase struct {
+	fn    string         // filename for reporting
+	flock flock.Releaser // File-system lock to prevent double opens
+
+	index    *memdb.DB              // Ordered key index pointing into the segments
+	segments map[uint32]*logSegment // Segment files (including obsolete ones), keyed by id
+	active   *logSegment            // Segment currently being appended to
+	nextID   uint32                 // Id to assign to the next created segment
+	seq      uint64                 // Sequence number of the last written entry
+	record   logRecord              // Reusable record buffer for the write path
+
+	segmentSize uint32 // Size threshold after which the active segment is sealed
+	cacheSize   int    // Initial buffer size to allocate for the index
+
+	snaps      map[*logSnapshot]struct{} // Live snapshots to maintain copy-on-write overlays for
+	readers    int                       // Number of live iterators and snapshots pinning obsolete segments
+	compacting bool                      // Whether a background compaction is already scheduled
+
+	quit   chan struct{} // Quit channel to abort running compactions on close
+	closed bool
+	lock   sync.RWMutex
+
+	compactLock sync.Mutex // Mutex serializing compactions
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewLogDatabase returns a log-structured database persisted in the given
+// directory. The cache allowance is used to size the in-memory index, whereas
+// the handles allowance is ignored as all segment files are kept open.
+func NewLogDatabase(file string, cache int, handles int) (*LogDatabase, error) {
+	logger := log.New("database", file)
+
+	// Ensure we have some minimal caching guarantees
+	if cache < 16 {
+		cache = 16
+	}
+	// Lock the database directory and mark it as a log database
+	if err := os.MkdirAll(file, 0755); err != nil {
+		return nil, err
+	}
+	lock, _, err := flock.New(filepath.Join(file, logLockFile))
+	if err != nil {
+		return nil, err
+	}
+	marker := filepath.Join(file, logMarkerFile)
+	if _, err := os.Stat(marker); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(marker, []byte(LogDBEngine+"\n"), 0644); err != nil {
+			lock.Release()
+			return nil, err
+		}
+	}
+	db := &LogDatabase{
+		fn:          file,
+		flock:       lock,
+		segments:    make(map[uint32]*logSegment),
+		segmentSize: logSegmentSize,
+		cacheSize:   cache / 4 * 1024 * 1024,
+		snaps:       make(map[*logSnapshot]struct{}),
+		quit:        make(chan struct{}),
+		log:         logger,
+	}
+	db.index = memdb.New(comparer.DefaultComparer, db.cacheSize)
+
+	// Rebuild the index from the segments and open the active one
+	start := time.Now()
+	if err := db.load(); err != nil {
+		for _, seg := range db.segments {
+			seg.close()
+		}
+		lock.Release()
+		return nil, err
+	}
+	logger.Info("Loaded log database", "segments", len(db.segments), "keys", db.index.Len(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return db, nil
+}
+
+// Path returns the path to the database directory.
+func (db *LogDatabase) Path() string {
+	return db.fn
+}
+
+// Put inserts the given value into the database.
+func (db *LogDatabase) Put(key []byte, value []byte) error {
+	return db.write([]kv{{k: key, v: value}}, false)
+}
+
+// Has retrieves if a key is present in the database.
+func (db *LogDatabase) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, errLogDBClosed
+	}
+	_, ok := db.lookup(key)
+	return ok, nil
+}
+
+// Get retrieves the given key if it's present in the database.
+func (db *LogDatabase) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, errLogDBClosed
+	}
+	ptr, ok := db.lookup(key)
+	if !ok {
+		return nil, errLogDBNotFound
+	}
+	return db.read(ptr)
+}
+
+// Delete removes the key from the database.
+func (db *LogDatabase) Delete(key []byte) error {
+	return db.write([]kv{{k: key, del: true}}, false)
+}
+
+// NewBatch creates a write-only database batch that buffers changes until a
+// final write is called, when they are appended to the log as a single record
+// and synced to disk.
+func (db *LogDatabase) NewBatch() Batch {
+	return &logBatch{db: db}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key (or
+// after, if it does not exist).
+//
+// Like with LevelDB, the iterator operates on an implicit snapshot of the
+// database taken at creation: modifications made afterwards are not visible.
+func (db *LogDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return &logIterator{err: errLogDBClosed}
+	}
+	snap := db.newSnapshot()
+	rng := bytesPrefixRange(prefix, start)
+
+	return &logIterator{
+		snap: snap,
+		live: db.index.NewIterator(rng),
+		over: snap.overlay.NewIterator(rng),
+	}
+}
+
+// NewSnapshot creates a database snapshot based on the current state. Instead
+// of copying the index, the snapshot records the previous location of every
+// key modified after its creation.
+func (db *LogDatabase) NewSnapshot() (Snapshot, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return nil, errLogDBClosed
+	}
+	return db.newSnapshot(), nil
+}
+
+// newSnapshot creates a snapshot tracking all subsequent modifications and pins
+// the segments it may reference. The caller must hold the write lock.
+func (db *LogDatabase) newSnapshot() *logSnapshot {
+	snap := &logSnapshot{
+		db:      db,
+		overlay: memdb.New(comparer.DefaultComparer, 0),
+	}
+	db.snaps[snap] = struct{}{}
+	db.readers++
+
+	return snap
+}
+
+// Stat returns a particular internal stat of the database. The only supported
+// property is "logdb.stats", summarizing the segments of the database.
+func (db *LogDatabase) Stat(property string) (string, error) {
+	if property != "logdb.stats" {
+		return "", fmt.Errorf("unknown property: %s", property)
+	}
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return "", errLogDBClosed
+	}
+	ids := make([]int, 0, len(db.segments))
+	for id := range db.segments {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	var (
+		out     bytes.Buffer
+		size    uint64
+		garbage uint64
+	)
+	out.WriteString(" Segment |  Size(MB)  | Garbage(MB) | Status\n")
+	out.WriteString("---------+------------+-------------+----------\n")
+	for _, id := range ids {
+		seg := db.segments[uint32(id)]
+
+		status := "sealed"
+		switch {
+		case seg == db.active:
+			status = "active"
+		case seg.obsolete:
+			status = "obsolete"
+		}
+		fmt.Fprintf(&out, " %7d | %10.5f | %11.5f | %s\n", seg.id, float64(seg.size)/1024/1024, float64(seg.garbage)/1024/1024, status)
+		if !seg.obsolete {
+			size += uint64(seg.size)
+			garbage += seg.garbage
+		}
+	}
+	fmt.Fprintf(&out, "Keys:%d Index(MB):%.5f Size(MB):%.5f Garbage(MB):%.5f\n", db.index.Len(), float64(db.index.Capacity())/1024/1024, float64(size)/1024/1024, float64(garbage)/1024/1024)
+	return out.String(), nil
+}
+
+// Compact rewrites all the sealed segments containing overwritten or deleted
+// data, discarding the stale entries. As the log database doesn't order its
+// segments by key, the requested range is ignored and the entire store is
+// compacted.
+func (db *LogDatabase) Compact(start []byte, limit []byte) error {
+	db.compactLock.Lock()
+	defer db.compactLock.Unlock()
+
+	// Gather all the sealed segments with garbage in them
+	db.lock.RLock()
+	if db.closed {
+		db.lock.RUnlock()
+		return errLogDBClosed
+	}
+	var (
+		victims []*logSegment
+		garbage uint64
+	)
+	for _, seg := range db.segments {
+		if seg != db.active && !seg.obsolete && seg.hints == nil && seg.garbage > 0 {
+			victims = append(victims, seg)
+			garbage += seg.garbage
+		}
+	}
+	db.lock.RUnlock()
+
+	if len(victims) == 0 {
+		return nil
+	}
+	sort.Slice(victims, func(i, j int) bool { return victims[i].id < victims[j].id })
+
+	// Copy all the live entries out of the victims into fresh segments. Dead
+	// puts and all tombstones are dropped: a tombstone is only needed to shadow
+	// an older put of the same key, which would be garbage in a victim too. The
+	// victims are recorded as obsolete before any of them is deleted, so a crash
+	// midway never resurrects such a put without the tombstone shadowing it.
+	var (
+		cstart  = time.Now()
+		out     *logSegment
+		rec     logRecord
+		origins []logPointer
+	)
+	rec.reset()
+
+	flush := func() error {
+		if len(rec.entries) == 0 {
+			return nil
+		}
+		db.lock.Lock()
+		defer db.lock.Unlock()
+
+		if db.closed {
+			return errLogDBClosed
+		}
+		if out != nil && uint64(out.size)+uint64(len(rec.buf)) > uint64(db.segmentSize) {
+			if err := out.seal(); err != nil {
+				return err
+			}
+			out = nil
+		}
+		if out == nil {
+			var err error
+			if out, err = db.createSegment(); err != nil {
+				return err
+			}
+		}
+		base, err := out.write(&rec)
+		if err != nil {
+			return err
+		}
+		// Move the index over to the new copies, unless overwritten meanwhile
+		for i := range rec.entries {
+			entry, origin := &rec.entries[i], origins[i]
+
+			cur, ok := db.lookup(entry.key)
+			if ok && cur.segment == origin.segment && cur.offset == origin.offset {
+				db.index.Put(entry.key, logPointer{out.id, base + entry.offset, entry.length, entry.seq}.encode())
+			} else {
+				out.garbage += logPointer{length: entry.length, seq: entry.seq}.size(entry.key)
+			}
+		}
+		rec.reset()
+		origins = origins[:0]
+		return nil
+	}
+	for _, victim := range victims {
+		_, err := victim.scan(func(entry *logEntry, base uint32) error {
+			select {
+			case <-db.quit:
+				return errLogDBClosed
+			default:
+			}
+			if entry.kind != logEntryPut {
+				return nil
+			}
+			db.lock.RLock()
+			cur, ok := db.lookup(entry.key)
+			db.lock.RUnlock()
+
+			if !ok || cur.segment != victim.id || cur.offset != base+entry.offset {
+				return nil
+			}
+			rec.add(logEntryPut, entry.seq, entry.key, entry.value)
+			origins = append(origins, logPointer{victim.id, base + entry.offset, entry.length, entry.seq})
+
+			if len(rec.buf) >= IdealBatchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	// Persist the new segments before dropping the victims
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return errLogDBClosed
+	}
+	if out != nil {
+		if err := out.seal(); err != nil {
+			return err
+		}
+	}
+	if err := db.active.file.Sync(); err != nil {
+		return err
+	}
+	if err := syncDir(db.fn); err != nil {
+		return err
+	}
+	for _, victim := range victims {
+		victim.obsolete = true
+	}
+	if err := db.writeObsolete(); err != nil {
+		for _, victim := range victims {
+			victim.obsolete = false
+		}
+		return err
+	}
+	db.cleanup()
+	db.shrinkIndex()
+
+	db.log.Info("Compacted log database", "segments", len(victims), "reclaimed", common.StorageSize(garbage), "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
+
+// Close flushes any pending data and closes all the segment files.
+func (db *LogDatabase) Close() {
+	db.lock.Lock()
+	if db.closed {
+		db.lock.Unlock()
+		return
+	}
+	db.closed = true
+	close(db.quit)
+	db.lock.Unlock()
+
+	// Wait for any running compaction to abort
+	db.compactLock.Lock()
+	defer db.compactLock.Unlock()
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var failure error
+	if err := db.active.file.Sync(); err != nil {
+		failure = err
+	}
+	for _, seg := range db.segments {
+		if err := seg.close(); err != nil && failure == nil {
+			failure = err
+		}
+	}
+	if err := db.flock.Release(); err != nil && failure == nil {
+		failure = err
+	}
+	if failure == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", failure)
+	}
+}
+
+// load opens all the segment files in the database directory, apart from the
+// ones made obsolete by a compaction, and rebuilds the index from their hint
+// files, or by replaying them if no valid hints exist.
+// The last segment is reopened for writing if it was not sealed.
+func (db *LogDatabase) load() error {
+	if err := db.dropObsolete(); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(db.fn)
+	if err != nil {
+		return err
+	}
+	var ids []uint32
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".vlog") {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), ".vlog"), 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for i, id := range ids {
+		seg, err := openSegment(db.fn, id)
+		if err != nil {
+			return err
+		}
+		db.segments[id] = seg
+		db.nextID = id + 1
+
+		hinted, err := db.loadHints(seg)
+		if err != nil {
+			return err
+		}
+		if hinted {
+			continue
+		}
+		// No hints available, replay the segment and rebuild them
+		if err := seg.openHints(); err != nil {
+			return err
+		}
+		if err := db.replay(seg); err != nil {
+			return err
+		}
+		if i == len(ids)-1 {
+			db.active = seg
+		} else if err := seg.seal(); err != nil {
+			return err
+		}
+	}
+	// Drop all the tombstones from the index, they're only needed during replay
+	var dead [][]byte
+	it := db.index.NewIterator(nil)
+	for it.Next() {
+		if ptr := decodeLogPointer(it.Value()); ptr.length == logTombstone {
+			dead = append(dead, common.CopyBytes(it.Key()))
+			db.segments[ptr.segment].garbage += ptr.size(it.Key())
+		}
+	}
+	it.Release()
+	for _, key := range dead {
+		db.index.Delete(key)
+	}
+	db.shrinkIndex()
+
+	// Start a new segment for writing if the last one was sealed
+	if db.active == nil {
+		if db.active, err = db.createSegment(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadHints loads the entries of a sealed segment from its hint file into the
+// index. False is returned if the segment has no valid hints.
+func (db *LogDatabase) loadHints(seg *logSegment) (bool, error) {
+	blob, err := ioutil.ReadFile(seg.hintPath())
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(blob) < 4 || crc32.Checksum(blob[:len(blob)-4], logCRCTable) != binary.BigEndian.Uint32(blob[len(blob)-4:]) {
+		db.log.Warn("Discarding corrupted segment hints", "segment", seg.id)
+		return false, os.Remove(seg.hintPath())
+	}
+	stat, err := seg.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	seg.size = uint32(stat.Size())
+
+	for blob = blob[:len(blob)-4]; len(blob) > 0; {
+		var entry logEntry
+		if blob, err = decodeLogHint(blob, &entry); err != nil {
+			return false, err
+		}
+		db.replayEntry(seg, &entry, 0)
+	}
+	return true, nil
+}
+
+// replay loads the entries of a segment into the index by decoding all the
+// records in it, regenerating the segment's hints along the way. A corrupted
+// tail, left behind by a crash during a write, is truncated off.
+func (db *LogDatabase) replay(seg *logSegment) error {
+	size, err := seg.scan(func(entry *logEntry, base uint32) error {
+		db.replayEntry(seg, entry, base)
+		return seg.hint(entry, base)
+	})
+	seg.size = size
+
+	if err == errLogRecordCorrupted {
+		db.log.Warn("Truncating corrupted segment tail", "segment", seg.id, "offset", seg.size)
+		err = seg.file.Truncate(int64(seg.size))
+	}
+	return err
+}
+
+// replayEntry inserts an entry loaded from a segment into the index, unless it
+// was superseded by an already loaded entry with a higher sequence number.
+func (db *LogDatabase) replayEntry(seg *logSegment, entry *logEntry, base uint32) {
+	if entry.seq > db.seq {
+		db.seq = entry.seq
+	}
+	ptr := logPointer{seg.id, base + entry.offset, entry.length, entry.seq}
+	if entry.kind == logEntryDelete {
+		ptr.offset, ptr.length = 0, logTombstone
+	}
+	if old, ok := db.lookupRaw(entry.key); ok {
+		if old.seq > entry.seq {
+			seg.garbage += ptr.size(entry.key)
+			return
+		}
+		db.segments[old.segment].garbage += old.size(entry.key)
+	}
+	db.index.Put(entry.key, ptr.encode())
+}
+
+// write appends a set of modifications as a single record to the active segment
+// and updates the index accordingly, optionally syncing the segment to disk.
+func (db *LogDatabase) write(ops []kv, sync bool) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return errLogDBClosed
+	}
+	rec := &db.record
+	rec.reset()
+	for _, op := range ops {
+		db.seq++
+		if op.del {
+			rec.add(logEntryDelete, db.seq, op.k, nil)
+		} else {
+			rec.add(logEntryPut, db.seq, op.k, op.v)
+		}
+	}
+	if len(rec.buf) > math.MaxInt32 {
+		return errLogRecordTooLarge
+	}
+	// Seal the active segment if the record would overflow it
+	if db.active.size > 0 && uint64(db.active.size)+uint64(len(rec.buf)) > uint64(db.segmentSize) {
+		if err := db.rotate(); err != nil {
+			return err
+		}
+	}
+	base, err := db.active.write(rec)
+	if err != nil {
+		return err
+	}
+	if sync {
+		if err := db.active.file.Sync(); err != nil {
+			return err
+		}
+	}
+	for i := range rec.entries {
+		db.apply(&rec.entries[i], base)
+	}
+	return nil
+}
+
+// apply updates the index with an entry just written into the active segment,
+// preserving the previous location of the key for any live snapshots.
+func (db *LogDatabase) apply(entry *logEntry, base uint32) {
+	old, exists := db.lookup(entry.key)
+	for snap := range db.snaps {
+		snap.preserve(entry.key, old, exists)
+	}
+	if exists {
+		if seg := db.segments[old.segment]; seg != nil {
+			seg.garbage += old.size(entry.key)
+		}
+	}
+	if entry.kind == logEntryDelete {
+		if exists {
+			db.index.Delete(entry.key)
+		}
+		db.active.garbage += logPointer{length: logTombstone, seq: entry.seq}.size(entry.key)
+		return
+	}
+	db.index.Put(entry.key, logPointer{db.active.id, base + entry.offset, entry.length, entry.seq}.encode())
+}
+
+// rotate seals the active segment and starts a new one, scheduling a background
+// compaction if the garbage in the sealed segments exceeds half of their size.
+func (db *LogDatabase) rotate() error {
+	if err := db.active.seal(); err != nil {
+		return err
+	}
+	seg, err := db.createSegment()
+	if err != nil {
+		return err
+	}
+	db.active = seg
+
+	var size, garbage uint64
+	for _, seg := range db.segments {
+		if seg != db.active && !seg.obsolete {
+			size, garbage = size+uint64(seg.size), garbage+seg.garbage
+		}
+	}
+	if !db.compacting && garbage >= uint64(db.segmentSize) && 2*garbage >= size {
+		db.compacting = true
+		go func() {
+			if err := db.Compact(nil, nil); err != nil && err != errLogDBClosed {
+				db.log.Error("Log database compaction failed", "err", err)
+			}
+			db.lock.Lock()
+			db.compacting = false
+			db.lock.Unlock()
+		}()
+	}
+	return nil
+}
+
+// createSegment creates a new, empty segment file with the next available id.
+func (db *LogDatabase) createSegment() (*logSegment, error) {
+	path := filepath.Join(db.fn, fmt.Sprintf("%06d.vlog", db.nextID))
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &logSegment{id: db.nextID, dir: db.fn, file: file}
+	if err := seg.openHints(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	db.segments[seg.id] = seg
+	db.nextID++
+
+	return seg, nil
+}
+
+// lookup retrieves the location of the live value of a key from the index.
+func (db *LogDatabase) lookup(key []byte) (logPointer, bool) {
+	ptr, ok := db.lookupRaw(key)
+	if !ok || ptr.length == logTombstone {
+		return logPointer{}, false
+	}
+	return ptr, true
+}
+
+// lookupRaw retrieves an index entry, including tombstones during replay.
+func (db *LogDatabase) lookupRaw(key []byte) (logPointer, bool) {
+	blob, err := db.index.Get(key)
+	if err != nil {
+		return logPointer{}, false
+	}
+	return decodeLogPointer(blob), true
+}
+
+// read retrieves a value from the segment files. The caller must hold the lock.
+func (db *LogDatabase) read(ptr logPointer) ([]byte, error) {
+	seg := db.segments[ptr.segment]
+	if seg == nil {
+		return nil, fmt.Errorf("missing log segment %d", ptr.segment)
+	}
+	value := make([]byte, ptr.length)
+	if n, err := seg.file.ReadAt(value, int64(ptr.offset)); n < len(value) {
+		return nil, err
+	}
+	return value, nil
+}
+
+// release unpins the obsolete segments on behalf of a released iterator or
+// snapshot, deleting them if no other readers remain.
+func (db *LogDatabase) release() {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.readers--
+	db.cleanup()
+}
+
+// cleanup deletes all the obsolete segments if they're not pinned by readers,
+// dropping them from the obsolete manifest afterwards. The caller must hold the
+// write lock.
+func (db *LogDatabase) cleanup() {
+	if db.readers > 0 || db.closed {
+		return
+	}
+	var removed int
+	for id, seg := range db.segments {
+		if !seg.obsolete {
+			continue
+		}
+		if err := seg.close(); err != nil {
+			db.log.Warn("Failed to close obsolete segment", "segment", id, "err", err)
+		}
+		if err := removeSegment(db.fn, id); err != nil {
+			db.log.Warn("Failed to remove obsolete segment", "segment", id, "err", err)
+			continue
+		}
+		delete(db.segments, id)
+		removed++
+	}
+	if removed == 0 {
+		return
+	}
+	if err := db.writeObsolete(); err != nil {
+		db.log.Warn("Failed to update obsolete segments", "err", err)
+	}
+}
+
+// writeObsolete atomically replaces the manifest of obsolete segments with the
+// ones currently marked as such, deleting it if there are none. The caller must
+// hold the write lock (or have exclusive access to the database).
+func (db *LogDatabase) writeObsolete() error {
+	var ids []uint32
+	for id, seg := range db.segments {
+		if seg.obsolete {
+			ids = append(ids, id)
+		}
+	}
+	path := filepath.Join(db.fn, logObsoleteFile)
+	if len(ids) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return syncDir(db.fn)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var blob bytes.Buffer
+	for _, id := range ids {
+		fmt.Fprintf(&blob, "%d\n", id)
+	}
+	file, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(blob.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	return syncDir(db.fn)
+}
+
+// dropObsolete deletes the segments listed in the obsolete manifest, left behind
+// by a crash before the cleanup after a compaction finished, then the manifest.
+func (db *LogDatabase) dropObsolete() error {
+	path := filepath.Join(db.fn, logObsoleteFile)
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Fields(string(blob)) {
+		id, err := strconv.ParseUint(line, 10, 32)
+		if err != nil {
+			return fmt.Errorf("corrupted obsolete segment manifest: %v", err)
+		}
+		db.log.Info("Removing obsolete segment", "segment", id)
+		if err := removeSegment(db.fn, uint32(id)); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(db.fn); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return syncDir(db.fn)
+}
+
+// shrinkIndex rebuilds the index if most of its buffer is occupied by stale
+// entries, since the underlying skip list never reclaims overwritten data. The
+// caller must hold the write lock (or have exclusive access to the database).
+func (db *LogDatabase) shrinkIndex() {
+	used := db.index.Capacity() - db.index.Free()
+	if used <= 2*db.index.Size()+db.cacheSize {
+		return
+	}
+	index := memdb.New(comparer.DefaultComparer, db.index.Size()+db.cacheSize)
+
+	it := db.index.NewIterator(nil)
+	for it.Next() {
+		index.Put(it.Key(), it.Value())
+	}
+	it.Release()
+
+	db.index = index
+}
+
+// removeSegment deletes the files of a segment, the hints first so a failure
+// never leaves hints behind for a missing segment.
+func removeSegment(dir string, id uint32) error {
+	seg := &logSegment{id: id, dir: dir}
+	if err := os.Remove(seg.hintPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(seg.path()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// syncDir flushes the entries of a directory to disk, making file creations,
+// renames and removals in it durable. Directories can't be synced on Windows,
+// where this is a noop.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return f.Sync()
+}
+
+// logSegment is a single append-only file of checksummed records.
+type logSegment struct {
+	id       uint32   // Unique id of the segment, also defining its file name
+	dir      string   // Directory containing the segment file
+	file     *os.File // File handle of the segment
+	size     uint32   // Number of bytes written into the segment
+	garbage  uint64   // Approximate number of bytes overwritten or deleted
+	obsolete bool     // Whether the segment was compacted and awaits deletion
+
+	hints    *bufio.Writer // Buffered writer for the hints of an unsealed segment
+	hintFile *os.File      // Temporary hint file of an unsealed segment
+	hintCRC  uint32        // Running checksum of the hints written so far
+}
+
+// openSegment opens an existing segment file for reading and writing.
+func openSegment(dir string, id uint32) (*logSegment, error) {
+	seg := &logSegment{id: id, dir: dir}
+
+	file, err := os.OpenFile(seg.path(), os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seg.file = file
+	return seg, nil
+}
+
+// path returns the location of the segment file.
+func (seg *logSegment) path() string {
+	return filepath.Join(seg.dir, fmt.Sprintf("%06d.vlog", seg.id))
+}
+
+// hintPath returns the location of the segment's hint file.
+func (seg *logSegment) hintPath() string {
+	return filepath.Join(seg.dir, fmt.Sprintf("%06d.hint", seg.id))
+}
+
+// openHints creates a temporary hint file, which will be populated as entries
+// are appended to the segment and moved into place when the segment is sealed.
+func (seg *logSegment) openHints() error {
+	file, err := os.OpenFile(seg.hintPath()+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	seg.hintFile, seg.hints, seg.hintCRC = file, bufio.NewWriterSize(file, 64*1024), 0
+	return nil
+}
+
+// hint appends the hint of an entry stored in the segment to the hint file.
+func (seg *logSegment) hint(entry *logEntry, base uint32) error {
+	blob := encodeLogHint(nil, entry, base)
+	seg.hintCRC = crc32.Update(seg.hintCRC, logCRCTable, blob)
+
+	_, err := seg.hints.Write(blob)
+	return err
+}
+
+// write appends a record to the end of the segment, returning the offset at
+// which it was written.
+func (seg *logSegment) write(rec *logRecord) (uint32, error) {
+	base := seg.size
+	if _, err := seg.file.WriteAt(rec.finish(), int64(base)); err != nil {
+		return 0, err
+	}
+	seg.size += uint32(len(rec.buf))
+
+	for i := range rec.entries {
+		if err := seg.hint(&rec.entries[i], base); err != nil {
+			return 0, err
+		}
+	}
+	return base, nil
+}
+
+// seal flushes the segment and its hints to disk, after which the segment is
+// immutable.
+func (seg *logSegment) seal() error {
+	if err := seg.hints.Flush(); err != nil {
+		return err
+	}
+	var trailer [4]byte
+	binary.BigEndian.PutUint32(trailer[:], seg.hintCRC)
+	if _, err := seg.hintFile.Write(trailer[:]); err != nil {
+		return err
+	}
+	if err := seg.hintFile.Sync(); err != nil {
+		return err
+	}
+	if err := seg.hintFile.Close(); err != nil {
+		return err
+	}
+	seg.hintFile, seg.hints = nil, nil
+
+	if err := seg.file.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(seg.hintPath()+".tmp", seg.hintPath())
+}
+
+// scan iterates over all the entries of the segment, invoking the callback
+// with each of them and the offset of the record containing it. The returned
+// size is the offset up to which the records were successfully decoded, which
+// is short of the file size if a corrupted record was found.
+func (seg *logSegment) scan(onEntry func(entry *logEntry, base uint32) error) (uint32, error) {
+	var (
+		reader = bufio.NewReaderSize(io.NewSectionReader(seg.file, 0, math.MaxInt64), 1024*1024)
+		header = make([]byte, logRecordHeaderSize)
+		offset uint32
+	)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return offset, nil
+			}
+			if err == io.ErrUnexpectedEOF {
+				return offset, errLogRecordCorrupted
+			}
+			return offset, err
+		}
+		length := binary.BigEndian.Uint32(header[4:])
+		if length > math.MaxInt32 {
+			return offset, errLogRecordCorrupted
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, errLogRecordCorrupted
+			}
+			return offset, err
+		}
+		if crc32.Checksum(payload, logCRCTable) != binary.BigEndian.Uint32(header) {
+			return offset, errLogRecordCorrupted
+		}
+		for rest := payload; len(rest) > 0; {
+			var (
+				entry logEntry
+				err   error
+			)
+			if rest, err = decodeLogEntry(rest, &entry, len(payload)-len(rest)); err != nil {
+				return offset, err
+			}
+			if err := onEntry(&entry, offset); err != nil {
+				return offset, err
+			}
+		}
+		offset += logRecordHeaderSize + length
+	}
+}
+
+// close releases the file handles of the segment.
+func (seg *logSegment) close() error {
+	if seg.hintFile != nil {
+		seg.hints.Flush()
+		seg.hintFile.Close()
+		seg.hintFile, seg.hints = nil, nil
+	}
+	return seg.file.Close()
+}
+
+// logPointer is the location of a value in the segment files, along with the
+// sequence number of the entry that wrote it.
+type logPointer struct {
+	segment uint32
+	offset  uint32
+	length  uint32
+	seq     uint64
+}
+
+// encode serializes the pointer into an index entry.
+func (p logPointer) encode() []byte {
+	blob := make([]byte, logPointerSize)
+	binary.BigEndian.PutUint32(blob[0:], p.segment)
+	binary.BigEndian.PutUint32(blob[4:], p.offset)
+	binary.BigEndian.PutUint32(blob[8:], p.length)
+	binary.BigEndian.PutUint64(blob[12:], p.seq)
+	return blob
+}
+
+// size calculates the number of bytes the entry occupies in its record.
+func (p logPointer) size(key []byte) uint64 {
+	size := 1 + uvarintSize(p.seq) + uvarintSize(uint64(len(key))) + uint64(len(key))
+	if p.length != logTombstone {
+		size += uvarintSize(uint64(p.length)) + uint64(p.length)
+	}
+	return size
+}
+
+// uvarintSize returns the number of bytes needed to encode a number as uvarint.
+func uvarintSize(x uint64) uint64 {
+	size := uint64(1)
+	for ; x >= 0x80; x >>= 7 {
+		size++
+	}
+	return size
+}
+
+// decodeLogPointer deserializes an index entry into a pointer.
+func decodeLogPointer(blob []byte) logPointer {
+	return logPointer{
+		segment: binary.BigEndian.Uint32(blob[0:]),
+		offset:  binary.BigEndian.Uint32(blob[4:]),
+		length:  binary.BigEndian.Uint32(blob[8:]),
+		seq:     binary.BigEndian.Uint64(blob[12:]),
+	}
+}
+
+// logEntry is a single modification stored in a segment record.
+type logEntry struct {
+	kind   byte   // Type of the modification (put or delete)
+	seq    uint64 // Sequence number of the modification
+	key    []byte // Key being modified
+	value  []byte // Value being inserted, only set when decoding records
+	offset uint32 // Offset of the value relative to the start of the record
+	length uint32 // Length of the value
+}
+
+// logRecord accumulates entries to be written into a segment as a single
+// checksummed record.
+type logRecord struct {
+	buf     []byte     // Record header followed by the encoded entries
+	entries []logEntry // Metadata of the encoded entries for the index and hints
+}
+
+// reset clears the record for reuse.
+func (rec *logRecord) reset() {
+	if rec.buf == nil {
+		rec.buf = make([]byte, logRecordHeaderSize, IdealBatchSize)
+	}
+	rec.buf = rec.buf[:logRecordHeaderSize]
+	rec.entries = rec.entries[:0]
+}
+
+// add encodes an entry into the record.
+func (rec *logRecord) add(kind byte, seq uint64, key []byte, value []byte) {
+	var scratch [binary.MaxVarintLen64]byte
+
+	rec.buf = append(rec.buf, kind)
+	rec.buf = append(rec.buf, scratch[:binary.PutUvarint(scratch[:], seq)]...)
+	rec.buf = append(rec.buf, scratch[:binary.PutUvarint(scratch[:], uint64(len(key)))]...)
+	rec.buf = append(rec.buf, key...)
+
+	entry := logEntry{kind: kind, seq: seq, key: key}
+	if kind == logEntryPut {
+		rec.buf = append(rec.buf, scratch[:binary.PutUvarint(scratch[:], uint64(len(value)))]...)
+		entry.offset, entry.length = uint32(len(rec.buf)), uint32(len(value))
+		rec.buf = append(rec.buf, value...)
+	}
+	rec.entries = append(rec.entries, entry)
+}
+
+// finish fills in the record header and returns the encoded record.
+func (rec *logRecord) finish() []byte {
+	payload := rec.buf[logRecordHeaderSize:]
+	binary.BigEndian.PutUint32(rec.buf[0:], crc32.Checksum(payload, logCRCTable))
+	binary.BigEndian.PutUint32(rec.buf[4:], uint32(len(payload)))
+	return rec.buf
+}
+
+// decodeLogEntry decodes the first entry of a record payload, returning the
+// remainder. The position is the offset of the entry within the payload, used
+// to compute the value offset relative to the start of the record.
+func decodeLogEntry(blob []byte, entry *logEntry, pos int) ([]byte, error) {
+	if len(blob) == 0 {
+		return nil, errLogRecordCorrupted
+	}
+	start := len(blob)
+
+	entry.kind, blob = blob[0], blob[1:]
+	seq, n := binary.Uvarint(blob)
+	if n <= 0 {
+		return nil, errLogRecordCorrupted
+	}
+	entry.seq, blob = seq, blob[n:]
+
+	size, n := binary.Uvarint(blob)
+	if n <= 0 || uint64(len(blob)-n) < size {
+		return nil, errLogRecordCorrupted
+	}
+	entry.key, blob = blob[n:n+int(size)], blob[n+int(size):]
+
+	switch entry.kind {
+	case logEntryDelete:
+		entry.value, entry.offset, entry.length = nil, 0, 0
+
+	case logEntryPut:
+		size, n := binary.Uvarint(blob)
+		if n <= 0 || uint64(len(blob)-n) < size {
+			return nil, errLogRecordCorrupted
+		}
+		entry.offset = uint32(logRecordHeaderSize + pos + start - len(blob) + n)
+		entry.length = uint32(size)
+		entry.value, blob = blob[n:n+int(size)], blob[n+int(size):]
+
+	default:
+		return nil, errLogRecordCorrupted
+	}
+	return blob, nil
+}
+
+// encodeLogHint appends the hint of an entry to a buffer. Contrary to records,
+// hints store the absolute offset of the values within the segment.
+func encodeLogHint(buf []byte, entry *logEntry, base uint32) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+
+	buf = append(buf, entry.kind)
+	buf = append(buf, scratch[:binary.PutUvarint(scratch[:], entry.seq)]...)
+	buf = append(buf, scratch[:binary.PutUvarint(scratch[:], uint64(len(entry.key)))]...)
+	buf = append(buf, entry.key...)
+	if entry.kind == logEntryPut {
+		buf = append(buf, scratch[:binary.PutUvarint(scratch[:], uint64(base+entry.offset))]...)
+		buf = append(buf, scratch[:binary.PutUvarint(scratch[:], uint64(entry.length))]...)
+	}
+	return buf
+}
+
+// decodeLogHint decodes the first hint of a hint file, returning the remainder.
+func decodeLogHint(blob []byte, entry *logEntry) ([]byte, error) {
+	if len(blob) == 0 {
+		return nil, errLogRecordCorrupted
+	}
+	entry.kind, blob = blob[0], blob[1:]
+
+	seq, n := binary.Uvarint(blob)
+	if n <= 0 {
+		return nil, errLogRecordCorrupted
+	}
+	entry.seq, blob = seq, blob[n:]
+
+	size, n := binary.Uvarint(blob)
+	if n <= 0 || uint64(len(blob)-n) < size {
+		return nil, errLogRecordCorrupted
+	}
+	entry.key, blob = blob[n:n+int(size)], blob[n+int(size):]
+
+	switch entry.kind {
+	case logEntryDelete:
+		entry.offset, entry.length = 0, 0
+
+	case logEntryPut:
+		offset, n := binary.Uvarint(blob)
+		if n <= 0 {
+			return nil, errLogRecordCorrupted
+		}
+		blob = blob[n:]
+
+		length, n := binary.Uvarint(blob)
+		if n <= 0 {
+			return nil, errLogRecordCorrupted
+		}
+		blob = blob[n:]
+
+		entry.offset, entry.length = uint32(offset), uint32(length)
+
+	default:
+		return nil, errLogRecordCorrupted
+	}
+	return blob, nil
+}
+
+// logBatch is a write-only batch that appends its modifications to the log as
+// a single record when written.
+type logBatch struct {
+	db     *LogDatabase
+	writes []kv
+	size   int
+}
+
+func (b *logBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, kv{common.CopyBytes(key), common.CopyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+func (b *logBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, kv{common.CopyBytes(key), nil, true})
+	b.size += 1
+	return nil
+}
+
+func (b *logBatch) Write() error {
+	if len(b.writes) == 0 {
+		return nil
+	}
+	return b.db.write(b.writes, true)
+}
+
+func (b *logBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *logBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// logIterator iterates over an implicit snapshot of a log database, merging the
+// live index with the locations preserved by the snapshot for keys modified
+// after its creation. Values are lazily retrieved from the segments.
+type logIterator struct {
+	snap *logSnapshot
+	live iterator.Iterator // Iterator over the live index of the database
+	over iterator.Iterator // Iterator over the overlay of the snapshot
+
+	last []byte     // Last key visited, used to reposition the iterators
+	key  []byte     // Key of the current key/value pair
+	ptr  logPointer // Location of the value of the current key/value pair
+	done bool       // Whether the iteration was exhausted
+
+	value []byte
+	err   error
+}
+
+// Next moves the iterator to the next key/value pair.
+//
+// As concurrent writes may insert entries into the overlay anywhere after the
+// current position, both iterators are repositioned from the last visited key
+// on every step instead of being advanced independently.
+func (it *logIterator) Next() bool {
+	if it.live == nil || it.err != nil || it.done {
+		return false
+	}
+	it.snap.db.lock.RLock()
+	defer it.snap.db.lock.RUnlock()
+
+	it.key, it.value = nil, nil
+	for {
+		liveOK, overOK := it.seek(it.live), it.seek(it.over)
+		if !liveOK && !overOK {
+			it.done = true
+			return false
+		}
+		var key, blob []byte
+		if overOK && (!liveOK || bytes.Compare(it.over.Key(), it.live.Key()) <= 0) {
+			key, blob = it.over.Key(), it.over.Value()
+		} else {
+			key, blob = it.live.Key(), it.live.Value()
+		}
+		it.last = common.CopyBytes(key)
+
+		// Skip keys inserted after the snapshot was taken
+		if len(blob) == 0 {
+			continue
+		}
+		it.key, it.ptr = it.last, decodeLogPointer(blob)
+		return true
+	}
+}
+
+// seek positions an iterator at the first entry after the last visited key,
+// returning whether such an entry exists.
+func (it *logIterator) seek(iter iterator.Iterator) bool {
+	if it.last == nil {
+		return iter.First()
+	}
+	if !iter.Seek(it.last) {
+		return false
+	}
+	if bytes.Equal(iter.Key(), it.last) {
+		return iter.Next()
+	}
+	return true
+}
+
+// Error returns any accumulated error.
+func (it *logIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	if it.live != nil {
+		if err := it.live.Error(); err != nil {
+			return err
+		}
+		return it.over.Error()
+	}
+	return nil
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *logIterator) Key() []byte {
+	if it.err != nil {
+		return nil
+	}
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// value is read from disk on first access.
+func (it *logIterator) Value() []byte {
+	if it.key == nil || it.err != nil || it.value != nil {
+		return it.value
+	}
+	it.snap.db.lock.RLock()
+	it.value, it.err = it.snap.db.read(it.ptr)
+	it.snap.db.lock.RUnlock()
+
+	return it.value
+}
+
+// Release releases the index iterators and the snapshot backing them.
+func (it *logIterator) Release() {
+	if it.live != nil {
+		it.live.Release()
+		it.over.Release()
+		it.live, it.over = nil, nil
+		it.key, it.value = nil, nil
+		it.snap.Release()
+	}
+}
+
+// logSnapshot is a frozen view of a log database. Rather than copying the index,
+// the database records into the snapshot's ordered overlay the previous location
+// of any key modified after the snapshot was taken (empty if the key was missing).
+type logSnapshot struct {
+	db       *LogDatabase
+	overlay  *memdb.DB
+	released bool
+}
+
+// preserve records the pre-modification location of a key, if it's the first
+// modification since the snapshot was created. The caller must hold the write
+// lock of the database.
+func (snap *logSnapshot) preserve(key []byte, old logPointer, exists bool) {
+	if snap.overlay.Contains(key) {
+		return
+	}
+	if !exists {
+		snap.overlay.Put(key, nil)
+		return
+	}
+	snap.overlay.Put(key, old.encode())
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *logSnapshot) Has(key []byte) (bool, error) {
+	snap.db.lock.RLock()
+	defer snap.db.lock.RUnlock()
+
+	if snap.released {
+		return false, errors.New("snapshot released")
+	}
+	if blob, err := snap.overlay.Get(key); err == nil {
+		return len(blob) > 0, nil
+	}
+	_, ok := snap.db.lookup(key)
+	return ok, nil
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *logSnapshot) Get(key []byte) ([]byte, error) {
+	snap.db.lock.RLock()
+	defer snap.db.lock.RUnlock()
+
+	if snap.released {
+		return nil, errors.New("snapshot released")
+	}
+	if blob, err := snap.overlay.Get(key); err == nil {
+		if len(blob) == 0 {
+			return nil, errLogDBNotFound
+		}
+		return snap.db.read(decodeLogPointer(blob))
+	}
+	ptr, ok := snap.db.lookup(key)
+	if !ok {
+		return nil, errLogDBNotFound
+	}
+	return snap.db.read(ptr)
+}
+
+// Release stops tracking modifications for the snapshot and unpins the segments.
+func (snap *logSnapshot) Release() {
+	snap.db.lock.Lock()
+	defer snap.db.lock.Unlock()
+
+	if snap.released {
+		return
+	}
+	snap.released, snap.overlay = true, nil
+	delete(snap.db.snaps, snap)
+
+	snap.db.readers--
+	snap.db.cleanup()
+}
@@ -0,0 +1,381 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// openTestLogDB opens a log database in the given directory, with a tiny segment
+// size to exercise rotation and compaction.
+func openTestLogDB(t *testing.T, dir string) *LogDatabase {
+	db, err := NewLogDatabase(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to open log database: %v", err)
+	}
+	db.segmentSize = 4096
+	return db
+}
+
+// checkLogDB verifies that the database contains exactly the given content.
+func checkLogDB(t *testing.T, db Database, want map[string]string) {
+	t.Helper()
+
+	for key, val := range want {
+		have, err := db.Get([]byte(key))
+		if err != nil {
+			t.Fatalf("key %q: failed to retrieve: %v", key, err)
+		}
+		if string(have) != val {
+			t.Fatalf("key %q: value mismatch: have %q, want %q", key, have, val)
+		}
+	}
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		if _, ok := want[string(it.Key())]; !ok {
+			t.Fatalf("unexpected key %q in database", it.Key())
+		}
+		count++
+	}
+	if count != len(want) {
+		t.Fatalf("key count mismatch: have %d, want %d", count, len(want))
+	}
+}
+
+// Tests that the content of a log database survives restarts, both from sealed
+// segments (loaded via hints) and from the active one (replayed).
+func TestLogDatabaseReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := openTestLogDB(t, dir)
+	want := make(map[string]string)
+	for i := 0; i < 500; i++ {
+		key, val := fmt.Sprintf("key-%03d", i), fmt.Sprintf("value-%d", i)
+		db.Put([]byte(key), []byte(val))
+		want[key] = val
+	}
+	// Overwrite and delete a few keys spanning multiple segments
+	batch := db.NewBatch()
+	for i := 0; i < 500; i += 7 {
+		key := fmt.Sprintf("key-%03d", i)
+		if i%2 == 0 {
+			batch.Delete([]byte(key))
+			delete(want, key)
+		} else {
+			batch.Put([]byte(key), []byte("updated"))
+			want[key] = "updated"
+		}
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatalf("failed to write batch: %v", err)
+	}
+	if len(db.segments) < 3 {
+		t.Fatalf("segments not rotated: have %d", len(db.segments))
+	}
+	checkLogDB(t, db, want)
+	db.Close()
+
+	// Reopen the database and ensure everything's loaded back
+	db = openTestLogDB(t, dir)
+	checkLogDB(t, db, want)
+
+	// Write some more and ensure the sequence numbers are continued
+	db.Put([]byte("key-001"), []byte("reopened"))
+	want["key-001"] = "reopened"
+	db.Close()
+
+	db = openTestLogDB(t, dir)
+	defer db.Close()
+	checkLogDB(t, db, want)
+}
+
+// Tests that iterators operate on a snapshot of the database, unaffected by any
+// modification made while iterating.
+func TestLogDatabaseIteratorSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := openTestLogDB(t, dir)
+	defer db.Close()
+
+	for i := 0; i < 200; i += 2 {
+		db.Put([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%d", i)))
+	}
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	for i := 0; i < 200; i += 2 {
+		if !it.Next() {
+			t.Fatalf("iterator exhausted at key %d", i)
+		}
+		if key := fmt.Sprintf("key-%03d", i); string(it.Key()) != key {
+			t.Fatalf("key mismatch: have %q, want %q", it.Key(), key)
+		}
+		if val := fmt.Sprintf("value-%d", i); string(it.Value()) != val {
+			t.Fatalf("key %d: value mismatch: have %q, want %q", i, it.Value(), val)
+		}
+		// Modify the keys ahead of the iterator: delete, overwrite and insert
+		batch := db.NewBatch()
+		batch.Delete([]byte(fmt.Sprintf("key-%03d", i+2)))
+		batch.Put([]byte(fmt.Sprintf("key-%03d", i+4)), []byte("updated"))
+		batch.Put([]byte(fmt.Sprintf("key-%03d", i+1)), []byte("inserted"))
+		if err := batch.Write(); err != nil {
+			t.Fatalf("failed to write batch: %v", err)
+		}
+	}
+	if it.Next() {
+		t.Fatalf("unexpected key %q after end of snapshot", it.Key())
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+}
+
+// Tests that a partially written record at the end of the active segment (e.g.
+// crash during write) is discarded on startup.
+func TestLogDatabaseTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := openTestLogDB(t, dir)
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+	path, size := db.active.path(), db.active.size
+	db.Close()
+
+	// Chop off the tail of the last record
+	if err := os.Truncate(path, int64(size)-1); err != nil {
+		t.Fatal(err)
+	}
+	db = openTestLogDB(t, dir)
+	checkLogDB(t, db, map[string]string{"a": "1"})
+
+	// Ensure new writes are appended after the last valid record
+	db.Put([]byte("c"), []byte("3"))
+	db.Close()
+
+	db = openTestLogDB(t, dir)
+	defer db.Close()
+	checkLogDB(t, db, map[string]string{"a": "1", "c": "3"})
+}
+
+// Tests that compaction discards the stale data from the sealed segments,
+// retaining the live entries and keeping live snapshots intact.
+func TestLogDatabaseCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := openTestLogDB(t, dir)
+	db.compacting = true // Disable background compactions
+
+	// Write a set of keys, overwriting them multiple times, and delete some
+	want := make(map[string]string)
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 100; i++ {
+			key, val := fmt.Sprintf("key-%03d", i), fmt.Sprintf("value-%d-%d", i, round)
+			db.Put([]byte(key), []byte(val))
+			want[key] = val
+		}
+	}
+	for i := 0; i < 100; i += 3 {
+		key := fmt.Sprintf("key-%03d", i)
+		db.Delete([]byte(key))
+		delete(want, key)
+	}
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	db.Put([]byte("key-001"), []byte("after-snapshot"))
+
+	before, _ := filepath.Glob(filepath.Join(dir, "*.vlog"))
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatalf("failed to compact database: %v", err)
+	}
+	// The snapshot pins the compacted segments, ensure it's still readable
+	if have, err := snap.Get([]byte("key-001")); err != nil || string(have) != want["key-001"] {
+		t.Fatalf("snapshot value mismatch: have %q (err %v), want %q", have, err, want["key-001"])
+	}
+	snap.Release()
+
+	after, _ := filepath.Glob(filepath.Join(dir, "*.vlog"))
+	if len(after) >= len(before) {
+		t.Fatalf("compaction didn't shrink the segment count: before %d, after %d", len(before), len(after))
+	}
+	want["key-001"] = "after-snapshot"
+	checkLogDB(t, db, want)
+
+	// Compacting again should be a noop as there's no garbage left in sealed segments
+	for _, seg := range db.segments {
+		if seg != db.active && seg.garbage > 0 {
+			t.Fatalf("sealed segment %d has garbage after compaction: %d", seg.id, seg.garbage)
+		}
+	}
+	db.Close()
+
+	// Ensure the compacted database is loaded correctly
+	db = openTestLogDB(t, dir)
+	defer db.Close()
+	checkLogDB(t, db, want)
+}
+
+// Tests that deleted keys stay deleted if the process crashes while the segments
+// made obsolete by a compaction are being removed, in whichever order.
+func TestLogDatabasePartialCleanup(t *testing.T) {
+	for _, ascending := range []bool{true, false} {
+		testLogDatabasePartialCleanup(t, ascending)
+	}
+}
+
+func testLogDatabasePartialCleanup(t *testing.T, ascending bool) {
+	dir, err := ioutil.TempDir("", "logdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := openTestLogDB(t, dir)
+	db.compacting = true // Disable background compactions
+
+	// Write a set of keys with plenty of garbage and compact them into segments
+	// with higher ids than the active one
+	want := make(map[string]string)
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 100; i++ {
+			key, val := fmt.Sprintf("key-%03d", i), fmt.Sprintf("value-%d-%d", i, round)
+			db.Put([]byte(key), []byte(val))
+			want[key] = val
+		}
+	}
+	active := db.active.id
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatalf("failed to compact database: %v", err)
+	}
+	// Delete a few keys from the active segment, which now has a lower id than
+	// the compacted puts it shadows, and seal it
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key-%03d", i)
+		db.Delete([]byte(key))
+		delete(want, key)
+	}
+	if db.active.id != active {
+		t.Fatalf("active segment rotated: have %d, want %d", db.active.id, active)
+	}
+	db.rotate()
+
+	// Compact again while a snapshot pins the victims, then crash before removing them
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatalf("failed to compact database: %v", err)
+	}
+	var obsolete []*logSegment
+	for _, seg := range db.segments {
+		if seg.obsolete {
+			obsolete = append(obsolete, seg)
+		}
+	}
+	if len(obsolete) < 2 {
+		t.Fatalf("too few obsolete segments: have %d, want at least 2", len(obsolete))
+	}
+	sort.Slice(obsolete, func(i, j int) bool { return (obsolete[i].id < obsolete[j].id) == ascending })
+	db.Close()
+	snap.Release()
+
+	// Remove a single obsolete segment, as if the cleanup crashed after it
+	os.Remove(obsolete[0].hintPath())
+	if err := os.Remove(obsolete[0].path()); err != nil {
+		t.Fatalf("failed to remove segment %d: %v", obsolete[0].id, err)
+	}
+	db = openTestLogDB(t, dir)
+	checkLogDB(t, db, want)
+	db.Close()
+
+	// Ensure the remaining obsolete segments were dropped on startup
+	for _, seg := range obsolete[1:] {
+		if _, err := os.Stat(seg.path()); !os.IsNotExist(err) {
+			t.Errorf("obsolete segment %d not removed: %v", seg.id, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, logObsoleteFile)); !os.IsNotExist(err) {
+		t.Errorf("obsolete segment manifest not removed: %v", err)
+	}
+}
+
+// Tests that databases are opened with the engine they were created with, and
+// that conflicting engine requests are rejected.
+func TestPreexistingEngine(t *testing.T) {
+	for _, engine := range []string{LevelDBEngine, LogDBEngine} {
+		dir, err := ioutil.TempDir("", "engine")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		if have := PreexistingEngine(dir); have != "" {
+			t.Fatalf("empty directory detected as %q", have)
+		}
+		db, err := NewDiskDatabase(engine, dir, 0, 0)
+		if err != nil {
+			t.Fatalf("failed to create %s database: %v", engine, err)
+		}
+		db.Put([]byte("key"), []byte("value"))
+		db.Close()
+
+		if have := PreexistingEngine(dir); have != engine {
+			t.Fatalf("engine mismatch: have %q, want %q", have, engine)
+		}
+		other := LevelDBEngine
+		if engine == LevelDBEngine {
+			other = LogDBEngine
+		}
+		if _, err := NewDiskDatabase(other, dir, 0, 0); err == nil {
+			t.Fatalf("%s database opened as %s", engine, other)
+		}
+		db, err = NewDiskDatabase("", dir, 0, 0)
+		if err != nil {
+			t.Fatalf("failed to reopen %s database: %v", engine, err)
+		}
+		if val, err := db.Get([]byte("key")); err != nil || string(val) != "value" {
+			t.Fatalf("value mismatch: have %q (err %v), want %q", val, err, "value")
+		}
+		db.Close()
+	}
+}
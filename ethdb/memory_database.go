@@ -17,10 +17,14 @@
 package ethdb
 
 import (
+	"bytes"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/vsportchain/go-vsc/common"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 /*
@@ -80,6 +84,96 @@ func (db *MemDatabase) Keys() [][]byte {
 	return keys
 }
 
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key (or
+// after, if it does not exist). The iterator operates on a copy of the database
+// content taken at creation time.
+func (db *MemDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		pr     = string(prefix)
+		st     = string(append(common.CopyBytes(prefix), start...))
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	// Collect the keys from the memory database corresponding to the given prefix
+	// and start
+	for key := range db.db {
+		if strings.HasPrefix(key, pr) && key >= st {
+			keys = append(keys, key)
+		}
+	}
+	// Sort the items and retrieve the associated values
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, common.CopyBytes(db.db[key]))
+	}
+	return iterator.NewArrayIterator(&memIterationSet{keys: keys, values: values})
+}
+
+// NewSnapshot creates a database snapshot based on the current state.
+func (db *MemDatabase) NewSnapshot() (Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	copied := make(map[string][]byte, len(db.db))
+	for key, val := range db.db {
+		copied[key] = common.CopyBytes(val)
+	}
+	return &memSnapshot{db: copied}, nil
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *MemDatabase) Stat(property string) (string, error) {
+	return "", errors.New("unknown property")
+}
+
+// Compact is not supported on a memory database, but there's no need either as
+// a memory database doesn't waste space anyway.
+func (db *MemDatabase) Compact(start []byte, limit []byte) error {
+	return nil
+}
+
+// memIterationSet is a sorted, immutable copy of a memory database's content
+// which implements iterator.Array.
+type memIterationSet struct {
+	keys   []string
+	values [][]byte
+}
+
+func (s *memIterationSet) Len() int { return len(s.keys) }
+
+func (s *memIterationSet) Search(key []byte) int {
+	return sort.Search(len(s.keys), func(i int) bool {
+		return bytes.Compare([]byte(s.keys[i]), key) >= 0
+	})
+}
+
+func (s *memIterationSet) Index(i int) ([]byte, []byte) {
+	return []byte(s.keys[i]), s.values[i]
+}
+
+// memSnapshot is a frozen copy of a memory database's content.
+type memSnapshot struct {
+	db map[string][]byte
+}
+
+func (snap *memSnapshot) Has(key []byte) (bool, error) {
+	_, ok := snap.db[string(key)]
+	return ok, nil
+}
+
+func (snap *memSnapshot) Get(key []byte) ([]byte, error) {
+	if entry, ok := snap.db[string(key)]; ok {
+		return common.CopyBytes(entry), nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (snap *memSnapshot) Release() {}
+
 func (db *MemDatabase) Delete(key []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -96,7 +190,10 @@ func (db *MemDatabase) NewBatch() Batch {
 
 func (db *MemDatabase) Len() int { return len(db.db) }
 
-type kv struct{ k, v []byte }
+type kv struct {
+	k, v []byte
+	del  bool
+}
 
 type memBatch struct {
 	db     *MemDatabase
@@ -105,16 +202,26 @@ type memBatch struct {
 }
 
 func (b *memBatch) Put(key, value []byte) error {
-	b.writes = append(b.writes, kv{common.CopyBytes(key), common.CopyBytes(value)})
+	b.writes = append(b.writes, kv{common.CopyBytes(key), common.CopyBytes(value), false})
 	b.size += len(value)
 	return nil
 }
 
+func (b *memBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, kv{common.CopyBytes(key), nil, true})
+	b.size += 1
+	return nil
+}
+
 func (b *memBatch) Write() error {
 	b.db.lock.Lock()
 	defer b.db.lock.Unlock()
 
 	for _, kv := range b.writes {
+		if kv.del {
+			delete(b.db.db, string(kv.k))
+			continue
+		}
 		b.db.db[string(kv.k)] = kv.v
 	}
 	return nil
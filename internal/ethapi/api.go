@@ -22,11 +22,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/vsportchain/go-vsc/accounts"
+	"github.com/vsportchain/go-vsc/accounts/abi"
 	"github.com/vsportchain/go-vsc/accounts/keystore"
 	"github.com/vsportchain/go-vsc/common"
 	"github.com/vsportchain/go-vsc/common/hexutil"
@@ -34,6 +36,7 @@ import (
 	"github.com/vsportchain/go-vsc/consensus/ethash"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/rawdb"
+	"github.com/vsportchain/go-vsc/core/state"
 	"github.com/vsportchain/go-vsc/core/types"
 	"github.com/vsportchain/go-vsc/core/vm"
 	"github.com/vsportchain/go-vsc/crypto"
@@ -42,8 +45,6 @@ import (
 	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rlp"
 	"github.com/vsportchain/go-vsc/rpc"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
@@ -78,21 +79,33 @@ func (s *PublicVSportChainAPI) ProtocolVersion() hexutil.Uint {
 // - highestBlock:  block number of the highest block header this node has received from peers
 // - pulledStates:  number of state entries processed until now
 // - knownStates:   number of known state entries that still need to be pulled
+// Full nodes also report the transaction indexing progress, with the node being
+// considered syncing until it's finished:
+// - txIndexTail:            oldest block whose transactions can be looked up by hash
+// - txIndexFinishedBlocks:  number of blocks whose transactions are indexed
+// - txIndexRemainingBlocks: number of blocks whose transactions are yet to be indexed
 func (s *PublicVSportChainAPI) Syncing() (interface{}, error) {
 	progress := s.b.Downloader().Progress()
+	txProgress, txErr := s.b.TxIndexProgress()
 
-	// Return not syncing if the synchronisation already completed
-	if progress.CurrentBlock >= progress.HighestBlock {
+	// Return not syncing if the synchronisation and indexing already completed
+	if progress.CurrentBlock >= progress.HighestBlock && (txErr != nil || txProgress.Done()) {
 		return false, nil
 	}
 	// Otherwise gather the block sync stats
-	return map[string]interface{}{
+	stats := map[string]interface{}{
 		"startingBlock": hexutil.Uint64(progress.StartingBlock),
 		"currentBlock":  hexutil.Uint64(progress.CurrentBlock),
 		"highestBlock":  hexutil.Uint64(progress.HighestBlock),
 		"pulledStates":  hexutil.Uint64(progress.PulledStates),
 		"knownStates":   hexutil.Uint64(progress.KnownStates),
-	}, nil
+	}
+	if txErr == nil {
+		stats["txIndexTail"] = hexutil.Uint64(txProgress.Tail)
+		stats["txIndexFinishedBlocks"] = hexutil.Uint64(txProgress.Indexed)
+		stats["txIndexRemainingBlocks"] = hexutil.Uint64(txProgress.Remaining)
+	}
+	return stats, nil
 }
 
 // PublicTxPoolAPI offers and API for the transaction pool. It only operates on data that is non confidential.
@@ -115,23 +128,154 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 
 	// Flatten the pending transactions
 	for account, txs := range pending {
-		dump := make(map[string]*RPCTransaction)
-		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
-		}
-		content["pending"][account.Hex()] = dump
+		content["pending"][account.Hex()] = flattenPoolTransactions(txs)
 	}
 	// Flatten the queued transactions
 	for account, txs := range queue {
-		dump := make(map[string]*RPCTransaction)
-		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
-		}
-		content["queued"][account.Hex()] = dump
+		content["queued"][account.Hex()] = flattenPoolTransactions(txs)
 	}
 	return content
 }
 
+// ContentFrom returns the transactions contained within the transaction pool,
+// sent by a single account.
+func (s *PublicTxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCTransaction {
+	pending, queue := s.b.TxPoolContentFrom(addr)
+
+	return map[string]map[string]*RPCTransaction{
+		"pending": flattenPoolTransactions(pending),
+		"queued":  flattenPoolTransactions(queue),
+	}
+}
+
+const (
+	// defaultTxPoolPageSize is the number of accounts returned in a page of the
+	// transaction pool content, if not requested otherwise.
+	defaultTxPoolPageSize = 100
+
+	// maxTxPoolPageSize is the maximum number of accounts returned in a page of
+	// the transaction pool content.
+	maxTxPoolPageSize = 1000
+)
+
+// TxPoolContentPage is a page of the transaction pool content, holding the
+// transactions of a range of accounts.
+type TxPoolContentPage struct {
+	Pending map[string]map[string]*RPCTransaction `json:"pending"`
+	Queued  map[string]map[string]*RPCTransaction `json:"queued"`
+	Next    *common.Address                       `json:"next"` // First account of the next page, nil if last
+}
+
+// ContentPage returns the transactions contained within the transaction pool,
+// sent by a page of accounts starting at the given address in ascending order.
+// The returned page carries the address to continue the iteration from.
+//
+// Pages hold defaultTxPoolPageSize accounts unless a limit is requested, which
+// must be positive and is capped at maxTxPoolPageSize.
+func (s *PublicTxPoolAPI) ContentPage(start *common.Address, limit *hexutil.Uint) (*TxPoolContentPage, error) {
+	size := defaultTxPoolPageSize
+	if limit != nil {
+		switch {
+		case *limit == 0:
+			return nil, errors.New("page limit must be positive")
+		case *limit > maxTxPoolPageSize:
+			size = maxTxPoolPageSize
+		default:
+			size = int(*limit)
+		}
+	}
+	senders := s.b.TxPoolSenders()
+	if start != nil {
+		senders = senders[sort.Search(len(senders), func(i int) bool {
+			return bytes.Compare(senders[i][:], start[:]) >= 0
+		}):]
+	}
+	page := &TxPoolContentPage{
+		Pending: make(map[string]map[string]*RPCTransaction),
+		Queued:  make(map[string]map[string]*RPCTransaction),
+	}
+	if len(senders) > size {
+		page.Next = &senders[size]
+		senders = senders[:size]
+	}
+	for _, addr := range senders {
+		pending, queue := s.b.TxPoolContentFrom(addr)
+		if len(pending) > 0 {
+			page.Pending[addr.Hex()] = flattenPoolTransactions(pending)
+		}
+		if len(queue) > 0 {
+			page.Queued[addr.Hex()] = flattenPoolTransactions(queue)
+		}
+	}
+	return page, nil
+}
+
+// flattenPoolTransactions converts a batch of pool transactions of an account
+// into their RPC representation, keyed by nonce.
+func flattenPoolTransactions(txs types.Transactions) map[string]*RPCTransaction {
+	dump := make(map[string]*RPCTransaction)
+	for _, tx := range txs {
+		dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	return dump
+}
+
+// RPCTxLifecycle is a lifecycle change of a transaction in the pool, delivered
+// to the lifecycle subscribers.
+type RPCTxLifecycle struct {
+	Hash   common.Hash    `json:"hash"`
+	From   common.Address `json:"from"`
+	Nonce  hexutil.Uint64 `json:"nonce"`
+	Event  string         `json:"event"`
+	Reason string         `json:"reason,omitempty"`
+}
+
+// Lifecycle creates a subscription that is triggered each time a transaction is
+// added to, promoted in, replaced in, dropped from (with a reason) or mined out
+// of the transaction pool. The events may be restricted to a single sender.
+func (s *PublicTxPoolAPI) Lifecycle(ctx context.Context, from *common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.NewTxsEvent, 128)
+		eventSub := s.b.SubscribeTxLifecycleEvent(events)
+		defer eventSub.Unsubscribe()
+
+		signer := types.NewEIP155Signer(s.b.ChainConfig().ChainId)
+		for {
+			select {
+			case ev := <-events:
+				// Private transactions are not disclosed to the subscribers
+				if ev.Private {
+					continue
+				}
+				for _, tx := range ev.Txs {
+					sender, _ := types.Sender(signer, tx)
+					if from != nil && sender != *from {
+						continue
+					}
+					notifier.Notify(rpcSub.ID, &RPCTxLifecycle{
+						Hash:   tx.Hash(),
+						From:   sender,
+						Nonce:  hexutil.Uint64(tx.Nonce()),
+						Event:  ev.Kind.String(),
+						Reason: ev.Reason,
+					})
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // Status returns the number of pending and queued transaction in the pool.
 func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
@@ -613,17 +757,108 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+// OverrideAccount indicates the overriding fields of an account during the
+// execution of a message call.
+//
+// Note, state and stateDiff can't be specified at the same time. If state is
+// set, message execution will only use the data in the given state. Otherwise
+// if stateDiff is set, all diff will be applied first and then execute the call
+// message.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   *hexutil.Big                 `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
 
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
-	if state == nil || err != nil {
-		return nil, 0, false, err
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
+
+// overridesBalance returns whether the balance of the given account is overridden.
+func (diff *StateOverride) overridesBalance(addr common.Address) bool {
+	if diff == nil {
+		return false
 	}
+	account, ok := (*diff)[addr]
+	return ok && account.Balance != nil
+}
+
+// Apply overrides the fields of specified accounts into the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		// Override account nonce.
+		if account.Nonce != nil {
+			state.SetNonce(addr, uint64(*account.Nonce))
+		}
+		// Override account(contract) code.
+		if account.Code != nil {
+			state.SetCode(addr, *account.Code)
+		}
+		// Override account balance.
+		if account.Balance != nil {
+			state.SetBalance(addr, (*big.Int)(account.Balance))
+		}
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		// Replace entire state if caller requires.
+		if account.State != nil {
+			state.SetStorage(addr, *account.State)
+		}
+		// Apply state diff into specified accounts.
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// BlockOverrides is a set of header fields to override during the execution
+// of a message call.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number"`
+	Time     *hexutil.Big    `json:"time"`
+	GasLimit *hexutil.Uint64 `json:"gasLimit"`
+	Coinbase *common.Address `json:"coinbase"`
+}
+
+// Apply overrides the given header fields into the given block context. It needs
+// to be called before the EVM is created, since the fork rules depend on them.
+func (diff *BlockOverrides) Apply(context *vm.Context) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		context.BlockNumber = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Time != nil {
+		context.Time = new(big.Int).Set(diff.Time.ToInt())
+	}
+	if diff.GasLimit != nil {
+		context.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		context.Coinbase = *diff.Coinbase
+	}
+}
+
+// toMessage converts the call arguments into a message to execute, using the
+// first local account as the sender and a generous gas allowance and the default
+// gas price if none were set. The gas allowance is bounded by the RPC gas cap.
+//
+// If the balance of the sender is overridden, the sender isn't funded for the
+// call (see fundSender) and its gas is priced at zero unless set explicitly.
+func (args *CallArgs) toMessage(b Backend, overrides *StateOverride) types.Message {
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -634,12 +869,37 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if gas == 0 {
 		gas = math.MaxUint64 / 2
 	}
-	if gasPrice.Sign() == 0 {
+	if gasCap := b.RPCGasCap(); gasCap != 0 && gas > gasCap {
+		log.Debug("Caller gas above allowance, capping", "requested", gas, "cap", gasCap)
+		gas = gasCap
+	}
+	if gasPrice.Sign() == 0 && !overrides.overridesBalance(addr) {
 		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
 	}
+	return types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
+
+// fundSender tops up the balance of the sender of a call so it can pay for any
+// gas allowance, unless its balance is overridden and has to be used as is.
+func fundSender(state *state.StateDB, sender common.Address, overrides *StateOverride) {
+	if !overrides.overridesBalance(sender) {
+		state.SetBalance(sender, math.MaxBig256)
+	}
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, 0, false, err
+	}
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	msg := args.toMessage(s.b, overrides)
+	fundSender(state, msg.From(), overrides)
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -654,10 +914,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	defer cancel()
 
 	// Get a new instance of the EVM.
-	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg)
+	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg, blockOverrides)
 	if err != nil {
 		return nil, 0, false, err
 	}
+
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -675,16 +936,58 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	return res, gas, failed, err
 }
 
+// revertError is an API error that encompasses an EVM revert with JSON error
+// code and the binary revert data.
+type revertError struct {
+	error
+	data string // revert data hex encoded
+}
+
+// ErrorCode returns the JSON error code for a revertal.
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert data.
+func (e *revertError) ErrorData() interface{} {
+	return e.data
+}
+
+// newRevertError creates a revertError instance with the provided revert data,
+// decoding the revert reason if the data is an Error(string) payload.
+func newRevertError(res []byte) *revertError {
+	err := errors.New("execution reverted")
+	if reason, errUnpack := abi.UnpackRevert(res); errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertError{
+		error: err,
+		data:  hexutil.Encode(res),
+	}
+}
+
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
-	return (hexutil.Bytes)(result), err
+//
+// Additionally, the caller can specify a batch of accounts for fields overriding
+// and a set of header fields to run the call in a modified block context.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
+	result, _, failed, err := s.doCall(ctx, args, blockNr, overrides, blockOverrides, vm.Config{}, s.b.RPCEVMTimeout())
+	if err != nil {
+		return nil, err
+	}
+	// If the call reverted with some data, surface it as the error data. Other
+	// failures don't return any data and are reported as before.
+	if failed && len(result) > 0 {
+		return nil, newRevertError(result)
+	}
+	return (hexutil.Bytes)(result), nil
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// given transaction against the current pending block, optionally with the
+// same state and block overrides as Call.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -693,6 +996,8 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	)
 	if uint64(args.Gas) >= params.TxGas {
 		hi = uint64(args.Gas)
+	} else if blockOverrides != nil && blockOverrides.GasLimit != nil {
+		hi = uint64(*blockOverrides.GasLimit)
 	} else {
 		// Retrieve the current pending block to act as the gas ceiling
 		block, err := s.b.BlockByNumber(ctx, rpc.PendingBlockNumber)
@@ -701,13 +1006,17 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 		}
 		hi = block.GasLimit()
 	}
+	if gasCap := s.b.RPCGasCap(); gasCap != 0 && hi > gasCap {
+		log.Debug("Caller gas above allowance, capping", "requested", hi, "cap", gasCap)
+		hi = gasCap
+	}
 	cap = hi
 
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, overrides, blockOverrides, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -725,6 +1034,11 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	// Reject the transaction as invalid if it still fails at the highest allowance
 	if hi == cap {
 		if !executable(hi) {
+			// If the transaction reverted with some data, surface the reason
+			args.Gas = hexutil.Uint64(hi)
+			if result, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, overrides, blockOverrides, vm.Config{}, 0); err == nil && failed && len(result) > 0 {
+				return 0, newRevertError(result)
+			}
 			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
 		}
 	}
@@ -933,6 +1247,116 @@ func newRPCTransactionFromBlockHash(b *types.Block, hash common.Hash) *RPCTransa
 	return nil
 }
 
+// PublicBundleAPI provides an API to simulate a sequence of dependent calls.
+type PublicBundleAPI struct {
+	b Backend
+}
+
+// NewPublicBundleAPI creates a new bundle simulation API.
+func NewPublicBundleAPI(b Backend) *PublicBundleAPI {
+	return &PublicBundleAPI{b}
+}
+
+// BundleCallResult is the outcome of a single call of a simulated bundle.
+type BundleCallResult struct {
+	ReturnValue  hexutil.Bytes  `json:"returnValue"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Failed       bool           `json:"failed"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Logs         []*types.Log   `json:"logs"`
+}
+
+// CallBundle executes the given calls one after the other on top of the state
+// of the given block number, each call seeing the state changes of the previous
+// ones. The state and block overrides are applied as for eth_call, and the RPC
+// timeout spans all the calls of the bundle.
+//
+// Note, this function doesn't make any changes in the state/blockchain and is
+// useful to simulate transactions depending on each other.
+func (s *PublicBundleAPI) CallBundle(ctx context.Context, calls []CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*BundleCallResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(calls) == 0 {
+		return nil, errors.New("empty call bundle")
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	// Setup a context with a timeout spanning all the calls of the bundle and
+	// make sure it is cancelled when the bundle has completed
+	var (
+		timeout = s.b.RPCEVMTimeout()
+		cancel  context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// Fund the senders once upfront, the value moved by the calls must carry over
+	msgs := make([]types.Message, len(calls))
+	for i, args := range calls {
+		msgs[i] = args.toMessage(s.b, overrides)
+		fundSender(state, msgs[i].From(), overrides)
+	}
+	var (
+		deleteEmptyObjects = s.b.ChainConfig().IsEIP158(header.Number)
+		results            = make([]*BundleCallResult, 0, len(calls))
+	)
+	for i, msg := range msgs {
+		evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vm.Config{}, blockOverrides)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cancel the evm if the bundle times out or the call returns
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel()
+			case <-done:
+			}
+		}()
+		state.Prepare(common.Hash{}, header.Hash(), i)
+		logs := len(state.GetLogs(common.Hash{}))
+
+		res, gas, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		close(done)
+
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("call %d: execution aborted (timeout = %v)", i, timeout)
+		}
+		result := &BundleCallResult{
+			ReturnValue: res,
+			GasUsed:     hexutil.Uint64(gas),
+			Failed:      failed,
+			Logs:        state.GetLogs(common.Hash{})[logs:],
+		}
+		if failed {
+			result.RevertReason, _ = abi.UnpackRevert(res)
+		}
+		results = append(results, result)
+
+		// Finalise the call as a transaction would be, so the next one starts
+		// from a clean refund counter and without the destructed accounts
+		state.Finalise(deleteEmptyObjects)
+	}
+	return results, nil
+}
+
 // PublicTransactionPoolAPI exposes methods for the RPC interface
 type PublicTransactionPoolAPI struct {
 	b         Backend
@@ -1034,7 +1458,11 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
-func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+//
+// If withRevertReason is set and the transaction failed, it is replayed on top of
+// its parent state to attach the revert data and the decoded revert reason. If the
+// replay isn't possible, the receipt is returned without them.
+func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash, withRevertReason *bool) (map[string]interface{}, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
 	if tx == nil {
 		return nil, nil
@@ -1081,9 +1509,69 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Replay failed transactions to retrieve the revert reason if requested
+	if withRevertReason != nil && *withRevertReason && len(receipt.PostState) == 0 && receipt.Status == types.ReceiptStatusFailed {
+		// The state needed for the replay might be pruned, which shouldn't hide the
+		// receipt itself, so serve it without the revert details in that case
+		data, err := s.replayRevert(ctx, blockHash, int(index))
+		if err != nil {
+			log.Debug("Failed to replay reverted transaction", "hash", hash, "err", err)
+		}
+		if len(data) > 0 {
+			fields["revertData"] = hexutil.Bytes(data)
+			if reason, err := abi.UnpackRevert(data); err == nil {
+				fields["revertReason"] = reason
+			}
+		}
+	}
 	return fields, nil
 }
 
+// replayRevert re-executes a failed transaction on top of its parent state and
+// returns the data it reverted with, if any. The replay is bounded by the same
+// gas cap and timeout as eth_call, and aborted if the request is cancelled.
+func (s *PublicTransactionPoolAPI) replayRevert(ctx context.Context, blockHash common.Hash, index int) ([]byte, error) {
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if block == nil || err != nil {
+		return nil, fmt.Errorf("block %x not found", blockHash)
+	}
+	msg, vmctx, statedb, err := s.b.StateAtTransaction(ctx, block, index)
+	if err != nil {
+		return nil, err
+	}
+	// The transaction must be replayed with its own gas allowance to fail the
+	// same way, so refuse it instead of capping if it's above the RPC gas cap
+	if gasCap := s.b.RPCGasCap(); gasCap != 0 && msg.Gas() > gasCap {
+		return nil, fmt.Errorf("transaction gas %d above allowance %d", msg.Gas(), gasCap)
+	}
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	vmenv := vm.NewEVM(vmctx, statedb, s.b.ChainConfig(), vm.Config{})
+
+	// Wait for the context to be done and cancel the evm, aborting the replay
+	go func() {
+		<-ctx.Done()
+		vmenv.Cancel()
+	}()
+	res, _, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("replay aborted: %v", ctx.Err())
+	}
+	if !failed {
+		return nil, nil
+	}
+	return res, nil
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
@@ -1235,6 +1723,26 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	return submitTransaction(ctx, s.b, tx)
 }
 
+// SendPrivateTransaction will add the signed transaction to the transaction pool
+// without announcing it to the network, so only the local miner may include it.
+// The transaction is dropped if not included until the deadline block, which
+// defaults to the configured private lifetime past the current head.
+func (s *PublicTransactionPoolAPI) SendPrivateTransaction(ctx context.Context, encodedTx hexutil.Bytes, deadline *hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	var number uint64
+	if deadline != nil {
+		number = uint64(*deadline)
+	}
+	if err := s.b.SendPrivateTx(ctx, tx, number); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "fullhash", tx.Hash().Hex(), "recipient", tx.To(), "deadline", number)
+	return tx.Hash(), nil
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19VSportChain Signed Message:\n" + len(message) + message).
 //
@@ -1402,6 +1910,22 @@ func (api *PublicDebugAPI) SeedHash(ctx context.Context, number uint64) (string,
 	return fmt.Sprintf("0x%x", ethash.SeedHash(number)), nil
 }
 
+// TxIndexProgress retrieves the progress of the transaction indexing: the oldest
+// block whose transactions can be looked up by hash, the number of blocks with
+// indexed transactions and the number of blocks still waiting to be indexed.
+func (api *PublicDebugAPI) TxIndexProgress() (map[string]interface{}, error) {
+	progress, err := api.b.TxIndexProgress()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tail":      hexutil.Uint64(progress.Tail),
+		"indexed":   hexutil.Uint64(progress.Indexed),
+		"remaining": hexutil.Uint64(progress.Remaining),
+		"done":      progress.Done(),
+	}, nil
+}
+
 // PrivateDebugAPI is the collection of VSportChain APIs exposed over the private
 // debugging endpoint.
 type PrivateDebugAPI struct {
@@ -1414,32 +1938,21 @@ func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
 	return &PrivateDebugAPI{b: b}
 }
 
-// ChaindbProperty returns leveldb properties of the chain database.
+// ChaindbProperty returns properties of the chain database. Properties without
+// an engine prefix are looked up as leveldb properties.
 func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return "", fmt.Errorf("chaindbProperty does not work for memory databases")
-	}
 	if property == "" {
 		property = "leveldb.stats"
-	} else if !strings.HasPrefix(property, "leveldb.") {
+	} else if !strings.Contains(property, ".") {
 		property = "leveldb." + property
 	}
-	return ldb.LDB().GetProperty(property)
+	return api.b.ChainDb().Stat(property)
 }
 
 func (api *PrivateDebugAPI) ChaindbCompact() error {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return fmt.Errorf("chaindbCompact does not work for memory databases")
-	}
 	for b := byte(0); b < 255; b++ {
 		log.Info("Compacting chain database", "range", fmt.Sprintf("0x%0.2X-0x%0.2X", b, b+1))
-		err := ldb.LDB().CompactRange(util.Range{Start: []byte{b}, Limit: []byte{b + 1}})
+		err := api.b.ChainDb().Compact([]byte{b}, []byte{b + 1})
 		if err != nil {
 			log.Error("Database compaction failed", "err", err)
 			return err
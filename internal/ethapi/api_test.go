@@ -0,0 +1,269 @@
+// Copyright 2018 The go-vsc Authors
+// This file is part of the go-vsc library.
+//
+// The go-vsc library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vsc library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vsc library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vsportchain/go-vsc/common"
+	"github.com/vsportchain/go-vsc/common/hexutil"
+	"github.com/vsportchain/go-vsc/common/math"
+	"github.com/vsportchain/go-vsc/consensus/ethash"
+	"github.com/vsportchain/go-vsc/core"
+	"github.com/vsportchain/go-vsc/core/state"
+	"github.com/vsportchain/go-vsc/core/types"
+	"github.com/vsportchain/go-vsc/core/vm"
+	"github.com/vsportchain/go-vsc/ethdb"
+	"github.com/vsportchain/go-vsc/params"
+	"github.com/vsportchain/go-vsc/rpc"
+)
+
+// balanceCode is the code of a contract returning the balance of its caller.
+var balanceCode = hexutil.MustDecode("0x333160005260206000f3")
+
+// testBackend implements the parts of Backend needed to execute calls on top of
+// a chain consisting of a genesis block only. Everything else panics.
+type testBackend struct {
+	Backend
+	chain   *core.BlockChain
+	gasCap  uint64
+	timeout time.Duration
+}
+
+// newTestBackend creates a backend on top of an empty test chain.
+func newTestBackend(t *testing.T) *testBackend {
+	db := ethdb.NewMemDatabase()
+	genesis := &core.Genesis{Config: params.TestChainConfig}
+	genesis.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return &testBackend{chain: chain}
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *testBackend) RPCGasCap() uint64                { return b.gasCap }
+func (b *testBackend) RPCEVMTimeout() time.Duration     { return b.timeout }
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	statedb, err := b.chain.State()
+	return statedb, b.chain.CurrentHeader(), err
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config, overrides *BlockOverrides) (*vm.EVM, func() error, error) {
+	context := core.NewEVMContext(msg, header, b.chain, nil)
+	overrides.Apply(&context)
+	return vm.NewEVM(context, state, b.chain.Config(), vmCfg), func() error { return nil }, nil
+}
+
+// Tests that the gas allowance of calls is bounded by the RPC gas cap.
+func TestCallGasCap(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	backend.gasCap = 50000
+	sender := common.HexToAddress("0x1000")
+
+	tests := []struct {
+		gas  hexutil.Uint64
+		want uint64
+	}{
+		{0, 50000},      // default allowance capped
+		{100000, 50000}, // requested allowance capped
+		{30000, 30000},  // requested allowance within the cap
+	}
+	for i, tt := range tests {
+		args := CallArgs{From: sender, Gas: tt.gas}
+		if have := args.toMessage(backend, nil).Gas(); have != tt.want {
+			t.Errorf("test %d: gas mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that an overridden balance of the sender of a call is used as is, instead
+// of being replaced by the funding of the sender.
+func TestCallSenderBalanceOverride(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	var (
+		api      = NewPublicBlockChainAPI(backend)
+		sender   = common.HexToAddress("0x1000")
+		contract = common.HexToAddress("0xc0de")
+		code     = hexutil.Bytes(balanceCode)
+	)
+	tests := []struct {
+		balance *big.Int // Overridden balance of the sender, nil if none
+		value   int64    // Value sent along with the call
+		want    *big.Int // Balance of the sender seen by the contract, nil if funded
+	}{
+		{balance: big.NewInt(1000), value: 0, want: big.NewInt(1000)},
+		{balance: big.NewInt(1000), value: 400, want: big.NewInt(600)},
+		{balance: nil, value: 400, want: nil},
+	}
+	for i, tt := range tests {
+		overrides := StateOverride{contract: {Code: &code}}
+		if tt.balance != nil {
+			overrides[sender] = OverrideAccount{Balance: (*hexutil.Big)(tt.balance)}
+		}
+		args := CallArgs{From: sender, To: &contract, Value: hexutil.Big(*big.NewInt(tt.value))}
+
+		res, err := api.Call(context.Background(), args, rpc.LatestBlockNumber, &overrides, nil)
+		if err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		have := new(big.Int).SetBytes(res)
+		switch {
+		case tt.want == nil && have.BitLen() < 255:
+			t.Errorf("test %d: sender not funded: balance %v", i, have)
+		case tt.want != nil && have.Cmp(tt.want) != 0:
+			t.Errorf("test %d: balance mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that the calls of a bundle see the balances left by the previous ones,
+// including the value moved by them and any overridden balances.
+func TestCallBundleBalances(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	var (
+		api      = NewPublicBundleAPI(backend)
+		funded   = common.HexToAddress("0x1000")
+		sender   = common.HexToAddress("0x2000")
+		contract = common.HexToAddress("0xc0de")
+		code     = hexutil.Bytes(balanceCode)
+	)
+	overrides := StateOverride{
+		contract: {Code: &code},
+		sender:   {Balance: (*hexutil.Big)(big.NewInt(1000))},
+	}
+	var (
+		gas      = hexutil.Uint64(100000)
+		gasPrice = hexutil.Big(*big.NewInt(1))
+	)
+	calls := []CallArgs{
+		{From: funded, To: &sender, Gas: gas, GasPrice: gasPrice, Value: hexutil.Big(*big.NewInt(500))}, // 1000 + 500
+		{From: sender, To: &funded, Value: hexutil.Big(*big.NewInt(1200))},                              // 1500 - 1200
+		{From: sender, To: &contract, Value: hexutil.Big(*big.NewInt(100))},                             // 300 - 100
+		{From: funded, To: &contract, Gas: gas, GasPrice: gasPrice},                                     // funded once only
+		{From: sender, To: &contract, Value: hexutil.Big(*big.NewInt(1000))},                            // insufficient
+	}
+	results, err := api.CallBundle(context.Background(), calls[:4], rpc.LatestBlockNumber, &overrides, nil)
+	if err != nil {
+		t.Fatalf("failed to call bundle: %v", err)
+	}
+	for i, result := range results {
+		if result.Failed {
+			t.Fatalf("call %d failed", i)
+		}
+	}
+	if have := new(big.Int).SetBytes(results[2].ReturnValue); have.Cmp(big.NewInt(200)) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want %v", have, 200)
+	}
+	// The funded sender paid for the first call's gas and value, got the value of
+	// the second call and paid upfront for the gas of the fourth one
+	want := new(big.Int).Sub(math.MaxBig256, new(big.Int).SetUint64(uint64(results[0].GasUsed)))
+	want.Add(want, big.NewInt(-500+1200-int64(gas)))
+	if have := new(big.Int).SetBytes(results[3].ReturnValue); have.Cmp(want) != 0 {
+		t.Errorf("funded sender balance mismatch: have %v, want %v", have, want)
+	}
+	// Spending more than left by the previous calls must fail
+	if _, err := api.CallBundle(context.Background(), append(calls[:3:3], calls[4]), rpc.LatestBlockNumber, &overrides, nil); err == nil {
+		t.Errorf("expected error for insufficient balance")
+	}
+}
+
+// Tests that call bundles are aborted once the RPC timeout expires.
+func TestCallBundleTimeout(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	backend.timeout = 100 * time.Millisecond
+
+	var (
+		api      = NewPublicBundleAPI(backend)
+		contract = common.HexToAddress("0xc0de")
+		code     = hexutil.Bytes(hexutil.MustDecode("0x5b600056")) // JUMPDEST, PUSH1 0, JUMP
+	)
+	overrides := StateOverride{contract: {Code: &code}}
+	calls := []CallArgs{{From: common.HexToAddress("0x1000"), To: &contract}}
+
+	start := time.Now()
+	if _, err := api.CallBundle(context.Background(), calls, rpc.LatestBlockNumber, &overrides, nil); err == nil {
+		t.Fatalf("expected error for endless call")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("bundle not aborted in time: %v", elapsed)
+	}
+}
+
+// poolBackend implements the parts of Backend needed to page through the content
+// of a transaction pool with the given senders and no transactions.
+type poolBackend struct {
+	Backend
+	senders []common.Address
+}
+
+func (b *poolBackend) TxPoolSenders() []common.Address { return b.senders }
+
+func (b *poolBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return nil, nil
+}
+
+// Tests that transaction pool content pages default to a small size, clamp only
+// too large limits and reject a zero limit.
+func TestContentPageLimit(t *testing.T) {
+	backend := new(poolBackend)
+	for i := 0; i < maxTxPoolPageSize+1; i++ {
+		backend.senders = append(backend.senders, common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+	api := NewPublicTxPoolAPI(backend)
+
+	tests := []struct {
+		limit *hexutil.Uint
+		next  int
+	}{
+		{nil, defaultTxPoolPageSize},
+		{newUint(2), 2},
+		{newUint(maxTxPoolPageSize + 5000), maxTxPoolPageSize},
+	}
+	for i, tt := range tests {
+		page, err := api.ContentPage(nil, tt.limit)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve page: %v", i, err)
+		}
+		if page.Next == nil || *page.Next != backend.senders[tt.next] {
+			t.Errorf("test %d: next account mismatch: have %v, want %x", i, page.Next, backend.senders[tt.next])
+		}
+	}
+	if _, err := api.ContentPage(nil, newUint(0)); err == nil {
+		t.Errorf("expected error for zero page limit")
+	}
+}
+
+// newUint returns a pointer to the given page limit.
+func newUint(n uint) *hexutil.Uint {
+	limit := hexutil.Uint(n)
+	return &limit
+}
@@ -19,6 +19,7 @@ package web3ext
 
 var Modules = map[string]string{
 	"admin":      Admin_JS,
+	"bft":        BFT_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
 	"debug":      Debug_JS,
@@ -29,7 +30,9 @@ var Modules = map[string]string{
 	"rpc":        RPC_JS,
 	"shh":        Shh_JS,
 	"swarmfs":    SWARMFS_JS,
+	"trace":      Trace_JS,
 	"txpool":     TxPool_JS,
+	"vsc":        Vsc_JS,
 }
 
 const Chequebook_JS = `
@@ -99,6 +102,17 @@ web3._extend({
 			call: 'clique_discard',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'clique_status',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSignerStats',
+			call: 'clique_getSignerStats',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -109,6 +123,52 @@ web3._extend({
 });
 `
 
+const BFT_JS = `
+web3._extend({
+	property: 'bft',
+	methods: [
+		new web3._extend.Method({
+			name: 'getSnapshot',
+			call: 'bft_getSnapshot',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getSnapshotAtHash',
+			call: 'bft_getSnapshotAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getValidators',
+			call: 'bft_getValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorsAtHash',
+			call: 'bft_getValidatorsAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'propose',
+			call: 'bft_propose',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'discard',
+			call: 'bft_discard',
+			params: 1
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'proposals',
+			getter: 'bft_proposals'
+		}),
+	]
+});
+`
+
 const Admin_JS = `
 web3._extend({
 	property: 'admin',
@@ -216,6 +276,10 @@ web3._extend({
 			name: 'chaindbCompact',
 			call: 'debug_chaindbCompact',
 		}),
+		new web3._extend.Method({
+			name: 'txIndexProgress',
+			call: 'debug_txIndexProgress',
+		}),
 		new web3._extend.Method({
 			name: 'metrics',
 			call: 'debug_metrics',
@@ -385,6 +449,42 @@ web3._extend({
 			params: 2,
 			inputFormatter:[null, null],
 		}),
+		new web3._extend.Method({
+			name: 'startSession',
+			call: 'debug_startSession',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'step',
+			call: 'debug_step',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'continueTo',
+			call: 'debug_continueTo',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'inspectMemory',
+			call: 'debug_inspectMemory',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'inspectStack',
+			call: 'debug_inspectStack',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'inspectStorage',
+			call: 'debug_inspectStorage',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'stopSession',
+			call: 'debug_stopSession',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -418,6 +518,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivateTransaction',
+			call: 'eth_sendPrivateTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'eth_getRawTransactionByHash',
@@ -605,10 +711,53 @@ web3._extend({
 });
 `
 
+const Trace_JS = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'replayBlockTransactions',
+			call: 'trace_replayBlockTransactions',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	]
+});
+`
+
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods: [
+		new web3._extend.Method({
+			name: 'contentFrom',
+			call: 'txpool_contentFrom',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'contentPage',
+			call: 'txpool_contentPage',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -631,3 +780,23 @@ web3._extend({
 	]
 });
 `
+
+const Vsc_JS = `
+web3._extend({
+	property: 'vsc',
+	methods: [
+		new web3._extend.Method({
+			name: 'getInternalTransactions',
+			call: 'vsc_getInternalTransactions',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'callBundle',
+			call: 'vsc_callBundle',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
+	]
+});
+`
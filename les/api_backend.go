@@ -17,12 +17,16 @@
 package les
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
+	"time"
 
 	"github.com/vsportchain/go-vsc/accounts"
 	"github.com/vsportchain/go-vsc/common"
-	"github.com/vsportchain/go-vsc/common/math"
 	"github.com/vsportchain/go-vsc/core"
 	"github.com/vsportchain/go-vsc/core/bloombits"
 	"github.com/vsportchain/go-vsc/core/rawdb"
@@ -33,6 +37,7 @@ import (
 	"github.com/vsportchain/go-vsc/eth/gasprice"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/event"
+	"github.com/vsportchain/go-vsc/internal/ethapi"
 	"github.com/vsportchain/go-vsc/light"
 	"github.com/vsportchain/go-vsc/params"
 	"github.com/vsportchain/go-vsc/rpc"
@@ -102,16 +107,46 @@ func (b *LesApiBackend) GetTd(hash common.Hash) *big.Int {
 	return b.eth.blockchain.GetTdByHash(hash)
 }
 
-func (b *LesApiBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
-	state.SetBalance(msg.From(), math.MaxBig256)
+func (b *LesApiBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config, overrides *ethapi.BlockOverrides) (*vm.EVM, func() error, error) {
 	context := core.NewEVMContext(msg, header, b.eth.blockchain, nil)
+	overrides.Apply(&context)
 	return vm.NewEVM(context, state, b.eth.chainConfig, vmCfg), state.Error, nil
 }
 
+func (b *LesApiBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int) (core.Message, vm.Context, *state.StateDB, error) {
+	parent := b.eth.blockchain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, vm.Context{}, nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb := light.NewState(ctx, parent, b.eth.odr)
+
+	// Recompute transactions up to the target index
+	signer := types.MakeSigner(b.eth.chainConfig, block.Number())
+
+	for idx, tx := range block.Transactions() {
+		msg, _ := tx.AsMessage(signer)
+		context := core.NewEVMContext(msg, block.Header(), b.eth.blockchain, nil)
+		if idx == txIndex {
+			return msg, context, statedb, nil
+		}
+		// Not yet the searched for transaction, execute on top of the current state
+		vmenv := vm.NewEVM(context, statedb, b.eth.chainConfig, vm.Config{})
+		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, vm.Context{}, nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+		}
+		statedb.Finalise(true)
+	}
+	return nil, vm.Context{}, nil, fmt.Errorf("tx index %d out of range for block %x", txIndex, block.Hash())
+}
+
 func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	return b.eth.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, deadline uint64) error {
+	return errors.New("private transactions are not supported by light clients")
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eth.txPool.RemoveTx(txHash)
 }
@@ -136,10 +171,42 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pending, queued := b.eth.txPool.Content()
+	return pending[addr], queued[addr]
+}
+
+func (b *LesApiBackend) TxPoolSenders() []common.Address {
+	pending, queued := b.eth.txPool.Content()
+
+	senders := make([]common.Address, 0, len(pending)+len(queued))
+	for addr := range pending {
+		senders = append(senders, addr)
+	}
+	for addr := range queued {
+		if _, ok := pending[addr]; !ok {
+			senders = append(senders, addr)
+		}
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+	return senders
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+// SubscribeTxLifecycleEvent returns a subscription never firing, as the light
+// transaction pool doesn't track the lifecycle of its transactions.
+func (b *LesApiBackend) SubscribeTxLifecycleEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainEvent(ch)
 }
@@ -160,6 +227,18 @@ func (b *LesApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEven
 	return b.eth.blockchain.SubscribeRemovedLogsEvent(ch)
 }
 
+func (b *LesApiBackend) TxIndexProgress() (core.TxIndexProgress, error) {
+	return core.TxIndexProgress{}, errors.New("transaction indexing is not supported by light clients")
+}
+
+func (b *LesApiBackend) RPCGasCap() uint64 {
+	return b.eth.config.RPCGasCap
+}
+
+func (b *LesApiBackend) RPCEVMTimeout() time.Duration {
+	return b.eth.config.RPCEVMTimeout
+}
+
 func (b *LesApiBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
@@ -251,6 +251,10 @@ func (self *worker) update() {
 		select {
 		// Handle ChainHeadEvent
 		case <-self.chainHeadCh:
+			// Move the consensus along first so the new work can be proposed right away
+			if handler, ok := self.engine.(consensus.Handler); ok {
+				handler.NewChainHead()
+			}
 			self.commitNewWork()
 
 		// Handle ChainSideEvent
@@ -279,7 +283,7 @@ func (self *worker) update() {
 				self.currentMu.Unlock()
 			} else {
 				// If we're mining, but nothing is being processed, wake on new transactions
-				if self.config.Clique != nil && self.config.Clique.Period == 0 {
+				if self.instantSealing() {
 					self.commitNewWork()
 				}
 			}
@@ -295,6 +299,19 @@ func (self *worker) update() {
 	}
 }
 
+// instantSealing returns whether the consensus engine sealing the next block
+// only seals blocks with transactions (0 second period), so mining needs to be
+// woken up by new transactions.
+func (self *worker) instantSealing() bool {
+	clique, bft := self.config.Clique, self.config.BFT
+	if sw := self.config.EngineSwitch; sw != nil {
+		if next := new(big.Int).Add(self.chain.CurrentBlock().Number(), common.Big1); next.Cmp(sw.Block) >= 0 {
+			clique, bft = sw.Clique, sw.BFT
+		}
+	}
+	return (clique != nil && clique.Period == 0) || (bft != nil && bft.Period == 0)
+}
+
 func (self *worker) wait() {
 	for {
 		mustCommitNewWork := true
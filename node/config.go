@@ -66,6 +66,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the backing database implementation to use for the persistent
+	// databases of the node ("leveldb" or "logdb"). If empty, the engine of any
+	// pre-existing database is used, defaulting to LevelDB for new ones.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -17,9 +17,11 @@
 package node
 
 import (
+	"path/filepath"
 	"reflect"
 
 	"github.com/vsportchain/go-vsc/accounts"
+	"github.com/vsportchain/go-vsc/core/rawdb"
 	"github.com/vsportchain/go-vsc/ethdb"
 	"github.com/vsportchain/go-vsc/event"
 	"github.com/vsportchain/go-vsc/p2p"
@@ -43,13 +45,50 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (et
 	if ctx.config.DataDir == "" {
 		return ethdb.NewMemDatabase(), nil
 	}
-	db, err := ethdb.NewLDBDatabase(ctx.config.resolvePath(name), cache, handles)
+	db, err := ethdb.NewDiskDatabase(ctx.config.DBEngine, ctx.config.resolvePath(name), cache, handles)
 	if err != nil {
 		return nil, err
 	}
 	return db, nil
 }
 
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's data directory,
+// also attaching a chain freezer to it that moves ancient chain data from the
+// database to immutable append-only files. If the node is an ephemeral one, a
+// memory database is returned.
+func (ctx *ServiceContext) OpenDatabaseWithFreezer(name string, cache int, handles int, freezer string, namespace string) (ethdb.Database, error) {
+	if ctx.config.DataDir == "" {
+		return ethdb.NewMemDatabase(), nil
+	}
+	return openDatabaseWithFreezer(ctx.config, name, cache, handles, freezer, namespace)
+}
+
+// openDatabaseWithFreezer opens the named key-value database and wraps it with a
+// freezer. The freezer is placed inside the database directory unless an
+// explicit location is requested, relative paths being resolved against the
+// node's instance directory.
+func openDatabaseWithFreezer(config *Config, name string, cache int, handles int, freezer string, namespace string) (ethdb.Database, error) {
+	root := config.resolvePath(name)
+
+	switch {
+	case freezer == "":
+		freezer = filepath.Join(root, "ancient")
+	case !filepath.IsAbs(freezer):
+		freezer = config.resolvePath(freezer)
+	}
+	kvdb, err := ethdb.NewDiskDatabase(config.DBEngine, root, cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	db, err := rawdb.NewDatabaseWithFreezer(kvdb, freezer, namespace)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 // ResolvePath resolves a user path into the data directory if that was relative
 // and if the user actually uses persistent storage. It will return an empty string
 // for emphemeral storage and the user's own input for absolute paths.
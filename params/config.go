@@ -17,6 +17,9 @@
 package params
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -82,16 +85,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the VSportChain core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -121,6 +124,39 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+	BFT    *BFTConfig    `json:"bft,omitempty"`
+
+	// Scheduled transition to another consensus engine
+	EngineSwitch *EngineSwitchConfig `json:"engineSwitch,omitempty"`
+
+	// Issuance schedule of the proof-of-work block rewards (nil = no rewards)
+	Reward *RewardConfig `json:"reward,omitempty"`
+
+	// Chain specific precompiled contracts, on top of the protocol defined ones
+	Precompiles map[common.Address]*PrecompileConfig `json:"precompiles,omitempty"`
+}
+
+// PrecompileConfig is the configuration of a chain specific precompiled contract.
+// The implementation itself is native code registered with the virtual machine
+// under the configured name.
+type PrecompileConfig struct {
+	Name  string   `json:"name"`            // Name of the registered implementation
+	Block *big.Int `json:"block,omitempty"` // Activation block (nil = never, 0 = from genesis)
+
+	Gas    *PrecompileGasConfig `json:"gas,omitempty"`    // Gas schedule overriding the implementation's own
+	Params json.RawMessage      `json:"params,omitempty"` // Implementation specific settings
+}
+
+// PrecompileGasConfig is a linear gas schedule for a precompiled contract,
+// charging a base price and a price per 32 byte word of input.
+type PrecompileGasConfig struct {
+	Base    uint64 `json:"base"`
+	PerWord uint64 `json:"perWord"`
+}
+
+// String implements the stringer interface, returning the precompile details.
+func (c *PrecompileConfig) String() string {
+	return fmt.Sprintf("{Name: %v Block: %v}", c.Name, c.Block)
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -135,6 +171,14 @@ func (c *EthashConfig) String() string {
 type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
+
+	// Governance is the address of a system contract deployed at genesis holding
+	// the list of authorized signers. If set, the signers are read from it at every
+	// epoch checkpoint instead of being voted in and out through the headers.
+	//
+	// The list is only checked against the contract by nodes executing the blocks,
+	// light clients and fast sync headers trust the list in checkpoint headers.
+	Governance *common.Address `json:"governance,omitempty"`
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -142,6 +186,100 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// BFTConfig is the consensus engine configs for byzantine fault tolerant sealing
+// with instant finality.
+type BFTConfig struct {
+	Period         uint64 `json:"period"`         // Number of seconds between blocks to enforce
+	Epoch          uint64 `json:"epoch"`          // Epoch length to reset votes and checkpoint
+	RequestTimeout uint64 `json:"requestTimeout"` // Milliseconds to wait for a round to commit before changing it
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *BFTConfig) String() string {
+	return "bft"
+}
+
+// EngineSwitchConfig schedules the transition of a running chain to another
+// consensus engine at a fork block, without the need of a new genesis.
+type EngineSwitchConfig struct {
+	Block *big.Int `json:"block"` // Block number from which on the new engine is active
+
+	// Consensus engine to switch to, exactly one needs to be set
+	Clique *CliqueConfig `json:"clique,omitempty"`
+	BFT    *BFTConfig    `json:"bft,omitempty"`
+
+	// Signers (or validators) authorized by the new engine from the fork block on
+	Signers []common.Address `json:"signers"`
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *EngineSwitchConfig) String() string {
+	switch {
+	case c.Clique != nil:
+		return fmt.Sprintf("%v@%v", c.Clique, c.Block)
+	case c.BFT != nil:
+		return fmt.Sprintf("%v@%v", c.BFT, c.Block)
+	default:
+		return fmt.Sprintf("unknown@%v", c.Block)
+	}
+}
+
+// RewardConfig is the issuance schedule of the proof-of-work block rewards. The
+// block reward starts at a fixed amount and halves every given number of blocks,
+// optionally sharing a percentage of the miner's reward with a treasury account.
+type RewardConfig struct {
+	Block           *big.Int `json:"block"`                     // Block number from which on rewards are paid
+	BlockReward     *big.Int `json:"blockReward"`               // Initial reward in wei for successfully mining a block
+	HalvingInterval uint64   `json:"halvingInterval,omitempty"` // Number of blocks after which the reward halves (0 = never)
+
+	// UncleRewards enables rewarding the miners of included uncles, as well as the
+	// miner including them, with the standard ethash formula.
+	UncleRewards bool `json:"uncleRewards,omitempty"`
+
+	Treasury      *common.Address `json:"treasury,omitempty"`      // Account receiving a share of every miner reward
+	TreasuryShare uint64          `json:"treasuryShare,omitempty"` // Percentage of the miner reward paid to the treasury (0-100)
+}
+
+// String implements the stringer interface, returning the reward schedule details.
+func (c *RewardConfig) String() string {
+	return fmt.Sprintf("{Block: %v BlockReward: %v Halving: %v Treasury: %d%%}", c.Block, c.BlockReward, c.HalvingInterval, c.TreasuryShare)
+}
+
+// BlockRewardAt returns the static reward in wei for mining the block with the
+// given number, taking the halving schedule into account.
+func (c *RewardConfig) BlockRewardAt(num *big.Int) *big.Int {
+	if c.BlockReward == nil || !isForked(c.Block, num) {
+		return new(big.Int)
+	}
+	reward := new(big.Int).Set(c.BlockReward)
+	if c.HalvingInterval == 0 {
+		return reward
+	}
+	halvings := new(big.Int).Sub(num, c.Block)
+	halvings.Div(halvings, new(big.Int).SetUint64(c.HalvingInterval))
+	if !halvings.IsUint64() || halvings.Uint64() >= uint64(reward.BitLen()) {
+		return new(big.Int)
+	}
+	return reward.Rsh(reward, uint(halvings.Uint64()))
+}
+
+// equal returns whether two reward schedules pay out the exact same rewards.
+func (c *RewardConfig) equal(other *RewardConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if !configNumEqual(c.Block, other.Block) || !configNumEqual(c.BlockReward, other.BlockReward) {
+		return false
+	}
+	if c.HalvingInterval != other.HalvingInterval || c.UncleRewards != other.UncleRewards || c.TreasuryShare != other.TreasuryShare {
+		return false
+	}
+	if c.Treasury == nil || other.Treasury == nil {
+		return c.Treasury == other.Treasury
+	}
+	return *c.Treasury == *other.Treasury
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -150,9 +288,14 @@ func (c *ChainConfig) String() string {
 		engine = c.Ethash
 	case c.Clique != nil:
 		engine = c.Clique
+	case c.BFT != nil:
+		engine = c.BFT
 	default:
 		engine = "unknown"
 	}
+	if c.EngineSwitch != nil {
+		engine = fmt.Sprintf("%v->%v", engine, c.EngineSwitch)
+	}
 	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Engine: %v}",
 		c.ChainId,
 		c.HomesteadBlock,
@@ -197,6 +340,15 @@ func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// IsPrecompileActive returns whether the chain specific precompiled contract
+// configured at addr is active at block num.
+func (c *ChainConfig) IsPrecompileActive(addr common.Address, num *big.Int) bool {
+	if precompile, ok := c.Precompiles[addr]; ok {
+		return isForked(precompile.Block, num)
+	}
+	return false
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -205,6 +357,8 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 		return GasTableHomestead
 	}
 	switch {
+	case c.IsConstantinople(num):
+		return GasTableConstantinople
 	case c.IsEIP158(num):
 		return GasTableEIP158
 	case c.IsEIP150(num):
@@ -214,6 +368,32 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	}
 }
 
+// CheckConfig checks the chain configuration for settings that can't be acted
+// upon, returning the first problem found.
+func (c *ChainConfig) CheckConfig() error {
+	if s := c.EngineSwitch; s != nil {
+		switch {
+		case s.Block == nil || s.Block.Sign() <= 0:
+			return errors.New("consensus engine switch: block must be above zero")
+		case (s.Clique == nil) == (s.BFT == nil):
+			return errors.New("consensus engine switch: exactly one of clique and bft must be set")
+		case len(s.Signers) == 0:
+			return errors.New("consensus engine switch: no signers")
+		}
+	}
+	if r := c.Reward; r != nil {
+		switch {
+		case r.Block == nil:
+			return errors.New("block rewards: no activation block")
+		case r.BlockReward == nil:
+			return errors.New("block rewards: no block reward")
+		case r.TreasuryShare > 100:
+			return fmt.Errorf("block rewards: treasury share %d%% above 100%%", r.TreasuryShare)
+		}
+	}
+	return nil
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -260,6 +440,71 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	if c.EngineSwitch != nil || newcfg.EngineSwitch != nil {
+		var stored, updated *big.Int
+		if c.EngineSwitch != nil {
+			stored = c.EngineSwitch.Block
+		}
+		if newcfg.EngineSwitch != nil {
+			updated = newcfg.EngineSwitch.Block
+		}
+		if isForkIncompatible(stored, updated, head) {
+			return newCompatError("consensus engine switch block", stored, updated)
+		}
+	}
+	if c.Reward != nil || newcfg.Reward != nil {
+		var stored, updated *big.Int
+		if c.Reward != nil {
+			stored = c.Reward.Block
+		}
+		if newcfg.Reward != nil {
+			updated = newcfg.Reward.Block
+		}
+		if isForkIncompatible(stored, updated, head) {
+			return newCompatError("block reward activation block", stored, updated)
+		}
+		if isForked(stored, head) && !c.Reward.equal(newcfg.Reward) {
+			return newCompatError("block reward schedule", stored, updated)
+		}
+	}
+	for addr, precompile := range c.Precompiles {
+		if err := checkPrecompileCompatible(addr, precompile, newcfg.Precompiles[addr], head); err != nil {
+			return err
+		}
+	}
+	for addr, precompile := range newcfg.Precompiles {
+		if _, ok := c.Precompiles[addr]; !ok {
+			if err := checkPrecompileCompatible(addr, nil, precompile, head); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkPrecompileCompatible checks whether the precompiled contract configured at
+// addr may be changed from stored to newcfg (either may be nil) without altering
+// the already imported chain.
+func checkPrecompileCompatible(addr common.Address, stored, newcfg *PrecompileConfig, head *big.Int) *ConfigCompatError {
+	var s1, s2 *big.Int
+	if stored != nil {
+		s1 = stored.Block
+	}
+	if newcfg != nil {
+		s2 = newcfg.Block
+	}
+	what := fmt.Sprintf("precompile %x activation block", addr)
+	if isForkIncompatible(s1, s2, head) {
+		return newCompatError(what, s1, s2)
+	}
+	if isForked(s1, head) {
+		// Compare the encoded forms, the raw params may differ in formatting only
+		enc1, _ := json.Marshal(stored)
+		enc2, _ := json.Marshal(newcfg)
+		if !bytes.Equal(enc1, enc2) {
+			return newCompatError(fmt.Sprintf("precompile %x configuration", addr), s1, s2)
+		}
+	}
 	return nil
 }
 
@@ -326,7 +571,7 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainId                                   *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
-	IsByzantium                               bool
+	IsByzantium, IsConstantinople             bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -334,5 +579,5 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num), IsConstantinople: c.IsConstantinople(num)}
 }
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/vsportchain/go-vsc/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,85 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(10), BlockReward: big.NewInt(1)}},
+			new:     &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(10), BlockReward: big.NewInt(2)}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(10), BlockReward: big.NewInt(1)}},
+			new:    &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(10), BlockReward: big.NewInt(2)}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "block reward schedule",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Clique: &CliqueConfig{}}},
+			new:     &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(20), Clique: &CliqueConfig{}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Clique: &CliqueConfig{}}},
+			new:    &ChainConfig{},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "consensus engine switch block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(10)}}},
+			new:     &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(20)}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(10)}}},
+			new:    &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(20)}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0100000000000000000000000000000000000000 activation block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(10)}}},
+			new:    &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "odds", Block: big.NewInt(10)}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0100000000000000000000000000000000000000 configuration",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(10)}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0100000000000000000000000000000000000000 activation block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{Precompiles: map[common.Address]*PrecompileConfig{{0x01, 0x00}: {Name: "ed25519", Block: big.NewInt(20)}}},
+			head:    15,
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +160,46 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockRewardAt(t *testing.T) {
+	config := &RewardConfig{Block: big.NewInt(100), BlockReward: big.NewInt(1000), HalvingInterval: 50}
+
+	tests := []struct {
+		number int64
+		want   int64
+	}{
+		{0, 0}, {99, 0}, {100, 1000}, {149, 1000}, {150, 500}, {200, 250}, {599, 1}, {600, 0}, {1000000, 0},
+	}
+	for _, tt := range tests {
+		if have := config.BlockRewardAt(big.NewInt(tt.number)); have.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("block %d: reward mismatch: have %v, want %v", tt.number, have, tt.want)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	signers := []common.Address{{0x01}}
+
+	tests := []struct {
+		config  *ChainConfig
+		wantErr bool
+	}{
+		{config: &ChainConfig{}},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Clique: &CliqueConfig{}, Signers: signers}}},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), BFT: &BFTConfig{}, Signers: signers}}},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Clique: &CliqueConfig{}, Signers: signers}}, wantErr: true},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(0), Clique: &CliqueConfig{}, Signers: signers}}, wantErr: true},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Signers: signers}}, wantErr: true},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Clique: &CliqueConfig{}, BFT: &BFTConfig{}, Signers: signers}}, wantErr: true},
+		{config: &ChainConfig{EngineSwitch: &EngineSwitchConfig{Block: big.NewInt(10), Clique: &CliqueConfig{}}}, wantErr: true},
+		{config: &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(0), BlockReward: big.NewInt(1), TreasuryShare: 100}}},
+		{config: &ChainConfig{Reward: &RewardConfig{BlockReward: big.NewInt(1)}}, wantErr: true},
+		{config: &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(0)}}, wantErr: true},
+		{config: &ChainConfig{Reward: &RewardConfig{Block: big.NewInt(0), BlockReward: big.NewInt(1), TreasuryShare: 101}}, wantErr: true},
+	}
+	for i, test := range tests {
+		if err := test.config.CheckConfig(); (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+	}
+}
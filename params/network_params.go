@@ -23,4 +23,10 @@ const (
 	// BloomBitsBlocks is the number of blocks a single bloom bit section vector
 	// contains.
 	BloomBitsBlocks uint64 = 4096
+
+	// ImmutabilityThreshold is the number of blocks after which a chain segment is
+	// considered immutable (i.e. soft finality). It is used as the default number
+	// of recent blocks kept in the key-value store before chain data is moved into
+	// the ancient store.
+	ImmutabilityThreshold uint64 = 90000
 )
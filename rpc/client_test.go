@@ -49,6 +49,42 @@ func TestClientRequest(t *testing.T) {
 	}
 }
 
+type DataErrorService struct{}
+
+type testDataError struct{}
+
+func (testDataError) Error() string          { return "testError" }
+func (testDataError) ErrorCode() int         { return 444 }
+func (testDataError) ErrorData() interface{} { return "testData" }
+
+func (s *DataErrorService) ReturnError() error {
+	return testDataError{}
+}
+
+func TestClientErrorData(t *testing.T) {
+	server := newTestServer("service", new(DataErrorService))
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var resp interface{}
+	err := client.Call(&resp, "service_returnError")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// Check code and data.
+	if e, ok := err.(DataError); !ok {
+		t.Fatalf("client did not return DataError: %v", err)
+	} else {
+		if e.ErrorCode() != (testDataError{}).ErrorCode() {
+			t.Fatalf("wrong error code %d, want %d", e.ErrorCode(), testDataError{}.ErrorCode())
+		}
+		if !reflect.DeepEqual(e.ErrorData(), testDataError{}.ErrorData()) {
+			t.Fatalf("wrong error data %#v, want %#v", e.ErrorData(), testDataError{}.ErrorData())
+		}
+	}
+}
+
 func TestClientBatchRequest(t *testing.T) {
 	server := newTestServer("service", new(Service))
 	defer server.Stop()
@@ -92,6 +92,13 @@ type Error interface {
 	ErrorCode() int // returns the code
 }
 
+// DataError wraps RPC errors, which contain some data in addition to the message
+// and the code.
+type DataError interface {
+	Error
+	ErrorData() interface{} // returns the error data
+}
+
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
 // a RPC session. Implementations must be go-routine safe since the codec can be called in
 // multiple go-routines concurrently.